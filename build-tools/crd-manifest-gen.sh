@@ -0,0 +1,27 @@
+#!/bin/bash
+
+# Generates the CRD manifests shipped in the docs and in the Helm chart from the
+# kubebuilder markers of the types in config/apis/cis, expects to be run from
+# the root of k8s-bigip-ctlr
+
+set -e
+
+CONTROLLER_GEN=${CONTROLLER_GEN:-"go run sigs.k8s.io/controller-tools/cmd/controller-gen@v0.17.3"}
+CRD_DIR=$(mktemp -d)
+trap 'rm -rf $CRD_DIR' EXIT
+
+$CONTROLLER_GEN crd:crdVersions=v1 paths=./config/apis/cis/... output:crd:dir=$CRD_DIR
+
+# The CRDs in the order of the manifest
+CRDS="virtualservers tlsprofiles transportservers externaldnses ingresslinks policies deployconfigs"
+if [ $(ls $CRD_DIR | wc -l) -ne $(echo $CRDS | wc -w) ]; then
+	echo "Generated CRDs $(ls $CRD_DIR) do not match the CRDs of the manifest $CRDS"
+	exit 1
+fi
+
+CRD_MANIFEST=docs/config_examples/customResourceDefinitions/customresourcedefinitions.yml
+for crd in $CRDS; do
+	cat $CRD_DIR/cis.f5.com_$crd.yaml
+done > $CRD_MANIFEST
+cp $CRD_MANIFEST helm-charts/f5-bigip-ctlr/crds/f5-bigip-ctlr-customresourcedefinitions.yml
+cp $CRD_DIR/cis.f5.com_ingresslinks.yaml docs/config_examples/customResource/IngressLink/ingresslink-customresourcedefinition.yaml
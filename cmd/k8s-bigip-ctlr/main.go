@@ -58,6 +58,7 @@ import (
 	"golang.org/x/crypto/ssh/terminal"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -120,18 +121,22 @@ var (
 	controllerMode     *string
 	defaultRouteDomain *int
 
-	pythonBaseDir    *string
-	logLevel         *string
-	ccclLogLevel     *string
-	logFile          *string
-	verifyInterval   *int
-	nodePollInterval *int
-	syncInterval     *int
-	printVersion     *bool
-	httpAddress      *string
-	dgPath           string
-	disableTeems     *bool
-	enableIPV6       *bool
+	pythonBaseDir      *string
+	logLevel           *string
+	ccclLogLevel       *string
+	logFile            *string
+	verifyInterval     *int
+	nodePollInterval   *int
+	nodeUpdateDebounce *int
+	nodeExcludeTaint   *string
+	nodeExcludeLabel   *string
+	nodeDrainTimeout   *int
+	syncInterval       *int
+	printVersion       *bool
+	httpAddress        *string
+	dgPath             string
+	disableTeems       *bool
+	enableIPV6         *bool
 
 	namespaces             *[]string
 	useNodeInternal        *bool
@@ -151,6 +156,17 @@ var (
 	schemaLocal            *string
 	manageIngressClassOnly *bool
 	ingressClass           *string
+	podProbeHealthMonitor  *bool
+	deployConfig           *string
+	multiClusterSecrets    *[]string
+	localClusterRatio      *int
+	localClusterPriority   *int
+	webhookServerAddress   *string
+	webhookCertFile        *string
+	webhookKeyFile         *string
+	resourceWorkers        *int
+	resourceQueueQPS       *int
+	resourceQueueBurst     *int
 
 	bigIPURL                  *string
 	bigIPUsername             *string
@@ -165,6 +181,11 @@ var (
 	ciphers                   *string
 	trustedCerts              *string
 	as3PostDelay              *int
+	as3PostQuietPeriod        *int
+	as3PostMaxDelay           *int
+	declarationCacheDir       *string
+	driftCheckInterval        *int
+	driftPolicy               *string
 
 	trustedCertsCfgmap     *string
 	agent                  *string
@@ -177,6 +198,11 @@ var (
 	vxlanMode        string
 	openshiftSDNName *string
 	flannelName      *string
+	orchestrationCNI *string
+	vxlanTunnelName  *string
+
+	staticRoutingMode             *bool
+	staticRouteNodeCIDRAnnotation *string
 
 	routeVserverAddr *string
 	routeLabel       *string
@@ -187,19 +213,25 @@ var (
 
 	routeSpecConfigmap *string
 
-	gtmBigIPURL      *string
-	gtmBigIPUsername *string
-	gtmBigIPPassword *string
-	gtmCredsDir      *string
+	gtmBigIPURL       *string
+	gtmBigIPUsername  *string
+	gtmBigIPPassword  *string
+	gtmCredsDir       *string
+	gtmDataCenterName *string
+	gtmServerName     *string
+	gtmServerAddress  *string
 
 	// package variables
 	isNodePort         bool
 	watchAllNamespaces bool
 	vxlanName          string
 	kubeClient         kubernetes.Interface
+	dynamicClient      dynamic.Interface
 	agRspChan          chan interface{}
 	eventChan          chan interface{}
 	configWriter       writer.Writer
+	netWriter          writer.Writer
+	routeWriter        writer.Writer
 	k8sVersion         string
 )
 
@@ -235,7 +267,16 @@ func _init() {
 	verifyInterval = globalFlags.Int("verify-interval", 30,
 		"Optional, interval (in seconds) at which to verify the BIG-IP configuration.")
 	nodePollInterval = globalFlags.Int("node-poll-interval", 30,
-		"Optional, interval (in seconds) at which to poll for cluster nodes.")
+		"Optional, interval (in seconds) at which to resync the watched cluster nodes.")
+	nodeUpdateDebounce = globalFlags.Int("node-update-debounce", 1000,
+		"Optional, time (in milliseconds) to coalesce the changes of cluster nodes before processing them.")
+	nodeExcludeTaint = globalFlags.String("node-exclude-taint", "",
+		"Optional, taint (<key> or <key>=<value>) of the cluster nodes to exclude from the NodePort pools.")
+	nodeExcludeLabel = globalFlags.String("node-exclude-label", "",
+		"Optional, label (<key> or <key>=<value>) of the cluster nodes to exclude from the NodePort pools.")
+	nodeDrainTimeout = globalFlags.Int("node-drain-timeout", 30,
+		"Optional, time (in seconds) to keep the excluded, cordoned or NotReady nodes disabled in the "+
+			"NodePort pools before removing them.")
 	syncInterval = globalFlags.Int("periodic-sync-interval", 30,
 		"Optional, interval (in seconds) at which to queue resources.")
 	printVersion = globalFlags.Bool("version", false,
@@ -277,7 +318,24 @@ func _init() {
 	ipam = bigIPFlags.Bool("ipam", false,
 		"Optional, when set to true, enable ipam feature for CRD.")
 	as3PostDelay = bigIPFlags.Int("as3-post-delay", 0,
-		"Optional, time (in seconds) that CIS waits to post the available AS3 declaration.")
+		"Optional, time (in seconds) that CIS waits to post the available AS3 declaration. "+
+			"Deprecated for the custom resources in favor of as3-post-quiet-period.")
+	as3PostQuietPeriod = bigIPFlags.Int("as3-post-quiet-period", 500,
+		"Optional, time (in milliseconds) without resource events that CIS waits to post the AS3 declaration "+
+			"of the custom resources. Deletions and TLS changes are posted right away.")
+	as3PostMaxDelay = bigIPFlags.Int("as3-post-max-delay", 5,
+		"Optional, maximum time (in seconds) that CIS delays the AS3 declaration of the custom resources "+
+			"during continuous resource events.")
+	declarationCacheDir = bigIPFlags.String("declaration-cache-dir", "",
+		"Optional, directory on a persistent volume where CIS saves the hashes of the AS3 tenant declarations posted to BIG-IP. "+
+			"After a restart, a tenant is not posted again when its first declaration matches its last post.")
+	driftCheckInterval = bigIPFlags.Int("drift-check-interval", 0,
+		"Optional, interval (in seconds) at which CIS posts the AS3 tenants it posted again as dry runs, which "+
+			"compare them with the configuration of BIG-IP, to detect the changes made on BIG-IP with AS3, the GUI "+
+			"or tmsh. Disabled by default.")
+	driftPolicy = bigIPFlags.String("drift-policy", controller.DriftPolicyReport,
+		"Optional, action on the tenants drifted on BIG-IP. 'report' logs and reports the drift in the metrics, "+
+			"'repost' also posts the declarations of the drifted tenants again.")
 	logAS3Response = bigIPFlags.Bool("log-as3-response", false,
 		"Optional, when set to true, add the body of AS3 API response in Controller logs.")
 	shareNodes = bigIPFlags.Bool("share-nodes", false,
@@ -293,7 +351,8 @@ func _init() {
 	agent = bigIPFlags.String("agent", "as3",
 		"Optional, when set to cccl, orchestration agent will be CCCL instead of AS3")
 	ccclGtmAgent = bigIPFlags.Bool("cccl-gtm-agent", true,
-		"Optional, Option to configure GTM objects using CCCL or AS3 Agent. Default Agent is CCCL.")
+		"Optional, Option to configure GTM objects using CCCL or AS3 Agent. Default Agent is CCCL. "+
+			"When migrating to the AS3 Agent, remove the GTM objects created by CCCL from the GTM Big-IP.")
 	overrideAS3UsageStr := "Optional, provide Namespace and Name of that ConfigMap as <namespace>/<configmap-name>." +
 		"The JSON key/values from this ConfigMap will override key/values from internally generated AS3 declaration."
 	overriderAS3CfgmapName = bigIPFlags.String("override-as3-declaration", "", overrideAS3UsageStr)
@@ -355,6 +414,34 @@ func _init() {
 			"resources that belong to its class - i.e. have the annotation `kubernetes.io/ingress.class` equal to the class."+
 			"Additionally, the Ingress controller processes Ingress resources that do not have that annotation,"+
 			"which can be disabled by setting the `-manage-ingress-class-only` flag")
+	podProbeHealthMonitor = kubeFlags.Bool("pod-probe-health-monitor", false,
+		"Optional, default `false`. Derive pool health monitors from the readinessProbe of the pods "+
+			"backing the pool when no monitor is defined in the custom resource.")
+	deployConfig = kubeFlags.String("deploy-config", "",
+		"Optional, name of the cluster scoped DeployConfig CR holding the defaults of the virtuals, "+
+			"such as TLS baseline, SNAT, HTTP/HTTPS ports, allowed VLANs and log profiles.")
+	multiClusterSecrets = kubeFlags.StringArray("multi-cluster-secret", []string{},
+		"Optional, <namespace>/<name> of a secret holding the kubeconfig of a remote cluster. "+
+			"Pool members of the same Service in the remote clusters are merged into the pools of the virtuals. "+
+			"Can be specified multiple times.")
+	localClusterRatio = kubeFlags.Int("local-cluster-ratio", 0,
+		"Optional, ratio of the pool members of the local cluster when remote clusters are configured.")
+	localClusterPriority = kubeFlags.Int("local-cluster-priority", 0,
+		"Optional, priority group of the pool members of the local cluster when remote clusters are configured.")
+	webhookServerAddress = kubeFlags.String("webhook-server-address", "",
+		"Optional, address (e.g. :8443) of the validating admission webhook of the custom resources. "+
+			"The webhook is served over TLS with the certificate and key of --webhook-cert-file and --webhook-key-file.")
+	webhookCertFile = kubeFlags.String("webhook-cert-file", "/etc/webhook/certs/tls.crt",
+		"Optional, certificate of the validating admission webhook.")
+	webhookKeyFile = kubeFlags.String("webhook-key-file", "/etc/webhook/certs/tls.key",
+		"Optional, private key of the validating admission webhook.")
+	resourceWorkers = kubeFlags.Int("resource-workers", 1,
+		"Optional, number of workers processing the resources in parallel. "+
+			"The events of a resource are processed in order.")
+	resourceQueueQPS = kubeFlags.Int("resource-queue-qps", 10,
+		"Optional, rate (per second) at which the resources are requeued for processing.")
+	resourceQueueBurst = kubeFlags.Int("resource-queue-burst", 100,
+		"Optional, burst of the resources requeued for processing above --resource-queue-qps.")
 
 	// If the flag is specified with no argument, default to LOOKUP
 	kubeFlags.Lookup("resolve-ingress-names").NoOptDefVal = "LOOKUP"
@@ -376,6 +463,17 @@ func _init() {
 	flannelName = vxlanFlags.String("flannel-name", "",
 		"Must be provided for BigIP Flannel integration, "+
 			"full path of BigIP Flannel VxLAN Tunnel")
+	orchestrationCNI = vxlanFlags.String("orchestration-cni", "",
+		"Optional, CNI of the VxLAN overlay for BigIP Cilium or Calico integration, "+
+			"'cilium' or 'calico'. vxlan-tunnel-name must be provided with it")
+	vxlanTunnelName = vxlanFlags.String("vxlan-tunnel-name", "",
+		"Optional, full path of BigIP VxLAN Tunnel of the orchestration-cni")
+	staticRoutingMode = vxlanFlags.Bool("static-routing-mode", false,
+		"Optional, maintain static routes on BigIP to the pod CIDRs of the nodes in cluster mode, "+
+			"in place of a VxLAN tunnel")
+	staticRouteNodeCIDRAnnotation = vxlanFlags.String("static-route-node-cidr-annotation", "",
+		"Optional, node annotation with the comma separated pod CIDRs of the node in static routing mode, "+
+			"in addition to spec.podCIDRs and the Cilium annotations")
 
 	vxlanFlags.Usage = func() {
 		fmt.Fprintf(os.Stderr, "  Openshift SDN:\n%s\n", vxlanFlags.FlagUsagesWrapped(width))
@@ -415,6 +513,13 @@ func _init() {
 	gtmCredsDir = gtmBigIPFlags.String("gtm-credentials-directory", "",
 		"Optional, directory that contains the GTM BIG-IP username, password, and/or "+
 			"url files. To be used instead of username, password, and/or url arguments.")
+	gtmDataCenterName = gtmBigIPFlags.String("gtm-data-center-name", "",
+		"Optional, name of the GSLB data center of the BIG-IP to be declared in /Common/Shared on the GTM Big-IP. "+
+			"Requires gtm-bigip-url, gtm-server-name and cccl-gtm-agent=false.")
+	gtmServerName = gtmBigIPFlags.String("gtm-server-name", "",
+		"Optional, name of the GSLB server of the BIG-IP to be declared in /Common/Shared on the GTM Big-IP.")
+	gtmServerAddress = gtmBigIPFlags.String("gtm-server-address", "",
+		"Optional, address of the GSLB server device. Defaults to the address of bigip-url.")
 	gtmBigIPFlags.Usage = func() {
 		fmt.Fprintf(os.Stderr, "  GTM:\n%s\n", gtmBigIPFlags.FlagUsagesWrapped(width))
 	}
@@ -539,6 +644,60 @@ func verifyArgs() error {
 		vxlanName = *flannelName
 	}
 
+	if flags.Changed("orchestration-cni") {
+		if *orchestrationCNI != vxlan.CNICilium && *orchestrationCNI != vxlan.CNICalico {
+			return fmt.Errorf("'%v' is not a valid orchestration-cni", *orchestrationCNI)
+		}
+		if flags.Changed("openshift-sdn-name") || flags.Changed("flannel-name") {
+			return fmt.Errorf("Cannot have orchestration-cni with openshift-sdn-name or flannel-name specified.")
+		}
+		if len(*vxlanTunnelName) == 0 {
+			return fmt.Errorf("Missing required parameter vxlan-tunnel-name")
+		}
+		if isNodePort {
+			return fmt.Errorf("Cannot run NodePort mode while supplying orchestration-cni. " +
+				"Must be in Cluster mode if using VXLAN.")
+		}
+		vxlanMode = "maintain"
+		vxlanName = *vxlanTunnelName
+	}
+
+	if *staticRoutingMode {
+		if isNodePort {
+			return fmt.Errorf("Cannot run NodePort mode with static-routing-mode. " +
+				"Must be in Cluster mode if using static routes.")
+		}
+		if len(vxlanMode) > 0 {
+			return fmt.Errorf("Cannot have both static-routing-mode and a VxLAN tunnel specified.")
+		}
+	}
+
+	if *resourceWorkers < 1 {
+		return fmt.Errorf("resource-workers must be at least 1")
+	}
+	if *resourceQueueQPS < 1 || *resourceQueueBurst < 1 {
+		return fmt.Errorf("resource-queue-qps and resource-queue-burst must be at least 1")
+	}
+	if *as3PostQuietPeriod < 0 || *as3PostMaxDelay < 0 {
+		return fmt.Errorf("as3-post-quiet-period and as3-post-max-delay must not be negative")
+	}
+	if *driftCheckInterval < 0 {
+		return fmt.Errorf("drift-check-interval must not be negative")
+	}
+	if *driftPolicy != controller.DriftPolicyReport && *driftPolicy != controller.DriftPolicyRepost {
+		return fmt.Errorf("'%v' is not a valid drift policy", *driftPolicy)
+	}
+	if *declarationCacheDir != "" {
+		if info, err := os.Stat(*declarationCacheDir); err != nil || !info.IsDir() {
+			return fmt.Errorf("declaration-cache-dir %v is not a directory", *declarationCacheDir)
+		}
+	}
+	if (*customResourceMode || *controllerMode != "") &&
+		flags.Changed("as3-post-delay") && !flags.Changed("as3-post-quiet-period") {
+		log.Warningf("[INIT] as3-post-delay is deprecated for the custom resources, use as3-post-quiet-period")
+		*as3PostQuietPeriod = *as3PostDelay * 1000
+	}
+
 	if *hubMode && !(*manageConfigMaps) {
 		return fmt.Errorf("Hubmode is supported only for configmaps")
 	}
@@ -696,12 +855,14 @@ func setupNodePolling(
 		if slashPos != -1 {
 			tunnelName = cleanPath[slashPos+1:]
 		}
-		vxMgr, err := vxlan.NewVxlanMgr(
+		vxMgr, err := vxlan.NewVxlanMgrForCNI(
 			vxlanMode,
 			tunnelName,
+			*orchestrationCNI,
 			appMgr.UseNodeInternal(),
-			getConfigWriter(),
+			getNetWriter(),
 			eventChanl,
+			dynamicClient,
 		)
 		if nil != err {
 			return fmt.Errorf("error creating vxlan manager: %v", err)
@@ -713,11 +874,36 @@ func setupNodePolling(
 			return fmt.Errorf("error registering node update listener for vxlan mode: %v",
 				err)
 		}
+		if registrar, ok := np.(pollers.TriggerRegistrar); ok && *orchestrationCNI == vxlan.CNICilium {
+			// The CiliumNodes are cached, and their changes update the fdb records
+			err = registrar.RegisterTrigger(vxMgr.NewCiliumNodeInformer)
+			if nil != err {
+				return fmt.Errorf("error registering CiliumNode trigger for vxlan mode: %v", err)
+			}
+		}
 		if eventChanl != nil {
 			vxMgr.ProcessAppmanagerEvents(kubeClient)
 		}
 	}
 
+	if *staticRoutingMode {
+		srMgr, err := vxlan.NewStaticRouteMgr(
+			appMgr.UseNodeInternal(),
+			*staticRouteNodeCIDRAnnotation,
+			routeWriter,
+		)
+		if nil != err {
+			return fmt.Errorf("error creating static route manager: %v", err)
+		}
+
+		// Register srMgr to watch for node updates to process static routes
+		err = np.RegisterListener(srMgr.ProcessNodeUpdate)
+		if nil != err {
+			return fmt.Errorf("error registering node update listener for static routing mode: %v",
+				err)
+		}
+	}
+
 	return nil
 }
 
@@ -803,7 +989,6 @@ func initController(
 		BIGIPURL:      *bigIPURL,
 		TrustedCerts:  "",
 		SSLInsecure:   true,
-		AS3PostDelay:  *as3PostDelay,
 		LogResponse:   *logAS3Response,
 	}
 
@@ -811,20 +996,29 @@ func initController(
 		GTMBigIpUsername: *gtmBigIPUsername,
 		GTMBigIpPassword: *gtmBigIPPassword,
 		GTMBigIpUrl:      *gtmBigIPURL,
+		DataCenterName:   *gtmDataCenterName,
+		ServerName:       *gtmServerName,
+		ServerAddress:    *gtmServerAddress,
 	}
 
 	agentParams := controller.AgentParams{
-		PostParams:     postMgrParams,
-		GTMParams:      GtmParams,
-		Partition:      (*bigIPPartitions)[0],
-		LogLevel:       *logLevel,
-		VerifyInterval: *verifyInterval,
-		VXLANName:      vxlanName,
-		PythonBaseDir:  *pythonBaseDir,
-		UserAgent:      getUserAgentInfo(),
-		HttpAddress:    *httpAddress,
-		EnableIPV6:     *enableIPV6,
-		CCCLGTMAgent:   *ccclGtmAgent,
+		PostParams:          postMgrParams,
+		GTMParams:           GtmParams,
+		Partition:           (*bigIPPartitions)[0],
+		LogLevel:            *logLevel,
+		VerifyInterval:      *verifyInterval,
+		VXLANName:           vxlanName,
+		PythonBaseDir:       *pythonBaseDir,
+		UserAgent:           getUserAgentInfo(),
+		HttpAddress:         *httpAddress,
+		EnableIPV6:          *enableIPV6,
+		CCCLGTMAgent:        *ccclGtmAgent,
+		SchemaLocal:         *schemaLocal,
+		PostQuietPeriod:     *as3PostQuietPeriod,
+		PostMaxDelay:        *as3PostMaxDelay,
+		DeclarationCacheDir: *declarationCacheDir,
+		DriftCheckInterval:  *driftCheckInterval,
+		DriftPolicy:         *driftPolicy,
 	}
 
 	// When CIS is configured in OCP cluster mode disable ARP in globalSection
@@ -846,6 +1040,10 @@ func initController(
 			VXLANMode:          vxlanMode,
 			UseNodeInternal:    *useNodeInternal,
 			NodePollInterval:   *nodePollInterval,
+			NodeUpdateDebounce: *nodeUpdateDebounce,
+			NodeExcludeTaint:   *nodeExcludeTaint,
+			NodeExcludeLabel:   *nodeExcludeLabel,
+			NodeDrainTimeout:   *nodeDrainTimeout,
 			NodeLabelSelector:  *nodeLabelSelector,
 			IPAM:               *ipam,
 			ShareNodes:         *shareNodes,
@@ -853,6 +1051,23 @@ func initController(
 			Mode:               controller.ControllerMode(*controllerMode),
 			RouteSpecConfigmap: *routeSpecConfigmap,
 			RouteLabel:         *routeLabel,
+
+			PodProbeHealthMonitor: *podProbeHealthMonitor,
+			DeployConfig:          *deployConfig,
+			MultiClusterSecrets:   *multiClusterSecrets,
+			LocalClusterRatio:     int32(*localClusterRatio),
+			LocalClusterPriority:  int32(*localClusterPriority),
+			OverrideAS3ConfigMap:  *overriderAS3CfgmapName,
+			WebhookServerAddress:  *webhookServerAddress,
+			WebhookCertFile:       *webhookCertFile,
+			WebhookKeyFile:        *webhookKeyFile,
+
+			StaticRoutingMode:             *staticRoutingMode,
+			StaticRouteNodeCIDRAnnotation: *staticRouteNodeCIDRAnnotation,
+			OrchestrationCNI:              *orchestrationCNI,
+			ResourceWorkers:               *resourceWorkers,
+			ResourceQueueQPS:              *resourceQueueQPS,
+			ResourceQueueBurst:            *resourceQueueBurst,
 		},
 	)
 
@@ -947,6 +1162,11 @@ func main() {
 		log.Fatalf("[INIT] error connecting to the client: %v", err)
 		os.Exit(1)
 	}
+	dynamicClient, err = dynamic.NewForConfig(config)
+	if err != nil {
+		log.Fatalf("[INIT] error connecting to the dynamic client: %v", err)
+		os.Exit(1)
+	}
 	td := &teem.TeemsData{
 		CisVersion:      version,
 		Agent:           *agent,
@@ -1041,24 +1261,40 @@ func main() {
 		BigIPPartitions: *bigIPPartitions,
 	}
 
-	subPidCh, err := startPythonDriver(getConfigWriter(), gs, bs, *pythonBaseDir)
-	if nil != err {
-		log.Fatalf("Could not initialize subprocess configuration: %v", err)
-	}
-	subPid := <-subPidCh
-	defer func(pid int) {
-		if 0 != pid {
-			var proc *os.Process
-			proc, err = os.FindProcess(pid)
-			if nil != err {
-				log.Warningf("Failed to find sub-process on exit: %v", err)
-			}
-			err = proc.Signal(os.Interrupt)
-			if nil != err {
-				log.Warningf("Could not stop sub-process on exit: %d - %v", pid, err)
-			}
+	// The static routes are always configured from CIS, the Python driver does not support them
+	routeWriter = vxlan.NewBigIPNetWriter(vxlan.NetParams{
+		BIGIPUsername: *bigIPUsername,
+		BIGIPPassword: *bigIPPassword,
+		BIGIPURL:      *bigIPURL,
+		TrustedCerts:  getBIGIPTrustedCerts(),
+		SSLInsecure:   *sslInsecure,
+		Partition:     vxlanPartition,
+		DisableARP:    disableARP,
+	})
+	// The Python driver is only needed by the CCCL agent, VXLAN FDB and ARP are configured from CIS
+	var subPid int
+	if *agent == cisAgent.CCCLAgent {
+		subPidCh, err := startPythonDriver(getConfigWriter(), gs, bs, *pythonBaseDir)
+		if nil != err {
+			log.Fatalf("Could not initialize subprocess configuration: %v", err)
 		}
-	}(subPid)
+		subPid = <-subPidCh
+		defer func(pid int) {
+			if 0 != pid {
+				proc, err := os.FindProcess(pid)
+				if nil != err {
+					log.Warningf("Failed to find sub-process on exit: %v", err)
+				}
+				err = proc.Signal(os.Interrupt)
+				if nil != err {
+					log.Warningf("Could not stop sub-process on exit: %d - %v", pid, err)
+				}
+			}
+		}(subPid)
+		netWriter = getConfigWriter()
+	} else {
+		netWriter = routeWriter
+	}
 
 	if _, isSet := os.LookupEnv("SCALE_PERF_ENABLE"); isSet {
 		now := time.Now()
@@ -1111,7 +1347,12 @@ func main() {
 	appMgr.TeemData = td
 	GetNamespaces(appMgr)
 	intervalFactor := time.Duration(*nodePollInterval)
-	np := pollers.NewNodePoller(appMgrParms.KubeClient, intervalFactor*time.Second, *nodeLabelSelector)
+	np := pollers.NewNodeInformer(
+		appMgrParms.KubeClient,
+		intervalFactor*time.Second,
+		time.Duration(*nodeUpdateDebounce)*time.Millisecond,
+		*nodeLabelSelector,
+	)
 	err = setupNodePolling(appMgr, np, eventChan, appMgrParms.KubeClient)
 	if nil != err {
 		log.Fatalf("Required polling utility for node updates failed setup: %v",
@@ -1158,6 +1399,14 @@ func getConfigWriter() writer.Writer {
 	return configWriter
 }
 
+// getNetWriter returns the writer of the VXLAN FDB records and ARP entries
+func getNetWriter() writer.Writer {
+	if netWriter == nil {
+		return getConfigWriter()
+	}
+	return netWriter
+}
+
 func getRouteConfig() appmanager.RouteConfig {
 	return appmanager.RouteConfig{
 		RouteVSAddr: *routeVserverAddr,
@@ -572,6 +572,44 @@ var _ = Describe("Main Tests", func() {
 			Expect(err.Error()).To(Equal("Cannot have both openshift-sdn-name and flannel-name specified."))
 		})
 
+		It("handles orchestration cni flags", func() {
+			defer _init()
+			os.Args = []string{
+				"./bin/k8s-bigip-ctlr",
+				"--namespace=testing",
+				"--bigip-partition=velcro1",
+				"--bigip-password=admin",
+				"--bigip-url=bigip.example.com",
+				"--bigip-username=admin",
+				"--pool-member-type=cluster",
+				"--orchestration-cni=calico",
+				"--vxlan-tunnel-name=/Common/vxlan-calico",
+			}
+
+			flags.Parse(os.Args)
+			err := verifyArgs()
+			Expect(err).To(BeNil())
+			Expect(vxlanMode).To(Equal("maintain"))
+			Expect(vxlanName).To(Equal("/Common/vxlan-calico"))
+
+			_init()
+			os.Args = []string{
+				"./bin/k8s-bigip-ctlr",
+				"--namespace=testing",
+				"--bigip-partition=velcro1",
+				"--bigip-password=admin",
+				"--bigip-url=bigip.example.com",
+				"--bigip-username=admin",
+				"--pool-member-type=cluster",
+				"--orchestration-cni=cilium",
+			}
+
+			flags.Parse(os.Args)
+			err = verifyArgs()
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(Equal("Missing required parameter vxlan-tunnel-name"))
+		})
+
 		It("handles empty vxlan flags", func() {
 			defer _init()
 			os.Args = []string{
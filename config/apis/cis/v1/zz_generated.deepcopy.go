@@ -26,6 +26,43 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoExternalDNSSpec) DeepCopyInto(out *AutoExternalDNSSpec) {
+	*out = *in
+	if in.Monitors != nil {
+		in, out := &in.Monitors, &out.Monitors
+		*out = make([]Monitor, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoExternalDNSSpec.
+func (in *AutoExternalDNSSpec) DeepCopy() *AutoExternalDNSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoExternalDNSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSPersistence) DeepCopyInto(out *DNSPersistence) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSPersistence.
+func (in *DNSPersistence) DeepCopy() *DNSPersistence {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSPersistence)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DNSPool) DeepCopyInto(out *DNSPool) {
 	*out = *in
@@ -35,6 +72,16 @@ func (in *DNSPool) DeepCopyInto(out *DNSPool) {
 		*out = make([]Monitor, len(*in))
 		copy(*out, *in)
 	}
+	if in.VirtualServers != nil {
+		in, out := &in.VirtualServers, &out.VirtualServers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]DNSTarget, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -48,6 +95,125 @@ func (in *DNSPool) DeepCopy() *DNSPool {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSTarget) DeepCopyInto(out *DNSTarget) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSTarget.
+func (in *DNSTarget) DeepCopy() *DNSTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefaultTLSSpec) DeepCopyInto(out *DefaultTLSSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultTLSSpec.
+func (in *DefaultTLSSpec) DeepCopy() *DefaultTLSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DefaultTLSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeployConfig) DeepCopyInto(out *DeployConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeployConfig.
+func (in *DeployConfig) DeepCopy() *DeployConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DeployConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeployConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeployConfigList) DeepCopyInto(out *DeployConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DeployConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeployConfigList.
+func (in *DeployConfigList) DeepCopy() *DeployConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(DeployConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeployConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeployConfigSpec) DeepCopyInto(out *DeployConfigSpec) {
+	*out = *in
+	out.TLS = in.TLS
+	if in.AllowVlans != nil {
+		in, out := &in.AllowVlans, &out.AllowVlans
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LogProfiles != nil {
+		in, out := &in.LogProfiles, &out.LogProfiles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeployConfigSpec.
+func (in *DeployConfigSpec) DeepCopy() *DeployConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeployConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExternalDNS) DeepCopyInto(out *ExternalDNS) {
 	*out = *in
@@ -118,6 +284,7 @@ func (in *ExternalDNSSpec) DeepCopyInto(out *ExternalDNSSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	out.Persistence = in.Persistence
 	return
 }
 
@@ -366,6 +533,17 @@ func (in *PolicySpec) DeepCopyInto(out *PolicySpec) {
 	out.LtmPolicies = in.LtmPolicies
 	out.IRules = in.IRules
 	in.Profiles.DeepCopyInto(&out.Profiles)
+	out.RateLimit = in.RateLimit
+	if in.TargetSelector != nil {
+		in, out := &in.TargetSelector, &out.TargetSelector
+		*out = new(PolicyTargetSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AutoExternalDNS != nil {
+		in, out := &in.AutoExternalDNS, &out.AutoExternalDNS
+		*out = new(AutoExternalDNSSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -379,6 +557,32 @@ func (in *PolicySpec) DeepCopy() *PolicySpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyTargetSelector) DeepCopyInto(out *PolicyTargetSelector) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ObjectSelector != nil {
+		in, out := &in.ObjectSelector, &out.ObjectSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyTargetSelector.
+func (in *PolicyTargetSelector) DeepCopy() *PolicyTargetSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyTargetSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Pool) DeepCopyInto(out *Pool) {
 	*out = *in
@@ -417,6 +621,22 @@ func (in *ProfileSpec) DeepCopy() *ProfileSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RateLimitSpec) DeepCopyInto(out *RateLimitSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimitSpec.
+func (in *RateLimitSpec) DeepCopy() *RateLimitSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RateLimitSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceAddress) DeepCopyInto(out *ServiceAddress) {
 	*out = *in
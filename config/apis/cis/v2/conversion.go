@@ -0,0 +1,436 @@
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	v1 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// V1SpecAnnotation holds the v1 spec of a resource whose v1 fields do not all
+// map to the v2 spec, such as a monitor overridden by monitors. It is restored
+// when the resource is converted back to v1 with the same v2 spec, which makes
+// the conversion lossless both ways.
+const V1SpecAnnotation = "cis.f5.com/v1-spec"
+
+// ConvertFromV1 converts a v1 resource or list of resources to v2
+func ConvertFromV1(obj runtime.Object) (runtime.Object, error) {
+	switch in := obj.(type) {
+	case *v1.VirtualServer:
+		return ConvertVirtualServerFromV1(in)
+	case *v1.TLSProfile:
+		return ConvertTLSProfileFromV1(in)
+	case *v1.TransportServer:
+		return ConvertTransportServerFromV1(in)
+	case *v1.ExternalDNS:
+		return ConvertExternalDNSFromV1(in)
+	case *v1.IngressLink:
+		return ConvertIngressLinkFromV1(in)
+	case *v1.Policy:
+		return ConvertPolicyFromV1(in)
+	case *v1.DeployConfig:
+		return ConvertDeployConfigFromV1(in)
+	case *v1.VirtualServerList:
+		out := &VirtualServerList{TypeMeta: listTypeMeta(in.TypeMeta), ListMeta: in.ListMeta}
+		for i := range in.Items {
+			vs, err := ConvertVirtualServerFromV1(&in.Items[i])
+			if err != nil {
+				return nil, err
+			}
+			out.Items = append(out.Items, *vs)
+		}
+		return out, nil
+	case *v1.TLSProfileList:
+		out := &TLSProfileList{TypeMeta: listTypeMeta(in.TypeMeta), ListMeta: in.ListMeta}
+		for i := range in.Items {
+			tls, err := ConvertTLSProfileFromV1(&in.Items[i])
+			if err != nil {
+				return nil, err
+			}
+			out.Items = append(out.Items, *tls)
+		}
+		return out, nil
+	case *v1.TransportServerList:
+		out := &TransportServerList{TypeMeta: listTypeMeta(in.TypeMeta), ListMeta: in.ListMeta}
+		for i := range in.Items {
+			ts, err := ConvertTransportServerFromV1(&in.Items[i])
+			if err != nil {
+				return nil, err
+			}
+			out.Items = append(out.Items, *ts)
+		}
+		return out, nil
+	case *v1.ExternalDNSList:
+		out := &ExternalDNSList{TypeMeta: listTypeMeta(in.TypeMeta), ListMeta: in.ListMeta}
+		for i := range in.Items {
+			edns, err := ConvertExternalDNSFromV1(&in.Items[i])
+			if err != nil {
+				return nil, err
+			}
+			out.Items = append(out.Items, *edns)
+		}
+		return out, nil
+	case *v1.IngressLinkList:
+		out := &IngressLinkList{}
+		return out, convertList(in, out)
+	case *v1.PolicyList:
+		out := &PolicyList{}
+		return out, convertList(in, out)
+	case *v1.DeployConfigList:
+		out := &DeployConfigList{}
+		return out, convertList(in, out)
+	}
+	return nil, fmt.Errorf("unsupported v1 type %T", obj)
+}
+
+// ConvertToV1 converts a v2 resource to v1
+func ConvertToV1(obj runtime.Object) (runtime.Object, error) {
+	switch in := obj.(type) {
+	case *VirtualServer:
+		return ConvertVirtualServerToV1(in)
+	case *TLSProfile:
+		return ConvertTLSProfileToV1(in)
+	case *TransportServer:
+		return ConvertTransportServerToV1(in)
+	case *ExternalDNS:
+		return ConvertExternalDNSToV1(in)
+	case *IngressLink:
+		return ConvertIngressLinkToV1(in)
+	case *Policy:
+		return ConvertPolicyToV1(in)
+	case *DeployConfig:
+		return ConvertDeployConfigToV1(in)
+	}
+	return nil, fmt.Errorf("unsupported v2 type %T", obj)
+}
+
+// ConvertVirtualServerFromV1 converts a v1 VirtualServer to v2, the top level
+// persistence and multiplex profiles move to the profiles and the monitor used
+// by CIS among monitor and monitors becomes the monitors of the pool
+func ConvertVirtualServerFromV1(in *v1.VirtualServer) (*VirtualServer, error) {
+	out := &VirtualServer{}
+	if err := convertObject(in, out); err != nil {
+		return nil, err
+	}
+	out.Spec = virtualServerSpecFromV1(in.Spec)
+	return out, setV1SpecAnnotation(&out.ObjectMeta, in.Spec, out.Spec, func() interface{} {
+		return virtualServerSpecToV1(out.Spec)
+	})
+}
+
+// ConvertVirtualServerToV1 converts a v2 VirtualServer to v1
+func ConvertVirtualServerToV1(in *VirtualServer) (*v1.VirtualServer, error) {
+	out := &v1.VirtualServer{}
+	if err := convertObject(in, out); err != nil {
+		return nil, err
+	}
+	out.Spec = virtualServerSpecToV1(in.Spec)
+	restoreV1Spec(&out.ObjectMeta, in.Spec, &out.Spec, func(spec interface{}) interface{} {
+		return virtualServerSpecFromV1(*spec.(*v1.VirtualServerSpec))
+	})
+	return out, nil
+}
+
+func virtualServerSpecFromV1(in v1.VirtualServerSpec) VirtualServerSpec {
+	var out VirtualServerSpec
+	mustConvert(in, &out)
+	out.Profiles.PersistenceProfile = in.PersistenceProfile
+	out.Profiles.ProfileMultiplex = in.ProfileMultiplex
+	for i, pl := range in.Pools {
+		// The monitor of a VirtualServer pool needs a send string
+		out.Pools[i].Monitors = monitorsFromV1(pl.Monitor, pl.Monitors,
+			pl.Monitor.Type != "" && pl.Monitor.Send != "")
+	}
+	return out
+}
+
+func virtualServerSpecToV1(in VirtualServerSpec) v1.VirtualServerSpec {
+	var out v1.VirtualServerSpec
+	mustConvert(in, &out)
+	out.PersistenceProfile = in.Profiles.PersistenceProfile
+	out.ProfileMultiplex = in.Profiles.ProfileMultiplex
+	out.Profiles.PersistenceProfile = ""
+	out.Profiles.ProfileMultiplex = ""
+	return out
+}
+
+// ConvertTransportServerFromV1 converts a v1 TransportServer to v2, the top
+// level persistence and L4 profiles move to the profiles and the monitor used
+// by CIS among monitor and monitors becomes the monitors of the pool
+func ConvertTransportServerFromV1(in *v1.TransportServer) (*TransportServer, error) {
+	out := &TransportServer{}
+	if err := convertObject(in, out); err != nil {
+		return nil, err
+	}
+	out.Spec = transportServerSpecFromV1(in.Spec)
+	return out, setV1SpecAnnotation(&out.ObjectMeta, in.Spec, out.Spec, func() interface{} {
+		return transportServerSpecToV1(out.Spec)
+	})
+}
+
+// ConvertTransportServerToV1 converts a v2 TransportServer to v1
+func ConvertTransportServerToV1(in *TransportServer) (*v1.TransportServer, error) {
+	out := &v1.TransportServer{}
+	if err := convertObject(in, out); err != nil {
+		return nil, err
+	}
+	out.Spec = transportServerSpecToV1(in.Spec)
+	restoreV1Spec(&out.ObjectMeta, in.Spec, &out.Spec, func(spec interface{}) interface{} {
+		return transportServerSpecFromV1(*spec.(*v1.TransportServerSpec))
+	})
+	return out, nil
+}
+
+func transportServerSpecFromV1(in v1.TransportServerSpec) TransportServerSpec {
+	var out TransportServerSpec
+	mustConvert(in, &out)
+	out.Profiles.PersistenceProfile = in.PersistenceProfile
+	out.Profiles.ProfileL4 = in.ProfileL4
+	out.Pool.Monitors = monitorsFromV1(in.Pool.Monitor, in.Pool.Monitors, in.Pool.Monitor.Type != "")
+	return out
+}
+
+func transportServerSpecToV1(in TransportServerSpec) v1.TransportServerSpec {
+	var out v1.TransportServerSpec
+	mustConvert(in, &out)
+	out.PersistenceProfile = in.Profiles.PersistenceProfile
+	out.ProfileL4 = in.Profiles.ProfileL4
+	out.Profiles.PersistenceProfile = ""
+	out.Profiles.ProfileL4 = ""
+	return out
+}
+
+// ConvertTLSProfileFromV1 converts a v1 TLSProfile to v2, the client and server
+// SSL profiles become lists
+func ConvertTLSProfileFromV1(in *v1.TLSProfile) (*TLSProfile, error) {
+	out := &TLSProfile{}
+	if err := convertObject(in, out); err != nil {
+		return nil, err
+	}
+	out.Spec = tlsProfileSpecFromV1(in.Spec)
+	return out, setV1SpecAnnotation(&out.ObjectMeta, in.Spec, out.Spec, func() interface{} {
+		return tlsProfileSpecToV1(out.Spec)
+	})
+}
+
+// ConvertTLSProfileToV1 converts a v2 TLSProfile to v1
+func ConvertTLSProfileToV1(in *TLSProfile) (*v1.TLSProfile, error) {
+	out := &v1.TLSProfile{}
+	if err := convertObject(in, out); err != nil {
+		return nil, err
+	}
+	out.Spec = tlsProfileSpecToV1(in.Spec)
+	restoreV1Spec(&out.ObjectMeta, in.Spec, &out.Spec, func(spec interface{}) interface{} {
+		return tlsProfileSpecFromV1(*spec.(*v1.TLSProfileSpec))
+	})
+	return out, nil
+}
+
+func tlsProfileSpecFromV1(in v1.TLSProfileSpec) TLSProfileSpec {
+	var out TLSProfileSpec
+	mustConvert(in, &out)
+	if len(in.TLS.ClientSSLs) == 0 && in.TLS.ClientSSL != "" {
+		out.TLS.ClientSSLs = []string{in.TLS.ClientSSL}
+	}
+	if len(in.TLS.ServerSSLs) == 0 && in.TLS.ServerSSL != "" {
+		out.TLS.ServerSSLs = []string{in.TLS.ServerSSL}
+	}
+	return out
+}
+
+func tlsProfileSpecToV1(in TLSProfileSpec) v1.TLSProfileSpec {
+	var out v1.TLSProfileSpec
+	mustConvert(in, &out)
+	return out
+}
+
+// ConvertExternalDNSFromV1 converts a v1 ExternalDNS to v2, the monitor used by
+// CIS among monitor and monitors becomes the monitors of the pool
+func ConvertExternalDNSFromV1(in *v1.ExternalDNS) (*ExternalDNS, error) {
+	out := &ExternalDNS{}
+	if err := convertObject(in, out); err != nil {
+		return nil, err
+	}
+	out.Spec = externalDNSSpecFromV1(in.Spec)
+	return out, setV1SpecAnnotation(&out.ObjectMeta, in.Spec, out.Spec, func() interface{} {
+		return externalDNSSpecToV1(out.Spec)
+	})
+}
+
+// ConvertExternalDNSToV1 converts a v2 ExternalDNS to v1
+func ConvertExternalDNSToV1(in *ExternalDNS) (*v1.ExternalDNS, error) {
+	out := &v1.ExternalDNS{}
+	if err := convertObject(in, out); err != nil {
+		return nil, err
+	}
+	out.Spec = externalDNSSpecToV1(in.Spec)
+	restoreV1Spec(&out.ObjectMeta, in.Spec, &out.Spec, func(spec interface{}) interface{} {
+		return externalDNSSpecFromV1(*spec.(*v1.ExternalDNSSpec))
+	})
+	return out, nil
+}
+
+func externalDNSSpecFromV1(in v1.ExternalDNSSpec) ExternalDNSSpec {
+	var out ExternalDNSSpec
+	mustConvert(in, &out)
+	for i, pl := range in.Pools {
+		// The monitors of a DNS pool override its monitor
+		out.Pools[i].Monitors = monitorsFromV1(pl.Monitor, pl.Monitors,
+			len(pl.Monitors) == 0 && pl.Monitor.Type != "")
+	}
+	return out
+}
+
+func externalDNSSpecToV1(in ExternalDNSSpec) v1.ExternalDNSSpec {
+	var out v1.ExternalDNSSpec
+	mustConvert(in, &out)
+	return out
+}
+
+// ConvertIngressLinkFromV1 converts a v1 IngressLink to v2
+func ConvertIngressLinkFromV1(in *v1.IngressLink) (*IngressLink, error) {
+	out := &IngressLink{}
+	return out, convertObject(in, out)
+}
+
+// ConvertIngressLinkToV1 converts a v2 IngressLink to v1
+func ConvertIngressLinkToV1(in *IngressLink) (*v1.IngressLink, error) {
+	out := &v1.IngressLink{}
+	return out, convertObject(in, out)
+}
+
+// ConvertPolicyFromV1 converts a v1 Policy to v2
+func ConvertPolicyFromV1(in *v1.Policy) (*Policy, error) {
+	out := &Policy{}
+	return out, convertObject(in, out)
+}
+
+// ConvertPolicyToV1 converts a v2 Policy to v1
+func ConvertPolicyToV1(in *Policy) (*v1.Policy, error) {
+	out := &v1.Policy{}
+	return out, convertObject(in, out)
+}
+
+// ConvertDeployConfigFromV1 converts a v1 DeployConfig to v2
+func ConvertDeployConfigFromV1(in *v1.DeployConfig) (*DeployConfig, error) {
+	out := &DeployConfig{}
+	return out, convertObject(in, out)
+}
+
+// ConvertDeployConfigToV1 converts a v2 DeployConfig to v1
+func ConvertDeployConfigToV1(in *DeployConfig) (*v1.DeployConfig, error) {
+	out := &v1.DeployConfig{}
+	return out, convertObject(in, out)
+}
+
+// monitorsFromV1 returns the monitors of a v1 pool, the monitor is used when it
+// is a BIG-IP monitor reference or when it overrides the monitors
+func monitorsFromV1(monitor v1.Monitor, monitors []v1.Monitor, overrides bool) []Monitor {
+	if (monitor.Name != "" && monitor.Reference == "bigip") || overrides {
+		monitors = []v1.Monitor{monitor}
+	}
+	var out []Monitor
+	mustConvert(monitors, &out)
+	return out
+}
+
+// setV1SpecAnnotation annotates the v2 resource with its v1 spec when the v1
+// spec can not be derived from the v2 spec
+func setV1SpecAnnotation(meta *metav1.ObjectMeta, v1Spec, v2Spec interface{}, toV1 func() interface{}) error {
+	delete(meta.Annotations, V1SpecAnnotation)
+	if jsonEqual(v1Spec, toV1()) {
+		if len(meta.Annotations) == 0 {
+			meta.Annotations = nil
+		}
+		return nil
+	}
+	data, err := json.Marshal(v1Spec)
+	if err != nil {
+		return err
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = make(map[string]string)
+	}
+	meta.Annotations[V1SpecAnnotation] = string(data)
+	return nil
+}
+
+// restoreV1Spec restores the v1 spec held by the annotation of the resource if
+// it converts to the current v2 spec, an outdated annotation is dropped
+func restoreV1Spec(meta *metav1.ObjectMeta, v2Spec, v1Spec interface{}, fromV1 func(interface{}) interface{}) {
+	data, ok := meta.Annotations[V1SpecAnnotation]
+	if !ok {
+		return
+	}
+	delete(meta.Annotations, V1SpecAnnotation)
+	if len(meta.Annotations) == 0 {
+		meta.Annotations = nil
+	}
+	stored := reflect.New(reflect.TypeOf(v1Spec).Elem()).Interface()
+	if err := json.Unmarshal([]byte(data), stored); err != nil {
+		return
+	}
+	if jsonEqual(fromV1(stored), v2Spec) {
+		mustConvert(stored, v1Spec)
+	}
+}
+
+// convertObject converts the object to the other version field by field, the
+// type meta is set to the version of the output when it is set on the input
+func convertObject(in, out runtime.Object) error {
+	if err := convertJSON(in, out); err != nil {
+		return err
+	}
+	setTypeMeta(in, out)
+	return nil
+}
+
+// convertList converts the list to the other version field by field
+func convertList(in, out runtime.Object) error {
+	return convertObject(in, out)
+}
+
+func setTypeMeta(in, out runtime.Object) {
+	gvk := in.GetObjectKind().GroupVersionKind()
+	if gvk.Empty() {
+		return
+	}
+	gvk.Version = SchemeGroupVersion.Version
+	if in.GetObjectKind().GroupVersionKind().Version == SchemeGroupVersion.Version {
+		gvk.Version = v1.SchemeGroupVersion.Version
+	}
+	out.GetObjectKind().SetGroupVersionKind(gvk)
+}
+
+func listTypeMeta(in metav1.TypeMeta) metav1.TypeMeta {
+	if in.APIVersion == "" {
+		return in
+	}
+	return metav1.TypeMeta{Kind: in.Kind, APIVersion: SchemeGroupVersion.String()}
+}
+
+func convertJSON(in, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// mustConvert converts between the types sharing their JSON fields, which can
+// not fail
+func mustConvert(in, out interface{}) {
+	if err := convertJSON(in, out); err != nil {
+		panic(err)
+	}
+}
+
+func jsonEqual(a, b interface{}) bool {
+	dataA, errA := json.Marshal(a)
+	dataB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(dataA, dataB)
+}
@@ -0,0 +1,204 @@
+package v2_test
+
+import (
+	v1 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v1"
+	. "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Conversion", func() {
+	var objectMeta metav1.ObjectMeta
+
+	BeforeEach(func() {
+		objectMeta = metav1.ObjectMeta{
+			Name:        "sample",
+			Namespace:   "default",
+			Labels:      map[string]string{"f5cr": "true"},
+			Annotations: map[string]string{"owner": "team"},
+		}
+	})
+
+	It("Converts VirtualServers both ways", func() {
+		vs := &v1.VirtualServer{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "cis.f5.com/v1", Kind: "VirtualServer"},
+			ObjectMeta: objectMeta,
+			Spec: v1.VirtualServerSpec{
+				Host:               "test.com",
+				PersistenceProfile: "source-address",
+				ProfileMultiplex:   "/Common/oneconnect",
+				Pools: []v1.Pool{
+					{
+						Path:    "/foo",
+						Service: "svc1",
+						Monitor: v1.Monitor{Type: "http", Send: "GET /", Interval: 10, Timeout: 31},
+					},
+					{
+						Path:     "/bar",
+						Service:  "svc2",
+						Monitors: []v1.Monitor{{Type: "tcp", Interval: 5}, {Name: "/Common/http", Reference: "bigip"}},
+					},
+				},
+			},
+			Status: v1.VirtualServerStatus{VSAddress: "1.2.3.4", StatusOk: "Ok"},
+		}
+
+		vsV2, err := ConvertVirtualServerFromV1(vs)
+		Expect(err).To(BeNil())
+		Expect(vsV2.APIVersion).To(Equal("cis.f5.com/v2"))
+		Expect(vsV2.Labels).To(Equal(vs.Labels))
+		Expect(vsV2.Status).To(Equal(VirtualServerStatus{VSAddress: "1.2.3.4", StatusOk: "Ok"}))
+		Expect(vsV2.Spec.Profiles.PersistenceProfile).To(Equal("source-address"))
+		Expect(vsV2.Spec.Profiles.ProfileMultiplex).To(Equal("/Common/oneconnect"))
+		Expect(vsV2.Spec.Pools[0].Monitors).To(Equal([]Monitor{{Type: "http", Send: "GET /", Interval: 10, Timeout: 31}}))
+		Expect(vsV2.Spec.Pools[1].Monitors).To(Equal([]Monitor{{Type: "tcp", Interval: 5}, {Name: "/Common/http", Reference: "bigip"}}))
+		Expect(vsV2.Annotations).To(HaveKey(V1SpecAnnotation), "The singular monitor should be kept for v1")
+
+		converted, err := ConvertVirtualServerToV1(vsV2)
+		Expect(err).To(BeNil())
+		Expect(converted).To(Equal(vs), "Conversion should be lossless")
+
+		// The spec changed in v2 no longer matches the v1 spec of the annotation
+		vsV2.Spec.Pools[0].Monitors[0].Interval = 20
+		converted, err = ConvertVirtualServerToV1(vsV2)
+		Expect(err).To(BeNil())
+		Expect(converted.Annotations).To(Equal(map[string]string{"owner": "team"}))
+		Expect(converted.Spec.Pools[0].Monitor).To(Equal(v1.Monitor{}))
+		Expect(converted.Spec.Pools[0].Monitors).To(Equal([]v1.Monitor{{Type: "http", Send: "GET /", Interval: 20, Timeout: 31}}))
+		Expect(converted.Spec.PersistenceProfile).To(Equal("source-address"))
+		Expect(converted.Spec.Profiles.PersistenceProfile).To(BeEmpty())
+	})
+
+	It("Keeps the monitors overriding an incomplete monitor", func() {
+		vs := &v1.VirtualServer{
+			ObjectMeta: objectMeta,
+			Spec: v1.VirtualServerSpec{
+				Pools: []v1.Pool{{
+					Service:  "svc1",
+					Monitor:  v1.Monitor{Type: "http"},
+					Monitors: []v1.Monitor{{Type: "tcp"}},
+				}},
+			},
+		}
+		vsV2, err := ConvertVirtualServerFromV1(vs)
+		Expect(err).To(BeNil())
+		Expect(vsV2.APIVersion).To(BeEmpty())
+		Expect(vsV2.Spec.Pools[0].Monitors).To(Equal([]Monitor{{Type: "tcp"}}))
+
+		converted, err := ConvertVirtualServerToV1(vsV2)
+		Expect(err).To(BeNil())
+		Expect(converted).To(Equal(vs))
+	})
+
+	It("Converts TransportServers both ways", func() {
+		ts := &v1.TransportServer{
+			ObjectMeta: objectMeta,
+			Spec: v1.TransportServerSpec{
+				VirtualServerPort:  1344,
+				PersistenceProfile: "source-address",
+				ProfileL4:          "/Common/fastL4",
+				Pool: v1.Pool{
+					Service:  "svc1",
+					Monitor:  v1.Monitor{Type: "tcp", Interval: 10},
+					Monitors: []v1.Monitor{{Type: "udp"}},
+				},
+			},
+		}
+		tsV2, err := ConvertTransportServerFromV1(ts)
+		Expect(err).To(BeNil())
+		Expect(tsV2.Spec.Profiles.PersistenceProfile).To(Equal("source-address"))
+		Expect(tsV2.Spec.Profiles.ProfileL4).To(Equal("/Common/fastL4"))
+		Expect(tsV2.Spec.Pool.Monitors).To(Equal([]Monitor{{Type: "tcp", Interval: 10}}))
+
+		converted, err := ConvertTransportServerToV1(tsV2)
+		Expect(err).To(BeNil())
+		Expect(converted).To(Equal(ts))
+	})
+
+	It("Converts TLSProfiles both ways", func() {
+		tls := &v1.TLSProfile{
+			ObjectMeta: objectMeta,
+			Spec: v1.TLSProfileSpec{
+				Hosts: []string{"test.com"},
+				TLS: v1.TLS{
+					Termination: "reencrypt",
+					ClientSSL:   "clientssl",
+					ServerSSLs:  []string{"serverssl", "foo-serverssl"},
+					Reference:   "secret",
+				},
+			},
+		}
+		tlsV2, err := ConvertTLSProfileFromV1(tls)
+		Expect(err).To(BeNil())
+		Expect(tlsV2.Spec.TLS).To(Equal(TLS{
+			Termination: "reencrypt",
+			ClientSSLs:  []string{"clientssl"},
+			ServerSSLs:  []string{"serverssl", "foo-serverssl"},
+			Reference:   "secret",
+		}))
+
+		converted, err := ConvertTLSProfileToV1(tlsV2)
+		Expect(err).To(BeNil())
+		Expect(converted).To(Equal(tls))
+
+		// A TLSProfile with the lists only needs no annotation
+		tls.Spec.TLS.ClientSSL = ""
+		tls.Spec.TLS.ClientSSLs = []string{"clientssl"}
+		tlsV2, err = ConvertTLSProfileFromV1(tls)
+		Expect(err).To(BeNil())
+		Expect(tlsV2.Annotations).To(Equal(map[string]string{"owner": "team"}))
+	})
+
+	It("Converts ExternalDNS both ways", func() {
+		edns := &v1.ExternalDNS{
+			ObjectMeta: objectMeta,
+			Spec: v1.ExternalDNSSpec{
+				DomainName: "test.com",
+				Pools: []v1.DNSPool{
+					{DataServerName: "/Common/GSLBServer", Monitor: v1.Monitor{Type: "https", Send: "GET /"}},
+					{
+						DataServerName: "/Common/GSLBServer",
+						Monitor:        v1.Monitor{Type: "http"},
+						Monitors:       []v1.Monitor{{Type: "tcp"}},
+					},
+				},
+			},
+		}
+		ednsV2, err := ConvertExternalDNSFromV1(edns)
+		Expect(err).To(BeNil())
+		Expect(ednsV2.Spec.Pools[0].Monitors).To(Equal([]Monitor{{Type: "https", Send: "GET /"}}))
+		Expect(ednsV2.Spec.Pools[1].Monitors).To(Equal([]Monitor{{Type: "tcp"}}))
+
+		converted, err := ConvertExternalDNSToV1(ednsV2)
+		Expect(err).To(BeNil())
+		Expect(converted).To(Equal(edns))
+	})
+
+	It("Converts the resources and lists of any kind", func() {
+		list := &v1.PolicyList{
+			TypeMeta: metav1.TypeMeta{APIVersion: "cis.f5.com/v1", Kind: "PolicyList"},
+			Items: []v1.Policy{{
+				ObjectMeta: objectMeta,
+				Spec:       v1.PolicySpec{Profiles: v1.ProfileSpec{PersistenceProfile: "source-address"}},
+			}},
+		}
+		obj, err := ConvertFromV1(list)
+		Expect(err).To(BeNil())
+		plcList, ok := obj.(*PolicyList)
+		Expect(ok).To(BeTrue())
+		Expect(plcList.APIVersion).To(Equal("cis.f5.com/v2"))
+		Expect(plcList.Items[0].Spec.Profiles.PersistenceProfile).To(Equal("source-address"))
+
+		obj, err = ConvertFromV1(&v1.VirtualServerList{Items: []v1.VirtualServer{{ObjectMeta: objectMeta}}})
+		Expect(err).To(BeNil())
+		Expect(obj.(*VirtualServerList).Items).To(HaveLen(1))
+
+		obj, err = ConvertToV1(&plcList.Items[0])
+		Expect(err).To(BeNil())
+		Expect(obj).To(Equal(&list.Items[0]))
+
+		_, err = ConvertFromV1(&plcList.Items[0])
+		Expect(err).To(MatchError(ContainSubstring("unsupported v1 type")))
+	})
+})
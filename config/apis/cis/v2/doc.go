@@ -0,0 +1,8 @@
+// +k8s:deepcopy-gen=package
+// +groupName=cis.f5.com
+// +kubebuilder:validation:Optional
+
+// Package v2 is the v2 version of the API, which holds a single field for each
+// setting of the v1 version. The resources are stored in the v1 version and
+// converted by the conversion webhook of CIS.
+package v2
@@ -0,0 +1,66 @@
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// SchemeGroupVersion define your schema name and the version
+var SchemeGroupVersion = schema.GroupVersion{
+	Group:   "cis.f5.com",
+	Version: "v2",
+}
+
+var (
+	// SchemeBuilder is an instance of Schema
+	SchemeBuilder      runtime.SchemeBuilder
+	localSchemeBuilder = &SchemeBuilder
+	// AddToScheme adds the schema
+	AddToScheme = localSchemeBuilder.AddToScheme
+)
+
+func init() {
+	// We only register manually written functions here. The registration of the
+	// generated functions takes place in the generated files. The separation
+	// makes the code compile even when the generated files are missing.
+	localSchemeBuilder.Register(addKnownTypes)
+}
+
+// Resource takes an unqualified resource and returns a Group qualified GroupResource
+func Resource(resource string) schema.GroupResource {
+	return SchemeGroupVersion.WithResource(resource).GroupResource()
+}
+
+// Adds the list of known types to the given scheme.
+func addKnownTypes(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(
+		SchemeGroupVersion,
+		&VirtualServer{},
+		&VirtualServerList{},
+		&TLSProfile{},
+		&TLSProfileList{},
+		&IngressLink{},
+		&IngressLinkList{},
+		&TransportServer{},
+		&TransportServerList{},
+		&ExternalDNS{},
+		&ExternalDNSList{},
+		&Policy{},
+		&PolicyList{},
+		&DeployConfig{},
+		&DeployConfigList{},
+	)
+
+	scheme.AddKnownTypes(
+		SchemeGroupVersion,
+		&metav1.Status{},
+	)
+
+	metav1.AddToGroupVersion(
+		scheme,
+		SchemeGroupVersion,
+	)
+
+	return nil
+}
@@ -0,0 +1,620 @@
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:unservedversion
+// +kubebuilder:validation:Optional
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=vs
+// +kubebuilder:printcolumn:name="host",type="string",JSONPath=".spec.host",description="hostname"
+// +kubebuilder:printcolumn:name="tlsProfileName",type="string",JSONPath=".spec.tlsProfileName",description="TLS Profile attached"
+// +kubebuilder:printcolumn:name="httpTraffic",type="string",JSONPath=".spec.httpTraffic",description="Http Traffic Termination"
+// +kubebuilder:printcolumn:name="IPAddress",type="string",JSONPath=".spec.virtualServerAddress",description="IP address of virtualServer"
+// +kubebuilder:printcolumn:name="ipamLabel",type="string",JSONPath=".spec.ipamLabel",description="ipamLabel for virtual server"
+// +kubebuilder:printcolumn:name="IPAMVSAddress",type="string",JSONPath=".status.vsAddress",description="IP address of virtualServer"
+// +kubebuilder:printcolumn:name="STATUS",type="string",JSONPath=".status.status",description="status of VirtualServer"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// VirtualServer defines the VirtualServer resource.
+type VirtualServer struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VirtualServerSpec   `json:"spec"`
+	Status VirtualServerStatus `json:"status,omitempty"`
+}
+
+// VirtualServerStatus is the status of the VirtualServer resource.
+type VirtualServerStatus struct {
+	// +kubebuilder:default=None
+	VSAddress string `json:"vsAddress,omitempty"`
+	// +kubebuilder:default=Pending
+	StatusOk string `json:"status,omitempty"`
+}
+
+// +kubebuilder:validation:XValidation:rule="!has(self.virtualServerHTTPPort) || !has(self.virtualServerHTTPSPort) || self.virtualServerHTTPPort != self.virtualServerHTTPSPort",message="virtualServerHTTPPort and virtualServerHTTPSPort must be different"
+
+// VirtualServerSpec is the spec of the VirtualServer resource.
+type VirtualServerSpec struct {
+	// +kubebuilder:validation:Pattern=`^(([a-zA-Z0-9\*]|[a-zA-Z0-9][a-zA-Z0-9\-]*[a-zA-Z0-9])\.)*([A-Za-z0-9]|[A-Za-z0-9][A-Za-z0-9\-]*[A-Za-z0-9])$`
+	Host string `json:"host,omitempty"`
+	// +kubebuilder:validation:Pattern=`^([A-z0-9-_+])*([A-z0-9])$`
+	HostGroup string `json:"hostGroup,omitempty"`
+	// +kubebuilder:validation:Pattern=`^(([0-9]|[1-9][0-9]|1[0-9]{2}|2[0-4][0-9]|25[0-5])\.){3}([0-9]|[1-9][0-9]|1[0-9]{2}|2[0-4][0-9]|25[0-5])|(([0-9a-fA-F]{1,4}:){7,7}[0-9a-fA-F]{1,4}|([0-9a-fA-F]{1,4}:){1,7}:|([0-9a-fA-F]{1,4}:){1,6}:[0-9a-fA-F]{1,4}|([0-9a-fA-F]{1,4}:){1,5}(:[0-9a-fA-F]{1,4}){1,2}|([0-9a-fA-F]{1,4}:){1,4}(:[0-9a-fA-F]{1,4}){1,3}|([0-9a-fA-F]{1,4}:){1,3}(:[0-9a-fA-F]{1,4}){1,4}|([0-9a-fA-F]{1,4}:){1,2}(:[0-9a-fA-F]{1,4}){1,5}|[0-9a-fA-F]{1,4}:((:[0-9a-fA-F]{1,4}){1,6})|:((:[0-9a-fA-F]{1,4}){1,7}|:)|fe80:(:[0-9a-fA-F]{0,4}){0,4}%[0-9a-zA-Z]{1,}|::(ffff(:0{1,4}){0,1}:){0,1}((25[0-5]|(2[0-4]|1{0,1}[0-9]){0,1}[0-9])\.){3,3}(25[0-5]|(2[0-4]|1{0,1}[0-9]){0,1}[0-9])|([0-9a-fA-F]{1,4}:){1,4}:((25[0-5]|(2[0-4]|1{0,1}[0-9]){0,1}[0-9])\.){3,3}(25[0-5]|(2[0-4]|1{0,1}[0-9]){0,1}[0-9]))$`
+	VirtualServerAddress string `json:"virtualServerAddress,omitempty"`
+	IPAMLabel            string `json:"ipamLabel,omitempty"`
+	// +kubebuilder:validation:Pattern=`^([A-z0-9-_+])*([A-z0-9])$`
+	VirtualServerName string `json:"virtualServerName,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	VirtualServerHTTPPort int32 `json:"virtualServerHTTPPort,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	VirtualServerHTTPSPort int32  `json:"virtualServerHTTPSPort,omitempty"`
+	Pools                  []Pool `json:"pools,omitempty"`
+	TLSProfileName         string `json:"tlsProfileName,omitempty"`
+	// +kubebuilder:validation:Enum=none;allow;redirect
+	HTTPTraffic string `json:"httpTraffic,omitempty"`
+	SNAT        string `json:"snat,omitempty"`
+	// +kubebuilder:validation:Pattern=`^\/([A-z0-9-_+]+\/)*([A-z0-9]+\/?)*$`
+	WAF string `json:"waf,omitempty"`
+	// +kubebuilder:validation:Pattern=`^\/([A-z0-9-_+]+\/)*([A-z0-9]+\/?)*$`
+	RewriteAppRoot string `json:"rewriteAppRoot,omitempty"`
+	// +kubebuilder:validation:items:Pattern=`^\/([A-z0-9-_+]+\/)*([A-z0-9-_]+\/?)*$`
+	AllowVLANs []string `json:"allowVlans,omitempty"`
+	IRules     []string `json:"iRules,omitempty"`
+	// +kubebuilder:validation:MaxItems=1
+	ServiceIPAddress []ServiceAddress `json:"serviceAddress,omitempty"`
+	// +kubebuilder:validation:Pattern=`^([A-z0-9-_+])*([A-z0-9])$`
+	PolicyName string `json:"policyName,omitempty"`
+	// +kubebuilder:validation:Pattern=`^\/([A-z0-9-_+]+\/)+([A-z0-9-]+\/?)*$`
+	DOS string `json:"dos,omitempty"`
+	// +kubebuilder:validation:Pattern=`^\/([A-z0-9-_+]+\/)+([A-z0-9-]+\/?)*$`
+	BotDefense string `json:"botDefense,omitempty"`
+	// Profiles holds the profiles of the virtual, including the persistence and multiplex profiles
+	Profiles         ProfileSpec `json:"profiles,omitempty"`
+	AllowSourceRange []string    `json:"allowSourceRange,omitempty"`
+	AS3Override      string      `json:"as3Override,omitempty"`
+}
+
+// ServiceAddress Service IP address definition (BIG-IP virtual-address).
+type ServiceAddress struct {
+	ArpEnabled bool `json:"arpEnabled,omitempty"`
+	// +kubebuilder:validation:Enum=enable;disable;selective
+	ICMPEcho string `json:"icmpEcho,omitempty"`
+	// +kubebuilder:validation:Enum=enable;disable;selective;always;any;all
+	RouteAdvertisement string `json:"routeAdvertisement,omitempty"`
+	// +kubebuilder:validation:Pattern=`^\/([A-z0-9-_+]+\/)+([A-z0-9-]+\/?)*$`
+	TrafficGroup    string `json:"trafficGroup,omitempty"`
+	SpanningEnabled bool   `json:"spanningEnabled,omitempty"`
+}
+
+// Pool defines a pool object in BIG-IP.
+type Pool struct {
+	// +kubebuilder:validation:Pattern=`^([A-z0-9-_+])*([A-z0-9])$`
+	Name string `json:"name,omitempty"`
+	// +kubebuilder:validation:Pattern=`^\/([A-z0-9-_+]+\/)*([A-z0-9-]+\/?)*$`
+	Path string `json:"path,omitempty"`
+	// +kubebuilder:validation:Pattern=`^([A-z0-9-_+])*([A-z0-9])$`
+	Service string `json:"service"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	ServicePort int32 `json:"servicePort"`
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9][-A-Za-z0-9_.\/]{0,61}[a-zA-Z0-9]=[a-zA-Z0-9][-A-Za-z0-9_.]{0,61}[a-zA-Z0-9]$`
+	NodeMemberLabel string    `json:"nodeMemberLabel,omitempty"`
+	Monitors        []Monitor `json:"monitors,omitempty"`
+	// +kubebuilder:validation:Pattern=`^\/([A-z0-9-_+]+\/)*([A-z0-9]+\/?)*$`
+	Rewrite string `json:"rewrite,omitempty"`
+	// +kubebuilder:validation:Enum=dynamic-ratio-member;dynamic-ratio-node;fastest-app-response;fastest-node;least-connections-member;least-connections-node;least-sessions;observed-member;observed-node;predictive-member;predictive-node;ratio-least-connections-member;ratio-least-connections-node;ratio-member;ratio-node;ratio-session;round-robin;weighted-least-connections-member;weighted-least-connections-node
+	Balance          string `json:"loadBalancingMethod,omitempty"`
+	ServiceNamespace string `json:"serviceNamespace,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65535
+	ReselectTries int32 `json:"reselectTries,omitempty"`
+	// +kubebuilder:validation:Enum=drop;none;reselect;reset
+	ServiceDownAction string `json:"serviceDownAction,omitempty"`
+}
+
+// Monitor defines a monitor object in BIG-IP.
+type Monitor struct {
+	Type string `json:"type"`
+	Send string `json:"send"`
+	Recv string `json:"recv"`
+	// +kubebuilder:validation:Minimum=1
+	Interval int `json:"interval"`
+	// +kubebuilder:validation:Minimum=0
+	Timeout int `json:"timeout"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65535
+	TargetPort int32 `json:"targetPort"`
+	// +kubebuilder:validation:Pattern=`^\/([A-z0-9-_+]+\/)+([A-z0-9-]+\/?)*$`
+	Name      string `json:"name,omitempty"`
+	Reference string `json:"reference,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+
+// VirtualServerList is a list of the VirtualServer resources.
+type VirtualServerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []VirtualServer `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:unservedversion
+// +kubebuilder:resource:shortName=tls
+
+// TLSProfile is a Custom Resource for TLS server
+type TLSProfile struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec TLSProfileSpec `json:"spec"`
+}
+
+// TLSProfileSpec is spec for TLSServer
+type TLSProfileSpec struct {
+	// +kubebuilder:validation:items:Pattern=`^(([a-zA-Z0-9\*]|[a-zA-Z0-9][a-zA-Z0-9\-]*[a-zA-Z0-9])\.)*([A-Za-z0-9]|[A-Za-z0-9][A-Za-z0-9\-]*[A-Za-z0-9])$`
+	Hosts []string `json:"hosts"`
+	TLS   TLS      `json:"tls"`
+}
+
+// +kubebuilder:validation:XValidation:rule="self.termination != 'edge' || (has(self.clientSSLs) && size(self.clientSSLs) > 0)",message="edge termination requires clientSSLs"
+// +kubebuilder:validation:XValidation:rule="self.termination != 'edge' || !has(self.serverSSLs) || size(self.serverSSLs) == 0",message="edge termination does not allow serverSSLs"
+// +kubebuilder:validation:XValidation:rule="self.termination != 'reencrypt' || (has(self.clientSSLs) && size(self.clientSSLs) > 0 && has(self.serverSSLs) && size(self.serverSSLs) > 0)",message="reencrypt termination requires clientSSLs and serverSSLs"
+// +kubebuilder:validation:XValidation:rule="self.termination != 'passthrough' || ((!has(self.clientSSLs) || size(self.clientSSLs) == 0) && (!has(self.serverSSLs) || size(self.serverSSLs) == 0))",message="passthrough termination does not allow SSL profiles"
+
+// TLS contains required fields for TLS termination
+type TLS struct {
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=edge;reencrypt;passthrough
+	Termination string   `json:"termination"`
+	ClientSSLs  []string `json:"clientSSLs,omitempty"`
+	ServerSSLs  []string `json:"serverSSLs,omitempty"`
+	// +kubebuilder:validation:Enum=bigip;secret
+	Reference string `json:"reference"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+
+// TLSProfileList is list of TLS servers
+type TLSProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []TLSProfile `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:unservedversion
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=il
+// +kubebuilder:printcolumn:name="IPAMVSAddress",type="string",JSONPath=".status.vsAddress",description="IP address of virtualServer"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// IngressLink is a Custom Resource for KIC Ingress
+type IngressLink struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IngressLinkSpec   `json:"spec"`
+	Status IngressLinkStatus `json:"status,omitempty"`
+}
+
+// IngressLinkStatus is the status of the ingressLink resource.
+type IngressLinkStatus struct {
+	VSAddress string `json:"vsAddress,omitempty"`
+}
+
+// IngressLinkSpec is Spec for IngressLink
+type IngressLinkSpec struct {
+	// +kubebuilder:validation:Pattern=`^(([0-9]|[1-9][0-9]|1[0-9]{2}|2[0-4][0-9]|25[0-5])\.){3}([0-9]|[1-9][0-9]|1[0-9]{2}|2[0-4][0-9]|25[0-5])$`
+	// +kubebuilder:validation:Format=ipv4
+	VirtualServerAddress string `json:"virtualServerAddress,omitempty"`
+	// +kubebuilder:validation:Pattern=`^(([a-zA-Z0-9\*]|[a-zA-Z0-9][a-zA-Z0-9\-]*[a-zA-Z0-9])\.)*([A-Za-z0-9]|[A-Za-z0-9][A-Za-z0-9\-]*[A-Za-z0-9])$`
+	Host      string                `json:"host,omitempty"`
+	Selector  *metav1.LabelSelector `json:"selector"`
+	IRules    []string              `json:"iRules,omitempty"`
+	IPAMLabel string                `json:"ipamLabel"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+
+// IngressLinkList is list of IngressLink
+type IngressLinkList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []IngressLink `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:unservedversion
+// +kubebuilder:validation:Optional
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=ts
+// +kubebuilder:printcolumn:name="virtualServerAddress",type="string",JSONPath=".spec.virtualServerAddress",description="IP address of virtualServer"
+// +kubebuilder:printcolumn:name="virtualServerPort",type="integer",JSONPath=".spec.virtualServerPort",description="Port of virtualServer"
+// +kubebuilder:printcolumn:name="pool",type="string",JSONPath=".spec.pool.service",description="Name of service"
+// +kubebuilder:printcolumn:name="poolPort",type="string",JSONPath=".spec.pool.servicePort",description="Port of service"
+// +kubebuilder:printcolumn:name="ipamLabel",type="string",JSONPath=".spec.ipamLabel",description="ipamLabel for transport server"
+// +kubebuilder:printcolumn:name="IPAMVSAddress",type="string",JSONPath=".status.vsAddress",description="IP address of transport server"
+// +kubebuilder:printcolumn:name="STATUS",type="string",JSONPath=".status.status",description="status of TransportServer"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// TransportServer defines the VirtualServer resource.
+type TransportServer struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TransportServerSpec   `json:"spec"`
+	Status TransportServerStatus `json:"status,omitempty"`
+}
+
+// TransportServerStatus is the status of the VirtualServer resource.
+type TransportServerStatus struct {
+	// +kubebuilder:default=None
+	VSAddress string `json:"vsAddress,omitempty"`
+	// +kubebuilder:default=Pending
+	StatusOk string `json:"status,omitempty"`
+}
+
+// TransportServerSpec is the spec of the VirtualServer resource.
+type TransportServerSpec struct {
+	// +kubebuilder:validation:Pattern=`^(([0-9]|[1-9][0-9]|1[0-9]{2}|2[0-4][0-9]|25[0-5])\.){3}([0-9]|[1-9][0-9]|1[0-9]{2}|2[0-4][0-9]|25[0-5])|(([0-9a-fA-F]{1,4}:){7,7}[0-9a-fA-F]{1,4}|([0-9a-fA-F]{1,4}:){1,7}:|([0-9a-fA-F]{1,4}:){1,6}:[0-9a-fA-F]{1,4}|([0-9a-fA-F]{1,4}:){1,5}(:[0-9a-fA-F]{1,4}){1,2}|([0-9a-fA-F]{1,4}:){1,4}(:[0-9a-fA-F]{1,4}){1,3}|([0-9a-fA-F]{1,4}:){1,3}(:[0-9a-fA-F]{1,4}){1,4}|([0-9a-fA-F]{1,4}:){1,2}(:[0-9a-fA-F]{1,4}){1,5}|[0-9a-fA-F]{1,4}:((:[0-9a-fA-F]{1,4}){1,6})|:((:[0-9a-fA-F]{1,4}){1,7}|:)|fe80:(:[0-9a-fA-F]{0,4}){0,4}%[0-9a-zA-Z]{1,}|::(ffff(:0{1,4}){0,1}:){0,1}((25[0-5]|(2[0-4]|1{0,1}[0-9]){0,1}[0-9])\.){3,3}(25[0-5]|(2[0-4]|1{0,1}[0-9]){0,1}[0-9])|([0-9a-fA-F]{1,4}:){1,4}:((25[0-5]|(2[0-4]|1{0,1}[0-9]){0,1}[0-9])\.){3,3}(25[0-5]|(2[0-4]|1{0,1}[0-9]){0,1}[0-9]))$`
+	VirtualServerAddress string `json:"virtualServerAddress"`
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	VirtualServerPort int32 `json:"virtualServerPort"`
+	// +kubebuilder:validation:Pattern=`^([A-z0-9-_+])*([A-z0-9])$`
+	VirtualServerName string `json:"virtualServerName"`
+	// +kubebuilder:validation:Pattern=`^(([a-zA-Z0-9\*]|[a-zA-Z0-9][a-zA-Z0-9\-]*[a-zA-Z0-9])\.)*([A-Za-z0-9]|[A-Za-z0-9][A-Za-z0-9\-]*[A-Za-z0-9])$`
+	Host string `json:"host,omitempty"`
+	// +kubebuilder:validation:Pattern=`^([A-z0-9-_+])*([A-z0-9])$`
+	HostGroup string `json:"hostGroup,omitempty"`
+	// +kubebuilder:validation:Enum=standard;performance
+	Mode string `json:"mode"`
+	SNAT string `json:"snat"`
+	// +kubebuilder:validation:Required
+	Pool Pool `json:"pool"`
+	// +kubebuilder:validation:items:Pattern=`^\/([A-z0-9-_+]+\/)*([A-z0-9-_]+\/?)*$`
+	AllowVLANs []string `json:"allowVlans,omitempty"`
+	// +kubebuilder:validation:Enum=tcp;udp;sctp
+	Type string `json:"type,omitempty"`
+	// +kubebuilder:validation:MaxItems=1
+	ServiceIPAddress []ServiceAddress `json:"serviceAddress"`
+	IPAMLabel        string           `json:"ipamLabel"`
+	IRules           []string         `json:"iRules,omitempty"`
+	// +kubebuilder:validation:Pattern=`^([A-z0-9-_+])*([A-z0-9])$`
+	PolicyName string `json:"policyName,omitempty"`
+	// +kubebuilder:validation:Pattern=`^\/([A-z0-9-_+]+\/)+([A-z0-9-]+\/?)*$`
+	DOS        string `json:"dos,omitempty"`
+	BotDefense string `json:"botDefense,omitempty"`
+	// Profiles holds the profiles of the virtual, including the persistence and L4 profiles
+	Profiles    ProfileSpec `json:"profiles,omitempty"`
+	AS3Override string      `json:"as3Override,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+
+// TransportServerList is list of TransportServer
+type TransportServerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []TransportServer `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:unservedversion
+// +kubebuilder:validation:Optional
+// +kubebuilder:resource:shortName=edns
+// +kubebuilder:printcolumn:name="domainName",type="string",JSONPath=".spec.domainName",description="Domain name of virtual server resource"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="CREATED ON",type="string",JSONPath=".metadata.creationTimestamp"
+
+// ExternalDNS defines the DNS resource.
+type ExternalDNS struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ExternalDNSSpec `json:"spec"`
+}
+
+type ExternalDNSSpec struct {
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^(\*\.)?(([a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9\-]*[a-zA-Z0-9])\.)*([A-Za-z0-9]|[A-Za-z0-9][A-Za-z0-9\-]*[A-Za-z0-9])$`
+	DomainName string `json:"domainName"`
+	// +kubebuilder:validation:Enum=A;AAAA;CNAME;MX
+	DNSRecordType string `json:"dnsRecordType"`
+	// +kubebuilder:validation:Enum=global-availability;ratio;round-robin;topology
+	LoadBalanceMethod string    `json:"loadBalanceMethod"`
+	Pools             []DNSPool `json:"pools"`
+	// Persistence answers a client with the same pool member
+	Persistence DNSPersistence `json:"persistence,omitempty"`
+	// LastResortPool is the path of the GSLB pool on BIG-IP answering when
+	// none of the pools is available
+	// +kubebuilder:validation:Pattern=`^\/([A-z0-9-_.]+\/)+[A-z0-9-_.]+$`
+	LastResortPool string `json:"lastResortPool,omitempty"`
+}
+
+type DNSPersistence struct {
+	Enabled bool `json:"enabled"`
+	// +kubebuilder:validation:Minimum=0
+	TTL int32 `json:"ttl,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=32
+	CidrIPv4 int32 `json:"cidrIPv4,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=128
+	CidrIPv6 int32 `json:"cidrIPv6,omitempty"`
+}
+
+type DNSPool struct {
+	// +kubebuilder:validation:Required
+	DataServerName string `json:"dataServerName"`
+	// +kubebuilder:validation:Enum=A;AAAA;CNAME;MX
+	DNSRecordType string `json:"dnsRecordType"`
+	// +kubebuilder:validation:Enum=drop-packet;fallback-ip;global-availability;packet-rate;ratio;return-to-dns;round-robin;static-persistence;topology;virtual-server-capacity;virtual-server-score;none
+	LoadBalanceMethod string `json:"loadBalanceMethod"`
+	// +kubebuilder:validation:Minimum=0
+	PriorityOrder int       `json:"order"`
+	Monitors      []Monitor `json:"monitors,omitempty"`
+	// DataCenter is the GSLB data center of the data server, it names the
+	// GSLB pool so that an ExternalDNS can span several data centers
+	DataCenter string `json:"dataCenter,omitempty"`
+	// VirtualServers are the virtuals of the data server served by another CIS
+	// instance, the pool is not populated with the local virtuals when set
+	// +kubebuilder:validation:items:Pattern=`^\/([A-z0-9-_.]+\/)+[A-z0-9-_.]+$`
+	VirtualServers []string `json:"virtualServers,omitempty"`
+	// FallbackIP answers the requests when none of the members is available
+	// +kubebuilder:validation:Format=ipv4
+	FallbackIP string `json:"fallbackIP,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	TTL int32 `json:"ttl,omitempty"`
+	// Targets are the domain names answered by CNAME and MX pools
+	Targets []DNSTarget `json:"targets,omitempty"`
+}
+
+type DNSTarget struct {
+	// DomainName is a static domain name for CNAME pools and the domain of
+	// another ExternalDNS for MX pools
+	// +kubebuilder:validation:Required
+	DomainName string `json:"domainName"`
+	// Priority of the MX record
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65535
+	Priority int32 `json:"priority,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+
+// ExternalDNSList is list of ExternalDNS
+type ExternalDNSList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ExternalDNS `json:"items"`
+}
+
+type PolicySpec struct {
+	L7Policies  L7PolicySpec  `json:"l7Policies,omitempty"`
+	L3Policies  L3PolicySpec  `json:"l3Policies,omitempty"`
+	LtmPolicies LtmIRulesSpec `json:"ltmPolicies,omitempty"`
+	IRules      LtmIRulesSpec `json:"iRules,omitempty"`
+	Profiles    ProfileSpec   `json:"profiles,omitempty"`
+	RateLimit   RateLimitSpec `json:"rateLimit,omitempty"`
+	SNAT        string        `json:"snat,omitempty"`
+	// TargetSelector attaches the policy to the objects it selects,
+	// which do not refer to a policy by name
+	TargetSelector *PolicyTargetSelector `json:"targetSelector,omitempty"`
+	// Precedence orders the policies selecting the same object, the highest wins
+	Precedence int32 `json:"precedence,omitempty"`
+	// AutoExternalDNS synthesizes a wide IP for each host of the virtuals of
+	// the policy, which are not declared by an ExternalDNS
+	AutoExternalDNS *AutoExternalDNSSpec `json:"autoExternalDNS,omitempty"`
+}
+
+// AutoExternalDNSSpec is the pool of the wide IPs synthesized for the hosts
+type AutoExternalDNSSpec struct {
+	// +kubebuilder:validation:Required
+	DataServerName string `json:"dataServerName"`
+	// +kubebuilder:validation:Enum=global-availability;ratio;round-robin;topology
+	LoadBalanceMethod string    `json:"loadBalanceMethod,omitempty"`
+	Monitors          []Monitor `json:"monitors,omitempty"`
+}
+
+// PolicyTargetSelector selects the VirtualServers, TransportServers and
+// Services of type LoadBalancer a Policy is attached to.
+// Without a NamespaceSelector only the namespace of the Policy is selected.
+type PolicyTargetSelector struct {
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	ObjectSelector    *metav1.LabelSelector `json:"objectSelector,omitempty"`
+}
+
+type L7PolicySpec struct {
+	// +kubebuilder:validation:Pattern=`^\/([A-z0-9-_+]+\/)+([A-z0-9]+\/?)*$`
+	WAF string `json:"waf,omitempty"`
+}
+
+type L3PolicySpec struct {
+	// +kubebuilder:validation:Pattern=`^\/([A-z0-9-_+]+\/)+([A-z0-9]+\/?)*$`
+	DOS string `json:"dos,omitempty"`
+	// +kubebuilder:validation:Pattern=`^\/([A-z0-9-_+]+\/)+([A-z0-9-]+\/?)*$`
+	BotDefense string `json:"botDefense,omitempty"`
+	// +kubebuilder:validation:Pattern=`^\/([A-z0-9-_+]+\/)+([A-z0-9]+\/?)*$`
+	FirewallPolicy string `json:"firewallPolicy,omitempty"`
+	// +kubebuilder:validation:items:Format=cidr
+	AllowSourceRange []string `json:"allowSourceRange,omitempty"`
+	// +kubebuilder:validation:items:Pattern=`^\/([A-z0-9-_+]+\/)*([A-z0-9-_\s]+\/?)*$`
+	AllowVlans []string `json:"allowVlans,omitempty"`
+	// MaxConnections caps the concurrent connections of the virtual, 0 means unlimited
+	// +kubebuilder:validation:Minimum=0
+	MaxConnections int32 `json:"maxConnections,omitempty"`
+	// ConnectionRateLimit caps the new connections per second of the virtual, 0 means unlimited
+	// +kubebuilder:validation:Minimum=0
+	ConnectionRateLimit int32 `json:"connectionRateLimit,omitempty"`
+}
+
+// +kubebuilder:validation:XValidation:rule="!has(self.key) || self.key != 'header' || (has(self.header) && self.header != '')",message="header is required when key is header"
+
+// RateLimitSpec limits the HTTP requests per second accepted from a single client
+type RateLimitSpec struct {
+	// +kubebuilder:validation:Minimum=1
+	RequestsPerSecond int32 `json:"requestsPerSecond,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	Burst int32 `json:"burst,omitempty"`
+	// Key identifies the client, either clientIP (default) or header
+	// +kubebuilder:validation:Enum=clientIP;header
+	Key string `json:"key,omitempty"`
+	// Header is the name of the header identifying the client, with the key header
+	// +kubebuilder:validation:Pattern="^[!#$%&'*+.^_`|~0-9A-Za-z-]+$"
+	Header string `json:"header,omitempty"`
+	// +kubebuilder:validation:Minimum=100
+	// +kubebuilder:validation:Maximum=599
+	ResponseCode int32 `json:"responseCode,omitempty"`
+}
+
+type LtmIRulesSpec struct {
+	// +kubebuilder:validation:Pattern=`^\/([A-z0-9-_+]+\/)+([A-z0-9]+\/?)*$`
+	Secure string `json:"secure,omitempty"`
+	// +kubebuilder:validation:Pattern=`^\/([A-z0-9-_+]+\/)+([A-z0-9]+\/?)*$`
+	InSecure string `json:"insecure,omitempty"`
+	// +kubebuilder:validation:Enum=low;high
+	Priority string `json:"priority,omitempty"`
+}
+
+type ProfileSpec struct {
+	TCP ProfileTCP `json:"tcp,omitempty"`
+	// +kubebuilder:validation:Pattern=`^\/([A-z0-9-_+]+\/)+([A-z0-9-]+\/?)*$`
+	UDP string `json:"udp,omitempty"`
+	// +kubebuilder:validation:Pattern=`^\/([A-z0-9-_+]+\/)+([A-z0-9-]+\/?)*$`
+	HTTP string `json:"http,omitempty"`
+	// +kubebuilder:validation:Pattern=`^\/([A-z0-9-_+]+\/)+([A-z0-9-]+\/?)*$`
+	HTTP2 string `json:"http2,omitempty"`
+	// +kubebuilder:validation:Pattern=`^\/([A-z0-9-_+]+\/)+([A-z0-9]+\/?)*$`
+	RewriteProfile     string `json:"rewriteProfile,omitempty"`
+	PersistenceProfile string `json:"persistenceProfile,omitempty"`
+	// +kubebuilder:validation:items:Pattern=`^\/([A-z0-9-_+]+\/)*([A-z0-9-_\s]+\/?)*$`
+	LogProfiles []string `json:"logProfiles,omitempty"`
+	// +kubebuilder:validation:Pattern=`^\/([A-z0-9-_+]+\/)+([A-z0-9-]+\/?)*$`
+	ProfileL4 string `json:"profileL4,omitempty"`
+	// +kubebuilder:validation:Pattern=`^\/([A-z0-9-_+]+\/)+([A-z0-9-]+\/?)*$`
+	ProfileMultiplex string `json:"profileMultiplex,omitempty"`
+}
+type ProfileTCP struct {
+	// +kubebuilder:validation:Pattern=`^\/([A-z0-9-_+]+\/)+([A-z0-9-]+\/?)*$`
+	Client string `json:"client,omitempty"`
+	// +kubebuilder:validation:Pattern=`^\/([A-z0-9-_+]+\/)+([A-z0-9-]+\/?)*$`
+	Server string `json:"server,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:unservedversion
+// +kubebuilder:resource:shortName=plc
+
+// Policy describes a Policy custom resource.
+type Policy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PolicySpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+
+// PolicyList is list of Policy resources
+type PolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []Policy `json:"items"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:unservedversion
+// +kubebuilder:resource:scope=Cluster,shortName=dc
+
+// DeployConfig describes a cluster scoped DeployConfig custom resource which
+// holds the defaults applied to every virtual created by CIS.
+type DeployConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec DeployConfigSpec `json:"spec"`
+}
+
+// DeployConfigSpec is the spec of the DeployConfig resource.
+// Empty fields leave the corresponding CLI defaults in effect.
+type DeployConfigSpec struct {
+	TLS  DefaultTLSSpec `json:"tls,omitempty"`
+	SNAT string         `json:"snat,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	HTTPPort int32 `json:"httpPort,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	HTTPSPort int32 `json:"httpsPort,omitempty"`
+	// +kubebuilder:validation:items:Pattern=`^\/([A-z0-9-_+]+\/)*([A-z0-9-_]+\/?)*$`
+	AllowVlans []string `json:"allowVlans,omitempty"`
+	// +kubebuilder:validation:items:Pattern=`^\/([A-z0-9-_+]+\/)*([A-z0-9-_\s]+\/?)*$`
+	LogProfiles []string `json:"logProfiles,omitempty"`
+	// DefaultRouteDomain is the route domain of the partitions, 0 falls back to
+	// the --default-route-domain of CIS
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65534
+	DefaultRouteDomain int32 `json:"defaultRouteDomain,omitempty"`
+	// DefaultPolicy is the <namespace>/<name> of the Policy attached to the
+	// resources which are not attached to any other Policy
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?\/[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`
+	DefaultPolicy string `json:"defaultPolicy,omitempty"`
+}
+
+// DefaultTLSSpec is the TLS baseline of the DeployConfig resource.
+type DefaultTLSSpec struct {
+	ClientSSL string `json:"clientSSL,omitempty"`
+	ServerSSL string `json:"serverSSL,omitempty"`
+	// +kubebuilder:validation:Enum="1.0";"1.1";"1.2";"1.3"
+	TLSVersion string `json:"tlsVersion,omitempty"`
+	Ciphers    string `json:"ciphers,omitempty"`
+	// +kubebuilder:validation:Pattern=`^\/([A-z0-9-_+]+\/)+([A-z0-9-_]+\/?)*$`
+	CipherGroup string `json:"cipherGroup,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+
+// DeployConfigList is list of DeployConfig resources
+type DeployConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []DeployConfig `json:"items"`
+}
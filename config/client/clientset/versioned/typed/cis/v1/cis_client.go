@@ -26,6 +26,7 @@ import (
 
 type CisV1Interface interface {
 	RESTClient() rest.Interface
+	DeployConfigsGetter
 	ExternalDNSesGetter
 	IngressLinksGetter
 	PoliciesGetter
@@ -39,6 +40,10 @@ type CisV1Client struct {
 	restClient rest.Interface
 }
 
+func (c *CisV1Client) DeployConfigs() DeployConfigInterface {
+	return newDeployConfigs(c)
+}
+
 func (c *CisV1Client) ExternalDNSes(namespace string) ExternalDNSInterface {
 	return newExternalDNSes(c, namespace)
 }
@@ -28,6 +28,10 @@ type FakeCisV1 struct {
 	*testing.Fake
 }
 
+func (c *FakeCisV1) DeployConfigs() v1.DeployConfigInterface {
+	return &FakeDeployConfigs{c}
+}
+
 func (c *FakeCisV1) ExternalDNSes(namespace string) v1.ExternalDNSInterface {
 	return &FakeExternalDNSes{c, namespace}
 }
@@ -24,6 +24,8 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// DeployConfigs returns a DeployConfigInformer.
+	DeployConfigs() DeployConfigInformer
 	// ExternalDNSes returns a ExternalDNSInformer.
 	ExternalDNSes() ExternalDNSInformer
 	// IngressLinks returns a IngressLinkInformer.
@@ -49,6 +51,11 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// DeployConfigs returns a DeployConfigInformer.
+func (v *version) DeployConfigs() DeployConfigInformer {
+	return &deployConfigInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // ExternalDNSes returns a ExternalDNSInformer.
 func (v *version) ExternalDNSes() ExternalDNSInformer {
 	return &externalDNSInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -18,6 +18,10 @@ limitations under the License.
 
 package v1
 
+// DeployConfigListerExpansion allows custom methods to be added to
+// DeployConfigLister.
+type DeployConfigListerExpansion interface{}
+
 // ExternalDNSListerExpansion allows custom methods to be added to
 // ExternalDNSLister.
 type ExternalDNSListerExpansion interface{}
@@ -0,0 +1,223 @@
+/*-
+* Copyright (c) 2016-2021, F5 Networks, Inc.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+
+	log "github.com/F5Networks/k8s-bigip-ctlr/pkg/vlogger"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// OverrideAS3TemplateKey is the data key of the AS3 override in the ConfigMap
+	OverrideAS3TemplateKey = "template"
+	// OverrideAS3Label stages the AS3 override ConfigMap when set to false
+	OverrideAS3Label = "overrideAS3"
+)
+
+// mergeAS3JSON merges src into dst recursively, values of src take precedence
+func mergeAS3JSON(src, dst interface{}) interface{} {
+	srcObj, ok := src.(map[string]interface{})
+	if !ok {
+		return src
+	}
+	dstObj, ok := dst.(map[string]interface{})
+	if !ok {
+		return srcObj
+	}
+	for key, dstVal := range dstObj {
+		if srcVal, ok := srcObj[key]; ok {
+			srcObj[key] = mergeAS3JSON(srcVal, dstVal)
+		} else {
+			srcObj[key] = dstVal
+		}
+	}
+	return srcObj
+}
+
+// overrideAS3Object returns the AS3 object with the JSON override merged into it
+func overrideAS3Object(obj interface{}, override string) (map[string]interface{}, error) {
+	var overrideObj map[string]interface{}
+	if err := json.Unmarshal([]byte(override), &overrideObj); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var as3Obj map[string]interface{}
+	if err := json.Unmarshal(data, &as3Obj); err != nil {
+		return nil, err
+	}
+	return mergeAS3JSON(overrideObj, as3Obj).(map[string]interface{}), nil
+}
+
+// processAS3OverridesForAS3 merges the AS3 override of each virtual into its AS3 Service
+func processAS3OverridesForAS3(rsMap ResourceMap, sharedApp as3Application) {
+	for _, cfg := range rsMap {
+		if cfg.Virtual.AS3Override == "" {
+			continue
+		}
+		svc, ok := sharedApp[cfg.Virtual.Name]
+		if !ok {
+			continue
+		}
+		merged, err := overrideAS3Object(svc, cfg.Virtual.AS3Override)
+		if err != nil {
+			log.Errorf("[AS3] Unable to override AS3 Service %v: %v", cfg.Virtual.Name, err)
+			continue
+		}
+		sharedApp[cfg.Virtual.Name] = merged
+	}
+}
+
+// processTenantAS3Overrides merges the AS3 overrides of the tenants into the
+// tenants of the declaration, overrides failing the AS3 schema are discarded
+func (agent *Agent) processTenantAS3Overrides(adc as3ADC, overrides map[string]string) {
+	for tenantName, override := range overrides {
+		tenant, ok := adc[tenantName].(as3Tenant)
+		// Tenants being removed are not overridden
+		if !ok || tenant[as3SharedApplication] == nil {
+			continue
+		}
+		merged, err := overrideAS3Object(tenant, override)
+		if err != nil {
+			log.Errorf("[AS3] Unable to override AS3 tenant %v: %v", tenantName, err)
+			continue
+		}
+		if agent.as3Validator != nil {
+			if err := agent.as3Validator.validateTenant(tenantName, tenantName, merged); err != nil {
+				log.Errorf("[AS3] Discarding invalid AS3 override of tenant %v: %v", tenantName, err)
+				continue
+			}
+		}
+		adc[tenantName] = as3Tenant(merged)
+	}
+}
+
+// handleAS3Override adds the AS3 override of the resource to the virtual once it
+// is validated against the AS3 Service of the virtual, returns true when the
+// validation result of the resource has changed
+func (ctlr *Controller) handleAS3Override(rsCfg *ResourceConfig, rscKey, override string) bool {
+	if override == "" {
+		return ctlr.as3OverrideErrors.set(rscKey, nil)
+	}
+	// Overrides of the resources sharing the virtual are merged in order
+	merged := override
+	if rsCfg.Virtual.AS3Override != "" {
+		mergedObj, err := overrideAS3Object(json.RawMessage(rsCfg.Virtual.AS3Override), override)
+		if err != nil {
+			return ctlr.as3OverrideErrors.set(rscKey, err)
+		}
+		data, _ := json.Marshal(mergedObj)
+		merged = string(data)
+	}
+	err := ctlr.validateAS3Override(rsCfg, merged)
+	if err != nil {
+		log.Errorf("Discarding invalid AS3 override of %v: %v", rscKey, err)
+	} else {
+		rsCfg.Virtual.AS3Override = merged
+	}
+	return ctlr.as3OverrideErrors.set(rscKey, err)
+}
+
+// validateAS3Override validates the AS3 Service of the virtual with the override merged
+func (ctlr *Controller) validateAS3Override(rsCfg *ResourceConfig, override string) error {
+	sharedApp := as3Application{
+		"class":    "Application",
+		"template": "shared",
+	}
+	switch rsCfg.MetaData.ResourceType {
+	case TransportServer:
+		createTransportServiceDecl(rsCfg, sharedApp)
+	default:
+		createServiceDecl(rsCfg, sharedApp, rsCfg.Virtual.Partition)
+	}
+	svc, err := overrideAS3Object(sharedApp[rsCfg.Virtual.Name], override)
+	if err != nil {
+		return err
+	}
+	if ctlr.Agent == nil || ctlr.Agent.as3Validator == nil {
+		return nil
+	}
+	sharedApp[rsCfg.Virtual.Name] = svc
+	// The virtuals are cached apart from the tenants, so that they do not evict each other
+	key := rsCfg.Virtual.Partition + "/" + rsCfg.Virtual.Name
+	return ctlr.Agent.as3Validator.validateTenant(key, rsCfg.Virtual.Partition, as3Tenant{
+		"class":              "Tenant",
+		as3SharedApplication: sharedApp,
+	})
+}
+
+// set records the validation error of the resource, returns true when it changed
+func (oe *as3OverrideErrors) set(rscKey string, err error) bool {
+	oe.Lock()
+	defer oe.Unlock()
+	prev, found := oe.errs[rscKey]
+	if err == nil {
+		delete(oe.errs, rscKey)
+		return found
+	}
+	if oe.errs == nil {
+		oe.errs = make(map[string]string)
+	}
+	oe.errs[rscKey] = err.Error()
+	return prev != err.Error()
+}
+
+func (oe *as3OverrideErrors) delete(rscKey string) {
+	oe.Lock()
+	defer oe.Unlock()
+	delete(oe.errs, rscKey)
+}
+
+// status returns the status of the resource with respect to its AS3 override
+func (oe *as3OverrideErrors) status(rscKey string) string {
+	oe.RLock()
+	defer oe.RUnlock()
+	if err, ok := oe.errs[rscKey]; ok {
+		return "Invalid AS3 override: " + err
+	}
+	return "Ok"
+}
+
+// processAS3OverrideConfigMap updates the AS3 overrides of the tenants from the
+// ConfigMap, which holds an AS3 declaration with the tenants to override
+func (ctlr *Controller) processAS3OverrideConfigMap(cm *v1.ConfigMap, isDelete bool) {
+	overrides := make(map[string]string)
+	if !isDelete && cm.Labels[OverrideAS3Label] != "false" {
+		var decl struct {
+			Declaration map[string]json.RawMessage `json:"declaration"`
+		}
+		if err := json.Unmarshal([]byte(cm.Data[OverrideAS3TemplateKey]), &decl); err != nil {
+			log.Errorf("Invalid AS3 override ConfigMap %v/%v: %v", cm.Namespace, cm.Name, err)
+			return
+		}
+		for tenant, override := range decl.Declaration {
+			var tenantObj map[string]interface{}
+			if err := json.Unmarshal(override, &tenantObj); err != nil {
+				log.Errorf("Invalid AS3 override of tenant %v in ConfigMap %v/%v: %v",
+					tenant, cm.Namespace, cm.Name, err)
+				continue
+			}
+			overrides[tenant] = string(override)
+		}
+	}
+	ctlr.resources.as3TenantOverrides = overrides
+}
@@ -0,0 +1,172 @@
+/*-
+* Copyright (c) 2016-2021, F5 Networks, Inc.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package controller
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/F5Networks/k8s-bigip-ctlr/pkg/vlogger"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+const (
+	// as3SchemaFileName is the AS3 schema bundled with CIS
+	as3SchemaFileName = "as3-schema-3.38.0-4-cis.json"
+)
+
+func newAS3SchemaValidator(schemaDir string, versionInfo as3VersionInfo) *as3SchemaValidator {
+	schemaVersion := versionInfo.as3SchemaVersion
+	if schemaVersion == "" {
+		schemaVersion = defaultAS3Version
+	}
+	return &as3SchemaValidator{
+		schemaURL:     getAS3SchemaURL(schemaDir, versionInfo),
+		schemaVersion: schemaVersion,
+		results:       make(map[string]as3ValidationResult),
+	}
+}
+
+// getAS3SchemaURL returns the URL of the AS3 schema matching the AS3 version of
+// BIG-IP when it is available in the local schema directory, otherwise the URL
+// of the bundled AS3 schema
+func getAS3SchemaURL(schemaDir string, versionInfo as3VersionInfo) string {
+	if !strings.HasPrefix(schemaDir, "file://") || versionInfo.as3Version == "" {
+		return schemaDir + as3SchemaFileName
+	}
+	dir := strings.TrimPrefix(schemaDir, "file://")
+	schemaFile := "as3-schema-" + versionInfo.as3Release + "-cis.json"
+	if _, err := os.Stat(filepath.Join(dir, schemaFile)); err == nil {
+		return schemaDir + schemaFile
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, "as3-schema-"+versionInfo.as3Version+"-*-cis.json"))
+	if len(matches) > 0 {
+		sort.Strings(matches)
+		return schemaDir + filepath.Base(matches[len(matches)-1])
+	}
+	log.Debugf("[AS3] Schema of AS3 version %v not found, using %v",
+		versionInfo.as3Release, as3SchemaFileName)
+	return schemaDir + as3SchemaFileName
+}
+
+// validate validates the AS3 declaration against the AS3 schema, the schema is
+// loaded on the first validation. Validation is skipped when the schema can not
+// be loaded, so that a missing schema does not block the declarations. Only the
+// latest result of each key is cached, as an unchanged tenant or virtual is
+// validated again with every update
+func (v *as3SchemaValidator) validate(key string, decl []byte) error {
+	v.Lock()
+	defer v.Unlock()
+
+	hash := fmt.Sprintf("%x", md5.Sum(decl))
+	if res, ok := v.results[key]; ok && res.hash == hash {
+		return res.err
+	}
+
+	if v.schema == nil {
+		if v.loadErr != nil {
+			return nil
+		}
+		schema, err := gojsonschema.NewSchema(gojsonschema.NewReferenceLoader(v.schemaURL))
+		if err != nil {
+			v.loadErr = err
+			log.Errorf("[AS3] Unable to load AS3 schema %v, skipping validation: %v", v.schemaURL, err)
+			return nil
+		}
+		log.Debugf("[AS3] Validating declarations with AS3 schema %v", v.schemaURL)
+		v.schema = schema
+	}
+
+	var err error
+	result, vErr := v.schema.Validate(gojsonschema.NewBytesLoader(decl))
+	if vErr != nil {
+		err = vErr
+	} else if !result.Valid() {
+		var errs []string
+		for _, desc := range result.Errors() {
+			errs = append(errs, desc.String())
+		}
+		err = fmt.Errorf("%v", strings.Join(errs, "; "))
+	}
+	v.results[key] = as3ValidationResult{hash: hash, err: err}
+	return err
+}
+
+// validateTenant validates the AS3 tenant within a declaration of its own, the
+// result is cached with the key
+func (v *as3SchemaValidator) validateTenant(key, tenantName string, tenant interface{}) error {
+	decl := map[string]interface{}{
+		"class": "AS3",
+		"declaration": map[string]interface{}{
+			"class":         "ADC",
+			"schemaVersion": v.schemaVersion,
+			tenantName:      tenant,
+		},
+	}
+	data, err := json.Marshal(decl)
+	if err != nil {
+		return err
+	}
+	return v.validate(key, data)
+}
+
+// quarantineInvalidTenants validates the updated tenants against the AS3 schema.
+// Invalid tenants are dropped from the incoming declaration, so that the last
+// good declaration of the tenant stays on BIG-IP, and are reported to the
+// resource status handler until their declaration is valid again
+func (agent *Agent) quarantineInvalidTenants(adc as3ADC) {
+	if agent.as3Validator == nil {
+		return
+	}
+	quarantined := make(map[string]string)
+	// The tenants which are not rebuilt remain quarantined
+	for tenant, err := range agent.quarantinedTenants {
+		if _, ok := adc[tenant]; !ok {
+			quarantined[tenant] = err
+		}
+	}
+	for tenant, decl := range agent.incomingTenantDeclMap {
+		if err := agent.as3Validator.validateTenant(tenant, tenant, decl); err != nil {
+			log.Errorf("[AS3] Quarantining tenant %v with invalid declaration: %v", tenant, err)
+			quarantined[tenant] = err.Error()
+			delete(agent.incomingTenantDeclMap, tenant)
+		}
+	}
+	for tenant := range agent.quarantinedTenants {
+		if _, ok := quarantined[tenant]; !ok {
+			log.Infof("[AS3] Releasing tenant %v from quarantine", tenant)
+		}
+	}
+	agent.quarantinedTenants = quarantined
+}
+
+// getResourceStatus returns the status of the VirtualServer or TransportServer
+// with respect to its AS3 override and the validity of the declaration of its tenant
+func (ctlr *Controller) getResourceStatus(rscKey, tenant string, invalidTenants map[string]string) string {
+	if status := ctlr.as3OverrideErrors.status(rscKey); status != "Ok" {
+		return status
+	}
+	if err, ok := invalidTenants[tenant]; ok {
+		return fmt.Sprintf("Invalid AS3 declaration of tenant %v: %v", tenant, err)
+	}
+	return "Ok"
+}
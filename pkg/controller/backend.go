@@ -27,9 +27,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/F5Networks/k8s-bigip-ctlr/pkg/health"
+	bigIPPrometheus "github.com/F5Networks/k8s-bigip-ctlr/pkg/prometheus"
 	rsc "github.com/F5Networks/k8s-bigip-ctlr/pkg/resource"
 	log "github.com/F5Networks/k8s-bigip-ctlr/pkg/vlogger"
+	"github.com/F5Networks/k8s-bigip-ctlr/pkg/vxlan"
 	"github.com/F5Networks/k8s-bigip-ctlr/pkg/writer"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 const (
@@ -78,6 +82,14 @@ func NewAgent(params AgentParams) *Agent {
 		userAgent:             params.UserAgent,
 		HttpAddress:           params.HttpAddress,
 		ccclGTMAgent:          params.CCCLGTMAgent,
+		postQuietPeriod:       time.Duration(params.PostQuietPeriod) * time.Millisecond,
+		postMaxDelay:          time.Duration(params.PostMaxDelay) * time.Second,
+		driftCheckInterval:    time.Duration(params.DriftCheckInterval) * time.Second,
+		driftPolicy:           params.DriftPolicy,
+	}
+	if params.DeclarationCacheDir != "" {
+		agent.declarationCache = newDeclarationCache(params.DeclarationCacheDir, params.Partition,
+			params.PostParams.BIGIPURL)
 	}
 	// agentWorker runs as a separate go routine
 	// blocks on postChan to get new/updated configuration to be posted to BIG-IP
@@ -87,6 +99,12 @@ func NewAgent(params AgentParams) *Agent {
 	// blocks on retryChan ; retries failed declarations and polls for accepted tenant statuses
 	go agent.retryWorker()
 
+	// driftReconciler runs as a separate go routine
+	// checks the tenants posted to BIG-IP for drift periodically
+	if agent.driftCheckInterval > 0 {
+		go agent.driftReconciler()
+	}
+
 	// If running in VXLAN mode, extract the partition name from the tunnel
 	// to be used in configuring a net instance of CCCL for that partition
 	var vxlanPartition string
@@ -134,8 +152,21 @@ func NewAgent(params AgentParams) *Agent {
 			GtmBigIPURL:      params.GTMParams.GTMBigIpUrl,
 		}
 	}
+	// VXLAN FDB records and ARP entries are configured from CIS, whichever GTM agent is used
+	agent.NetWriter = vxlan.NewBigIPNetWriter(vxlan.NetParams{
+		BIGIPUsername: params.PostParams.BIGIPUsername,
+		BIGIPPassword: params.PostParams.BIGIPPassword,
+		BIGIPURL:      params.PostParams.BIGIPURL,
+		TrustedCerts:  params.PostParams.TrustedCerts,
+		SSLInsecure:   params.PostParams.SSLInsecure,
+		Partition:     vxlanPartition,
+		DisableARP:    params.DisableARP,
+	})
 	//For IPV6 net config is not required. f5-sdk doesnt support ipv6
-	if !(params.EnableIPV6) {
+	// The Python driver is only needed by the CCCL GTM agent, so it does not configure the network
+	if !(params.EnableIPV6) && params.CCCLGTMAgent {
+		gs.VXLANPartition = ""
+		gs.DisableARP = true
 		agent.startPythonDriver(
 			gs,
 			bs,
@@ -143,6 +174,8 @@ func NewAgent(params AgentParams) *Agent {
 			params.PythonBaseDir,
 		)
 	}
+	//Enable "/health" and "/metrics" endpoint with controller
+	go agent.serveHealthAndMetrics()
 	// Set the AS3 version for the agent
 	err = agent.IsBigIPAppServicesAvailable()
 	if err != nil {
@@ -150,9 +183,28 @@ func NewAgent(params AgentParams) *Agent {
 		agent.Stop()
 		os.Exit(1)
 	}
+	// Tenants are validated against the AS3 schema of the detected AS3 version
+	if params.SchemaLocal != "" {
+		agent.as3Validator = newAS3SchemaValidator(params.SchemaLocal, agent.AS3VersionInfo)
+	}
+	// GTM configuration is posted to the GTM BIG-IP by its own agent
+	agent.GTMAgent = NewGTMAgent(params)
 	return agent
 }
 
+// serveHealthAndMetrics serves the "/health" and "/metrics" endpoints, the health
+// check tracks the Python driver when it runs
+func (agent *Agent) serveHealthAndMetrics() {
+	// Expose Prometheus metrics
+	http.Handle("/metrics", promhttp.Handler())
+	hc := &health.HealthChecker{
+		SubPID: agent.PythonDriverPID,
+	}
+	http.Handle("/health", hc.HealthCheckHandler())
+	bigIPPrometheus.RegisterMetrics()
+	log.Fatal(http.ListenAndServe(agent.HttpAddress, nil).Error())
+}
+
 func (agent *Agent) Stop() {
 	agent.ConfigWriter.Stop()
 	if !(agent.EnableIPV6) {
@@ -164,26 +216,35 @@ func (agent *Agent) Stop() {
 // compatible with BIG-IP, it will return with error if any one of the
 // requirements are not met
 func (agent *Agent) IsBigIPAppServicesAvailable() error {
-	version, build, schemaVersion, err := agent.PostManager.GetBigipAS3Version()
+	am, err := agent.PostManager.getAS3VersionInfo()
 	if err != nil {
-		log.Errorf("[AS3] %v ", err)
 		return err
 	}
+	agent.AS3VersionInfo = am
+	return nil
+}
+
+// getAS3VersionInfo fetches the AS3 version of the BIG-IP and verifies it is supported by CIS
+func (postMgr *PostManager) getAS3VersionInfo() (as3VersionInfo, error) {
+	version, build, schemaVersion, err := postMgr.GetBigipAS3Version()
+	if err != nil {
+		log.Errorf("[AS3] %v ", err)
+		return as3VersionInfo{}, err
+	}
 	am := as3VersionInfo{
 		as3Version:       version,
 		as3SchemaVersion: schemaVersion,
 		as3Release:       version + "-" + build,
 	}
-	agent.AS3VersionInfo = am
 	versionstr := version[:strings.LastIndex(version, ".")]
 	bigIPAS3Version, err := strconv.ParseFloat(versionstr, 64)
 	if err != nil {
 		log.Errorf("[AS3] Error while converting AS3 version to float")
-		return err
+		return am, err
 	}
 	if bigIPAS3Version >= as3SupportedVersion && bigIPAS3Version <= as3Version {
 		log.Debugf("[AS3] BIGIP is serving with AS3 version: %v", version)
-		return nil
+		return am, nil
 	}
 
 	if bigIPAS3Version > as3Version {
@@ -192,12 +253,11 @@ func (agent *Agent) IsBigIPAppServicesAvailable() error {
 		as3Build := defaultAS3Build
 		am.as3Release = am.as3Version + "-" + as3Build
 		log.Debugf("[AS3] BIGIP is serving with AS3 version: %v", bigIPAS3Version)
-		agent.AS3VersionInfo = am
-		return nil
+		return am, nil
 	}
 
-	return fmt.Errorf("CIS versions >= 2.0 are compatible with AS3 versions >= %v. "+
-		"Upgrade AS3 version in BIGIP from %v to %v or above.", as3SupportedVersion,
+	return am, fmt.Errorf("CIS versions >= 2.0 are compatible with AS3 versions >= %v. "+
+		"Upgrade AS3 version in BIGIP from %v to %v or above.", as3SupportedVersion,
 		bigIPAS3Version, as3SupportedVersion)
 }
 
@@ -208,33 +268,128 @@ func (agent *Agent) PostConfig(rsConfig ResourceConfigRequest) {
 	// Either Case1 or Case2 executes, which ensures the above
 	select {
 	case agent.postChan <- rsConfig:
-	case <-agent.postChan:
+	case prevConfig := <-agent.postChan:
+		// The partitions changed in the earlier config are rebuilt with the latest config
+		rsConfig.coalesce(prevConfig)
 		agent.postChan <- rsConfig
 
 	}
 }
 
+// coalesce merges an earlier config request into the latest one
+func (config *ResourceConfigRequest) coalesce(prevConfig ResourceConfigRequest) {
+	config.mergeDirtyPartitions(prevConfig)
+	config.events += prevConfig.events
+	config.priority = config.priority || prevConfig.priority
+}
+
+// debounceConfig waits for the config requests to settle. The latest request is
+// returned after postQuietPeriod without newer requests, or postMaxDelay after the
+// first one. Priority requests are returned right away
+func (agent *Agent) debounceConfig(rsConfig ResourceConfigRequest) ResourceConfigRequest {
+	if rsConfig.priority || agent.postQuietPeriod <= 0 {
+		return rsConfig
+	}
+	quiet := time.NewTimer(agent.postQuietPeriod)
+	defer quiet.Stop()
+	var maxDelay <-chan time.Time
+	if agent.postMaxDelay > 0 {
+		maxTimer := time.NewTimer(agent.postMaxDelay)
+		defer maxTimer.Stop()
+		maxDelay = maxTimer.C
+	}
+	for {
+		select {
+		case latestConfig := <-agent.postChan:
+			latestConfig.coalesce(rsConfig)
+			rsConfig = latestConfig
+			if rsConfig.priority {
+				return rsConfig
+			}
+			if !quiet.Stop() {
+				select {
+				case <-quiet.C:
+				default:
+				}
+			}
+			quiet.Reset(agent.postQuietPeriod)
+		case <-quiet.C:
+			return rsConfig
+		case <-maxDelay:
+			log.Debugf("[AS3] Posting the config after the max delay of %v", agent.postMaxDelay)
+			return rsConfig
+		}
+	}
+}
+
+// mergeDirtyPartitions adds the dirty partitions of an earlier config request
+func (config *ResourceConfigRequest) mergeDirtyPartitions(prevConfig ResourceConfigRequest) {
+	if config.dirtyPartitions == nil {
+		return
+	}
+	if prevConfig.dirtyPartitions == nil {
+		config.dirtyPartitions = nil
+		return
+	}
+	dirtyPartitions := make(map[string]struct{}, len(config.dirtyPartitions)+len(prevConfig.dirtyPartitions))
+	for partition := range prevConfig.dirtyPartitions {
+		dirtyPartitions[partition] = struct{}{}
+	}
+	for partition := range config.dirtyPartitions {
+		dirtyPartitions[partition] = struct{}{}
+	}
+	config.dirtyPartitions = dirtyPartitions
+}
+
+// isPartitionDirty checks if the tenant of the partition has to be rebuilt
+func (config ResourceConfigRequest) isPartitionDirty(partition string) bool {
+	if config.dirtyPartitions == nil {
+		return true
+	}
+	_, ok := config.dirtyPartitions[partition]
+	return ok
+}
+
 // agentWorker blocks on postChan
 // whenever it gets unblocked, it creates an as3 declaration for modified tenants and posts the request
 func (agent *Agent) agentWorker() {
 	for rsConfig := range agent.postChan {
+		// For the very first post after starting controller, need not wait to post
+		agent.declUpdate.Lock()
+		firstPost := agent.firstPost
+		agent.declUpdate.Unlock()
+		if !firstPost {
+			rsConfig = agent.debounceConfig(rsConfig)
+		}
+
 		// If there are no retries going on in parallel, acquiring lock will be straight forward.
 		// Otherwise, we will wait for retryWorker to complete its current iteration
 		agent.declUpdate.Lock()
 
 		// Fetch the latest config from channel
 		select {
-		case rsConfig = <-agent.postChan:
+		case latestConfig := <-agent.postChan:
+			latestConfig.coalesce(rsConfig)
+			rsConfig = latestConfig
 		case <-time.After(1 * time.Microsecond):
 		}
 
 		if !(agent.EnableIPV6) && agent.ccclGTMAgent {
 			agent.PostGTMConfig(rsConfig)
 		}
+		if agent.GTMAgent != nil {
+			agent.GTMAgent.PostConfig(agent.createAS3GTMConfigADC(rsConfig, as3ADC{}))
+		}
 
+		quarantinedTenants := agent.quarantinedTenants
 		decl := agent.createTenantAS3Declaration(rsConfig)
 
 		if len(agent.incomingTenantDeclMap) == 0 {
+			// Report the changes in quarantined tenants even if there is nothing to post
+			if len(quarantinedTenants)+len(agent.quarantinedTenants) > 0 &&
+				!reflect.DeepEqual(quarantinedTenants, agent.quarantinedTenants) {
+				agent.notifyRscStatusHandler(rsConfig.reqId, true)
+			}
 			agent.declUpdate.Unlock()
 			continue
 		}
@@ -259,6 +414,9 @@ func (agent *Agent) agentWorker() {
 			agent.tenantResponseMap[tenant] = tenantResponse{}
 		}
 
+		bigIPPrometheus.CoalescedEvents.Observe(float64(rsConfig.events))
+		log.Debugf("[AS3] Posting the config of %v resource events", rsConfig.events)
+
 		// Update the priority tenants first
 		if len(priorityTenants) > 0 {
 			agent.postTenantsDeclaration(decl, rsConfig, priorityTenants)
@@ -308,10 +466,14 @@ func (agent *Agent) notifyRscStatusHandler(id int, overwriteCfg bool) {
 	rscUpdateMeta := resourceStatusMeta{
 		id,
 		make(map[string]struct{}),
+		make(map[string]string),
 	}
 	for tenant := range agent.retryTenantDeclMap {
 		rscUpdateMeta.failedTenants[tenant] = struct{}{}
 	}
+	for tenant, err := range agent.quarantinedTenants {
+		rscUpdateMeta.invalidTenants[tenant] = err
+	}
 	// If triggerred from retry block, process the previous successful request completely
 	if !overwriteCfg {
 		agent.respChan <- rscUpdateMeta
@@ -353,7 +515,12 @@ func (agent *Agent) updatePoolMembers(rsConfig ResourceConfigRequest) {
 	for _, poolMem := range allPoolMembers {
 		allPoolMems = append(
 			allPoolMems,
-			rsc.Member(poolMem),
+			rsc.Member{
+				Address: poolMem.Address,
+				Port:    poolMem.Port,
+				SvcPort: poolMem.SvcPort,
+				Session: poolMem.Session,
+			},
 		)
 	}
 	if agent.EventChan != nil {
@@ -370,6 +537,7 @@ func (agent *Agent) updateTenantResponse(agentWorkerUpdate bool) {
 		Non 200 ok tenants will be added to retryTenantDeclMap map
 		Locks to update the map will be acquired in the calling method
 	*/
+	postedTenants := make(map[string]as3Tenant)
 	for tenant, resp := range agent.tenantResponseMap {
 		if resp.agentResponseCode == 200 {
 			// update cachedTenantDeclMap with successfully posted declaration
@@ -378,6 +546,7 @@ func (agent *Agent) updateTenantResponse(agentWorkerUpdate bool) {
 			} else {
 				agent.cachedTenantDeclMap[tenant] = agent.retryTenantDeclMap[tenant].as3Decl.(as3Tenant)
 			}
+			postedTenants[tenant] = agent.cachedTenantDeclMap[tenant]
 			// if received the 200 response remove the entry from tenantPriorityMap
 			if _, ok := agent.tenantPriorityMap[tenant]; ok {
 				delete(agent.tenantPriorityMap, tenant)
@@ -389,6 +558,7 @@ func (agent *Agent) updateTenantResponse(agentWorkerUpdate bool) {
 			agent.updateRetryMap(tenant, resp, agent.retryTenantDeclMap[tenant].as3Decl)
 		}
 	}
+	agent.declarationCache.update(postedTenants)
 }
 
 // retryWorker blocks on retryChan
@@ -542,7 +712,12 @@ func (agent *Agent) createTenantAS3Declaration(config ResourceConfigRequest) as3
 	// Re-initialise incomingTenantDeclMap map and tenantPriorityMap for each new config request
 	agent.incomingTenantDeclMap = make(map[string]as3Tenant)
 	agent.tenantPriorityMap = make(map[string]int)
-	for tenant, cfg := range agent.createAS3LTMAndGTMConfigADC(config) {
+	adc := agent.createAS3LTMAndGTMConfigADC(config)
+	for tenant, cfg := range adc {
+		if _, ok := agent.cachedTenantDeclMap[tenant]; !ok && agent.declarationCache.isPrimed(tenant, cfg.(as3Tenant)) {
+			// The tenant is unchanged since it was posted before the restart
+			agent.cachedTenantDeclMap[tenant] = cfg.(as3Tenant)
+		}
 		if !reflect.DeepEqual(cfg, agent.cachedTenantDeclMap[tenant]) {
 			agent.incomingTenantDeclMap[tenant] = cfg.(as3Tenant)
 		} else {
@@ -565,20 +740,27 @@ func (agent *Agent) createTenantAS3Declaration(config ResourceConfigRequest) as3
 	//	}
 	//}
 
+	// Invalid tenants are held back, BIG-IP retains their last good declaration
+	agent.quarantineInvalidTenants(adc)
+
 	return agent.createAS3Declaration(agent.incomingTenantDeclMap)
 }
 
 func (agent *Agent) createAS3Declaration(tenantDeclMap map[string]as3Tenant) as3Declaration {
+	return newAS3Declaration(tenantDeclMap, agent.AS3VersionInfo, agent.userAgent)
+}
+
+func newAS3Declaration(tenantDeclMap map[string]as3Tenant, versionInfo as3VersionInfo, userAgent string) as3Declaration {
 	var as3Config map[string]interface{}
 
-	baseAS3ConfigTemplate := fmt.Sprintf(baseAS3Config, agent.AS3VersionInfo.as3Version, agent.AS3VersionInfo.as3Release, agent.AS3VersionInfo.as3SchemaVersion)
+	baseAS3ConfigTemplate := fmt.Sprintf(baseAS3Config, versionInfo.as3Version, versionInfo.as3Release, versionInfo.as3SchemaVersion)
 	_ = json.Unmarshal([]byte(baseAS3ConfigTemplate), &as3Config)
 
 	adc := as3Config["declaration"].(map[string]interface{})
 
 	controlObj := make(map[string]interface{})
 	controlObj["class"] = "Controls"
-	controlObj["userAgent"] = agent.userAgent
+	controlObj["userAgent"] = userAgent
 	adc["controls"] = controlObj
 
 	for tenant, decl := range tenantDeclMap {
@@ -594,9 +776,20 @@ func (agent *Agent) createAS3Declaration(tenantDeclMap map[string]as3Tenant) as3
 
 func (agent *Agent) createAS3LTMAndGTMConfigADC(config ResourceConfigRequest) as3ADC {
 	adc := agent.createAS3LTMConfigADC(config)
-	if !agent.ccclGTMAgent {
+	if !agent.ccclGTMAgent && agent.GTMAgent == nil {
+		if config.dirtyPartitions != nil {
+			// GTM config is merged only into the tenants being rebuilt
+			gtmConfig := make(GTMConfig)
+			for pn, gtmPartitionConfig := range config.gtmConfig {
+				if config.isPartitionDirty(pn) {
+					gtmConfig[pn] = gtmPartitionConfig
+				}
+			}
+			config.gtmConfig = gtmConfig
+		}
 		adc = agent.createAS3GTMConfigADC(config, adc)
 	}
+	agent.processTenantAS3Overrides(adc, config.as3TenantOverrides)
 
 	return adc
 }
@@ -633,24 +826,52 @@ func (agent *Agent) createAS3GTMConfigADC(config ResourceConfigRequest, adc as3A
 				LBMode:     wideIP.LBMethod,
 				Pools:      make([]as3GSLBDomainPool, 0, len(wideIP.Pools)),
 			}
+			if wideIP.Persistence.Enabled {
+				gslbDomain.PersistenceEnabled = true
+				gslbDomain.TTLPersistence = wideIP.Persistence.TTL
+				gslbDomain.PersistCidrIPv4 = wideIP.Persistence.CidrIPv4
+				gslbDomain.PersistCidrIPv6 = wideIP.Persistence.CidrIPv6
+			}
+			if wideIP.LastResortPool != "" {
+				gslbDomain.LastResortPool = &as3ResourcePointer{BigIP: wideIP.LastResortPool}
+				gslbDomain.LastResortPoolType = wideIP.RecordType
+			}
 			for _, pool := range wideIP.Pools {
 				gslbPool := as3GSLBPool{
 					Class:      "GSLB_Pool",
 					RecordType: pool.RecordType,
 					LBMode:     pool.LBMethod,
-					Members:    make([]as3GSLBPoolMemberA, 0, len(pool.Members)),
+					FallbackIP: pool.FallbackIP,
+					TTL:        pool.TTL,
+					Members:    make([]as3GSLBPoolMember, 0, len(pool.Members)+len(pool.Targets)),
 					Monitors:   make([]as3ResourcePointer, 0, len(pool.Monitors)),
 				}
+				if pool.FallbackIP != "" {
+					gslbPool.LBModeFallback = "fallback-ip"
+				}
 
 				for _, mem := range pool.Members {
-					gslbPool.Members = append(gslbPool.Members, as3GSLBPoolMemberA{
+					gslbPool.Members = append(gslbPool.Members, as3GSLBPoolMember{
 						Enabled: true,
-						Server: as3ResourcePointer{
+						Server: &as3ResourcePointer{
 							BigIP: pool.DataServer,
 						},
 						VirtualServer: mem,
 					})
 				}
+				for _, target := range pool.Targets {
+					member := as3GSLBPoolMember{Enabled: true}
+					switch pool.RecordType {
+					case "CNAME":
+						// The CNAME targets are not wide IPs of BIG-IP
+						member.DomainName = target.DomainName
+						member.IsDomainNameStatic = true
+					case "MX":
+						member.DomainName = as3ResourcePointer{Use: gtmDomainName(target.DomainName)}
+						member.Priority = target.Priority
+					}
+					gslbPool.Members = append(gslbPool.Members, member)
+				}
 
 				for _, mon := range pool.Monitors {
 					gslbMon := as3GSLBMonitor{
@@ -661,6 +882,10 @@ func (agent *Agent) createAS3GTMConfigADC(config ResourceConfigRequest, adc as3A
 						Receive:  mon.Recv,
 						Timeout:  mon.Timeout,
 					}
+					if mon.TargetPort != 0 {
+						// Monitor the port on the address of each member
+						gslbMon.Target = fmt.Sprintf("*:%d", mon.TargetPort)
+					}
 
 					gslbPool.Monitors = append(gslbPool.Monitors, as3ResourcePointer{
 						Use: mon.Name,
@@ -672,7 +897,7 @@ func (agent *Agent) createAS3GTMConfigADC(config ResourceConfigRequest, adc as3A
 				sharedApp[pool.Name] = gslbPool
 			}
 
-			sharedApp[domainName] = gslbDomain
+			sharedApp[gtmDomainName(domainName)] = gslbDomain
 		}
 		adc[pn] = tenantDecl
 	}
@@ -683,6 +908,9 @@ func (agent *Agent) createAS3GTMConfigADC(config ResourceConfigRequest, adc as3A
 func (agent *Agent) createAS3LTMConfigADC(config ResourceConfigRequest) as3ADC {
 	adc := as3ADC{}
 	for tenantName, partitionConfig := range config.ltmConfig {
+		if !config.isPartitionDirty(tenantName) {
+			continue
+		}
 		// TODO partitionConfig priority can be overridden by another request if agent is unable to process the prioritized request in time
 		if partitionConfig.Priority > 0 {
 			agent.tenantPriorityMap[tenantName] = partitionConfig.Priority
@@ -725,6 +953,9 @@ func (agent *Agent) createAS3LTMConfigADC(config ResourceConfigRequest) as3ADC {
 
 		processDataGroupForAS3(partitionConfig.ResourceMap, sharedApp)
 
+		// AS3 overrides of the virtuals are merged last
+		processAS3OverridesForAS3(partitionConfig.ResourceMap, sharedApp)
+
 		// Create AS3 Tenant
 		tenantDecl := as3Tenant{
 			"class":              "Tenant",
@@ -846,6 +1077,9 @@ func createPoolDecl(cfg *ResourceConfig, sharedApp as3Application, shareNodes bo
 			if shareNodes {
 				member.ShareNodes = shareNodes
 			}
+			member.Ratio = val.Ratio
+			member.PriorityGroup = val.PriorityGroup
+			member.AdminState = val.AdminState
 			pool.Members = append(pool.Members, member)
 		}
 		for _, val := range v.MonitorNames {
@@ -890,7 +1124,8 @@ func processIrulesForCRD(cfg *ResourceConfig, svc *as3Service) {
 		if strings.HasSuffix(iRuleNoPort, HttpRedirectIRuleName) ||
 			strings.HasSuffix(iRuleNoPort, HttpRedirectNoHostIRuleName) ||
 			strings.HasSuffix(iRuleName, TLSIRuleName) ||
-			strings.HasSuffix(iRuleName, ABPathIRuleName) {
+			strings.HasSuffix(iRuleName, ABPathIRuleName) ||
+			strings.HasSuffix(iRuleName, RateLimitIRuleName) {
 
 			IRules = append(IRules, iRuleName)
 		} else {
@@ -964,6 +1199,8 @@ func createServiceDecl(cfg *ResourceConfig, sharedApp as3Application, tenant str
 			BigIP: cfg.Virtual.ProfileBotDefense,
 		}
 	}
+	svc.MaxConnections = cfg.Virtual.MaxConnections
+	svc.RateLimit = cfg.Virtual.ConnectionRateLimit
 
 	if len(cfg.Virtual.TCP.Client) > 0 || len(cfg.Virtual.TCP.Server) > 0 {
 		if cfg.Virtual.TCP.Client == "" {
@@ -1524,6 +1761,8 @@ func createTransportServiceDecl(cfg *ResourceConfig, sharedApp as3Application) {
 			BigIP: cfg.Virtual.ProfileBotDefense,
 		}
 	}
+	svc.MaxConnections = cfg.Virtual.MaxConnections
+	svc.RateLimit = cfg.Virtual.ConnectionRateLimit
 
 	if len(cfg.Virtual.TCP.Client) > 0 || len(cfg.Virtual.TCP.Server) > 0 {
 		if cfg.Virtual.TCP.Client == "" {
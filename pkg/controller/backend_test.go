@@ -2,7 +2,13 @@ package controller
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+	"time"
 
+	cisapiv2 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v2"
 	"github.com/F5Networks/k8s-bigip-ctlr/pkg/test"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -292,6 +298,27 @@ var _ = Describe("Backend Tests", func() {
 			Expect(agent.incomingTenantDeclMap["default"]).To(Equal(deletedTenantDecl), "Failed to Create AS3 Declaration for deleted tenant")
 			Expect(adc["default"]).To(Equal(map[string]interface{}(deletedTenantDecl)), "Failed to Create AS3 Declaration for deleted tenant")
 		})
+		It("Handles connection and request rate limits", func() {
+			rsCfg := &ResourceConfig{}
+			rsCfg.Virtual.Name = "crd_vs_172.13.14.15"
+			rsCfg.Virtual.Destination = "/test/172.13.14.5:8080"
+			rsCfg.Virtual.MaxConnections = 1000
+			rsCfg.Virtual.ConnectionRateLimit = 50
+			rsCfg.Virtual.IRules = []string{
+				"/test/crd_vs_172.13.14.15_" + RateLimitIRuleName,
+				"/Common/custom_irule",
+			}
+			sharedApp := as3Application{}
+			createServiceDecl(rsCfg, sharedApp, "test")
+
+			svc := sharedApp["crd_vs_172.13.14.15"].(*as3Service)
+			Expect(svc.MaxConnections).To(Equal(int32(1000)))
+			Expect(svc.RateLimit).To(Equal(int32(50)))
+			Expect(svc.IRules).To(Equal([]interface{}{
+				"crd_vs_172.13.14.15_" + RateLimitIRuleName,
+				&as3ResourcePointer{BigIP: "/Common/custom_irule"},
+			}))
+		})
 		It("Handles Persistence Methods", func() {
 			svc := &as3Service{}
 			// Default persistence methods
@@ -402,6 +429,223 @@ var _ = Describe("Backend Tests", func() {
 			Expect(sharedApp).To(HaveKey("pool1_monitor"))
 			Expect(sharedApp["pool1_monitor"].(as3GSLBMonitor).Class).To(Equal("GSLB_Monitor"))
 		})
+
+		It("GTM Config with fallback IP and monitor target", func() {
+			gtmConfig := GTMConfig{
+				DEFAULT_PARTITION: GTMPartitionConfig{
+					WideIPs: map[string]WideIP{
+						"test.com": {
+							DomainName: "test.com",
+							RecordType: "A",
+							LBMethod:   "topology",
+							Pools: []GSLBPool{
+								{
+									Name:       "pool1",
+									RecordType: "A",
+									LBMethod:   "round-robin",
+									DataServer: "/Common/DC2Server",
+									Members:    []string{"/default/Shared/vs1"},
+									FallbackIP: "10.9.0.10",
+									TTL:        60,
+									Monitors:   []Monitor{{Name: "pool1_monitor", Type: "tcp", TargetPort: 8443}},
+								},
+								{
+									Name:       "pool2",
+									RecordType: "A",
+									LBMethod:   "round-robin",
+									Monitors:   []Monitor{{Name: "pool2_monitor", Type: "tcp"}},
+								},
+							},
+						},
+					},
+				},
+			}
+			adc := agent.createAS3GTMConfigADC(
+				ResourceConfigRequest{gtmConfig: gtmConfig},
+				as3ADC{},
+			)
+			sharedApp := adc[DEFAULT_PARTITION].(as3Tenant)[as3SharedApplication].(as3Application)
+			Expect(sharedApp["test.com"].(as3GLSBDomain).LBMode).To(Equal("topology"))
+			Expect(sharedApp["test.com"].(as3GLSBDomain).Pools).To(Equal([]as3GSLBDomainPool{{Use: "pool1"}, {Use: "pool2"}}))
+
+			pool := sharedApp["pool1"].(as3GSLBPool)
+			Expect(pool.FallbackIP).To(Equal("10.9.0.10"))
+			Expect(pool.LBModeFallback).To(Equal("fallback-ip"))
+			Expect(pool.TTL).To(Equal(int32(60)))
+			Expect(pool.Members[0].Server.BigIP).To(Equal("/Common/DC2Server"))
+			Expect(sharedApp["pool1_monitor"].(as3GSLBMonitor).Target).To(Equal("*:8443"))
+
+			Expect(sharedApp["pool2"].(as3GSLBPool).LBModeFallback).To(BeEmpty())
+			Expect(sharedApp["pool2_monitor"].(as3GSLBMonitor).Target).To(BeEmpty(),
+				"The monitor should target the address and port of each member")
+		})
+
+		It("GTM Config with CNAME, MX and wildcard domains", func() {
+			gtmConfig := GTMConfig{
+				DEFAULT_PARTITION: GTMPartitionConfig{
+					WideIPs: map[string]WideIP{
+						"*.apps.example.com": {
+							DomainName:     "*.apps.example.com",
+							RecordType:     "A",
+							LBMethod:       "round-robin",
+							Persistence:    cisapiv2.DNSPersistence{Enabled: true, TTL: 300, CidrIPv4: 24},
+							LastResortPool: "/Common/sorry",
+							Pools: []GSLBPool{{
+								Name:       "wildcard_apps.example.com_pool",
+								RecordType: "A",
+								DataServer: "/Common/GSLBServer",
+								Members:    []string{"/default/Shared/vs1"},
+							}},
+						},
+						"www.example.com": {
+							DomainName: "www.example.com",
+							RecordType: "CNAME",
+							Pools: []GSLBPool{{
+								Name:       "cname_pool",
+								RecordType: "CNAME",
+								Targets:    []cisapiv2.DNSTarget{{DomainName: "app.cdn.net"}},
+							}},
+						},
+						"example.com": {
+							DomainName: "example.com",
+							RecordType: "MX",
+							Pools: []GSLBPool{{
+								Name:       "mx_pool",
+								RecordType: "MX",
+								Targets:    []cisapiv2.DNSTarget{{DomainName: "*.apps.example.com", Priority: 20}},
+							}},
+						},
+					},
+				},
+			}
+			adc := agent.createAS3GTMConfigADC(
+				ResourceConfigRequest{gtmConfig: gtmConfig},
+				as3ADC{},
+			)
+			sharedApp := adc[DEFAULT_PARTITION].(as3Tenant)[as3SharedApplication].(as3Application)
+
+			Expect(sharedApp).To(HaveKey("wildcard_apps.example.com"))
+			domain := sharedApp["wildcard_apps.example.com"].(as3GLSBDomain)
+			Expect(domain.DomainName).To(Equal("*.apps.example.com"))
+			Expect(domain.PersistenceEnabled).To(BeTrue())
+			Expect(domain.TTLPersistence).To(Equal(int32(300)))
+			Expect(domain.PersistCidrIPv4).To(Equal(int32(24)))
+			Expect(domain.LastResortPool).To(Equal(&as3ResourcePointer{BigIP: "/Common/sorry"}))
+			Expect(domain.LastResortPoolType).To(Equal("A"))
+
+			Expect(sharedApp["www.example.com"].(as3GLSBDomain).LastResortPool).To(BeNil())
+			Expect(sharedApp["cname_pool"].(as3GSLBPool).Members).To(Equal([]as3GSLBPoolMember{
+				{Enabled: true, DomainName: "app.cdn.net", IsDomainNameStatic: true},
+			}))
+			Expect(sharedApp["mx_pool"].(as3GSLBPool).Members).To(Equal([]as3GSLBPoolMember{
+				{Enabled: true, DomainName: as3ResourcePointer{Use: "wildcard_apps.example.com"}, Priority: 20},
+			}))
+		})
+	})
+
+	Describe("GTM Agent", func() {
+		var gtm *GTMAgent
+		var mockPM *mockPostManager
+		var adc as3ADC
+		BeforeEach(func() {
+			DEFAULT_PARTITION = "default"
+			mockPM = newMockPostManger()
+			mockPM.BIGIPURL = "gtm.bigip.com"
+			gtm = &GTMAgent{
+				PostManager:           mockPM.PostManager,
+				dataCenter:            "DC1",
+				serverName:            "bigip1",
+				serverAddress:         "10.1.1.1",
+				postChan:              make(chan as3ADC, 1),
+				retryChan:             make(chan struct{}, 1),
+				cachedTenantDeclMap:   make(map[string]as3Tenant),
+				incomingTenantDeclMap: make(map[string]as3Tenant),
+				retryTenantDeclMap:    make(map[string]*tenantParams),
+			}
+			agent := newMockAgent(nil)
+			adc = agent.createAS3GTMConfigADC(ResourceConfigRequest{
+				gtmConfig: GTMConfig{
+					DEFAULT_PARTITION: GTMPartitionConfig{
+						WideIPs: map[string]WideIP{
+							"test.com": {
+								DomainName: "test.com",
+								RecordType: "A",
+								LBMethod:   "round-robin",
+								Pools: []GSLBPool{
+									{
+										Name:       "pool1",
+										RecordType: "A",
+										LBMethod:   "round-robin",
+										DataServer: "/Common/Shared/bigip1",
+										Members:    []string{"vs1"},
+									},
+								},
+							},
+						},
+					},
+				},
+			}, as3ADC{})
+		})
+
+		It("Declares the data center and the server in Common", func() {
+			tenants := gtm.createGTMTenants(adc)
+			Expect(tenants).To(HaveKey(DEFAULT_PARTITION))
+			Expect(tenants).To(HaveKey(gtmPartition))
+			sharedApp := tenants[gtmPartition][as3SharedApplication].(as3Application)
+			Expect(sharedApp["DC1"]).To(Equal(as3GSLBDataCenter{Class: "GSLB_Data_Center"}))
+			Expect(sharedApp["bigip1"]).To(Equal(as3GSLBServer{
+				Class:           "GSLB_Server",
+				DataCenter:      as3ResourcePointer{Use: "DC1"},
+				Devices:         []as3GSLBServerDevice{{Address: "10.1.1.1"}},
+				VSDiscoveryMode: "enabled-no-delete",
+			}))
+
+			gtm.serverName = ""
+			Expect(gtm.createGTMTenants(adc)).NotTo(HaveKey(gtmPartition))
+		})
+
+		It("Posts the GTM tenants and tracks the failed ones", func() {
+			mockPM.setResponses([]responceCtx{
+				{tenant: gtmPartition, status: http.StatusOK},
+				{tenant: DEFAULT_PARTITION, status: http.StatusUnprocessableEntity},
+			}, http.MethodPost)
+			gtm.postTenants(adc)
+			Expect(gtm.cachedTenantDeclMap).To(HaveKey(gtmPartition))
+			Expect(gtm.cachedTenantDeclMap).NotTo(HaveKey(DEFAULT_PARTITION))
+			Expect(gtm.retryTenantDeclMap).To(HaveKey(DEFAULT_PARTITION))
+			Expect(gtm.retryTenantDeclMap[DEFAULT_PARTITION].agentResponseCode).To(Equal(http.StatusUnprocessableEntity))
+
+			mockPM.setResponses([]responceCtx{
+				{tenant: DEFAULT_PARTITION, status: http.StatusOK},
+			}, http.MethodPost)
+			gtm.retryTenants()
+			Expect(gtm.retryTenantDeclMap).To(BeEmpty())
+			Expect(gtm.cachedTenantDeclMap).To(HaveKey(DEFAULT_PARTITION))
+
+			// Unchanged tenants are not posted again
+			gtm.postTenants(adc)
+			Expect(gtm.incomingTenantDeclMap).To(BeEmpty())
+
+			// Tenants without GTM configuration are removed
+			mockPM.setResponses([]responceCtx{
+				{tenant: DEFAULT_PARTITION, status: http.StatusOK},
+			}, http.MethodPost)
+			gtm.postTenants(as3ADC{})
+			Expect(gtm.cachedTenantDeclMap[DEFAULT_PARTITION]).To(Equal(as3Tenant{"class": "Tenant"}))
+		})
+
+		It("Retries to get the AS3 version of the GTM BIG-IP", func() {
+			mockPM.setResponses([]responceCtx{
+				{status: http.StatusServiceUnavailable, body: `{"code": 503}`},
+				{status: http.StatusOK, body: `{"version": "3.38.0", "release": "4", "schemaCurrent": "3.38.0"}`},
+			}, http.MethodGet)
+			gtm.waitForAS3VersionInfo(time.Millisecond)
+			Expect(gtm.AS3VersionInfo).To(Equal(as3VersionInfo{
+				as3Version:       "3.38.0",
+				as3SchemaVersion: "3.38.0",
+				as3Release:       "3.38.0-4",
+			}))
+		})
 	})
 
 	Describe("Misc", func() {
@@ -440,4 +684,343 @@ var _ = Describe("Backend Tests", func() {
 		})
 	})
 
+	Describe("AS3 overrides", func() {
+		var agent *Agent
+		var rsCfg *ResourceConfig
+		var config ResourceConfigRequest
+		BeforeEach(func() {
+			writer := &test.MockWriter{
+				FailStyle: test.Success,
+				Sections:  make(map[string]interface{}),
+			}
+			agent = newMockAgent(writer)
+			workingDir, _ := os.Getwd()
+			agent.as3Validator = newAS3SchemaValidator("file://"+workingDir+"/../../schemas/", as3VersionInfo{})
+
+			rsCfg = &ResourceConfig{}
+			rsCfg.MetaData.Active = true
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Name = "crd_vs_172.13.14.15"
+			rsCfg.Virtual.Destination = "/test/172.13.14.5:8080"
+			rsCfg.Virtual.SNAT = "auto"
+			rsCfg.Virtual.AS3Override = `{"maxConnections": 500, "clonePools": {"ingress": {"bigip": "/Common/clone"}}}`
+			rsCfg.customProfiles = make(map[SecretKey]CustomProfile)
+
+			config = ResourceConfigRequest{
+				ltmConfig:          make(LTMConfig),
+				gtmConfig:          GTMConfig{},
+				defaultRouteDomain: 1,
+			}
+			config.ltmConfig["test"] = &PartitionConfig{make(ResourceMap), 0}
+			config.ltmConfig["test"].ResourceMap[rsCfg.Virtual.Name] = rsCfg
+		})
+
+		It("Merges the override of the virtual into its AS3 Service", func() {
+			adc := agent.createAS3LTMConfigADC(config)
+			svc := adc["test"].(as3Tenant)[as3SharedApplication].(as3Application)[rsCfg.Virtual.Name].(map[string]interface{})
+			Expect(svc["class"]).To(Equal("Service_HTTP"))
+			Expect(svc["maxConnections"]).To(BeEquivalentTo(500))
+			Expect(svc["clonePools"]).To(Equal(map[string]interface{}{
+				"ingress": map[string]interface{}{"bigip": "/Common/clone"},
+			}))
+		})
+
+		It("Merges the valid overrides of the tenants", func() {
+			config.as3TenantOverrides = map[string]string{
+				"test":    `{"Shared": {"crd_vs_172.13.14.15": {"remark": "overridden"}}}`,
+				"unknown": `{"Shared": {"class": "Application"}}`,
+			}
+			adc := agent.createAS3LTMAndGTMConfigADC(config)
+			Expect(adc).NotTo(HaveKey("unknown"), "Override should not create tenants")
+			svc := adc["test"].(as3Tenant)[as3SharedApplication].(map[string]interface{})[rsCfg.Virtual.Name].(map[string]interface{})
+			Expect(svc["remark"]).To(Equal("overridden"))
+			Expect(svc["maxConnections"]).To(BeEquivalentTo(500))
+
+			config.as3TenantOverrides["test"] = `{"Shared": {"crd_vs_172.13.14.15": {"maxConnections": "many"}}}`
+			adc = agent.createAS3LTMAndGTMConfigADC(config)
+			svc = adc["test"].(as3Tenant)[as3SharedApplication].(as3Application)[rsCfg.Virtual.Name].(map[string]interface{})
+			Expect(svc["maxConnections"]).To(BeEquivalentTo(500), "Invalid tenant override should be discarded")
+		})
+	})
+
+	Describe("AS3 schema validation", func() {
+		var agent *Agent
+		var schemaDir string
+		var config ResourceConfigRequest
+		newRsCfg := func(name, monitorType string) *ResourceConfig {
+			rsCfg := &ResourceConfig{}
+			rsCfg.MetaData.Active = true
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Name = name
+			rsCfg.Virtual.Destination = "/test/172.13.14.5:8080"
+			rsCfg.Virtual.SNAT = "auto"
+			rsCfg.Monitors = []Monitor{{Name: name + "_monitor", Type: monitorType, Interval: 5, Timeout: 16, Send: "GET /"}}
+			rsCfg.customProfiles = make(map[SecretKey]CustomProfile)
+			return rsCfg
+		}
+		BeforeEach(func() {
+			writer := &test.MockWriter{
+				FailStyle: test.Success,
+				Sections:  make(map[string]interface{}),
+			}
+			agent = newMockAgent(writer)
+			agent.cachedTenantDeclMap = make(map[string]as3Tenant)
+			agent.respChan = make(chan resourceStatusMeta, 1)
+			workingDir, _ := os.Getwd()
+			schemaDir = "file://" + workingDir + "/../../schemas/"
+			agent.as3Validator = newAS3SchemaValidator(schemaDir, as3VersionInfo{})
+
+			config = ResourceConfigRequest{
+				ltmConfig:          make(LTMConfig),
+				gtmConfig:          GTMConfig{},
+				defaultRouteDomain: 1,
+			}
+			config.ltmConfig["test"] = &PartitionConfig{make(ResourceMap), 0}
+			config.ltmConfig["test"].ResourceMap["crd_vs_1"] = newRsCfg("crd_vs_1", "http")
+			config.ltmConfig["bad"] = &PartitionConfig{make(ResourceMap), 0}
+			config.ltmConfig["bad"].ResourceMap["crd_vs_2"] = newRsCfg("crd_vs_2", "invalid")
+		})
+
+		It("Selects the AS3 schema of the AS3 version", func() {
+			Expect(getAS3SchemaURL(schemaDir, as3VersionInfo{})).To(Equal(schemaDir + as3SchemaFileName))
+			Expect(getAS3SchemaURL(schemaDir, as3VersionInfo{
+				as3Version: "3.38.0", as3Release: "3.38.0-4", as3SchemaVersion: "3.38.0",
+			})).To(Equal(schemaDir + "as3-schema-3.38.0-4-cis.json"))
+			Expect(getAS3SchemaURL(schemaDir, as3VersionInfo{
+				as3Version: "3.30.0", as3Release: "3.30.0-5", as3SchemaVersion: "3.30.0",
+			})).To(Equal(schemaDir+as3SchemaFileName), "Bundled schema should be the fallback")
+			validator := newAS3SchemaValidator("http://schemas/", as3VersionInfo{as3Version: "3.30.0", as3SchemaVersion: "3.30.0"})
+			Expect(validator.schemaURL).To(Equal("http://schemas/" + as3SchemaFileName))
+			Expect(validator.schemaVersion).To(Equal("3.30.0"))
+		})
+
+		It("Skips validation when the schema is not available", func() {
+			agent.as3Validator = newAS3SchemaValidator("file:///nonexistent/", as3VersionInfo{})
+			agent.createTenantAS3Declaration(config)
+			Expect(agent.incomingTenantDeclMap).To(HaveKey("bad"))
+			Expect(agent.quarantinedTenants).To(BeEmpty())
+		})
+
+		It("Quarantines the invalid tenants", func() {
+			decl := agent.createTenantAS3Declaration(config)
+			Expect(agent.incomingTenantDeclMap).To(HaveKey("test"))
+			Expect(agent.incomingTenantDeclMap).NotTo(HaveKey("bad"), "Invalid tenant should not be posted")
+			Expect(agent.quarantinedTenants).To(HaveKey("bad"))
+			Expect(string(decl)).NotTo(ContainSubstring("crd_vs_2"))
+
+			// Healthy tenant is posted and the last good declaration of the invalid tenant is retained
+			agent.cachedTenantDeclMap["test"] = agent.incomingTenantDeclMap["test"]
+			agent.notifyRscStatusHandler(1, true)
+			rscUpdateMeta := <-agent.respChan
+			Expect(rscUpdateMeta.invalidTenants).To(HaveKey("bad"))
+			Expect(rscUpdateMeta.invalidTenants).NotTo(HaveKey("test"))
+
+			ctlr := &Controller{}
+			Expect(ctlr.getResourceStatus("default/vs2", "bad", rscUpdateMeta.invalidTenants)).To(
+				HavePrefix("Invalid AS3 declaration of tenant bad:"))
+			Expect(ctlr.getResourceStatus("default/vs1", "test", rscUpdateMeta.invalidTenants)).To(Equal("Ok"))
+
+			// Fixed tenant is released from quarantine
+			config.ltmConfig["bad"].ResourceMap["crd_vs_2"] = newRsCfg("crd_vs_2", "tcp")
+			agent.createTenantAS3Declaration(config)
+			Expect(agent.incomingTenantDeclMap).To(HaveKey("bad"))
+			Expect(agent.incomingTenantDeclMap).NotTo(HaveKey("test"))
+			Expect(agent.quarantinedTenants).To(BeEmpty())
+		})
+
+		It("Retains the quarantine of the tenants which are not rebuilt", func() {
+			agent.createTenantAS3Declaration(config)
+			Expect(agent.quarantinedTenants).To(HaveKey("bad"))
+
+			config.ltmConfig["test"].ResourceMap["crd_vs_1"] = newRsCfg("crd_vs_1", "tcp")
+			config.dirtyPartitions = map[string]struct{}{"test": {}}
+			agent.createTenantAS3Declaration(config)
+			Expect(agent.incomingTenantDeclMap).To(HaveKey("test"))
+			Expect(agent.quarantinedTenants).To(HaveKey("bad"))
+		})
+
+		It("Caches the latest validation result of each tenant", func() {
+			agent.createTenantAS3Declaration(config)
+			Expect(agent.as3Validator.results).To(HaveLen(2))
+			badResult := agent.as3Validator.results["bad"]
+			Expect(badResult.err).To(HaveOccurred())
+
+			for _, monitorType := range []string{"tcp", "udp", "http"} {
+				config.ltmConfig["test"].ResourceMap["crd_vs_1"] = newRsCfg("crd_vs_1", monitorType)
+				agent.createTenantAS3Declaration(config)
+			}
+			Expect(agent.as3Validator.results).To(HaveLen(2), "Updated tenant should replace its result")
+			Expect(agent.as3Validator.results["bad"]).To(Equal(badResult))
+			Expect(agent.quarantinedTenants).To(HaveKey("bad"))
+		})
+	})
+
+	Describe("Incremental tenant rebuild", func() {
+		var agent *Agent
+		var config ResourceConfigRequest
+		BeforeEach(func() {
+			writer := &test.MockWriter{
+				FailStyle: test.Success,
+				Sections:  make(map[string]interface{}),
+			}
+			agent = newMockAgent(writer)
+			agent.cachedTenantDeclMap = make(map[string]as3Tenant)
+			config = newBenchmarkConfig(2, 2)
+		})
+
+		It("Rebuilds only the tenants of the dirty partitions", func() {
+			config.dirtyPartitions = map[string]struct{}{"tenant_1": {}}
+			adc := agent.createAS3LTMAndGTMConfigADC(config)
+			Expect(adc).To(HaveKey("tenant_1"))
+			Expect(adc).NotTo(HaveKey("tenant_0"))
+
+			config.dirtyPartitions = nil
+			adc = agent.createAS3LTMAndGTMConfigADC(config)
+			Expect(adc).To(HaveKey("tenant_0"))
+			Expect(adc).To(HaveKey("tenant_1"))
+		})
+
+		It("Merges the dirty partitions of the dropped config requests", func() {
+			config.dirtyPartitions = map[string]struct{}{"tenant_0": {}}
+			agent.PostConfig(config)
+			latestConfig := config
+			latestConfig.dirtyPartitions = map[string]struct{}{"tenant_1": {}}
+			agent.PostConfig(latestConfig)
+			rsConfig := <-agent.postChan
+			Expect(rsConfig.dirtyPartitions).To(Equal(map[string]struct{}{"tenant_0": {}, "tenant_1": {}}))
+
+			agent.PostConfig(ResourceConfigRequest{})
+			agent.PostConfig(latestConfig)
+			rsConfig = <-agent.postChan
+			Expect(rsConfig.dirtyPartitions).To(BeNil(), "All the partitions should be rebuilt")
+		})
+	})
+
+	Describe("Debounce config requests", func() {
+		var agent *Agent
+		BeforeEach(func() {
+			agent = newMockAgent(nil)
+			agent.postQuietPeriod = 50 * time.Millisecond
+			agent.postMaxDelay = 5 * time.Second
+		})
+
+		It("Coalesces the config requests until the quiet period", func() {
+			go func() {
+				for i := 0; i < 3; i++ {
+					agent.PostConfig(ResourceConfigRequest{reqId: i + 2, events: 2})
+					time.Sleep(10 * time.Millisecond)
+				}
+			}()
+			rsConfig := agent.debounceConfig(ResourceConfigRequest{reqId: 1, events: 1})
+			Expect(rsConfig.reqId).To(Equal(4), "Latest request should be posted")
+			Expect(rsConfig.events).To(Equal(7))
+			Expect(agent.postChan).To(BeEmpty())
+		})
+
+		It("Posts the config requests within the max delay", func() {
+			agent.postMaxDelay = 100 * time.Millisecond
+			done := make(chan struct{})
+			defer close(done)
+			go func() {
+				for {
+					select {
+					case <-done:
+						return
+					case <-time.After(10 * time.Millisecond):
+						agent.PostConfig(ResourceConfigRequest{events: 1})
+					}
+				}
+			}()
+			start := time.Now()
+			rsConfig := agent.debounceConfig(ResourceConfigRequest{events: 1})
+			Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+			Expect(rsConfig.events).To(BeNumerically(">", 1))
+		})
+
+		It("Posts the priority config requests right away", func() {
+			agent.postQuietPeriod = 5 * time.Second
+			start := time.Now()
+			rsConfig := agent.debounceConfig(ResourceConfigRequest{events: 1, priority: true})
+			Expect(rsConfig.priority).To(BeTrue())
+			Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+
+			go agent.PostConfig(ResourceConfigRequest{reqId: 2, events: 1, priority: true})
+			rsConfig = agent.debounceConfig(ResourceConfigRequest{reqId: 1, events: 1})
+			Expect(rsConfig.reqId).To(Equal(2))
+			Expect(rsConfig.events).To(Equal(2))
+			Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		})
+
+		It("Retains the priority of the dropped config requests", func() {
+			agent.PostConfig(ResourceConfigRequest{reqId: 1, events: 1, priority: true})
+			agent.PostConfig(ResourceConfigRequest{reqId: 2, events: 3})
+			rsConfig := <-agent.postChan
+			Expect(rsConfig.reqId).To(Equal(2))
+			Expect(rsConfig.events).To(Equal(4))
+			Expect(rsConfig.priority).To(BeTrue())
+		})
+	})
 })
+
+// newBenchmarkConfig creates a config request of the tenants with the virtuals
+func newBenchmarkConfig(tenants, virtuals int) ResourceConfigRequest {
+	config := ResourceConfigRequest{
+		ltmConfig:          make(LTMConfig),
+		shareNodes:         true,
+		gtmConfig:          GTMConfig{},
+		defaultRouteDomain: 1,
+	}
+	for i := 0; i < tenants; i++ {
+		partition := fmt.Sprintf("tenant_%d", i)
+		config.ltmConfig[partition] = &PartitionConfig{make(ResourceMap), 0}
+		for j := 0; j < virtuals; j++ {
+			rsCfg := &ResourceConfig{}
+			rsCfg.MetaData.Active = true
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Name = fmt.Sprintf("crd_vs_10.%d.%d.1", i, j)
+			rsCfg.Virtual.Destination = fmt.Sprintf("/%s/10.%d.%d.1:80", partition, i, j)
+			rsCfg.Virtual.SNAT = "auto"
+			rsCfg.Pools = Pools{
+				Pool{
+					Name: fmt.Sprintf("pool_%d_%d", i, j),
+					Members: []PoolMember{
+						{Address: fmt.Sprintf("10.244.%d.%d", i, j), Port: 8080},
+						{Address: fmt.Sprintf("10.245.%d.%d", i, j), Port: 8080},
+					},
+				},
+			}
+			rsCfg.customProfiles = make(map[SecretKey]CustomProfile)
+			config.ltmConfig[partition].ResourceMap[rsCfg.Virtual.Name] = rsCfg
+		}
+	}
+	return config
+}
+
+func benchmarkTenantAS3Declaration(b *testing.B, tenants, virtuals int, dirtyPartitions map[string]struct{}) {
+	config := newBenchmarkConfig(tenants, virtuals)
+	config.dirtyPartitions = dirtyPartitions
+	agent := &Agent{
+		cachedTenantDeclMap: make(map[string]as3Tenant),
+		userAgent:           "as3",
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		agent.createTenantAS3Declaration(config)
+	}
+}
+
+func BenchmarkTenantAS3DeclarationAllDirty50x20(b *testing.B) {
+	benchmarkTenantAS3Declaration(b, 50, 20, nil)
+}
+
+func BenchmarkTenantAS3DeclarationOneDirty50x20(b *testing.B) {
+	benchmarkTenantAS3Declaration(b, 50, 20, map[string]struct{}{"tenant_0": {}})
+}
+
+func BenchmarkTenantAS3DeclarationAllDirty200x20(b *testing.B) {
+	benchmarkTenantAS3Declaration(b, 200, 20, nil)
+}
+
+func BenchmarkTenantAS3DeclarationOneDirty200x20(b *testing.B) {
+	benchmarkTenantAS3Declaration(b, 200, 20, map[string]struct{}{"tenant_0": {}})
+}
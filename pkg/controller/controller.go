@@ -35,6 +35,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/util/workqueue"
@@ -57,6 +58,16 @@ const (
 	CustomPolicy = "CustomPolicy"
 	// IPAM is a F5 Custom Resource Kind
 	IPAM = "IPAM"
+	// DeployConfig is a cluster scoped F5 Custom Resource Kind holding the defaults of virtuals
+	DeployConfig = "DeployConfig"
+	// OverrideAS3ConfigMap is the ConfigMap holding the AS3 overrides of the tenants
+	OverrideAS3ConfigMap = "OverrideAS3ConfigMap"
+	// RemoteService is a change in the Service or Endpoints of a remote cluster
+	RemoteService = "RemoteService"
+	// RemoteCluster is a change in the reachability or the nodes of a remote cluster
+	RemoteCluster = "RemoteCluster"
+	// MultiClusterSecret is the secret holding the kubeconfig of a remote cluster
+	MultiClusterSecret = "MultiClusterSecret"
 	// Service is a k8s native Service Resource.
 	Service = "Service"
 	//Pod  is a k8s native object
@@ -124,12 +135,27 @@ func NewController(params Params) *Controller {
 		defaultRouteDomain: params.DefaultRouteDomain,
 		mode:               params.Mode,
 		namespaceLabel:     params.NamespaceLabel,
+
+		podProbeHealthMonitor: params.PodProbeHealthMonitor,
+		staticRoutingMode:     params.StaticRoutingMode,
+		staticRouteAnnotation: params.StaticRouteNodeCIDRAnnotation,
+		orchestrationCNI:      params.OrchestrationCNI,
+		deployConfig:          params.DeployConfig,
+		localClusterRatio:     params.LocalClusterRatio,
+		localClusterPriority:  params.LocalClusterPriority,
+		remoteClusters:        make(map[string]*RemoteClusterInformer),
+		as3OverrideCM:         params.OverrideAS3ConfigMap,
+		nodeExcludeTaint:      params.NodeExcludeTaint,
+		nodeExcludeLabel:      params.NodeExcludeLabel,
+		nodeDrainTimeout:      time.Duration(params.NodeDrainTimeout) * time.Second,
+		nodeDrainStart:        make(map[string]time.Time),
+		resourceWorkers:       params.ResourceWorkers,
 	}
 
 	log.Debug("Controller Created")
 
 	ctlr.resourceQueue = workqueue.NewNamedRateLimitingQueue(
-		workqueue.DefaultControllerRateLimiter(), "nextgen-resource-controller")
+		newResourceRateLimiter(params.ResourceQueueQPS, params.ResourceQueueBurst), "nextgen-resource-controller")
 	ctlr.comInformers = make(map[string]*CommonInformer)
 	ctlr.nrInformers = make(map[string]*NRInformer)
 	ctlr.crInformers = make(map[string]*CRInformer)
@@ -156,6 +182,10 @@ func NewController(params Params) *Controller {
 		log.Errorf("Failed to Setup Clients: %v", err)
 	}
 
+	if params.WebhookServerAddress != "" {
+		ctlr.webhook = newAdmissionWebhook(ctlr, params)
+	}
+
 	if ctlr.namespaceLabel == "" {
 		if len(params.Namespaces) == 0 {
 			ctlr.namespaces[""] = true
@@ -182,8 +212,11 @@ func NewController(params Params) *Controller {
 		log.Error("Failed to Setup Informers")
 	}
 
+	ctlr.setupRemoteClusters(params.MultiClusterSecrets)
+
 	err := ctlr.SetupNodePolling(
 		params.NodePollInterval,
+		params.NodeUpdateDebounce,
 		params.NodeLabelSelector,
 		params.VXLANMode,
 		params.VXLANName,
@@ -271,6 +304,7 @@ func (ctlr *Controller) createIPAMResource() error {
 	ipamCR, err := ctlr.ipamCli.Create(f5ipam)
 	if err == nil {
 		log.Debugf("[ipam] Created IPAM Custom Resource: \n%v\n", ipamCR)
+		ctlr.resources.ipamCR = ipamCR
 		return nil
 	}
 
@@ -285,6 +319,7 @@ func (ctlr *Controller) createIPAMResource() error {
 		ipamCR, err = ctlr.ipamCli.Create(f5ipam)
 		if err == nil {
 			log.Debugf("[ipam] Created IPAM Custom Resource: \n%v\n", ipamCR)
+			ctlr.resources.ipamCR = ipamCR
 			return nil
 		}
 	}
@@ -324,6 +359,11 @@ func (ctlr *Controller) setupClients(config *rest.Config) error {
 		return fmt.Errorf("Failed to create kubeClient: %v", err)
 	}
 
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("Failed to create dynamic kubeClient: %v", err)
+	}
+
 	var ipamCRConfig *rest.Config
 	if ipamCRConfig, err = rest.InClusterConfig(); err != nil {
 		log.Errorf("error creating client configuration: %v", err)
@@ -345,6 +385,7 @@ func (ctlr *Controller) setupClients(config *rest.Config) error {
 	ctlr.kubeAPIClient = kubeIPAMClient
 	ctlr.kubeCRClient = kubeCRClient
 	ctlr.kubeClient = kubeClient
+	ctlr.dynamicClient = dynamicClient
 	ctlr.routeClientV1 = rclient
 	return nil
 }
@@ -356,6 +397,18 @@ func (ctlr *Controller) setupInformers() error {
 			return err
 		}
 	}
+	if ctlr.deployConfig != "" {
+		if err := ctlr.createDeployConfigInformer(ctlr.deployConfig); err != nil {
+			log.Errorf("Unable to setup DeployConfig informer: %v", err)
+			return err
+		}
+	}
+	if ctlr.as3OverrideCM != "" {
+		if err := ctlr.createOverrideCMInformer(ctlr.as3OverrideCM); err != nil {
+			log.Errorf("Unable to setup AS3 override ConfigMap informer: %v", err)
+			return err
+		}
+	}
 	return nil
 }
 
@@ -374,6 +427,20 @@ func (ctlr *Controller) Start() {
 	for _, inf := range ctlr.comInformers {
 		inf.start()
 	}
+	// DeployConfig defaults apply to all modes
+	if ctlr.dcInformer != nil {
+		ctlr.dcInformer.start()
+	}
+	if ctlr.overrideCMInformer != nil {
+		ctlr.overrideCMInformer.start()
+	}
+	// remote clusters are not waited upon as they may be unreachable
+	for _, rc := range ctlr.getRemoteClusters() {
+		ctlr.startRemoteCluster(rc)
+	}
+	if ctlr.mcSecretInformer != nil {
+		ctlr.mcSecretInformer.start()
+	}
 	switch ctlr.mode {
 	case OpenShiftMode, KubernetesMode:
 		// nrInformers only with openShiftMode
@@ -391,6 +458,11 @@ func (ctlr *Controller) Start() {
 		go ctlr.ipamCli.Start()
 	}
 
+	// webhook is started once the informer caches are synced
+	if ctlr.webhook != nil {
+		ctlr.webhook.start()
+	}
+
 	ctlr.nodePoller.Run()
 
 	stopChan := make(chan struct{})
@@ -423,6 +495,26 @@ func (ctlr *Controller) Stop() {
 	for _, nsInf := range ctlr.nsInformers {
 		nsInf.stop()
 	}
+	ctlr.nsLabelInformerLock.Lock()
+	if ctlr.nsLabelInformer != nil {
+		ctlr.nsLabelInformer.stop()
+	}
+	ctlr.nsLabelInformerLock.Unlock()
+	if ctlr.dcInformer != nil {
+		ctlr.dcInformer.stop()
+	}
+	if ctlr.overrideCMInformer != nil {
+		ctlr.overrideCMInformer.stop()
+	}
+	if ctlr.webhook != nil {
+		ctlr.webhook.stop()
+	}
+	if ctlr.mcSecretInformer != nil {
+		ctlr.mcSecretInformer.stop()
+	}
+	for _, rc := range ctlr.getRemoteClusters() {
+		rc.stop()
+	}
 
 	ctlr.nodePoller.Stop()
 	ctlr.Agent.Stop()
@@ -3,7 +3,7 @@ package controller
 import (
 	"bytes"
 	"fmt"
-	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v1"
+	cisapiv2 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v2"
 	"github.com/F5Networks/k8s-bigip-ctlr/pkg/writer"
 	mockhc "github.com/f5devcentral/mockhttpclient"
 	. "github.com/onsi/ginkgo"
@@ -98,6 +98,7 @@ func newMockAgent(writer writer.Writer) *Agent {
 		PostManager:     nil,
 		Partition:       "test",
 		ConfigWriter:    writer,
+		NetWriter:       writer,
 		EventChan:       make(chan interface{}),
 		postChan:        make(chan ResourceConfigRequest, 1),
 		PythonDriverPID: 0,
@@ -106,12 +107,12 @@ func newMockAgent(writer writer.Writer) *Agent {
 		userAgent: "",
 	}
 }
-func (m *mockController) addEDNS(edns *cisapiv1.ExternalDNS) {
+func (m *mockController) addEDNS(edns *cisapiv2.ExternalDNS) {
 	appInf, _ := m.getNamespacedCommonInformer(edns.ObjectMeta.Namespace)
 	appInf.ednsInformer.GetStore().Add(edns)
 }
 
-func (m *mockController) deleteEDNS(edns *cisapiv1.ExternalDNS) {
+func (m *mockController) deleteEDNS(edns *cisapiv2.ExternalDNS) {
 	appInf, _ := m.getNamespacedCommonInformer(edns.ObjectMeta.Namespace)
 	appInf.ednsInformer.GetStore().Delete(edns)
 }
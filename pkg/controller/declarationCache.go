@@ -0,0 +1,157 @@
+/*-
+* Copyright (c) 2016-2021, F5 Networks, Inc.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	log "github.com/F5Networks/k8s-bigip-ctlr/pkg/vlogger"
+)
+
+type (
+	// declarationCache is the file with the hashes of the tenant declarations
+	// last posted to BIG-IP, it survives the restarts of the controller
+	declarationCache struct {
+		file string
+		// primed are the hashes of the persisted tenants not yet rebuilt since the start
+		primed map[string]string
+		state  declarationCacheState
+	}
+
+	declarationCacheState struct {
+		BIGIPURL string `json:"bigipURL"`
+		// Tenants holds the hash of the declaration of each tenant
+		Tenants map[string]string `json:"tenants"`
+	}
+)
+
+// newDeclarationCache loads the tenant hashes persisted in the directory, the
+// hashes of another BIG-IP are discarded
+func newDeclarationCache(dir, partition, bigipURL string) *declarationCache {
+	dc := &declarationCache{
+		file: filepath.Join(dir, fmt.Sprintf("as3-tenants-%s.json", partition)),
+		state: declarationCacheState{
+			BIGIPURL: bigipURL,
+			Tenants:  make(map[string]string),
+		},
+	}
+	data, err := ioutil.ReadFile(dc.file)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warningf("[AS3] Failed to read the declaration cache %v: %v", dc.file, err)
+		}
+		return dc
+	}
+	var state declarationCacheState
+	if err = json.Unmarshal(data, &state); err != nil {
+		log.Warningf("[AS3] Discarding the invalid declaration cache %v: %v", dc.file, err)
+		return dc
+	}
+	if state.BIGIPURL != bigipURL {
+		log.Infof("[AS3] Discarding the declaration cache of BIG-IP %v", state.BIGIPURL)
+		return dc
+	}
+	dc.primed = state.Tenants
+	for tenant, hash := range state.Tenants {
+		dc.state.Tenants[tenant] = hash
+	}
+	log.Infof("[AS3] Loaded the declarations of %v tenants from %v", len(dc.primed), dc.file)
+	return dc
+}
+
+// isPrimed checks if the tenant declaration matches the one persisted before the
+// restart. A tenant is checked only once, when it is first rebuilt
+func (dc *declarationCache) isPrimed(tenant string, decl as3Tenant) bool {
+	if dc == nil {
+		return false
+	}
+	primedHash, ok := dc.primed[tenant]
+	if !ok {
+		return false
+	}
+	delete(dc.primed, tenant)
+	hash, err := tenantHash(decl)
+	return err == nil && hash == primedHash
+}
+
+// update records the hashes of the tenant declarations posted successfully, the
+// cache is written only when a hash has changed
+func (dc *declarationCache) update(tenants map[string]as3Tenant) {
+	if dc == nil || len(tenants) == 0 {
+		return
+	}
+	changed := false
+	for tenant, decl := range tenants {
+		hash, err := tenantHash(decl)
+		if err != nil {
+			log.Warningf("[AS3] Failed to cache the declaration of tenant %v: %v", tenant, err)
+			if _, ok := dc.state.Tenants[tenant]; ok {
+				delete(dc.state.Tenants, tenant)
+				changed = true
+			}
+			continue
+		}
+		if dc.state.Tenants[tenant] != hash {
+			dc.state.Tenants[tenant] = hash
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+	if err := dc.save(); err != nil {
+		log.Warningf("[AS3] Failed to write the declaration cache %v: %v", dc.file, err)
+	}
+}
+
+// save writes the cache to a temporary file renamed over the cache, so that a
+// restart never reads a partially written cache
+func (dc *declarationCache) save() error {
+	data, err := json.Marshal(dc.state)
+	if err != nil {
+		return err
+	}
+	tmpFile, err := ioutil.TempFile(filepath.Dir(dc.file), filepath.Base(dc.file)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err = tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err = tmpFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), dc.file)
+}
+
+// tenantHash returns the hash of the JSON of the tenant declaration
+func tenantHash(decl as3Tenant) (string, error) {
+	data, err := json.Marshal(decl)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/F5Networks/k8s-bigip-ctlr/pkg/test"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Declaration Cache Tests", func() {
+	var dir string
+	var agent *Agent
+	var config ResourceConfigRequest
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "declaration-cache")
+		Expect(err).ToNot(HaveOccurred())
+		writer := &test.MockWriter{
+			FailStyle: test.Success,
+			Sections:  make(map[string]interface{}),
+		}
+		agent = newMockAgent(writer)
+		agent.cachedTenantDeclMap = make(map[string]as3Tenant)
+		agent.retryTenantDeclMap = make(map[string]*tenantParams)
+		config = newBenchmarkConfig(2, 2)
+	})
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("Primes the tenants posted before the restart", func() {
+		adc := agent.createAS3LTMAndGTMConfigADC(config)
+		dc := newDeclarationCache(dir, "test", "https://127.0.0.1")
+		Expect(dc.isPrimed("tenant_0", adc["tenant_0"].(as3Tenant))).To(BeFalse())
+		dc.update(map[string]as3Tenant{
+			"tenant_0": adc["tenant_0"].(as3Tenant),
+			"tenant_1": adc["tenant_1"].(as3Tenant),
+		})
+
+		dc = newDeclarationCache(dir, "test", "https://127.0.0.1")
+		Expect(dc.isPrimed("tenant_0", adc["tenant_0"].(as3Tenant))).To(BeTrue())
+		Expect(dc.isPrimed("tenant_0", adc["tenant_0"].(as3Tenant))).To(BeFalse(),
+			"Tenant should be primed only once")
+		Expect(dc.isPrimed("tenant_1", adc["tenant_0"].(as3Tenant))).To(BeFalse(),
+			"Changed tenant should not be primed")
+		Expect(dc.state.Tenants).To(HaveLen(2), "Persisted tenants should be retained")
+
+		dc = newDeclarationCache(dir, "test", "https://127.0.0.2")
+		Expect(dc.isPrimed("tenant_0", adc["tenant_0"].(as3Tenant))).To(BeFalse(),
+			"Declarations of another BIG-IP should be discarded")
+		dc = newDeclarationCache(dir, "other", "https://127.0.0.1")
+		Expect(dc.isPrimed("tenant_0", adc["tenant_0"].(as3Tenant))).To(BeFalse(),
+			"Declarations of another partition should be discarded")
+	})
+
+	It("Skips the tenants unchanged since the restart", func() {
+		adc := agent.createAS3LTMAndGTMConfigADC(config)
+		newDeclarationCache(dir, "test", "https://127.0.0.1").update(map[string]as3Tenant{
+			"tenant_0": adc["tenant_0"].(as3Tenant),
+			"tenant_1": adc["tenant_1"].(as3Tenant),
+		})
+
+		agent.declarationCache = newDeclarationCache(dir, "test", "https://127.0.0.1")
+		delete(config.ltmConfig["tenant_1"].ResourceMap, "crd_vs_10.1.0.1")
+		agent.createTenantAS3Declaration(config)
+		Expect(agent.incomingTenantDeclMap).NotTo(HaveKey("tenant_0"))
+		Expect(agent.incomingTenantDeclMap).To(HaveKey("tenant_1"))
+		Expect(agent.cachedTenantDeclMap).To(HaveKey("tenant_0"))
+
+		agent.PostManager = &PostManager{
+			tenantResponseMap: map[string]tenantResponse{"tenant_1": {agentResponseCode: 200}},
+		}
+		agent.updateTenantResponse(true)
+		dc := newDeclarationCache(dir, "test", "https://127.0.0.1")
+		Expect(dc.isPrimed("tenant_1", agent.incomingTenantDeclMap["tenant_1"])).To(BeTrue(),
+			"Posted tenant should be persisted")
+	})
+
+	It("Writes the cache only when a tenant has changed", func() {
+		adc := agent.createAS3LTMAndGTMConfigADC(config)
+		dc := newDeclarationCache(dir, "test", "https://127.0.0.1")
+		dc.update(map[string]as3Tenant{"tenant_0": adc["tenant_0"].(as3Tenant)})
+		data, err := ioutil.ReadFile(dc.file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).NotTo(ContainSubstring("crd_vs_"), "Only the hashes should be persisted")
+
+		Expect(os.Remove(dc.file)).To(Succeed())
+		dc.update(map[string]as3Tenant{"tenant_0": adc["tenant_0"].(as3Tenant)})
+		Expect(dc.file).NotTo(BeAnExistingFile(), "Unchanged tenant should not rewrite the cache")
+		dc.update(map[string]as3Tenant{"tenant_1": adc["tenant_1"].(as3Tenant)})
+		Expect(dc.file).To(BeAnExistingFile())
+	})
+})
@@ -0,0 +1,180 @@
+/*-
+* Copyright (c) 2016-2021, F5 Networks, Inc.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	bigIPPrometheus "github.com/F5Networks/k8s-bigip-ctlr/pkg/prometheus"
+	log "github.com/F5Networks/k8s-bigip-ctlr/pkg/vlogger"
+)
+
+const (
+	// DriftPolicyReport reports the tenants changed on BIG-IP
+	DriftPolicyReport = "report"
+	// DriftPolicyRepost reports and reposts the tenants changed on BIG-IP
+	DriftPolicyRepost = "repost"
+
+	// maxDriftChanges is the count of the changes logged for a tenant
+	maxDriftChanges = 10
+)
+
+// desiredTenant is the declaration of a tenant last posted to BIG-IP
+type desiredTenant struct {
+	tenantDecl as3Tenant
+	// removed is set for the tenants removed from BIG-IP
+	removed bool
+}
+
+// driftReconciler periodically posts the tenants posted to BIG-IP again as AS3
+// dry runs, which compare them with the configuration of BIG-IP, to detect the
+// changes made on BIG-IP, with AS3 or otherwise with the GUI or tmsh
+func (agent *Agent) driftReconciler() {
+	ticker := time.NewTicker(agent.driftCheckInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		agent.reconcileDrift()
+	}
+}
+
+// reconcileDrift reports the tenants which have drifted on BIG-IP, and reposts
+// them with the repost policy. Returns the drifted tenants
+func (agent *Agent) reconcileDrift() []string {
+	desired := agent.getDesiredTenants()
+	tenants := make([]string, 0, len(desired))
+	for tenant := range desired {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+
+	var driftedTenants []string
+	for _, tenant := range tenants {
+		decl, err := agent.createDryRunDeclaration(tenant, desired[tenant].tenantDecl)
+		if err != nil {
+			log.Warningf("[AS3] Failed to prepare the declaration of tenant %v to detect drift: %v", tenant, err)
+			continue
+		}
+		changes, err := agent.dryRunTenant(tenant, decl)
+		if err != nil {
+			log.Warningf("[AS3] Failed to detect the drift of tenant %v: %v", tenant, err)
+			continue
+		}
+		if len(changes) == 0 {
+			if desired[tenant].removed {
+				// The drift of the tenants removed by CIS is reported only while they drift
+				bigIPPrometheus.TenantDrift.DeleteLabelValues(tenant)
+			} else {
+				bigIPPrometheus.TenantDrift.WithLabelValues(tenant).Set(0)
+			}
+			continue
+		}
+		bigIPPrometheus.TenantDrift.WithLabelValues(tenant).Set(1)
+		if len(changes) > maxDriftChanges {
+			changes = append(changes[:maxDriftChanges], fmt.Sprintf("and %v more", len(changes)-maxDriftChanges))
+		}
+		log.Warningf("[AS3] Tenant %v has drifted on BIG-IP, changes: %v", tenant, strings.Join(changes, ", "))
+		driftedTenants = append(driftedTenants, tenant)
+	}
+
+	if len(driftedTenants) > 0 && agent.driftPolicy == DriftPolicyRepost {
+		agent.repostDriftedTenants(driftedTenants, desired)
+	}
+	return driftedTenants
+}
+
+// getDesiredTenants returns the tenants posted successfully, the tenants being
+// retried or held back are reconciled by the retries and the next posts
+func (agent *Agent) getDesiredTenants() map[string]desiredTenant {
+	agent.declUpdate.Lock()
+	defer agent.declUpdate.Unlock()
+	desired := make(map[string]desiredTenant, len(agent.cachedTenantDeclMap))
+	for tenant, tenantDecl := range agent.cachedTenantDeclMap {
+		if _, ok := agent.retryTenantDeclMap[tenant]; ok {
+			continue
+		}
+		if _, ok := agent.quarantinedTenants[tenant]; ok {
+			continue
+		}
+		// A tenant declared with just its class is removed from BIG-IP
+		desired[tenant] = desiredTenant{tenantDecl: tenantDecl, removed: len(tenantDecl) <= 1}
+	}
+	return desired
+}
+
+// createDryRunDeclaration returns the AS3 declaration of the tenant as a dry
+// run, with the differences found by AS3 traced in the response
+func (agent *Agent) createDryRunDeclaration(tenant string, tenantDecl as3Tenant) (as3Declaration, error) {
+	var as3Config map[string]interface{}
+	if err := json.Unmarshal([]byte(agent.createAS3Declaration(map[string]as3Tenant{tenant: tenantDecl})), &as3Config); err != nil {
+		return "", err
+	}
+	adc := as3Config["declaration"].(map[string]interface{})
+	controls := adc["controls"].(map[string]interface{})
+	controls["dryRun"] = true
+	controls["traceResponse"] = true
+	decl, err := json.Marshal(as3Config)
+	return as3Declaration(decl), err
+}
+
+// repostDriftedTenants posts the desired declarations of the drifted tenants,
+// unless they have been updated since they were checked
+func (agent *Agent) repostDriftedTenants(tenants []string, desired map[string]desiredTenant) {
+	agent.declUpdate.Lock()
+	defer agent.declUpdate.Unlock()
+
+	var repostTenants []string
+	repostDecl := make(map[string]as3Tenant)
+	agent.tenantResponseMap = make(map[string]tenantResponse)
+	for _, tenant := range tenants {
+		if _, ok := agent.retryTenantDeclMap[tenant]; ok {
+			continue
+		}
+		if !reflect.DeepEqual(agent.cachedTenantDeclMap[tenant], desired[tenant].tenantDecl) {
+			continue
+		}
+		repostTenants = append(repostTenants, tenant)
+		repostDecl[tenant] = desired[tenant].tenantDecl
+		agent.tenantResponseMap[tenant] = tenantResponse{}
+		bigIPPrometheus.TenantDriftReposts.WithLabelValues(tenant).Inc()
+	}
+	if len(repostTenants) == 0 {
+		return
+	}
+	log.Infof("[AS3] Reposting the drifted tenants %v", repostTenants)
+
+	agent.incomingTenantDeclMap = repostDecl
+	agent.publishConfig(agentConfig{
+		data:      string(agent.createAS3Declaration(repostDecl)),
+		as3APIURL: agent.getAS3APIURL(repostTenants),
+		id:        0,
+	})
+	agent.updateTenantResponse(true)
+
+	if len(agent.retryTenantDeclMap) > 0 {
+		// Activate retry
+		select {
+		case agent.retryChan <- struct{}{}:
+		case <-agent.retryChan:
+			agent.retryChan <- struct{}{}
+		}
+	}
+}
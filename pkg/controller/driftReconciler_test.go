@@ -0,0 +1,196 @@
+package controller
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+
+	bigIPPrometheus "github.com/F5Networks/k8s-bigip-ctlr/pkg/prometheus"
+	"github.com/F5Networks/k8s-bigip-ctlr/pkg/test"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// as3StandIn serves the AS3 declare API of the tenants, the tenants hold the
+// configuration of BIG-IP
+type as3StandIn struct {
+	sync.Mutex
+	tenants map[string]interface{}
+	posts   int
+}
+
+// diffTenant returns the AS3 trace of the differences between the desired
+// and the current configuration of a tenant
+func diffTenant(path []interface{}, desired, current interface{}, diff []interface{}) []interface{} {
+	desiredMap, desiredIsMap := desired.(map[string]interface{})
+	currentMap, currentIsMap := current.(map[string]interface{})
+	if desiredIsMap && currentIsMap {
+		for key, value := range desiredMap {
+			diff = diffTenant(append(path[:len(path):len(path)], key), value, currentMap[key], diff)
+		}
+		for key, value := range currentMap {
+			if _, ok := desiredMap[key]; !ok {
+				diff = diffTenant(append(path[:len(path):len(path)], key), nil, value, diff)
+			}
+		}
+		return diff
+	}
+	switch {
+	case reflect.DeepEqual(desired, current):
+		return diff
+	case current == nil:
+		return append(diff, map[string]interface{}{"kind": "N", "path": path, "rhs": desired})
+	case desired == nil:
+		return append(diff, map[string]interface{}{"kind": "D", "path": path, "lhs": current})
+	}
+	return append(diff, map[string]interface{}{"kind": "E", "path": path, "lhs": current, "rhs": desired})
+}
+
+func (standIn *as3StandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	standIn.Lock()
+	defer standIn.Unlock()
+	tenants := strings.Split(strings.TrimPrefix(r.URL.Path, "/mgmt/shared/appsvcs/declare/"), ",")
+	switch r.Method {
+	case http.MethodPost:
+		data, _ := ioutil.ReadAll(r.Body)
+		var as3Config struct {
+			Declaration map[string]interface{} `json:"declaration"`
+		}
+		_ = json.Unmarshal(data, &as3Config)
+		var results []interface{}
+		if controls, _ := as3Config.Declaration["controls"].(map[string]interface{}); controls["dryRun"] == true {
+			traces := make(map[string]interface{})
+			for _, tenant := range tenants {
+				var desired interface{}
+				if decl, _ := as3Config.Declaration[tenant].(map[string]interface{}); len(decl) > 1 {
+					desired = decl
+				}
+				diff := diffTenant([]interface{}{"/" + tenant}, desired, standIn.tenants[tenant], nil)
+				message := "success"
+				if len(diff) == 0 {
+					message = "no change"
+				}
+				results = append(results, map[string]interface{}{
+					"code": http.StatusOK, "tenant": tenant, "message": message, "dryRun": true})
+				if controls["traceResponse"] == true {
+					traces[tenant+"Diff"] = diff
+				}
+			}
+			data, _ = json.Marshal(map[string]interface{}{"results": results, "traces": traces})
+			w.Write(data)
+			return
+		}
+		standIn.posts++
+		for _, tenant := range tenants {
+			if decl, _ := as3Config.Declaration[tenant].(map[string]interface{}); len(decl) > 1 {
+				standIn.tenants[tenant] = decl
+			} else {
+				delete(standIn.tenants, tenant)
+			}
+			results = append(results, map[string]interface{}{"code": http.StatusOK, "tenant": tenant})
+		}
+		data, _ = json.Marshal(map[string]interface{}{"results": results})
+		w.Write(data)
+	}
+}
+
+var _ = Describe("Drift Reconciler Tests", func() {
+	var agent *Agent
+	var standIn *as3StandIn
+	var server *httptest.Server
+	BeforeEach(func() {
+		writer := &test.MockWriter{
+			FailStyle: test.Success,
+			Sections:  make(map[string]interface{}),
+		}
+		standIn = &as3StandIn{tenants: make(map[string]interface{})}
+		server = httptest.NewServer(standIn)
+		agent = newMockAgent(writer)
+		agent.PostManager = NewPostManager(PostParams{BIGIPURL: server.URL})
+		agent.cachedTenantDeclMap = make(map[string]as3Tenant)
+		agent.retryTenantDeclMap = make(map[string]*tenantParams)
+		agent.tenantPriorityMap = make(map[string]int)
+		agent.retryChan = make(chan struct{}, 1)
+		agent.driftPolicy = DriftPolicyReport
+
+		// The tenants are posted to BIG-IP
+		adc := agent.createAS3LTMAndGTMConfigADC(newBenchmarkConfig(2, 1))
+		adc["tenant_2"] = as3Tenant{"class": "Tenant"}
+		for tenant, decl := range adc {
+			agent.cachedTenantDeclMap[tenant] = decl.(as3Tenant)
+		}
+		agent.tenantResponseMap = make(map[string]tenantResponse)
+		agent.publishConfig(agentConfig{
+			data:      string(agent.createAS3Declaration(agent.cachedTenantDeclMap)),
+			as3APIURL: agent.getAS3APIURL([]string{"tenant_0", "tenant_1", "tenant_2"}),
+		})
+		Expect(standIn.tenants).To(HaveLen(2))
+		standIn.posts = 0
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Reports the tenants changed on BIG-IP", func() {
+		Expect(agent.reconcileDrift()).To(BeEmpty())
+		Expect(bigIPPrometheus.TenantDrift.DeleteLabelValues("tenant_2")).To(BeFalse(),
+			"Drift of the removed tenant should not be reported")
+
+		standIn.Lock()
+		app := standIn.tenants["tenant_1"].(map[string]interface{})[as3SharedApplication].(map[string]interface{})
+		app["crd_vs_10.1.0.1"].(map[string]interface{})["virtualPort"] = 8080
+		standIn.tenants["tenant_2"] = map[string]interface{}{"class": "Tenant", "App": map[string]interface{}{}}
+		standIn.Unlock()
+		Expect(agent.reconcileDrift()).To(Equal([]string{"tenant_1", "tenant_2"}))
+		Expect(standIn.posts).To(BeZero(), "Drifted tenants should only be reported")
+
+		delete(standIn.tenants, "tenant_0")
+		Expect(agent.reconcileDrift()).To(Equal([]string{"tenant_0", "tenant_1", "tenant_2"}))
+		Expect(bigIPPrometheus.TenantDrift.DeleteLabelValues("tenant_2")).To(BeTrue())
+	})
+
+	It("Reposts the tenants changed on BIG-IP", func() {
+		agent.driftPolicy = DriftPolicyRepost
+		delete(standIn.tenants, "tenant_0")
+		Expect(agent.reconcileDrift()).To(Equal([]string{"tenant_0"}))
+		Expect(standIn.posts).To(Equal(1))
+		Expect(standIn.tenants).To(HaveKey("tenant_0"))
+		Expect(agent.reconcileDrift()).To(BeEmpty(), "Reposted tenant should be in sync")
+
+		// The tenants being retried are reconciled by the retries
+		delete(standIn.tenants, "tenant_0")
+		agent.retryTenantDeclMap["tenant_0"] = &tenantParams{as3Decl: agent.cachedTenantDeclMap["tenant_0"]}
+		Expect(agent.reconcileDrift()).To(BeEmpty())
+		Expect(standIn.posts).To(Equal(1))
+	})
+
+	It("Detects the changes from the response of the dry run", func() {
+		decl, err := agent.createDryRunDeclaration("tenant_1", agent.cachedTenantDeclMap["tenant_1"])
+		Expect(err).To(BeNil())
+		var as3Config map[string]interface{}
+		Expect(json.Unmarshal([]byte(decl), &as3Config)).To(Succeed())
+		controls := as3Config["declaration"].(map[string]interface{})["controls"].(map[string]interface{})
+		Expect(controls).To(HaveKeyWithValue("dryRun", true))
+		Expect(controls).To(HaveKeyWithValue("traceResponse", true))
+
+		var response string
+		server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(response))
+		})
+		response = `{"results": [{"code": 200, "tenant": "tenant_1", "message": "success", "dryRun": true}],
+			"traces": {"tenant_1Diff": [{"kind": "E", "path": ["/tenant_1/Shared/vs", "properties", "destination"],
+			"lhs": "10.1.0.1:8080", "rhs": "10.1.0.1:80", "tags": ["tmsh"]}]}}`
+		Expect(agent.dryRunTenant("tenant_1", decl)).To(Equal([]string{"E /tenant_1/Shared/vs/properties/destination"}))
+
+		response = `{"results": [{"code": 200, "tenant": "tenant_1", "message": "no change", "dryRun": true}]}`
+		Expect(agent.dryRunTenant("tenant_1", decl)).To(BeEmpty())
+
+		response = `{"results": [{"code": 422, "tenant": "tenant_1", "message": "declaration failed"}]}`
+		_, err = agent.dryRunTenant("tenant_1", decl)
+		Expect(err).NotTo(BeNil())
+	})
+})
@@ -0,0 +1,275 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"time"
+
+	log "github.com/F5Networks/k8s-bigip-ctlr/pkg/vlogger"
+)
+
+// NewGTMAgent creates the agent posting GTM configuration to the GTM BIG-IP.
+// It returns nil when GTM is configured by the CCCL GTM agent or no separate GTM BIG-IP is given,
+// in which case GTM configuration is posted along with the LTM declaration
+func NewGTMAgent(params AgentParams) *GTMAgent {
+	if params.CCCLGTMAgent || params.GTMParams.GTMBigIpUrl == "" {
+		return nil
+	}
+	postParams := params.PostParams
+	postParams.BIGIPURL = params.GTMParams.GTMBigIpUrl
+	if params.GTMParams.GTMBigIpUsername != "" && params.GTMParams.GTMBigIpPassword != "" {
+		postParams.BIGIPUsername = params.GTMParams.GTMBigIpUsername
+		postParams.BIGIPPassword = params.GTMParams.GTMBigIpPassword
+	} else {
+		log.Warning("[GTM] Using the default bigip credentials as GTM BIGIP Username or GTM BIGIP Password is missing on CIS args.")
+	}
+	gtm := &GTMAgent{
+		PostManager:           NewPostManager(postParams),
+		userAgent:             params.UserAgent,
+		dataCenter:            params.GTMParams.DataCenterName,
+		serverName:            params.GTMParams.ServerName,
+		serverAddress:         params.GTMParams.ServerAddress,
+		postChan:              make(chan as3ADC, 1),
+		retryChan:             make(chan struct{}, 1),
+		cachedTenantDeclMap:   make(map[string]as3Tenant),
+		incomingTenantDeclMap: make(map[string]as3Tenant),
+		retryTenantDeclMap:    make(map[string]*tenantParams),
+	}
+	if gtm.serverAddress == "" {
+		// The GTM server defaults to the LTM BIG-IP
+		if u, err := url.Parse(params.PostParams.BIGIPURL); err == nil {
+			gtm.serverAddress = u.Hostname()
+		}
+	}
+	// gtmWorker blocks on postChan to get new/updated GTM configuration to be posted to GTM BIG-IP
+	go gtm.gtmWorker()
+
+	// retryWorker retries the failed GTM tenants and polls for the accepted ones
+	go gtm.retryWorker()
+
+	return gtm
+}
+
+// PostConfig queues the GTM tenants, only the latest configuration is retained
+func (gtm *GTMAgent) PostConfig(adc as3ADC) {
+	select {
+	case gtm.postChan <- adc:
+	case <-gtm.postChan:
+		gtm.postChan <- adc
+	}
+}
+
+func (gtm *GTMAgent) gtmWorker() {
+	// The GTM configuration is held until the AS3 version of the GTM BIG-IP is known
+	gtm.waitForAS3VersionInfo(timeoutMedium)
+	for adc := range gtm.postChan {
+		gtm.declUpdate.Lock()
+
+		// Fetch the latest config from channel
+		select {
+		case adc = <-gtm.postChan:
+		case <-time.After(1 * time.Microsecond):
+		}
+
+		gtm.postTenants(adc)
+
+		gtm.declUpdate.Unlock()
+	}
+}
+
+// waitForAS3VersionInfo fetches the AS3 version of the GTM BIG-IP, retrying while
+// the GTM BIG-IP is unavailable, so that it does not stop the controller
+func (gtm *GTMAgent) waitForAS3VersionInfo(retryInterval time.Duration) {
+	for {
+		am, err := gtm.getAS3VersionInfo()
+		if err == nil {
+			gtm.AS3VersionInfo = am
+			return
+		}
+		log.Errorf("[GTM] Unable to get the AS3 version of the GTM BIG-IP, retrying in %v: %v", retryInterval, err)
+		<-time.After(retryInterval)
+	}
+}
+
+// postTenants posts the GTM tenants with updated configuration
+func (gtm *GTMAgent) postTenants(adc as3ADC) {
+	gtm.incomingTenantDeclMap = make(map[string]as3Tenant)
+	for tenant, cfg := range gtm.createGTMTenants(adc) {
+		if !reflect.DeepEqual(cfg, gtm.cachedTenantDeclMap[tenant]) {
+			gtm.incomingTenantDeclMap[tenant] = cfg
+		} else {
+			delete(gtm.retryTenantDeclMap, tenant)
+			log.Debugf("[GTM] No change in %v tenant configuration", tenant)
+		}
+	}
+	if len(gtm.incomingTenantDeclMap) == 0 {
+		return
+	}
+
+	// The pools refer to the server, so Common is posted ahead of the other tenants
+	if _, ok := gtm.incomingTenantDeclMap[gtmPartition]; ok {
+		gtm.postDeclaration([]string{gtmPartition})
+	}
+	var tenants []string
+	for tenant := range gtm.incomingTenantDeclMap {
+		if tenant != gtmPartition {
+			tenants = append(tenants, tenant)
+		}
+	}
+	if len(tenants) > 0 {
+		sort.Strings(tenants)
+		gtm.postDeclaration(tenants)
+	}
+
+	if len(gtm.retryTenantDeclMap) > 0 {
+		// Activate retry
+		select {
+		case gtm.retryChan <- struct{}{}:
+		case <-gtm.retryChan:
+			gtm.retryChan <- struct{}{}
+		}
+	}
+}
+
+// createGTMTenants adds the data center and server tenant to the GTM tenants,
+// and removes the tenants no longer carrying any GTM configuration
+func (gtm *GTMAgent) createGTMTenants(adc as3ADC) map[string]as3Tenant {
+	tenants := make(map[string]as3Tenant)
+	for tenant, cfg := range adc {
+		tenants[tenant] = cfg.(as3Tenant)
+	}
+	for tenant := range gtm.cachedTenantDeclMap {
+		if _, ok := tenants[tenant]; !ok && tenant != gtmPartition {
+			tenants[tenant] = as3Tenant{
+				"class": "Tenant",
+			}
+		}
+	}
+	if gtm.dataCenter != "" && gtm.serverName != "" {
+		sharedApp := as3Application{}
+		sharedApp["class"] = "Application"
+		sharedApp["template"] = "shared"
+		sharedApp[gtm.dataCenter] = as3GSLBDataCenter{
+			Class: "GSLB_Data_Center",
+		}
+		sharedApp[gtm.serverName] = as3GSLBServer{
+			Class:      "GSLB_Server",
+			DataCenter: as3ResourcePointer{Use: gtm.dataCenter},
+			Devices:    []as3GSLBServerDevice{{Address: gtm.serverAddress}},
+			// Virtual servers of the LTM BIG-IP are discovered and retained on the server
+			VSDiscoveryMode: "enabled-no-delete",
+		}
+		tenants[gtmPartition] = as3Tenant{
+			"class":              "Tenant",
+			as3SharedApplication: sharedApp,
+		}
+	}
+	return tenants
+}
+
+func (gtm *GTMAgent) postDeclaration(tenants []string) {
+	decls := make(map[string]as3Tenant)
+	gtm.tenantResponseMap = make(map[string]tenantResponse)
+	for _, tenant := range tenants {
+		decls[tenant] = gtm.incomingTenantDeclMap[tenant]
+		gtm.tenantResponseMap[tenant] = tenantResponse{}
+	}
+	cfg := agentConfig{
+		data:      string(newAS3Declaration(decls, gtm.AS3VersionInfo, gtm.userAgent)),
+		as3APIURL: gtm.getAS3APIURL(tenants),
+	}
+	gtm.publishConfig(cfg)
+
+	for tenant, resp := range gtm.tenantResponseMap {
+		if decl, ok := decls[tenant]; ok {
+			gtm.updateRetryMap(tenant, resp, decl)
+		}
+	}
+}
+
+func (gtm *GTMAgent) updateRetryMap(tenant string, resp tenantResponse, decl as3Tenant) {
+	if resp.agentResponseCode == http.StatusOK {
+		gtm.cachedTenantDeclMap[tenant] = decl
+		delete(gtm.retryTenantDeclMap, tenant)
+	} else {
+		gtm.retryTenantDeclMap[tenant] = &tenantParams{
+			decl,
+			tenantResponse{resp.agentResponseCode, resp.taskId},
+		}
+	}
+}
+
+// retryWorker blocks on retryChan
+// whenever it gets unblocked, retries failed GTM tenants and polls for accepted tenant statuses
+func (gtm *GTMAgent) retryWorker() {
+	for range gtm.retryChan {
+		for len(gtm.retryTenantDeclMap) != 0 {
+			<-time.After(timeoutMedium)
+
+			gtm.declUpdate.Lock()
+			gtm.retryTenants()
+			gtm.declUpdate.Unlock()
+		}
+	}
+}
+
+func (gtm *GTMAgent) retryTenants() {
+	// Poll for the status of accepted tenants, a task is polled once for all its tenants
+	polled := make(map[string]struct{})
+	for _, cfg := range gtm.retryTenantDeclMap {
+		if cfg.taskId == "" {
+			continue
+		}
+		if _, found := polled[cfg.taskId]; found {
+			continue
+		}
+		polled[cfg.taskId] = struct{}{}
+		gtm.tenantResponseMap = make(map[string]tenantResponse)
+		for t, c := range gtm.retryTenantDeclMap {
+			if c.taskId == cfg.taskId {
+				gtm.tenantResponseMap[t] = c.tenantResponse
+			}
+		}
+		gtm.getTenantConfigStatus(cfg.taskId)
+		for t, resp := range gtm.tenantResponseMap {
+			if params, ok := gtm.retryTenantDeclMap[t]; ok {
+				gtm.updateRetryMap(t, resp, params.as3Decl.(as3Tenant))
+			}
+		}
+	}
+
+	var tenants []string
+	for tenant, cfg := range gtm.retryTenantDeclMap {
+		if cfg.taskId == "" {
+			tenants = append(tenants, tenant)
+		}
+	}
+	if len(tenants) == 0 {
+		return
+	}
+	sort.Strings(tenants)
+	gtm.incomingTenantDeclMap = make(map[string]as3Tenant)
+	for _, tenant := range tenants {
+		gtm.incomingTenantDeclMap[tenant] = gtm.retryTenantDeclMap[tenant].as3Decl.(as3Tenant)
+	}
+	log.Debugf("[GTM] Posting failed tenants %v", tenants)
+	gtm.postDeclaration(tenants)
+}
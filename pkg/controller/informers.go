@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
 	routeapi "github.com/openshift/api/route/v1"
@@ -27,11 +28,12 @@ import (
 	"k8s.io/apimachinery/pkg/watch"
 
 	ficV1 "github.com/F5Networks/f5-ipam-controller/pkg/ipamapis/apis/fic/v1"
-	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v1"
-	cisinfv1 "github.com/F5Networks/k8s-bigip-ctlr/config/client/informers/externalversions/cis/v1"
+	cisapiv2 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v2"
+	"github.com/F5Networks/k8s-bigip-ctlr/config/client/clientset/versioned"
 	log "github.com/F5Networks/k8s-bigip-ctlr/pkg/vlogger"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/client-go/tools/cache"
 )
 
@@ -283,7 +285,7 @@ func (ctlr *Controller) newNamespacedCustomResourceInformer(
 		stopCh:    make(chan struct{}),
 	}
 
-	crInf.ilInformer = cisinfv1.NewFilteredIngressLinkInformer(
+	crInf.ilInformer = newIngressLinkInformer(
 		ctlr.kubeCRClient,
 		namespace,
 		resyncPeriod,
@@ -291,21 +293,21 @@ func (ctlr *Controller) newNamespacedCustomResourceInformer(
 		everything,
 	)
 
-	crInf.vsInformer = cisinfv1.NewFilteredVirtualServerInformer(
+	crInf.vsInformer = newVirtualServerInformer(
 		ctlr.kubeCRClient,
 		namespace,
 		resyncPeriod,
 		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
 		crOptions,
 	)
-	crInf.tlsInformer = cisinfv1.NewFilteredTLSProfileInformer(
+	crInf.tlsInformer = newTLSProfileInformer(
 		ctlr.kubeCRClient,
 		namespace,
 		resyncPeriod,
 		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
 		crOptions,
 	)
-	crInf.tsInformer = cisinfv1.NewFilteredTransportServerInformer(
+	crInf.tsInformer = newTransportServerInformer(
 		ctlr.kubeCRClient,
 		namespace,
 		resyncPeriod,
@@ -419,7 +421,7 @@ func (ctlr *Controller) newNamespacedCommonResourceInformer(
 			cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
 		),
 	}
-	comInf.ednsInformer = cisinfv1.NewFilteredExternalDNSInformer(
+	comInf.ednsInformer = newExternalDNSInformer(
 		ctlr.kubeCRClient,
 		namespace,
 		resyncPeriod,
@@ -427,15 +429,15 @@ func (ctlr *Controller) newNamespacedCommonResourceInformer(
 		crOptions,
 	)
 
-	comInf.plcInformer = cisinfv1.NewFilteredPolicyInformer(
+	comInf.plcInformer = newPolicyInformer(
 		ctlr.kubeCRClient,
 		namespace,
 		resyncPeriod,
 		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
 		crOptions,
 	)
-	//enable pod informer for nodeport local mode
-	if ctlr.PoolMemberType == NodePortLocal {
+	//enable pod informer for nodeport local mode and probe based health monitors
+	if ctlr.PoolMemberType == NodePortLocal || ctlr.podProbeHealthMonitor {
 		comInf.podInformer = cache.NewSharedIndexInformer(
 			cache.NewFilteredListWatchFromClient(
 				restClientv1,
@@ -527,7 +529,7 @@ func (ctlr *Controller) addCommonResourceEventHandlers(comInf *CommonInformer) {
 		comInf.plcInformer.AddEventHandler(
 			&cache.ResourceEventHandlerFuncs{
 				AddFunc:    func(obj interface{}) { ctlr.enqueuePolicy(obj, Create) },
-				UpdateFunc: func(obj, cur interface{}) { ctlr.enqueuePolicy(cur, Update) },
+				UpdateFunc: func(obj, cur interface{}) { ctlr.enqueueUpdatedPolicy(obj, cur) },
 				DeleteFunc: func(obj interface{}) { ctlr.enqueueDeletedPolicy(obj) },
 			},
 		)
@@ -649,7 +651,7 @@ func (ctlr *Controller) enqueueDeletedIPAM(obj interface{}) {
 }
 
 func (ctlr *Controller) enqueueVirtualServer(obj interface{}) {
-	vs := obj.(*cisapiv1.VirtualServer)
+	vs := obj.(*cisapiv2.VirtualServer)
 	log.Debugf("Enqueueing VirtualServer: %v", vs)
 	key := &rqKey{
 		namespace: vs.ObjectMeta.Namespace,
@@ -663,8 +665,8 @@ func (ctlr *Controller) enqueueVirtualServer(obj interface{}) {
 }
 
 func (ctlr *Controller) enqueueUpdatedVirtualServer(oldObj, newObj interface{}) {
-	oldVS := oldObj.(*cisapiv1.VirtualServer)
-	newVS := newObj.(*cisapiv1.VirtualServer)
+	oldVS := oldObj.(*cisapiv2.VirtualServer)
+	newVS := newObj.(*cisapiv2.VirtualServer)
 	updateEvent := true
 	if oldVS.Spec.VirtualServerAddress != newVS.Spec.VirtualServerAddress ||
 		oldVS.Spec.VirtualServerHTTPPort != newVS.Spec.VirtualServerHTTPPort ||
@@ -700,7 +702,7 @@ func (ctlr *Controller) enqueueUpdatedVirtualServer(oldObj, newObj interface{})
 }
 
 func (ctlr *Controller) enqueueDeletedVirtualServer(obj interface{}) {
-	vs := obj.(*cisapiv1.VirtualServer)
+	vs := obj.(*cisapiv2.VirtualServer)
 	log.Debugf("Enqueueing VirtualServer: %v", vs)
 	key := &rqKey{
 		namespace: vs.ObjectMeta.Namespace,
@@ -714,7 +716,7 @@ func (ctlr *Controller) enqueueDeletedVirtualServer(obj interface{}) {
 }
 
 func (ctlr *Controller) enqueueTLSProfile(obj interface{}, event string) {
-	tls := obj.(*cisapiv1.TLSProfile)
+	tls := obj.(*cisapiv2.TLSProfile)
 	log.Infof("Enqueueing TLSProfile: %v", tls)
 	key := &rqKey{
 		namespace: tls.ObjectMeta.Namespace,
@@ -728,7 +730,7 @@ func (ctlr *Controller) enqueueTLSProfile(obj interface{}, event string) {
 }
 
 func (ctlr *Controller) enqueueTransportServer(obj interface{}) {
-	ts := obj.(*cisapiv1.TransportServer)
+	ts := obj.(*cisapiv2.TransportServer)
 	log.Infof("Enqueueing TransportServer: %v", ts)
 	key := &rqKey{
 		namespace: ts.ObjectMeta.Namespace,
@@ -742,8 +744,8 @@ func (ctlr *Controller) enqueueTransportServer(obj interface{}) {
 }
 
 func (ctlr *Controller) enqueueUpdatedTransportServer(oldObj, newObj interface{}) {
-	oldVS := oldObj.(*cisapiv1.TransportServer)
-	newVS := newObj.(*cisapiv1.TransportServer)
+	oldVS := oldObj.(*cisapiv2.TransportServer)
+	newVS := newObj.(*cisapiv2.TransportServer)
 
 	if oldVS.Spec.VirtualServerAddress != newVS.Spec.VirtualServerAddress ||
 		oldVS.Spec.VirtualServerPort != newVS.Spec.VirtualServerPort ||
@@ -774,7 +776,7 @@ func (ctlr *Controller) enqueueUpdatedTransportServer(oldObj, newObj interface{}
 }
 
 func (ctlr *Controller) enqueueDeletedTransportServer(obj interface{}) {
-	vs := obj.(*cisapiv1.TransportServer)
+	vs := obj.(*cisapiv2.TransportServer)
 	log.Debugf("Enqueueing TransportServer: %v", vs)
 	key := &rqKey{
 		namespace: vs.ObjectMeta.Namespace,
@@ -788,7 +790,7 @@ func (ctlr *Controller) enqueueDeletedTransportServer(obj interface{}) {
 }
 
 func (ctlr *Controller) enqueuePolicy(obj interface{}, event string) {
-	pol := obj.(*cisapiv1.Policy)
+	pol := obj.(*cisapiv2.Policy)
 	log.Infof("Enqueueing Policy: %v", pol)
 	key := &rqKey{
 		namespace: pol.ObjectMeta.Namespace,
@@ -801,8 +803,26 @@ func (ctlr *Controller) enqueuePolicy(obj interface{}, event string) {
 	ctlr.resourceQueue.Add(key)
 }
 
+func (ctlr *Controller) enqueueUpdatedPolicy(oldObj, newObj interface{}) {
+	oldPol := oldObj.(*cisapiv2.Policy)
+	newPol := newObj.(*cisapiv2.Policy)
+	// Resources selected only by the old targetSelector have to be detached
+	if !reflect.DeepEqual(oldPol.Spec.TargetSelector, newPol.Spec.TargetSelector) {
+		log.Debugf("Enqueueing Old Policy: %v", oldPol)
+		key := &rqKey{
+			namespace: oldPol.ObjectMeta.Namespace,
+			kind:      CustomPolicy,
+			rscName:   oldPol.ObjectMeta.Name,
+			rsc:       oldObj,
+			event:     Update,
+		}
+		ctlr.resourceQueue.Add(key)
+	}
+	ctlr.enqueuePolicy(newObj, Update)
+}
+
 func (ctlr *Controller) enqueueDeletedPolicy(obj interface{}) {
-	pol := obj.(*cisapiv1.Policy)
+	pol := obj.(*cisapiv2.Policy)
 	log.Infof("Enqueueing Policy: %v", pol)
 	key := &rqKey{
 		namespace: pol.ObjectMeta.Namespace,
@@ -816,7 +836,7 @@ func (ctlr *Controller) enqueueDeletedPolicy(obj interface{}) {
 }
 
 func (ctlr *Controller) enqueueIngressLink(obj interface{}) {
-	ingLink := obj.(*cisapiv1.IngressLink)
+	ingLink := obj.(*cisapiv2.IngressLink)
 	log.Infof("Enqueueing IngressLink: %v", ingLink)
 	key := &rqKey{
 		namespace: ingLink.ObjectMeta.Namespace,
@@ -830,7 +850,7 @@ func (ctlr *Controller) enqueueIngressLink(obj interface{}) {
 }
 
 func (ctlr *Controller) enqueueDeletedIngressLink(obj interface{}) {
-	ingLink := obj.(*cisapiv1.IngressLink)
+	ingLink := obj.(*cisapiv2.IngressLink)
 	log.Infof("Enqueueing IngressLink: %v on Delete", ingLink)
 	key := &rqKey{
 		namespace: ingLink.ObjectMeta.Namespace,
@@ -844,8 +864,8 @@ func (ctlr *Controller) enqueueDeletedIngressLink(obj interface{}) {
 }
 
 func (ctlr *Controller) enqueueUpdatedIngressLink(oldObj, newObj interface{}) {
-	oldIngLink := oldObj.(*cisapiv1.IngressLink)
-	newIngLink := newObj.(*cisapiv1.IngressLink)
+	oldIngLink := oldObj.(*cisapiv2.IngressLink)
+	newIngLink := newObj.(*cisapiv2.IngressLink)
 
 	if oldIngLink.Spec.VirtualServerAddress != newIngLink.Spec.VirtualServerAddress ||
 		oldIngLink.Spec.IPAMLabel != newIngLink.Spec.IPAMLabel {
@@ -873,7 +893,7 @@ func (ctlr *Controller) enqueueUpdatedIngressLink(oldObj, newObj interface{}) {
 }
 
 func (ctlr *Controller) enqueueExternalDNS(obj interface{}) {
-	edns := obj.(*cisapiv1.ExternalDNS)
+	edns := obj.(*cisapiv2.ExternalDNS)
 	log.Infof("Enqueueing ExternalDNS: %v", edns)
 	key := &rqKey{
 		namespace: edns.ObjectMeta.Namespace,
@@ -887,8 +907,8 @@ func (ctlr *Controller) enqueueExternalDNS(obj interface{}) {
 }
 
 func (ctlr *Controller) enqueueUpdatedExternalDNS(oldObj, newObj interface{}) {
-	oldEDNS := oldObj.(*cisapiv1.ExternalDNS)
-	edns := newObj.(*cisapiv1.ExternalDNS)
+	oldEDNS := oldObj.(*cisapiv2.ExternalDNS)
+	edns := newObj.(*cisapiv2.ExternalDNS)
 
 	if oldEDNS.Spec.DomainName != edns.Spec.DomainName {
 		key := &rqKey{
@@ -915,7 +935,7 @@ func (ctlr *Controller) enqueueUpdatedExternalDNS(oldObj, newObj interface{}) {
 }
 
 func (ctlr *Controller) enqueueDeletedExternalDNS(obj interface{}) {
-	edns := obj.(*cisapiv1.ExternalDNS)
+	edns := obj.(*cisapiv2.ExternalDNS)
 	log.Infof("Enqueueing ExternalDNS: %v", edns)
 	key := &rqKey{
 		namespace: edns.ObjectMeta.Namespace,
@@ -1187,6 +1207,7 @@ func (ctlr *Controller) createNamespaceLabeledInformer(label string) error {
 	ctlr.nsInformers[label].nsInformer.AddEventHandlerWithResyncPeriod(
 		&cache.ResourceEventHandlerFuncs{
 			AddFunc:    func(obj interface{}) { ctlr.enqueueNamespace(obj) },
+			UpdateFunc: func(old, cur interface{}) { ctlr.enqueueUpdatedNamespaceLabels(old, cur) },
 			DeleteFunc: func(obj interface{}) { ctlr.enqueueDeletedNamespace(obj) },
 		},
 		resyncPeriod,
@@ -1195,6 +1216,77 @@ func (ctlr *Controller) createNamespaceLabeledInformer(label string) error {
 	return nil
 }
 
+// createNamespaceLabelsInformer watches the labels of all the namespaces, which
+// the namespaceSelector of the Policy targetSelector is matched with when the
+// namespaces are not watched by their label
+func (ctlr *Controller) createNamespaceLabelsInformer() {
+	resyncPeriod := 0 * time.Second
+	ctlr.nsLabelInformer = &NSInformer{
+		stopCh: make(chan struct{}),
+		nsInformer: cache.NewSharedIndexInformer(
+			&cache.ListWatch{
+				ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+					return ctlr.kubeClient.CoreV1().Namespaces().List(context.TODO(), options)
+				},
+				WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+					return ctlr.kubeClient.CoreV1().Namespaces().Watch(context.TODO(), options)
+				},
+			},
+			&corev1.Namespace{},
+			resyncPeriod,
+			cache.Indexers{},
+		),
+	}
+	ctlr.nsLabelInformer.nsInformer.AddEventHandlerWithResyncPeriod(
+		&cache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(old, cur interface{}) { ctlr.enqueueUpdatedNamespaceLabels(old, cur) },
+		},
+		resyncPeriod,
+	)
+}
+
+// enqueueUpdatedNamespaceLabels enqueues the resources of the namespace when its
+// labels change, as they may be attached to or detached from the Policies
+// selecting the namespaces by their labels
+func (ctlr *Controller) enqueueUpdatedNamespaceLabels(oldObj, newObj interface{}) {
+	oldNS := oldObj.(*corev1.Namespace)
+	newNS := newObj.(*corev1.Namespace)
+	if reflect.DeepEqual(oldNS.Labels, newNS.Labels) || !ctlr.hasNamespaceSelectorPolicy() {
+		return
+	}
+	namespace := newNS.Name
+	if _, ok := ctlr.getNamespacedCRInformer(namespace); ok {
+		log.Debugf("Enqueueing the resources of Namespace %v on the update of its labels", namespace)
+		for _, vs := range ctlr.getAllVirtualServers(namespace) {
+			ctlr.enqueueVirtualServer(vs)
+		}
+		for _, ts := range ctlr.getAllTransportServers(namespace) {
+			ctlr.enqueueTransportServer(ts)
+		}
+	}
+	if _, ok := ctlr.getNamespacedCommonInformer(namespace); ok {
+		for _, svc := range ctlr.getAllLBServices(namespace) {
+			ctlr.enqueueService(svc)
+		}
+	}
+}
+
+// hasNamespaceSelectorPolicy returns true if any Policy selects the namespaces by their labels
+func (ctlr *Controller) hasNamespaceSelectorPolicy() bool {
+	for _, comInf := range ctlr.comInformers {
+		if comInf.plcInformer == nil {
+			continue
+		}
+		for _, obj := range comInf.plcInformer.GetIndexer().List() {
+			plc := obj.(*cisapiv2.Policy)
+			if plc.Spec.TargetSelector != nil && plc.Spec.TargetSelector.NamespaceSelector != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (ctlr *Controller) enqueueNamespace(obj interface{}) {
 	ns := obj.(*corev1.Namespace)
 	log.Infof("Enqueueing Namespace: %v", ns)
@@ -1221,6 +1313,131 @@ func (ctlr *Controller) enqueueDeletedNamespace(obj interface{}) {
 	ctlr.resourceQueue.Add(key)
 }
 
+func (dcInfr *DCInformer) start() {
+	if dcInfr.dcInformer != nil {
+		log.Infof("Starting DeployConfig Informer")
+		go dcInfr.dcInformer.Run(dcInfr.stopCh)
+	}
+}
+
+func (dcInfr *DCInformer) stop() {
+	close(dcInfr.stopCh)
+}
+
+// createDeployConfigInformer watches the cluster scoped DeployConfig with the given name
+func (ctlr *Controller) createDeployConfigInformer(name string) error {
+	if name == "" {
+		return fmt.Errorf("cannot set a DeployConfig informer without a name")
+	}
+	dcOptions := func(options *metav1.ListOptions) {
+		options.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+	}
+	resyncPeriod := 0 * time.Second
+
+	ctlr.dcInformer = &DCInformer{
+		stopCh: make(chan struct{}),
+		dcInformer: newDeployConfigInformer(
+			ctlr.kubeCRClient,
+			resyncPeriod,
+			cache.Indexers{},
+			dcOptions,
+		),
+	}
+
+	ctlr.dcInformer.dcInformer.AddEventHandlerWithResyncPeriod(
+		&cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { ctlr.enqueueDeployConfig(obj, Create) },
+			UpdateFunc: func(old, cur interface{}) { ctlr.enqueueDeployConfig(cur, Update) },
+			DeleteFunc: func(obj interface{}) { ctlr.enqueueDeployConfig(obj, Delete) },
+		},
+		resyncPeriod,
+	)
+
+	return nil
+}
+
+func (ctlr *Controller) enqueueDeployConfig(obj interface{}, event string) {
+	dc := obj.(*cisapiv2.DeployConfig)
+	log.Infof("Enqueueing DeployConfig: %v", dc)
+	key := &rqKey{
+		kind:    DeployConfig,
+		rscName: dc.ObjectMeta.Name,
+		rsc:     obj,
+		event:   event,
+	}
+
+	ctlr.resourceQueue.Add(key)
+}
+
+func (ocmInfr *OverrideCMInformer) start() {
+	if ocmInfr.cmInformer != nil {
+		log.Infof("Starting AS3 override ConfigMap Informer")
+		go ocmInfr.cmInformer.Run(ocmInfr.stopCh)
+	}
+}
+
+func (ocmInfr *OverrideCMInformer) stop() {
+	close(ocmInfr.stopCh)
+}
+
+// createOverrideCMInformer watches the AS3 override ConfigMap with the given <namespace>/<name>
+func (ctlr *Controller) createOverrideCMInformer(cmKey string) error {
+	splits := strings.Split(cmKey, "/")
+	if len(splits) != 2 {
+		return fmt.Errorf("invalid AS3 override ConfigMap %v, expected <namespace>/<name>", cmKey)
+	}
+	cmOptions := func(options *metav1.ListOptions) {
+		options.FieldSelector = fields.OneTermEqualSelector("metadata.name", splits[1]).String()
+	}
+	resyncPeriod := 0 * time.Second
+
+	ctlr.overrideCMInformer = &OverrideCMInformer{
+		stopCh: make(chan struct{}),
+		cmInformer: cache.NewSharedIndexInformer(
+			cache.NewFilteredListWatchFromClient(
+				ctlr.kubeClient.CoreV1().RESTClient(),
+				"configmaps",
+				splits[0],
+				cmOptions,
+			),
+			&corev1.ConfigMap{},
+			resyncPeriod,
+			cache.Indexers{},
+		),
+	}
+
+	ctlr.overrideCMInformer.cmInformer.AddEventHandlerWithResyncPeriod(
+		&cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { ctlr.enqueueOverrideConfigMap(obj, Create) },
+			UpdateFunc: func(old, cur interface{}) { ctlr.enqueueOverrideConfigMap(cur, Update) },
+			DeleteFunc: func(obj interface{}) { ctlr.enqueueOverrideConfigMap(obj, Delete) },
+		},
+		resyncPeriod,
+	)
+
+	return nil
+}
+
+func (ctlr *Controller) enqueueOverrideConfigMap(obj interface{}, event string) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	cm, ok := obj.(*corev1.ConfigMap)
+	if !ok {
+		return
+	}
+	log.Infof("Enqueueing AS3 override ConfigMap: %v/%v", cm.Namespace, cm.Name)
+	key := &rqKey{
+		namespace: cm.ObjectMeta.Namespace,
+		kind:      OverrideAS3ConfigMap,
+		rscName:   cm.ObjectMeta.Name,
+		rsc:       obj,
+		event:     event,
+	}
+
+	ctlr.resourceQueue.Add(key)
+}
+
 func (ctlr *Controller) checkCoreserviceLabels(labels map[string]string) bool {
 	for _, v := range labels {
 		if _, ok := K8SCoreServices[v]; ok {
@@ -1229,3 +1446,197 @@ func (ctlr *Controller) checkCoreserviceLabels(labels map[string]string) bool {
 	}
 	return false
 }
+
+// newCustomResourceInformer returns an informer of the cis.f5.com resources
+// which lists and watches the stored v1 resources and caches them converted
+// to v2, as CIS consumes the v2 resources only. The conversion happens in CIS,
+// so the informers work without the conversion webhook and the v2 version
+// being served by the API server
+func newCustomResourceInformer(
+	list func(options metav1.ListOptions) (runtime.Object, error),
+	watchResources func(options metav1.ListOptions) (watch.Interface, error),
+	objType runtime.Object,
+	resyncPeriod time.Duration,
+	indexers cache.Indexers,
+	tweakListOptions func(*metav1.ListOptions),
+) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				obj, err := list(options)
+				if err != nil {
+					return nil, err
+				}
+				return cisapiv2.ConvertFromV1(obj)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				w, err := watchResources(options)
+				if err != nil {
+					return nil, err
+				}
+				return watch.Filter(w, func(event watch.Event) (watch.Event, bool) {
+					// Events without a resource, such as errors, are passed as is
+					if obj, err := cisapiv2.ConvertFromV1(event.Object); err == nil {
+						event.Object = obj
+					}
+					return event, true
+				}), nil
+			},
+		},
+		objType,
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func newVirtualServerInformer(
+	client versioned.Interface,
+	namespace string,
+	resyncPeriod time.Duration,
+	indexers cache.Indexers,
+	tweakListOptions func(*metav1.ListOptions),
+) cache.SharedIndexInformer {
+	return newCustomResourceInformer(
+		func(options metav1.ListOptions) (runtime.Object, error) {
+			return client.CisV1().VirtualServers(namespace).List(context.TODO(), options)
+		},
+		func(options metav1.ListOptions) (watch.Interface, error) {
+			return client.CisV1().VirtualServers(namespace).Watch(context.TODO(), options)
+		},
+		&cisapiv2.VirtualServer{},
+		resyncPeriod,
+		indexers,
+		tweakListOptions,
+	)
+}
+
+func newTLSProfileInformer(
+	client versioned.Interface,
+	namespace string,
+	resyncPeriod time.Duration,
+	indexers cache.Indexers,
+	tweakListOptions func(*metav1.ListOptions),
+) cache.SharedIndexInformer {
+	return newCustomResourceInformer(
+		func(options metav1.ListOptions) (runtime.Object, error) {
+			return client.CisV1().TLSProfiles(namespace).List(context.TODO(), options)
+		},
+		func(options metav1.ListOptions) (watch.Interface, error) {
+			return client.CisV1().TLSProfiles(namespace).Watch(context.TODO(), options)
+		},
+		&cisapiv2.TLSProfile{},
+		resyncPeriod,
+		indexers,
+		tweakListOptions,
+	)
+}
+
+func newTransportServerInformer(
+	client versioned.Interface,
+	namespace string,
+	resyncPeriod time.Duration,
+	indexers cache.Indexers,
+	tweakListOptions func(*metav1.ListOptions),
+) cache.SharedIndexInformer {
+	return newCustomResourceInformer(
+		func(options metav1.ListOptions) (runtime.Object, error) {
+			return client.CisV1().TransportServers(namespace).List(context.TODO(), options)
+		},
+		func(options metav1.ListOptions) (watch.Interface, error) {
+			return client.CisV1().TransportServers(namespace).Watch(context.TODO(), options)
+		},
+		&cisapiv2.TransportServer{},
+		resyncPeriod,
+		indexers,
+		tweakListOptions,
+	)
+}
+
+func newIngressLinkInformer(
+	client versioned.Interface,
+	namespace string,
+	resyncPeriod time.Duration,
+	indexers cache.Indexers,
+	tweakListOptions func(*metav1.ListOptions),
+) cache.SharedIndexInformer {
+	return newCustomResourceInformer(
+		func(options metav1.ListOptions) (runtime.Object, error) {
+			return client.CisV1().IngressLinks(namespace).List(context.TODO(), options)
+		},
+		func(options metav1.ListOptions) (watch.Interface, error) {
+			return client.CisV1().IngressLinks(namespace).Watch(context.TODO(), options)
+		},
+		&cisapiv2.IngressLink{},
+		resyncPeriod,
+		indexers,
+		tweakListOptions,
+	)
+}
+
+func newExternalDNSInformer(
+	client versioned.Interface,
+	namespace string,
+	resyncPeriod time.Duration,
+	indexers cache.Indexers,
+	tweakListOptions func(*metav1.ListOptions),
+) cache.SharedIndexInformer {
+	return newCustomResourceInformer(
+		func(options metav1.ListOptions) (runtime.Object, error) {
+			return client.CisV1().ExternalDNSes(namespace).List(context.TODO(), options)
+		},
+		func(options metav1.ListOptions) (watch.Interface, error) {
+			return client.CisV1().ExternalDNSes(namespace).Watch(context.TODO(), options)
+		},
+		&cisapiv2.ExternalDNS{},
+		resyncPeriod,
+		indexers,
+		tweakListOptions,
+	)
+}
+
+func newPolicyInformer(
+	client versioned.Interface,
+	namespace string,
+	resyncPeriod time.Duration,
+	indexers cache.Indexers,
+	tweakListOptions func(*metav1.ListOptions),
+) cache.SharedIndexInformer {
+	return newCustomResourceInformer(
+		func(options metav1.ListOptions) (runtime.Object, error) {
+			return client.CisV1().Policies(namespace).List(context.TODO(), options)
+		},
+		func(options metav1.ListOptions) (watch.Interface, error) {
+			return client.CisV1().Policies(namespace).Watch(context.TODO(), options)
+		},
+		&cisapiv2.Policy{},
+		resyncPeriod,
+		indexers,
+		tweakListOptions,
+	)
+}
+
+func newDeployConfigInformer(
+	client versioned.Interface,
+	resyncPeriod time.Duration,
+	indexers cache.Indexers,
+	tweakListOptions func(*metav1.ListOptions),
+) cache.SharedIndexInformer {
+	return newCustomResourceInformer(
+		func(options metav1.ListOptions) (runtime.Object, error) {
+			return client.CisV1().DeployConfigs().List(context.TODO(), options)
+		},
+		func(options metav1.ListOptions) (watch.Interface, error) {
+			return client.CisV1().DeployConfigs().Watch(context.TODO(), options)
+		},
+		&cisapiv2.DeployConfig{},
+		resyncPeriod,
+		indexers,
+		tweakListOptions,
+	)
+}
@@ -2,7 +2,7 @@ package controller
 
 import (
 	ficV1 "github.com/F5Networks/f5-ipam-controller/pkg/ipamapis/apis/fic/v1"
-	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v1"
+	cisapiv2 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v2"
 	crdfake "github.com/F5Networks/k8s-bigip-ctlr/config/client/clientset/versioned/fake"
 	"github.com/F5Networks/k8s-bigip-ctlr/pkg/test"
 	. "github.com/onsi/ginkgo"
@@ -66,7 +66,7 @@ var _ = Describe("Informers Tests", func() {
 			vs := test.NewVirtualServer(
 				"SampleVS",
 				namespace,
-				cisapiv1.VirtualServerSpec{
+				cisapiv2.VirtualServerSpec{
 					Host:                 "test.com",
 					VirtualServerAddress: "1.2.3.4",
 				})
@@ -78,7 +78,7 @@ var _ = Describe("Informers Tests", func() {
 			newVS := test.NewVirtualServer(
 				"SampleVS",
 				namespace,
-				cisapiv1.VirtualServerSpec{
+				cisapiv2.VirtualServerSpec{
 					Host:                 "test.com",
 					VirtualServerAddress: "1.2.3.5",
 				})
@@ -100,7 +100,7 @@ var _ = Describe("Informers Tests", func() {
 			updatedVS1 := test.NewVirtualServer(
 				"SampleVS",
 				namespace,
-				cisapiv1.VirtualServerSpec{
+				cisapiv2.VirtualServerSpec{
 					Host:                 "test.com",
 					VirtualServerAddress: "1.2.3.5",
 					SNAT:                 "none",
@@ -118,7 +118,7 @@ var _ = Describe("Informers Tests", func() {
 			updatedVS2 := test.NewVirtualServer(
 				"SampleVS",
 				namespace,
-				cisapiv1.VirtualServerSpec{
+				cisapiv2.VirtualServerSpec{
 					Host:                 "test.com",
 					VirtualServerAddress: "5.6.7.8",
 					SNAT:                 "none",
@@ -145,11 +145,11 @@ var _ = Describe("Informers Tests", func() {
 			tlsp := test.NewTLSProfile(
 				"SampleTLS",
 				namespace,
-				cisapiv1.TLSProfileSpec{
+				cisapiv2.TLSProfileSpec{
 					Hosts: []string{"test.com", "prod.com"},
-					TLS: cisapiv1.TLS{
+					TLS: cisapiv2.TLS{
 						Termination: "edge",
-						ClientSSL:   "2359qhfniqlur89phuf;rhfi",
+						ClientSSLs:  []string{"2359qhfniqlur89phuf;rhfi"},
 					},
 				})
 			mockCtlr.enqueueTLSProfile(tlsp, Create)
@@ -162,7 +162,7 @@ var _ = Describe("Informers Tests", func() {
 			ts := test.NewTransportServer(
 				"SampleTS",
 				namespace,
-				cisapiv1.TransportServerSpec{
+				cisapiv2.TransportServerSpec{
 					SNAT:                 "auto",
 					VirtualServerAddress: "1.2.3.4",
 				})
@@ -174,7 +174,7 @@ var _ = Describe("Informers Tests", func() {
 			newTS := test.NewTransportServer(
 				"SampleTS",
 				namespace,
-				cisapiv1.TransportServerSpec{
+				cisapiv2.TransportServerSpec{
 					SNAT:                 "auto",
 					VirtualServerAddress: "1.2.3.5",
 				})
@@ -205,7 +205,7 @@ var _ = Describe("Informers Tests", func() {
 				"SampleIL",
 				namespace,
 				"1",
-				cisapiv1.IngressLinkSpec{
+				cisapiv2.IngressLinkSpec{
 					VirtualServerAddress: "1.2.3.4",
 					Selector:             selctor,
 					IRules:               iRules,
@@ -220,7 +220,7 @@ var _ = Describe("Informers Tests", func() {
 				"SampleIL",
 				namespace,
 				"1",
-				cisapiv1.IngressLinkSpec{
+				cisapiv2.IngressLinkSpec{
 					VirtualServerAddress: "1.2.3.5",
 					Selector:             selctor,
 					IRules:               iRules,
@@ -244,7 +244,7 @@ var _ = Describe("Informers Tests", func() {
 			edns := test.NewExternalDNS(
 				"SampleEDNS",
 				namespace,
-				cisapiv1.ExternalDNSSpec{
+				cisapiv2.ExternalDNSSpec{
 					DomainName:        "test.com",
 					LoadBalanceMethod: "round-robin",
 				})
@@ -256,7 +256,7 @@ var _ = Describe("Informers Tests", func() {
 			newEDNS := test.NewExternalDNS(
 				"SampleEDNS",
 				namespace,
-				cisapiv1.ExternalDNSSpec{
+				cisapiv2.ExternalDNSSpec{
 					DomainName:        "prod.com",
 					LoadBalanceMethod: "round-robin",
 				})
@@ -278,7 +278,7 @@ var _ = Describe("Informers Tests", func() {
 			plc := test.NewPolicy(
 				"SamplePolicy",
 				namespace,
-				cisapiv1.PolicySpec{})
+				cisapiv2.PolicySpec{})
 			mockCtlr.enqueuePolicy(plc, Create)
 			key, quit := mockCtlr.resourceQueue.Get()
 			Expect(key).ToNot(BeNil(), "Enqueue New Policy Failed")
@@ -287,7 +287,7 @@ var _ = Describe("Informers Tests", func() {
 			newPlc := test.NewPolicy(
 				"SamplePolicy2",
 				namespace,
-				cisapiv1.PolicySpec{})
+				cisapiv2.PolicySpec{})
 			mockCtlr.enqueueDeletedPolicy(newPlc)
 			key, quit = mockCtlr.resourceQueue.Get()
 			Expect(key).ToNot(BeNil(), "Enqueue Updated Policy Failed")
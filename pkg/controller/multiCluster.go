@@ -0,0 +1,625 @@
+/*-
+* Copyright (c) 2016-2021, F5 Networks, Inc.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/F5Networks/k8s-bigip-ctlr/pkg/vlogger"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	// MultiClusterKubeConfigKey is the data key of the kubeconfig in a multi cluster secret
+	MultiClusterKubeConfigKey = "kubeconfig"
+	// ClusterRatioAnnotation sets the ratio of the pool members of a remote cluster
+	ClusterRatioAnnotation = "cis.f5.com/cluster-ratio"
+	// ClusterPriorityAnnotation sets the priority group of the pool members of a remote cluster
+	ClusterPriorityAnnotation = "cis.f5.com/cluster-priority"
+
+	remoteClusterHealthInterval = 10 * time.Second
+)
+
+// setupRemoteClusters creates the informers of the remote clusters from the
+// kubeconfig held by each of the given secrets, and watches the secrets so
+// that the rotated kubeconfigs are picked up
+func (ctlr *Controller) setupRemoteClusters(secrets []string) {
+	if len(secrets) > 0 {
+		ctlr.mcSecretInformer = &MultiClusterSecretInformer{stopCh: make(chan struct{})}
+	}
+	for _, secretKey := range secrets {
+		splits := strings.Split(secretKey, "/")
+		if len(splits) != 2 {
+			log.Errorf("[MultiCluster] Unable to setup remote cluster from secret %v: "+
+				"invalid secret, expected <namespace>/<name>", secretKey)
+			continue
+		}
+		ctlr.addMultiClusterSecretInformer(splits[0], splits[1])
+		secret, err := ctlr.kubeClient.CoreV1().Secrets(splits[0]).Get(context.TODO(), splits[1], metav1.GetOptions{})
+		if err != nil {
+			log.Errorf("[MultiCluster] Unable to setup remote cluster from secret %v: %v", secretKey, err)
+			continue
+		}
+		rc, err := ctlr.newRemoteClusterFromSecret(secret)
+		if err != nil {
+			log.Errorf("[MultiCluster] Unable to setup remote cluster from secret %v: %v", secretKey, err)
+			continue
+		}
+		ctlr.setRemoteCluster(rc)
+		log.Infof("[MultiCluster] Watching remote cluster %v", rc.name)
+	}
+}
+
+// getRemoteCluster returns the remote cluster of the given name
+func (ctlr *Controller) getRemoteCluster(name string) (*RemoteClusterInformer, bool) {
+	ctlr.remoteClustersLock.RLock()
+	defer ctlr.remoteClustersLock.RUnlock()
+	rc, ok := ctlr.remoteClusters[name]
+	return rc, ok
+}
+
+// getRemoteClusters returns the remote clusters in the order of their names
+func (ctlr *Controller) getRemoteClusters() []*RemoteClusterInformer {
+	ctlr.remoteClustersLock.RLock()
+	defer ctlr.remoteClustersLock.RUnlock()
+	clusters := make([]*RemoteClusterInformer, 0, len(ctlr.remoteClusters))
+	for _, rc := range ctlr.remoteClusters {
+		clusters = append(clusters, rc)
+	}
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i].name < clusters[j].name })
+	return clusters
+}
+
+func (ctlr *Controller) setRemoteCluster(rc *RemoteClusterInformer) {
+	ctlr.remoteClustersLock.Lock()
+	defer ctlr.remoteClustersLock.Unlock()
+	ctlr.remoteClusters[rc.name] = rc
+}
+
+func (ctlr *Controller) deleteRemoteCluster(name string) {
+	ctlr.remoteClustersLock.Lock()
+	defer ctlr.remoteClustersLock.Unlock()
+	delete(ctlr.remoteClusters, name)
+}
+
+func (ctlr *Controller) newRemoteClusterFromSecret(secret *v1.Secret) (*RemoteClusterInformer, error) {
+	kubeConfig, ok := secret.Data[MultiClusterKubeConfigKey]
+	if !ok {
+		return nil, fmt.Errorf("secret has no %v", MultiClusterKubeConfigKey)
+	}
+	config, err := clientcmd.RESTConfigFromKubeConfig(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	rc := ctlr.newRemoteClusterInformer(secret.Name, kubeClient)
+	rc.secretVersion = secret.ResourceVersion
+	rc.ratio, err = parseClusterWeight(secret.Annotations, ClusterRatioAnnotation)
+	if err != nil {
+		return nil, err
+	}
+	rc.priority, err = parseClusterWeight(secret.Annotations, ClusterPriorityAnnotation)
+	if err != nil {
+		return nil, err
+	}
+	return rc, nil
+}
+
+// addMultiClusterSecretInformer watches the kubeconfig secret of a remote cluster
+func (ctlr *Controller) addMultiClusterSecretInformer(namespace, name string) {
+	secretOptions := func(options *metav1.ListOptions) {
+		options.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+	}
+	resyncPeriod := 0 * time.Second
+	secretInformer := cache.NewSharedIndexInformer(
+		cache.NewFilteredListWatchFromClient(
+			ctlr.kubeClient.CoreV1().RESTClient(),
+			"secrets",
+			namespace,
+			secretOptions,
+		),
+		&v1.Secret{},
+		resyncPeriod,
+		cache.Indexers{},
+	)
+	secretInformer.AddEventHandlerWithResyncPeriod(
+		&cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { ctlr.enqueueMultiClusterSecret(obj, Create) },
+			UpdateFunc: func(old, cur interface{}) { ctlr.enqueueMultiClusterSecret(cur, Update) },
+			DeleteFunc: func(obj interface{}) { ctlr.enqueueMultiClusterSecret(obj, Delete) },
+		},
+		resyncPeriod,
+	)
+	ctlr.mcSecretInformer.secretInformers = append(ctlr.mcSecretInformer.secretInformers, secretInformer)
+}
+
+func (ctlr *Controller) enqueueMultiClusterSecret(obj interface{}, event string) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	secret, ok := obj.(*v1.Secret)
+	if !ok {
+		return
+	}
+	log.Debugf("[MultiCluster] Enqueueing kubeconfig secret %v/%v", secret.Namespace, secret.Name)
+	key := &rqKey{
+		namespace: secret.Namespace,
+		kind:      MultiClusterSecret,
+		rscName:   secret.Name,
+		rsc:       obj,
+		event:     event,
+	}
+	ctlr.resourceQueue.Add(key)
+}
+
+func (mcsInfr *MultiClusterSecretInformer) start() {
+	for _, secretInformer := range mcsInfr.secretInformers {
+		go secretInformer.Run(mcsInfr.stopCh)
+	}
+}
+
+func (mcsInfr *MultiClusterSecretInformer) stop() {
+	close(mcsInfr.stopCh)
+}
+
+// processMultiClusterSecret recreates the informers of the remote cluster when
+// its kubeconfig secret is updated, and removes the remote cluster along with
+// its pool members when the secret is deleted
+func (ctlr *Controller) processMultiClusterSecret(secret *v1.Secret, isDelete bool) {
+	oldRC, ok := ctlr.getRemoteCluster(secret.Name)
+	if isDelete {
+		if !ok {
+			return
+		}
+		log.Infof("[MultiCluster] Kubeconfig secret %v/%v deleted, removing remote cluster %v",
+			secret.Namespace, secret.Name, oldRC.name)
+		oldRC.stop()
+		ctlr.deleteRemoteCluster(oldRC.name)
+		ctlr.updateRemoteClusterServices(oldRC)
+		return
+	}
+	if ok && oldRC.secretVersion == secret.ResourceVersion {
+		return
+	}
+	rc, err := ctlr.newRemoteClusterFromSecret(secret)
+	if err != nil {
+		log.Errorf("[MultiCluster] Unable to setup remote cluster from secret %v/%v: %v",
+			secret.Namespace, secret.Name, err)
+		return
+	}
+	if ok {
+		log.Infof("[MultiCluster] Kubeconfig secret %v/%v updated, reconnecting to remote cluster %v",
+			secret.Namespace, secret.Name, rc.name)
+		oldRC.stop()
+	} else {
+		log.Infof("[MultiCluster] Watching remote cluster %v", rc.name)
+	}
+	// The pool members are refreshed as the new informers list the remote services
+	ctlr.setRemoteCluster(rc)
+	ctlr.startRemoteCluster(rc)
+}
+
+func parseClusterWeight(annotations map[string]string, key string) (int32, error) {
+	val, ok := annotations[key]
+	if !ok {
+		return 0, nil
+	}
+	weight, err := strconv.ParseInt(val, 10, 32)
+	if err != nil || weight < 0 {
+		return 0, fmt.Errorf("invalid %v annotation: %v", key, val)
+	}
+	return int32(weight), nil
+}
+
+// newRemoteClusterInformer creates the Service, Endpoints and Node informers
+// of a remote cluster across all of its namespaces
+func (ctlr *Controller) newRemoteClusterInformer(
+	name string,
+	kubeClient kubernetes.Interface,
+) *RemoteClusterInformer {
+	resyncPeriod := 0 * time.Second
+	ctx := context.TODO()
+
+	// The remote cluster is up once its informers are synced
+	rc := &RemoteClusterInformer{
+		name:       name,
+		kubeClient: kubeClient,
+		stopCh:     make(chan struct{}),
+		svcInformer: cache.NewSharedIndexInformer(
+			&cache.ListWatch{
+				ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+					return kubeClient.CoreV1().Services("").List(ctx, options)
+				},
+				WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+					return kubeClient.CoreV1().Services("").Watch(ctx, options)
+				},
+			},
+			&v1.Service{},
+			resyncPeriod,
+			cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+		),
+		epsInformer: cache.NewSharedIndexInformer(
+			&cache.ListWatch{
+				ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+					return kubeClient.CoreV1().Endpoints("").List(ctx, options)
+				},
+				WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+					return kubeClient.CoreV1().Endpoints("").Watch(ctx, options)
+				},
+			},
+			&v1.Endpoints{},
+			resyncPeriod,
+			cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+		),
+		nodeInformer: cache.NewSharedIndexInformer(
+			&cache.ListWatch{
+				ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+					return kubeClient.CoreV1().Nodes().List(ctx, options)
+				},
+				WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+					return kubeClient.CoreV1().Nodes().Watch(ctx, options)
+				},
+			},
+			&v1.Node{},
+			resyncPeriod,
+			cache.Indexers{},
+		),
+	}
+
+	enqueueRemoteService := func(obj interface{}) {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		meta, ok := obj.(metav1.Object)
+		if !ok {
+			return
+		}
+		ctlr.enqueueRemoteService(rc, meta.GetNamespace(), meta.GetName())
+	}
+	svcHandler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    enqueueRemoteService,
+		UpdateFunc: func(old, cur interface{}) { enqueueRemoteService(cur) },
+		DeleteFunc: enqueueRemoteService,
+	}
+	rc.svcInformer.AddEventHandler(svcHandler)
+	rc.epsInformer.AddEventHandler(svcHandler)
+
+	// Node changes only matter to the pool members in nodeport mode
+	if ctlr.PoolMemberType == NodePort {
+		rc.nodeInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) { ctlr.enqueueRemoteCluster(rc) },
+			UpdateFunc: func(old, cur interface{}) {
+				oldNode, ok1 := old.(*v1.Node)
+				curNode, ok2 := cur.(*v1.Node)
+				if ok1 && ok2 && reflect.DeepEqual(oldNode.Status.Addresses, curNode.Status.Addresses) &&
+					reflect.DeepEqual(oldNode.Labels, curNode.Labels) &&
+					ctlr.isNodeExcluded(*oldNode) == ctlr.isNodeExcluded(*curNode) {
+					return
+				}
+				ctlr.enqueueRemoteCluster(rc)
+			},
+			DeleteFunc: func(obj interface{}) { ctlr.enqueueRemoteCluster(rc) },
+		})
+	}
+
+	return rc
+}
+
+func (ctlr *Controller) enqueueRemoteService(rc *RemoteClusterInformer, namespace, name string) {
+	log.Debugf("[MultiCluster] Enqueueing Service %v/%v of cluster %v", namespace, name, rc.name)
+	key := &rqKey{
+		namespace: namespace,
+		kind:      RemoteService,
+		rscName:   name,
+		rsc:       rc.name,
+		event:     Update,
+	}
+	ctlr.resourceQueue.Add(key)
+}
+
+func (ctlr *Controller) enqueueRemoteCluster(rc *RemoteClusterInformer) {
+	log.Debugf("[MultiCluster] Enqueueing remote cluster %v", rc.name)
+	key := &rqKey{
+		kind:    RemoteCluster,
+		rscName: rc.name,
+		rsc:     rc.name,
+		event:   Update,
+	}
+	ctlr.resourceQueue.Add(key)
+}
+
+// startRemoteCluster runs the informers of the remote cluster along with
+// the reachability check which withdraws its pool members while it is down.
+// The check starts once the informers are synced, so that the pool members
+// are not withdrawn from the pools while the remote services are listed
+func (ctlr *Controller) startRemoteCluster(rc *RemoteClusterInformer) {
+	go rc.svcInformer.Run(rc.stopCh)
+	go rc.epsInformer.Run(rc.stopCh)
+	synced := []cache.InformerSynced{rc.svcInformer.HasSynced, rc.epsInformer.HasSynced}
+	if ctlr.PoolMemberType == NodePort {
+		go rc.nodeInformer.Run(rc.stopCh)
+		synced = append(synced, rc.nodeInformer.HasSynced)
+	}
+	go func() {
+		if !cache.WaitForCacheSync(rc.stopCh, synced...) {
+			return
+		}
+		log.Debugf("[MultiCluster] Informers of remote cluster %v synced", rc.name)
+		wait.Until(func() { ctlr.checkRemoteCluster(rc) }, remoteClusterHealthInterval, rc.stopCh)
+	}()
+}
+
+func (rc *RemoteClusterInformer) stop() {
+	close(rc.stopCh)
+}
+
+func (rc *RemoteClusterInformer) isUp() bool {
+	rc.RLock()
+	defer rc.RUnlock()
+	return rc.up
+}
+
+// setUp records the reachability of the remote cluster and
+// returns true when it changed
+func (rc *RemoteClusterInformer) setUp(up bool) bool {
+	rc.Lock()
+	defer rc.Unlock()
+	changed := rc.up != up
+	rc.up = up
+	return changed
+}
+
+func (ctlr *Controller) checkRemoteCluster(rc *RemoteClusterInformer) {
+	_, err := rc.kubeClient.Discovery().ServerVersion()
+	if !rc.setUp(err == nil) {
+		return
+	}
+	if err != nil {
+		log.Errorf("[MultiCluster] Remote cluster %v is unreachable, withdrawing its pool members: %v", rc.name, err)
+	} else {
+		log.Infof("[MultiCluster] Remote cluster %v is reachable, restoring its pool members", rc.name)
+	}
+	ctlr.enqueueRemoteCluster(rc)
+}
+
+// processRemoteCluster refreshes the pool members of the local services
+// which are also served by the given remote cluster
+func (ctlr *Controller) processRemoteCluster(clusterName string) {
+	rc, ok := ctlr.getRemoteCluster(clusterName)
+	if !ok {
+		return
+	}
+	ctlr.updateRemoteClusterServices(rc)
+}
+
+// updateRemoteClusterServices refreshes the pool members of the local services
+// which are also served by the given remote cluster
+func (ctlr *Controller) updateRemoteClusterServices(rc *RemoteClusterInformer) {
+	for _, obj := range rc.svcInformer.GetIndexer().List() {
+		remoteSvc := obj.(*v1.Service)
+		svc := ctlr.getLocalService(remoteSvc.Namespace, remoteSvc.Name)
+		if svc == nil {
+			continue
+		}
+		ctlr.updatePoolMembersForService(svc)
+	}
+}
+
+// getLocalService returns the service of the local cluster with the given
+// namespace and name, or nil when it is not watched
+func (ctlr *Controller) getLocalService(namespace, name string) *v1.Service {
+	comInf, ok := ctlr.getNamespacedCommonInformer(namespace)
+	if !ok {
+		return nil
+	}
+	obj, found, _ := comInf.svcInformer.GetIndexer().GetByKey(namespace + "/" + name)
+	if !found {
+		return nil
+	}
+	return obj.(*v1.Service)
+}
+
+// updatePoolMembersForService refreshes the pool members of all the
+// resources served by the service without reprocessing them
+func (ctlr *Controller) updatePoolMembersForService(svc *v1.Service) {
+	if svc.Spec.Type == v1.ServiceTypeLoadBalancer {
+		if err := ctlr.processLBServices(svc, false); err != nil {
+			log.Errorf("[MultiCluster] Unable to update pool members of service %v/%v: %v",
+				svc.Namespace, svc.Name, err)
+		}
+		return
+	}
+	switch ctlr.mode {
+	case OpenShiftMode:
+		ctlr.updatePoolMembersForRoutes(svc, false)
+	default:
+		ctlr.updatePoolMembersForVirtuals(svc)
+	}
+}
+
+// withRemotePoolMembers returns the given local pool members followed by the
+// members of the same service from the reachable remote clusters, weighted
+// with the ratio and priority group of their cluster
+func (ctlr *Controller) withRemotePoolMembers(rsCfg *ResourceConfig, pool Pool, members []PoolMember) []PoolMember {
+	remoteClusters := ctlr.getRemoteClusters()
+	if len(remoteClusters) == 0 {
+		return members
+	}
+	merged := make([]PoolMember, 0, len(members))
+	for _, member := range members {
+		member.Ratio = ctlr.localClusterRatio
+		member.PriorityGroup = ctlr.localClusterPriority
+		merged = append(merged, member)
+	}
+	// clusters are merged in the order of their names to keep the pools stable
+	for _, rc := range remoteClusters {
+		if !rc.isUp() {
+			continue
+		}
+		var remoteMembers []PoolMember
+		if ctlr.PoolMemberType == NodePort {
+			remoteMembers = ctlr.getRemoteNodePortMembers(rc, pool)
+		} else {
+			remoteMembers = getRemoteClusterMembers(rc, pool)
+		}
+		for _, member := range remoteMembers {
+			member.Ratio = rc.ratio
+			member.PriorityGroup = rc.priority
+			merged = append(merged, member)
+		}
+		if len(remoteMembers) > 0 {
+			rsCfg.MetaData.Active = true
+		}
+	}
+	return merged
+}
+
+// getRemoteClusterMembers returns the endpoints of the pool service in the remote cluster
+func getRemoteClusterMembers(rc *RemoteClusterInformer, pool Pool) []PoolMember {
+	obj, found, _ := rc.epsInformer.GetIndexer().GetByKey(pool.ServiceNamespace + "/" + pool.ServiceName)
+	if !found {
+		return nil
+	}
+	eps := obj.(*v1.Endpoints)
+	var members []PoolMember
+	for _, subset := range eps.Subsets {
+		for _, p := range subset.Ports {
+			// The pool service port is matched by name when it is named, otherwise by number
+			if pool.ServicePort.Type == intstr.String {
+				if p.Name != pool.ServicePort.StrVal {
+					continue
+				}
+			} else if p.Port != pool.ServicePort.IntVal {
+				continue
+			}
+			for _, addr := range subset.Addresses {
+				members = append(members, PoolMember{
+					Address: addr.IP,
+					Port:    p.Port,
+					Session: "user-enabled",
+				})
+			}
+		}
+	}
+	return members
+}
+
+// getRemoteNodePortMembers returns the nodes of the remote cluster
+// with the nodeport of the pool service in the remote cluster. As in the
+// local cluster, the services with externalTrafficPolicy Local are only
+// served by the nodes hosting one of their endpoints
+func (ctlr *Controller) getRemoteNodePortMembers(rc *RemoteClusterInformer, pool Pool) []PoolMember {
+	obj, found, _ := rc.svcInformer.GetIndexer().GetByKey(pool.ServiceNamespace + "/" + pool.ServiceName)
+	if !found {
+		return nil
+	}
+	svc := obj.(*v1.Service)
+	var nodePort int32
+	for _, svcPort := range svc.Spec.Ports {
+		if svcPort.TargetPort == pool.ServicePort {
+			nodePort = svcPort.NodePort
+		}
+	}
+	if nodePort == 0 {
+		return nil
+	}
+	var localNodes map[string]struct{}
+	if svc.Spec.ExternalTrafficPolicy == v1.ServiceExternalTrafficPolicyTypeLocal {
+		localNodes = getRemoteEndpointNodes(rc, svc.Namespace, svc.Name)
+	}
+
+	var labelKey, labelValue string
+	if pool.NodeMemberLabel != "" {
+		label := strings.Split(pool.NodeMemberLabel, "=")
+		if len(label) != 2 {
+			log.Warningf("Invalid NodeMemberLabel: %v", pool.NodeMemberLabel)
+			return nil
+		}
+		labelKey, labelValue = label[0], label[1]
+	}
+	addrType := v1.NodeExternalIP
+	if ctlr.UseNodeInternal {
+		addrType = v1.NodeInternalIP
+	}
+
+	var nodes []*v1.Node
+	for _, obj := range rc.nodeInformer.GetIndexer().List() {
+		nodes = append(nodes, obj.(*v1.Node))
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+
+	var members []PoolMember
+	for _, node := range nodes {
+		if ctlr.isNodeExcluded(*node) {
+			continue
+		}
+		if localNodes != nil {
+			if _, ok := localNodes[node.Name]; !ok {
+				continue
+			}
+		}
+		if labelKey != "" && node.Labels[labelKey] != labelValue {
+			continue
+		}
+		for _, addr := range node.Status.Addresses {
+			if addr.Type == addrType {
+				members = append(members, PoolMember{
+					Address: addr.Address,
+					Port:    nodePort,
+					Session: "user-enabled",
+				})
+			}
+		}
+	}
+	return members
+}
+
+// getRemoteEndpointNodes returns the nodes hosting a ready endpoint of the service in the remote cluster
+func getRemoteEndpointNodes(rc *RemoteClusterInformer, namespace, name string) map[string]struct{} {
+	nodes := make(map[string]struct{})
+	obj, found, _ := rc.epsInformer.GetIndexer().GetByKey(namespace + "/" + name)
+	if !found {
+		return nodes
+	}
+	for _, subset := range obj.(*v1.Endpoints).Subsets {
+		for _, addr := range subset.Addresses {
+			if addr.NodeName != nil {
+				nodes[*addr.NodeName] = struct{}{}
+			}
+		}
+	}
+	return nodes
+}
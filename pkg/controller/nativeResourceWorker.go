@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v1"
+	cisapiv2 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v2"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"os"
 	"sort"
@@ -44,7 +44,7 @@ func (ctlr *Controller) processRoutes(routeGroup string, triggerDelete bool) err
 
 	if triggerDelete || len(routes) == 0 {
 		// Delete all possible virtuals for this route group
-		for _, portStruct := range getBasicVirtualPorts() {
+		for _, portStruct := range ctlr.getBasicVirtualPorts() {
 			rsName := frameRouteVSName(extdSpec.VServerName, extdSpec.VServerAddr, portStruct)
 			vs := ctlr.getVirtualServer(partition, rsName)
 			if vs != nil {
@@ -57,7 +57,7 @@ func (ctlr *Controller) processRoutes(routeGroup string, triggerDelete bool) err
 		return nil
 	}
 
-	portStructs := getVirtualPortsForRoutes(routes)
+	portStructs := ctlr.getVirtualPortsForRoutes(routes)
 	vsMap := make(ResourceMap)
 	processingError := false
 
@@ -145,7 +145,7 @@ func (ctlr *Controller) processRoutes(routeGroup string, triggerDelete bool) err
 	if !processingError {
 		var hosts []string
 		for name, rscfg := range vsMap {
-			rsMap := ctlr.resources.getPartitionResourceMap(partition)
+			rsMap := ctlr.resources.getPartitionResourceMapForUpdate(partition)
 			rsMap[name] = rscfg
 
 			if len(rscfg.MetaData.hosts) > 0 {
@@ -199,7 +199,7 @@ func (ctlr *Controller) handleRouteGroupExtendedSpec(rsCfg *ResourceConfig, extd
 		if !exist {
 			return fmt.Errorf("Policy Not Found: %v", policy)
 		}
-		plc := obj.(*cisapiv1.Policy)
+		plc := obj.(*cisapiv2.Policy)
 		if plc != nil {
 			err := ctlr.handleVSResourceConfigForPolicy(rsCfg, plc)
 			if err != nil {
@@ -265,8 +265,9 @@ func (ctlr *Controller) prepareResourceConfigFromRoute(
 
 	// Use default SNAT if not provided by user
 	if rsCfg.Virtual.SNAT == "" {
-		rsCfg.Virtual.SNAT = DEFAULT_SNAT
+		rsCfg.Virtual.SNAT = ctlr.defaultSNAT()
 	}
+	ctlr.setDeployConfigDefaults(rsCfg)
 
 	backendSvcs := GetRouteBackends(route)
 
@@ -597,7 +598,7 @@ func (ctlr *Controller) GetServiceRouteWithoutHealthAnnotation(service *v1.Servi
 
 func (ctlr *Controller) updatePoolMembersForRoutes(svc *v1.Service, updatePoolHealthMon bool) {
 	namespace := svc.Namespace
-	for _, portStruct := range getBasicVirtualPorts() {
+	for _, portStruct := range ctlr.getBasicVirtualPorts() {
 		routeGroup, ok := ctlr.resources.invertedNamespaceLabelMap[namespace]
 		if !ok {
 			continue
@@ -632,9 +633,19 @@ func (ctlr *Controller) updatePoolMembersForRoutes(svc *v1.Service, updatePoolHe
 func (ctlr *Controller) processGlobalExtendedRouteConfig() {
 	splits := strings.Split(ctlr.routeSpecCMKey, "/")
 	ns, cmName := splits[0], splits[1]
-	cm, err := ctlr.kubeClient.CoreV1().ConfigMaps(ns).Get(context.TODO(), cmName, metav1.GetOptions{})
-	if err != nil {
-		log.Errorf("Unable to Get Extended Route Spec Config Map: %v, %v", ctlr.routeSpecCMKey, err)
+	var cm *v1.ConfigMap
+	var err error
+	// The config map is read from the informer of its namespace when it is watched
+	if nrInf, ok := ctlr.getNamespacedNativeInformer(ns); ok && nrInf.cmInformer != nil {
+		if obj, found, _ := nrInf.cmInformer.GetIndexer().GetByKey(ctlr.routeSpecCMKey); found {
+			cm = obj.(*v1.ConfigMap)
+		}
+	}
+	if cm == nil {
+		cm, err = ctlr.kubeClient.CoreV1().ConfigMaps(ns).Get(context.TODO(), cmName, metav1.GetOptions{})
+		if err != nil {
+			log.Errorf("Unable to Get Extended Route Spec Config Map: %v, %v", ctlr.routeSpecCMKey, err)
+		}
 	}
 	err = ctlr.setNamespaceLabelMode(cm)
 	if err != nil {
@@ -932,6 +943,15 @@ func (ctlr *Controller) processConfigMap(cm *v1.ConfigMap, isDelete bool) (error
 }
 
 func (ctlr *Controller) readBaseRouteConfigFromGlobalCM(baseRouteConfig BaseRouteConfig) {
+	ctlr.resources.globalBaseRouteConfig = baseRouteConfig
+	ctlr.updateBaseRouteConfig()
+}
+
+// updateBaseRouteConfig computes the base route config, the route spec configmap
+// takes precedence over the DeployConfig which takes precedence over the built-in defaults
+func (ctlr *Controller) updateBaseRouteConfig() {
+	dcTLS := ctlr.deployConfigSpec.TLS
+	baseRouteConfig := ctlr.resources.globalBaseRouteConfig
 
 	//declare default configuration for TLS Ciphers
 	ctlr.resources.baseRouteConfig.TLSCipher = TLSCipher{
@@ -941,6 +961,24 @@ func (ctlr *Controller) readBaseRouteConfigFromGlobalCM(baseRouteConfig BaseRout
 	}
 	ctlr.resources.baseRouteConfig.DefaultTLS = DefaultSSLProfile{}
 
+	if dcTLS.TLSVersion != "" {
+		ctlr.resources.baseRouteConfig.TLSCipher.TLSVersion = dcTLS.TLSVersion
+	}
+	if dcTLS.Ciphers != "" {
+		ctlr.resources.baseRouteConfig.TLSCipher.Ciphers = dcTLS.Ciphers
+	}
+	if dcTLS.CipherGroup != "" {
+		ctlr.resources.baseRouteConfig.TLSCipher.CipherGroup = dcTLS.CipherGroup
+	}
+	// The client and server SSL profiles are set independently
+	if dcTLS.ClientSSL != "" || dcTLS.ServerSSL != "" {
+		ctlr.resources.baseRouteConfig.DefaultTLS = DefaultSSLProfile{
+			ClientSSL: dcTLS.ClientSSL,
+			ServerSSL: dcTLS.ServerSSL,
+			Reference: BIGIP,
+		}
+	}
+
 	if (baseRouteConfig != BaseRouteConfig{}) {
 		if baseRouteConfig.TLSCipher.TLSVersion != "" {
 			ctlr.resources.baseRouteConfig.TLSCipher.TLSVersion = baseRouteConfig.TLSCipher.TLSVersion
@@ -954,8 +992,12 @@ func (ctlr *Controller) readBaseRouteConfigFromGlobalCM(baseRouteConfig BaseRout
 		}
 	}
 	if baseRouteConfig.DefaultTLS != (DefaultSSLProfile{}) {
-		ctlr.resources.baseRouteConfig.DefaultTLS.ClientSSL = baseRouteConfig.DefaultTLS.ClientSSL
-		ctlr.resources.baseRouteConfig.DefaultTLS.ServerSSL = baseRouteConfig.DefaultTLS.ServerSSL
+		if baseRouteConfig.DefaultTLS.ClientSSL != "" {
+			ctlr.resources.baseRouteConfig.DefaultTLS.ClientSSL = baseRouteConfig.DefaultTLS.ClientSSL
+		}
+		if baseRouteConfig.DefaultTLS.ServerSSL != "" {
+			ctlr.resources.baseRouteConfig.DefaultTLS.ServerSSL = baseRouteConfig.DefaultTLS.ServerSSL
+		}
 		ctlr.resources.baseRouteConfig.DefaultTLS.Reference = baseRouteConfig.DefaultTLS.Reference
 	}
 }
@@ -1122,30 +1164,30 @@ func isPassthroughRoute(route *routeapi.Route) bool {
 	return false
 }
 
-func getBasicVirtualPorts() []portStruct {
+func (ctlr *Controller) getBasicVirtualPorts() []portStruct {
 	return []portStruct{
 		{
 			protocol: "http",
-			port:     DEFAULT_HTTP_PORT,
+			port:     ctlr.defaultHTTPPort(),
 		},
 		{
 			protocol: "https",
-			port:     DEFAULT_HTTPS_PORT,
+			port:     ctlr.defaultHTTPSPort(),
 		},
 	}
 }
 
-func getVirtualPortsForRoutes(routes []*routeapi.Route) []portStruct {
+func (ctlr *Controller) getVirtualPortsForRoutes(routes []*routeapi.Route) []portStruct {
 	ports := []portStruct{
 		{
 			protocol: "http",
-			port:     DEFAULT_HTTP_PORT,
+			port:     ctlr.defaultHTTPPort(),
 		},
 	}
 
 	for _, rt := range routes {
 		if isSecureRoute(rt) {
-			return getBasicVirtualPorts()
+			return ctlr.getBasicVirtualPorts()
 		}
 	}
 	return ports
@@ -1425,6 +1467,15 @@ func (ctlr *Controller) getNamespacesForRouteGroup(namespaceGroup string) []stri
 		ctlr.resources.invertedNamespaceLabelMap[namespaceGroup] = namespaceGroup
 	} else {
 		nsLabel := fmt.Sprintf("%v,%v", ctlr.namespaceLabel, namespaceGroup)
+		// The namespaces are read from the namespace informer of the label when it is set up
+		if nsInf, ok := ctlr.nsInformers[nsLabel]; ok {
+			for _, obj := range nsInf.nsInformer.GetIndexer().List() {
+				ns := obj.(*v1.Namespace)
+				namespaces = append(namespaces, ns.Name)
+				ctlr.resources.invertedNamespaceLabelMap[ns.Name] = namespaceGroup
+			}
+			return namespaces
+		}
 		nss, err := ctlr.kubeClient.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{LabelSelector: nsLabel})
 		if err != nil {
 			log.Errorf("Unable to Fetch Namespaces: %v", err)
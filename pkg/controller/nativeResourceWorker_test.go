@@ -2,7 +2,7 @@ package controller
 
 import (
 	"fmt"
-	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v1"
+	cisapiv2 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v2"
 	"strings"
 	"time"
 
@@ -337,17 +337,17 @@ extendedRouteSpec:
 			newEDNS := test.NewExternalDNS(
 				"SampleEDNS",
 				"default",
-				cisapiv1.ExternalDNSSpec{
+				cisapiv2.ExternalDNSSpec{
 					DomainName: "test.com",
-					Pools: []cisapiv1.DNSPool{
+					Pools: []cisapiv2.DNSPool{
 						{
 							DataServerName: "DataServer",
-							Monitor: cisapiv1.Monitor{
+							Monitors: []cisapiv2.Monitor{{
 								Type:     "http",
 								Send:     "GET /health",
 								Interval: 10,
 								Timeout:  10,
-							},
+							}},
 						},
 					},
 				})
@@ -418,17 +418,17 @@ extendedRouteSpec:
 			barEDNS := test.NewExternalDNS(
 				"barEDNS",
 				"default",
-				cisapiv1.ExternalDNSSpec{
+				cisapiv2.ExternalDNSSpec{
 					DomainName: "pytest-bar-1.com",
-					Pools: []cisapiv1.DNSPool{
+					Pools: []cisapiv2.DNSPool{
 						{
 							DataServerName: "DataServer",
-							Monitor: cisapiv1.Monitor{
+							Monitors: []cisapiv2.Monitor{{
 								Type:     "http",
 								Send:     "GET /health",
 								Interval: 10,
 								Timeout:  10,
-							},
+							}},
 						},
 					},
 				})
@@ -6,7 +6,7 @@ import (
 	"strings"
 	"time"
 
-	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v1"
+	cisapiv2 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v2"
 
 	"github.com/F5Networks/k8s-bigip-ctlr/pkg/pollers"
 	"github.com/F5Networks/k8s-bigip-ctlr/pkg/vxlan"
@@ -17,12 +17,18 @@ import (
 
 func (ctlr *Controller) SetupNodePolling(
 	nodePollInterval int,
+	nodeUpdateDebounce int,
 	nodeLabelSelector string,
 	vxlanMode string,
 	vxlanName string,
 ) error {
 	intervalFactor := time.Duration(nodePollInterval)
-	ctlr.nodePoller = pollers.NewNodePoller(ctlr.kubeClient, intervalFactor*time.Second, nodeLabelSelector)
+	ctlr.nodePoller = pollers.NewNodeInformer(
+		ctlr.kubeClient,
+		intervalFactor*time.Second,
+		time.Duration(nodeUpdateDebounce)*time.Millisecond,
+		nodeLabelSelector,
+	)
 
 	// Register appMgr to watch for node updates to keep track of watched nodes
 	err := ctlr.nodePoller.RegisterListener(ctlr.ProcessNodeUpdate)
@@ -39,12 +45,14 @@ func (ctlr *Controller) SetupNodePolling(
 		if slashPos != -1 {
 			tunnelName = cleanPath[slashPos+1:]
 		}
-		vxMgr, err := vxlan.NewVxlanMgr(
+		vxMgr, err := vxlan.NewVxlanMgrForCNI(
 			vxlanMode,
 			tunnelName,
+			ctlr.orchestrationCNI,
 			ctlr.UseNodeInternal,
-			ctlr.Agent.ConfigWriter,
+			ctlr.Agent.NetWriter,
 			ctlr.Agent.EventChan,
+			ctlr.dynamicClient,
 		)
 		if nil != err {
 			return fmt.Errorf("error creating vxlan manager: %v", err)
@@ -56,12 +64,37 @@ func (ctlr *Controller) SetupNodePolling(
 			return fmt.Errorf("error registering node update listener for vxlan mode: %v",
 				err)
 		}
+		if registrar, ok := ctlr.nodePoller.(pollers.TriggerRegistrar); ok && ctlr.orchestrationCNI == vxlan.CNICilium {
+			// The CiliumNodes are cached, and their changes update the fdb records
+			err = registrar.RegisterTrigger(vxMgr.NewCiliumNodeInformer)
+			if nil != err {
+				return fmt.Errorf("error registering CiliumNode trigger for vxlan mode: %v", err)
+			}
+		}
 		if ctlr.Agent.EventChan != nil {
 			// It handles arp entries related to PoolMembers
 			vxMgr.ProcessAppmanagerEvents(ctlr.kubeClient)
 		}
 	}
 
+	if ctlr.staticRoutingMode {
+		srMgr, err := vxlan.NewStaticRouteMgr(
+			ctlr.UseNodeInternal,
+			ctlr.staticRouteAnnotation,
+			ctlr.Agent.NetWriter,
+		)
+		if nil != err {
+			return fmt.Errorf("error creating static route manager: %v", err)
+		}
+
+		// Register srMgr to watch for node updates to process static routes
+		err = ctlr.nodePoller.RegisterListener(srMgr.ProcessNodeUpdate)
+		if nil != err {
+			return fmt.Errorf("error registering node update listener for static routing mode: %v",
+				err)
+		}
+	}
+
 	return nil
 }
 
@@ -74,6 +107,24 @@ func (ctlr *Controller) ProcessNodeUpdate(
 		return
 	}
 
+	ctlr.nodeUpdateLock.Lock()
+	defer ctlr.nodeUpdateLock.Unlock()
+	ctlr.lastNodeUpdate = obj
+	ctlr.processNodes(obj)
+}
+
+// reevaluateNodes processes the latest nodes again once the drain of a node has
+// timed out, so that the drained node is removed without waiting for a node update
+func (ctlr *Controller) reevaluateNodes() {
+	ctlr.nodeUpdateLock.Lock()
+	defer ctlr.nodeUpdateLock.Unlock()
+	if ctlr.lastNodeUpdate != nil {
+		log.Debugf("Processing Node Updates after the drain timeout")
+		ctlr.processNodes(ctlr.lastNodeUpdate)
+	}
+}
+
+func (ctlr *Controller) processNodes(obj interface{}) {
 	newNodes, err := ctlr.getNodes(obj)
 	if nil != err {
 		log.Warningf("Unable to get list of nodes, err=%+v", err)
@@ -92,7 +143,7 @@ func (ctlr *Controller) ProcessNodeUpdate(
 					virtuals := crInf.vsInformer.GetIndexer().List()
 					if len(virtuals) != 0 {
 						for _, virtual := range virtuals {
-							vs := virtual.(*cisapiv1.VirtualServer)
+							vs := virtual.(*cisapiv2.VirtualServer)
 							qKey := &rqKey{
 								vs.ObjectMeta.Namespace,
 								VirtualServer,
@@ -106,7 +157,7 @@ func (ctlr *Controller) ProcessNodeUpdate(
 					transportVirtuals := crInf.tsInformer.GetIndexer().List()
 					if len(transportVirtuals) != 0 {
 						for _, virtual := range transportVirtuals {
-							vs := virtual.(*cisapiv1.TransportServer)
+							vs := virtual.(*cisapiv2.TransportServer)
 							qKey := &rqKey{
 								vs.ObjectMeta.Namespace,
 								TransportServer,
@@ -120,7 +171,7 @@ func (ctlr *Controller) ProcessNodeUpdate(
 					ingressLinks := crInf.ilInformer.GetIndexer().List()
 					if len(ingressLinks) != 0 {
 						for _, ingressLink := range ingressLinks {
-							il := ingressLink.(*cisapiv1.IngressLink)
+							il := ingressLink.(*cisapiv2.IngressLink)
 							qKey := &rqKey{
 								il.ObjectMeta.Namespace,
 								IngressLink,
@@ -210,14 +261,32 @@ func (ctlr *Controller) getNodes(
 	}
 
 	// Append list of nodes to watchedNodes
+	drainStart := make(map[string]time.Time)
 	for _, node := range nodes {
+		var draining bool
+		if ctlr.isNodeExcluded(node) {
+			// Excluded nodes are drained before their removal
+			start, found := ctlr.nodeDrainStart[node.ObjectMeta.Name]
+			if !found {
+				start = time.Now()
+				log.Debugf("Draining node %v", node.ObjectMeta.Name)
+				// The node is removed once the drain timeout elapses, even without a node update
+				time.AfterFunc(ctlr.nodeDrainTimeout, ctlr.reevaluateNodes)
+			}
+			drainStart[node.ObjectMeta.Name] = start
+			if time.Since(start) >= ctlr.nodeDrainTimeout {
+				continue
+			}
+			draining = true
+		}
 		nodeAddrs := node.Status.Addresses
 		for _, addr := range nodeAddrs {
 			if addr.Type == addrType {
 				n := Node{
-					Name:   node.ObjectMeta.Name,
-					Addr:   addr.Address,
-					Labels: make(map[string]string),
+					Name:     node.ObjectMeta.Name,
+					Addr:     addr.Address,
+					Labels:   make(map[string]string),
+					Draining: draining,
 				}
 				for k, v := range node.ObjectMeta.Labels {
 					n.Labels[k] = v
@@ -226,10 +295,51 @@ func (ctlr *Controller) getNodes(
 			}
 		}
 	}
+	ctlr.nodeDrainStart = drainStart
 
 	return watchedNodes, nil
 }
 
+// isNodeExcluded checks if the node is cordoned, NotReady, marked as excluded
+// from external load balancers or has the exclusion taint or label
+func (ctlr *Controller) isNodeExcluded(node v1.Node) bool {
+	if node.Spec.Unschedulable {
+		return true
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady && condition.Status != v1.ConditionTrue {
+			return true
+		}
+	}
+	if _, ok := node.ObjectMeta.Labels[v1.LabelNodeExcludeBalancers]; ok {
+		return true
+	}
+	if ctlr.nodeExcludeLabel != "" {
+		key, value, hasValue := splitKeyValue(ctlr.nodeExcludeLabel)
+		if v, ok := node.ObjectMeta.Labels[key]; ok && (!hasValue || v == value) {
+			return true
+		}
+	}
+	if ctlr.nodeExcludeTaint != "" {
+		key, value, hasValue := splitKeyValue(ctlr.nodeExcludeTaint)
+		for _, taint := range node.Spec.Taints {
+			if taint.Key == key && (!hasValue || taint.Value == value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// splitKeyValue splits <key>=<value>, the value is optional
+func splitKeyValue(str string) (string, string, bool) {
+	kv := strings.SplitN(str, "=", 2)
+	if len(kv) == 2 {
+		return kv[0], kv[1], true
+	}
+	return kv[0], "", false
+}
+
 func (ctlr *Controller) getNodesWithLabel(
 	nodeMemberLabel string,
 ) []Node {
@@ -1,9 +1,10 @@
 package controller
 
 import (
-	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v1"
+	"time"
+
+	cisapiv2 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v2"
 	crdfake "github.com/F5Networks/k8s-bigip-ctlr/config/client/clientset/versioned/fake"
-	cisinfv1 "github.com/F5Networks/k8s-bigip-ctlr/config/client/informers/externalversions/cis/v1"
 	"github.com/F5Networks/k8s-bigip-ctlr/pkg/test"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -29,10 +30,20 @@ var _ = Describe("Node Poller Handler", func() {
 	It("Setup", func() {
 		err := mockCtlr.SetupNodePolling(
 			30,
+			1000,
 			"",
 			"maintain",
 			"test/vxlan")
 		Expect(err).To(BeNil(), "Failed to setup Node Poller")
+
+		mockCtlr.staticRoutingMode = true
+		err = mockCtlr.SetupNodePolling(
+			30,
+			1000,
+			"",
+			"",
+			"")
+		Expect(err).To(BeNil(), "Failed to setup Node Poller with static routing")
 	})
 
 	It("Nodes", func() {
@@ -80,6 +91,71 @@ var _ = Describe("Node Poller Handler", func() {
 		Expect(nodes).To(BeNil(), "Failed to Validate Nodes with Label")
 	})
 
+	It("Drains and removes the excluded nodes", func() {
+		nodeAddr := []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.4"}}
+		nodeObjs := []v1.Node{
+			*test.NewNode("worker1", "1", false, nodeAddr, nil),
+			*test.NewNode("worker2", "1", true, nodeAddr, nil),
+			*test.NewNode("worker3", "1", false, nodeAddr, nil),
+			*test.NewNode("worker4", "1", false, nodeAddr, nil),
+			*test.NewNode("worker5", "1", false, nodeAddr, nil),
+			*test.NewNode("worker6", "1", false, nodeAddr, nil),
+		}
+		nodeObjs[2].Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionUnknown}}
+		nodeObjs[3].Spec.Taints = []v1.Taint{{Key: "maintenance", Value: "true", Effect: v1.TaintEffectNoSchedule}}
+		nodeObjs[4].Labels = map[string]string{"lb": "exclude"}
+		nodeObjs[5].Labels = map[string]string{v1.LabelNodeExcludeBalancers: ""}
+		mockCtlr.nodeExcludeTaint = "maintenance"
+		mockCtlr.nodeExcludeLabel = "lb=exclude"
+		mockCtlr.nodeDrainTimeout = time.Hour
+
+		nodes, err := mockCtlr.getNodes(nodeObjs)
+		Expect(err).To(BeNil())
+		Expect(nodes).To(HaveLen(6))
+		Expect(nodes[0].Draining).To(BeFalse())
+		for _, node := range nodes[1:] {
+			Expect(node.Draining).To(BeTrue(), node.Name+" is not draining")
+		}
+		Expect(mockCtlr.nodeDrainStart).To(HaveLen(5))
+
+		// Nodes are removed after the drain timeout
+		mockCtlr.nodeDrainStart["worker2"] = time.Now().Add(-2 * time.Hour)
+		nodes, err = mockCtlr.getNodes(nodeObjs)
+		Expect(err).To(BeNil())
+		Expect(nodes).To(HaveLen(5))
+		nodes, err = mockCtlr.getNodes(nodeObjs)
+		Expect(nodes).To(HaveLen(5))
+
+		// Healthy nodes are no longer drained
+		nodeObjs[2].Status.Conditions[0].Status = v1.ConditionTrue
+		nodeObjs[4].Labels["lb"] = "include"
+		nodes, err = mockCtlr.getNodes(nodeObjs)
+		Expect(err).To(BeNil())
+		Expect(nodes).To(HaveLen(5))
+		Expect(nodes[1].Draining).To(BeFalse())
+		Expect(nodes[3].Draining).To(BeFalse())
+		Expect(mockCtlr.nodeDrainStart).To(HaveLen(3))
+	})
+
+	It("Removes the drained nodes after the drain timeout without a node update", func() {
+		nodeAddr := []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.4"}}
+		nodeObjs := []v1.Node{
+			*test.NewNode("worker1", "1", false, nodeAddr, nil),
+			*test.NewNode("worker2", "1", true, nodeAddr, nil),
+		}
+		mockCtlr.nodeDrainTimeout = 100 * time.Millisecond
+		mockCtlr.ProcessNodeUpdate(nodeObjs, nil)
+		oldNodes := func() []Node {
+			mockCtlr.nodeUpdateLock.Lock()
+			defer mockCtlr.nodeUpdateLock.Unlock()
+			return mockCtlr.oldNodes
+		}
+		Expect(oldNodes()).To(HaveLen(2))
+		Expect(oldNodes()[1].Draining).To(BeTrue())
+		Eventually(oldNodes).Should(HaveLen(1))
+		Expect(oldNodes()[0].Name).To(Equal("worker1"))
+	})
+
 	Describe("Processes CIS monitored resources on node update", func() {
 		BeforeEach(func() {
 			namespace := ""
@@ -91,7 +167,7 @@ var _ = Describe("Node Poller Handler", func() {
 			mockCtlr.comInformers = make(map[string]*CommonInformer)
 			_ = mockCtlr.addNamespacedInformers("", false)
 			mockCtlr.resources = NewResourceStore()
-			mockCtlr.crInformers[""].ilInformer = cisinfv1.NewFilteredIngressLinkInformer(
+			mockCtlr.crInformers[""].ilInformer = newIngressLinkInformer(
 				mockCtlr.kubeCRClient,
 				namespace,
 				0,
@@ -118,10 +194,10 @@ var _ = Describe("Node Poller Handler", func() {
 			typeMeta := metav1.TypeMeta{
 				Kind: IngressLink,
 			}
-			ingressLink := &cisapiv1.IngressLink{
+			ingressLink := &cisapiv2.IngressLink{
 				ObjectMeta: meta,
 				TypeMeta:   typeMeta,
-				Spec: cisapiv1.IngressLinkSpec{
+				Spec: cisapiv2.IngressLinkSpec{
 					Host:                 "abc.com",
 					VirtualServerAddress: "10.11.12.13",
 				},
@@ -85,13 +85,6 @@ func (postMgr *PostManager) getAS3TaskIdURL(taskId string) string {
 
 // publishConfig posts incoming configuration to BIG-IP
 func (postMgr *PostManager) publishConfig(cfg agentConfig) {
-	// For the very first post after starting controller, need not wait to post
-	if !postMgr.firstPost && postMgr.AS3PostDelay != 0 {
-		// Time (in seconds) that CIS waits to post the AS3 declaration to BIG-IP.
-		log.Debugf("[AS3] Delaying post to BIG-IP for %v seconds", postMgr.AS3PostDelay)
-		_ = <-time.After(time.Duration(postMgr.AS3PostDelay) * time.Second)
-	}
-
 	log.Debug("[AS3] PostManager Accepted the configuration")
 
 	// postConfig updates the tenantResponseMap with response codes
@@ -348,6 +341,59 @@ func (postMgr *PostManager) GetBigipRegKey() (string, error) {
 	return "", fmt.Errorf("Error response from BIGIP with status code %v", httpResp.StatusCode)
 }
 
+// dryRunTenant posts the declaration of the tenant to AS3 as a dry run, which
+// compares it with the configuration of BIG-IP without applying it. Returns the
+// changes AS3 would make to the tenant on BIG-IP
+func (postMgr *PostManager) dryRunTenant(tenant string, decl as3Declaration) ([]string, error) {
+	req, err := http.NewRequest("POST", postMgr.getAS3APIURL([]string{tenant}), bytes.NewBuffer([]byte(decl)))
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(postMgr.BIGIPUsername, postMgr.BIGIPPassword)
+
+	httpResp, responseMap := postMgr.httpPOST(req)
+	if httpResp == nil || responseMap == nil {
+		return nil, fmt.Errorf("no valid response from BIGIP")
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Error response from BIGIP with status code %v", httpResp.StatusCode)
+	}
+
+	var result map[string]interface{}
+	results, _ := responseMap["results"].([]interface{})
+	for _, r := range results {
+		if res, ok := r.(map[string]interface{}); ok && res["tenant"] == tenant {
+			result = res
+		}
+	}
+	if result == nil {
+		return nil, fmt.Errorf("no result for the tenant in the response from BIGIP")
+	}
+	if code, _ := result["code"].(float64); int(code) != http.StatusOK {
+		return nil, fmt.Errorf("dry run failed with status code %v: %v", result["code"], result["message"])
+	}
+
+	// With traceResponse, AS3 returns the differences found in the tenant
+	traces, _ := responseMap["traces"].(map[string]interface{})
+	if diff, ok := traces[tenant+"Diff"].([]interface{}); ok {
+		var changes []string
+		for _, d := range diff {
+			change, _ := d.(map[string]interface{})
+			path, _ := change["path"].([]interface{})
+			elems := make([]string, 0, len(path))
+			for _, elem := range path {
+				elems = append(elems, strings.TrimPrefix(fmt.Sprint(elem), "/"))
+			}
+			changes = append(changes, fmt.Sprintf("%v /%v", change["kind"], strings.Join(elems, "/")))
+		}
+		return changes, nil
+	}
+	if result["message"] == "no change" {
+		return nil, nil
+	}
+	return []string{fmt.Sprint(result["message"])}, nil
+}
+
 func (postMgr *PostManager) httpReq(request *http.Request) (*http.Response, map[string]interface{}) {
 	httpResp, err := postMgr.httpClient.Do(request)
 	if err != nil {
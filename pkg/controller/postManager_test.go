@@ -13,7 +13,6 @@ var _ = Describe("PostManager Tests", func() {
 		mockPM = newMockPostManger()
 		mockPM.tenantResponseMap = make(map[string]tenantResponse)
 		mockPM.LogResponse = true
-		mockPM.AS3PostDelay = 2
 	})
 
 	It("Setup Client", func() {
@@ -19,17 +19,12 @@ package controller
 import (
 	"bufio"
 	"fmt"
-	"net/http"
 	"os"
 	"os/exec"
 	"strings"
 	"syscall"
 	"time"
 
-	bigIPPrometheus "github.com/F5Networks/k8s-bigip-ctlr/pkg/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
-
-	"github.com/F5Networks/k8s-bigip-ctlr/pkg/health"
 	"github.com/F5Networks/k8s-bigip-ctlr/pkg/writer"
 
 	log "github.com/F5Networks/k8s-bigip-ctlr/pkg/vlogger"
@@ -176,8 +171,6 @@ func (agent *Agent) startPythonDriver(
 
 	subPid := <-subPidCh
 	agent.PythonDriverPID = subPid
-	//Enable "/health" and "/metrics" endpoint with controller
-	go agent.healthCheckPythonDriver()
 
 	return
 }
@@ -195,15 +188,3 @@ func (agent *Agent) stopPythonDriver() {
 		}
 	}
 }
-
-func (agent *Agent) healthCheckPythonDriver() {
-	// Expose Prometheus metrics
-	http.Handle("/metrics", promhttp.Handler())
-	// Add health check to track whether Python process still alive
-	hc := &health.HealthChecker{
-		SubPID: agent.PythonDriverPID,
-	}
-	http.Handle("/health", hc.HealthCheckHandler())
-	bigIPPrometheus.RegisterMetrics()
-	log.Fatal(http.ListenAndServe(agent.HttpAddress, nil).Error())
-}
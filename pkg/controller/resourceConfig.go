@@ -33,7 +33,7 @@ import (
 
 	routeapi "github.com/openshift/api/route/v1"
 
-	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v1"
+	cisapiv2 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v2"
 	log "github.com/F5Networks/k8s-bigip-ctlr/pkg/vlogger"
 	v1 "k8s.io/api/core/v1"
 )
@@ -51,6 +51,7 @@ func (rs *ResourceStore) Init() {
 	rs.ltmConfigCache = make(LTMConfig)
 	rs.gtmConfig = make(GTMConfig)
 	rs.gtmConfigCache = make(GTMConfig)
+	rs.dirtyPartitions = make(map[string]struct{})
 	rs.poolMemCache = make(PoolMemberCache)
 	rs.nplStore = make(NPLStore)
 	rs.extdSpecMap = make(extendedSpecMap)
@@ -91,6 +92,10 @@ const (
 	HttpsRedirectDgName = "https_redirect_dg"
 	TLSIRuleName        = "tls_irule"
 	ABPathIRuleName     = "ab_deployment_path_irule"
+	RateLimitIRuleName  = "rate_limit_irule"
+
+	// Default response code for requests exceeding the rate limit
+	DefaultRateLimitResponseCode = 429
 )
 
 // constants for TLS references
@@ -214,19 +219,19 @@ func (ctlr *Controller) virtualPorts(input interface{}) []portStruct {
 
 	http := portStruct{
 		protocol: "http",
-		port:     DEFAULT_HTTP_PORT,
+		port:     ctlr.defaultHTTPPort(),
 	}
 
 	https := portStruct{
 		protocol: "https",
-		port:     DEFAULT_HTTPS_PORT,
+		port:     ctlr.defaultHTTPSPort(),
 	}
 
 	var ports []portStruct
 
 	switch input.(type) {
-	case *cisapiv1.VirtualServer:
-		vs := input.(*cisapiv1.VirtualServer)
+	case *cisapiv2.VirtualServer:
+		vs := input.(*cisapiv2.VirtualServer)
 		if vs.Spec.VirtualServerHTTPPort != 0 {
 			http.port = vs.Spec.VirtualServerHTTPPort
 		}
@@ -262,7 +267,7 @@ func formatCustomVirtualServerName(name string, port int32) string {
 	return fmt.Sprintf("%s_%d", name, port)
 }
 
-func (ctlr *Controller) framePoolName(ns string, pool cisapiv1.Pool, host string) string {
+func (ctlr *Controller) framePoolName(ns string, pool cisapiv2.Pool, host string) string {
 
 	poolName := pool.Name
 	if poolName == "" {
@@ -392,17 +397,141 @@ func (ctlr *Controller) fetchTargetPort(namespace, svcName string, servicePort i
 	return targetPort
 }
 
+// getPodProbeMonitor builds a health monitor from the readinessProbe (or livenessProbe when
+// no readinessProbe is defined) of the pods backing the service.
+// Returns nil if no pod with a usable probe is found.
+func (ctlr *Controller) getPodProbeMonitor(
+	namespace string,
+	svcName string,
+	targetPort intstr.IntOrString,
+	partition string,
+) *Monitor {
+	if !ctlr.podProbeHealthMonitor {
+		return nil
+	}
+	comInf, ok := ctlr.getNamespacedCommonInformer(namespace)
+	if !ok || comInf.podInformer == nil {
+		return nil
+	}
+	svc := ctlr.GetService(namespace, svcName)
+	if svc == nil {
+		return nil
+	}
+	objs, err := comInf.podInformer.GetIndexer().ByIndex(cache.NamespaceIndex, namespace)
+	if err != nil {
+		log.Debugf("Unable to fetch pods in namespace %v: %v", namespace, err)
+		return nil
+	}
+	var pods []*v1.Pod
+	for _, obj := range objs {
+		pod := obj.(*v1.Pod)
+		if ctlr.matchSvcSelectorPodLabels(svc.Spec.Selector, pod.Labels) {
+			pods = append(pods, pod)
+		}
+	}
+	// Pods of a service share the same template, sort them so that the
+	// monitor does not flap between pods of different revisions
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].Name < pods[j].Name
+	})
+
+	for _, pod := range pods {
+		for _, container := range pod.Spec.Containers {
+			port, found := resolveContainerPort(container, targetPort)
+			if !found {
+				// container ports are informational, a single container pod
+				// may serve the target port without listing it
+				if len(pod.Spec.Containers) != 1 || targetPort.Type != intstr.Int {
+					continue
+				}
+				port = targetPort.IntVal
+			}
+			probe := container.ReadinessProbe
+			if probe == nil {
+				probe = container.LivenessProbe
+			}
+			if probe == nil {
+				continue
+			}
+			monitor := &Monitor{
+				Partition: partition,
+				Interval:  int(probe.PeriodSeconds),
+				Type:      "tcp",
+			}
+			if monitor.Interval == 0 {
+				// kubernetes default periodSeconds
+				monitor.Interval = 10
+			}
+			failureThreshold := probe.FailureThreshold
+			if failureThreshold == 0 {
+				failureThreshold = 3
+			}
+			// BIG-IP marks the member down once timeout elapses without a
+			// successful check, which maps to failureThreshold probes
+			monitor.Timeout = int(failureThreshold)*monitor.Interval + int(probe.TimeoutSeconds)
+			var probePort intstr.IntOrString
+			switch {
+			case probe.HTTPGet != nil:
+				monitor.Type = "http"
+				if probe.HTTPGet.Scheme == v1.URISchemeHTTPS {
+					monitor.Type = "https"
+				}
+				monitor.Path = probe.HTTPGet.Path
+				if monitor.Path == "" {
+					monitor.Path = "/"
+				}
+				send := fmt.Sprintf("GET %s HTTP/1.0\r\n", monitor.Path)
+				for _, header := range probe.HTTPGet.HTTPHeaders {
+					send += fmt.Sprintf("%s: %s\r\n", header.Name, header.Value)
+				}
+				monitor.Send = send + "\r\n"
+				// kubernetes treats any status code in the range 200-399 as success
+				monitor.Recv = "HTTP/1\\.[01] [23]"
+				probePort = probe.HTTPGet.Port
+			case probe.TCPSocket != nil:
+				probePort = probe.TCPSocket.Port
+			default:
+				// exec and other probe types can not be run by BIG-IP,
+				// fall back to a tcp monitor on the pool member port
+				log.Debugf("Unsupported probe type on pod %v/%v, using tcp monitor", pod.Namespace, pod.Name)
+			}
+			if (intstr.IntOrString{}) != probePort {
+				resolved := probePort.IntVal
+				if probePort.Type == intstr.String {
+					resolved, _ = resolveContainerPort(container, probePort)
+				}
+				if resolved != 0 && resolved != port {
+					monitor.TargetPort = resolved
+				}
+			}
+			return monitor
+		}
+	}
+	return nil
+}
+
+// resolveContainerPort returns the port number of the container matching the given port
+func resolveContainerPort(container v1.Container, port intstr.IntOrString) (int32, bool) {
+	for _, cp := range container.Ports {
+		if (port.Type == intstr.String && cp.Name == port.StrVal) ||
+			(port.Type == intstr.Int && cp.ContainerPort == port.IntVal) {
+			return cp.ContainerPort, true
+		}
+	}
+	return 0, false
+}
+
 // Prepares resource config based on VirtualServer resource config
 func (ctlr *Controller) prepareRSConfigFromVirtualServer(
 	rsCfg *ResourceConfig,
-	vs *cisapiv1.VirtualServer,
+	vs *cisapiv2.VirtualServer,
 	passthroughVS bool,
 ) error {
 
 	var httpPort int32
-	httpPort = DEFAULT_HTTP_PORT
+	httpPort = ctlr.defaultHTTPPort()
 	var snat string
-	snat = DEFAULT_SNAT
+	snat = ctlr.defaultSNAT()
 	var pools Pools
 	var rules *Rules
 	var monitors []Monitor
@@ -412,12 +541,7 @@ func (ctlr *Controller) prepareRSConfigFromVirtualServer(
 
 		poolName := ctlr.framePoolName(vs.Namespace, pl, vs.Spec.Host)
 		//check for custom monitor
-		var monitorName string
-		if pl.Monitor.Name != "" && pl.Monitor.Reference == BIGIP {
-			monitorName = pl.Monitor.Name
-		} else {
-			monitorName = pl.Name + "-monitor"
-		}
+		monitorName := pl.Name + "-monitor"
 
 		if _, ok := framedPools[poolName]; ok {
 			// Pool with same name framed earlier, so skipping this pool
@@ -445,25 +569,7 @@ func (ctlr *Controller) prepareRSConfigFromVirtualServer(
 			ReselectTries:     pl.ReselectTries,
 			ServiceDownAction: pl.ServiceDownAction,
 		}
-		if pl.Monitor.Name != "" && pl.Monitor.Reference == "bigip" {
-			pool.MonitorNames = append(pool.MonitorNames, MonitorName{Name: pl.Monitor.Name, Reference: pl.Monitor.Reference})
-		} else if pl.Monitor.Send != "" && pl.Monitor.Type != "" {
-			if pl.Name == "" {
-				monitorName = formatMonitorName(vs.ObjectMeta.Namespace, pl.Service, pl.Monitor.Type, pl.ServicePort, vs.Spec.Host, pl.Path)
-			}
-			pool.MonitorNames = append(pool.MonitorNames, MonitorName{Name: JoinBigipPath(rsCfg.Virtual.Partition, monitorName)})
-			monitor := Monitor{
-				Name:       monitorName,
-				Partition:  rsCfg.Virtual.Partition,
-				Type:       pl.Monitor.Type,
-				Interval:   pl.Monitor.Interval,
-				Send:       pl.Monitor.Send,
-				Recv:       pl.Monitor.Recv,
-				Timeout:    pl.Monitor.Timeout,
-				TargetPort: pl.Monitor.TargetPort,
-			}
-			monitors = append(monitors, monitor)
-		} else if pl.Monitors != nil {
+		if pl.Monitors != nil {
 			for _, monitor := range pl.Monitors {
 				if monitor.Name != "" && monitor.Reference == BIGIP {
 					pool.MonitorNames = append(pool.MonitorNames, MonitorName{Name: monitor.Name, Reference: monitor.Reference})
@@ -491,6 +597,13 @@ func (ctlr *Controller) prepareRSConfigFromVirtualServer(
 					rsCfg.Monitors = append(rsCfg.Monitors, monitor)
 				}
 			}
+		} else if monitor := ctlr.getPodProbeMonitor(svcNamespace, pl.Service, targetPort, rsCfg.Virtual.Partition); monitor != nil {
+			if pl.Name == "" {
+				monitorName = formatMonitorName(vs.ObjectMeta.Namespace, pl.Service, monitor.Type, pl.ServicePort, vs.Spec.Host, pl.Path)
+			}
+			monitor.Name = monitorName
+			pool.MonitorNames = append(pool.MonitorNames, MonitorName{Name: JoinBigipPath(rsCfg.Virtual.Partition, monitorName)})
+			monitors = append(monitors, *monitor)
 		}
 		pools = append(pools, pool)
 	}
@@ -521,8 +634,9 @@ func (ctlr *Controller) prepareRSConfigFromVirtualServer(
 	if len(vs.Spec.AllowVLANs) > 0 {
 		rsCfg.Virtual.AllowVLANs = vs.Spec.AllowVLANs
 	}
-	if vs.Spec.PersistenceProfile != "" {
-		rsCfg.Virtual.PersistenceProfile = vs.Spec.PersistenceProfile
+	ctlr.setDeployConfigDefaults(rsCfg)
+	if vs.Spec.Profiles.PersistenceProfile != "" {
+		rsCfg.Virtual.PersistenceProfile = vs.Spec.Profiles.PersistenceProfile
 	}
 
 	if len(vs.Spec.Profiles.TCP.Client) > 0 || len(vs.Spec.Profiles.TCP.Server) > 0 {
@@ -542,8 +656,8 @@ func (ctlr *Controller) prepareRSConfigFromVirtualServer(
 		rsCfg.Virtual.ProfileBotDefense = vs.Spec.BotDefense
 	}
 
-	if vs.Spec.ProfileMultiplex != "" {
-		rsCfg.Virtual.ProfileMultiplex = vs.Spec.ProfileMultiplex
+	if vs.Spec.Profiles.ProfileMultiplex != "" {
+		rsCfg.Virtual.ProfileMultiplex = vs.Spec.Profiles.ProfileMultiplex
 	}
 
 	// Do not Create Virtual Server L7 Forwarding policies if HTTPTraffic is set to None or Redirect
@@ -847,8 +961,8 @@ func (ctlr *Controller) handleTLS(
 // Return value is whether or not a custom profile was updated
 func (ctlr *Controller) handleVirtualServerTLS(
 	rsCfg *ResourceConfig,
-	vs *cisapiv1.VirtualServer,
-	tls *cisapiv1.TLSProfile,
+	vs *cisapiv2.VirtualServer,
+	tls *cisapiv2.TLSProfile,
 	ip string,
 ) bool {
 	if 0 == len(vs.Spec.TLSProfileName) {
@@ -863,23 +977,13 @@ func (ctlr *Controller) handleVirtualServerTLS(
 	var httpsPort int32
 
 	if vs.Spec.VirtualServerHTTPSPort == 0 {
-		httpsPort = DEFAULT_HTTPS_PORT
+		httpsPort = ctlr.defaultHTTPSPort()
 	} else {
 		httpsPort = vs.Spec.VirtualServerHTTPSPort
 	}
 	bigIPSSLProfiles := BigIPSSLProfiles{}
-	// Giving priority to ClientSSLs over ClientSSL
-	if len(tls.Spec.TLS.ClientSSLs) > 0 {
-		bigIPSSLProfiles.clientSSLs = tls.Spec.TLS.ClientSSLs
-	} else if tls.Spec.TLS.ClientSSL != "" {
-		bigIPSSLProfiles.clientSSLs = append(bigIPSSLProfiles.clientSSLs, tls.Spec.TLS.ClientSSL)
-	}
-	// Giving priority to ServerSSLs over ServerSSL
-	if len(tls.Spec.TLS.ServerSSLs) > 0 {
-		bigIPSSLProfiles.serverSSLs = tls.Spec.TLS.ServerSSLs
-	} else if tls.Spec.TLS.ServerSSL != "" {
-		bigIPSSLProfiles.serverSSLs = append(bigIPSSLProfiles.serverSSLs, tls.Spec.TLS.ServerSSL)
-	}
+	bigIPSSLProfiles.clientSSLs = tls.Spec.TLS.ClientSSLs
+	bigIPSSLProfiles.serverSSLs = tls.Spec.TLS.ServerSSLs
 	var poolPathRefs []poolPathRef
 	for _, pl := range vs.Spec.Pools {
 
@@ -907,36 +1011,41 @@ func (ctlr *Controller) handleVirtualServerTLS(
 
 // validate TLSProfile
 // validation includes valid parameters for the type of termination(edge, re-encrypt and Pass-through)
-func validateTLSProfile(tls *cisapiv1.TLSProfile) bool {
+func validateTLSProfile(tls *cisapiv2.TLSProfile) bool {
+	if err := validateTLSProfileSpec(tls); err != nil {
+		log.Errorf("%v", err)
+		return false
+	}
+	return true
+}
+
+// validateTLSProfileSpec returns the error of the TLSProfile spec if any
+func validateTLSProfileSpec(tls *cisapiv2.TLSProfile) error {
 	//validation for re-encrypt termination
 	if tls.Spec.TLS.Termination == "reencrypt" {
 		// Should contain both client and server SSL profiles
-		if (tls.Spec.TLS.ClientSSL == "" || tls.Spec.TLS.ServerSSL == "") && (len(tls.Spec.TLS.ClientSSLs) == 0 || len(tls.Spec.TLS.ServerSSLs) == 0) {
-			log.Errorf("TLSProfile %s of type re-encrypt termination should contain both "+
+		if len(tls.Spec.TLS.ClientSSLs) == 0 || len(tls.Spec.TLS.ServerSSLs) == 0 {
+			return fmt.Errorf("TLSProfile %s of type re-encrypt termination should contain both "+
 				"ClientSSLs and ServerSSLs", tls.ObjectMeta.Name)
-			return false
 		}
 	} else if tls.Spec.TLS.Termination == "edge" {
 		// Should contain only client SSL
-		if tls.Spec.TLS.ClientSSL == "" && len(tls.Spec.TLS.ClientSSLs) == 0 {
-			log.Errorf("TLSProfile %s of type edge termination should contain ClientSSLs",
+		if len(tls.Spec.TLS.ClientSSLs) == 0 {
+			return fmt.Errorf("TLSProfile %s of type edge termination should contain ClientSSLs",
 				tls.ObjectMeta.Name)
-			return false
 		}
-		if tls.Spec.TLS.ServerSSL != "" || len(tls.Spec.TLS.ServerSSLs) != 0 {
-			log.Errorf("TLSProfile %s of type edge termination should NOT contain ServerSSLs",
+		if len(tls.Spec.TLS.ServerSSLs) != 0 {
+			return fmt.Errorf("TLSProfile %s of type edge termination should NOT contain ServerSSLs",
 				tls.ObjectMeta.Name)
-			return false
 		}
 	} else {
 		// Pass-through
-		if (tls.Spec.TLS.ClientSSL != "") || (tls.Spec.TLS.ServerSSL != "") || len(tls.Spec.TLS.ClientSSLs) != 0 || len(tls.Spec.TLS.ServerSSLs) != 0 {
-			log.Errorf("TLSProfile %s of type Pass-through termination should NOT contain either "+
+		if len(tls.Spec.TLS.ClientSSLs) != 0 || len(tls.Spec.TLS.ServerSSLs) != 0 {
+			return fmt.Errorf("TLSProfile %s of type Pass-through termination should NOT contain either "+
 				"ClientSSLs or ServerSSLs", tls.ObjectMeta.Name)
-			return false
 		}
 	}
-	return true
+	return nil
 }
 
 // ConvertStringToProfileRef converts strings to profile references
@@ -1054,7 +1163,20 @@ func (rc *ResourceConfig) FindPolicy(controlType string) *Policy {
 	return nil
 }
 
+// getPartitionResourceMap returns the ResourceMap of the partition to be read,
+// nil if the partition does not exist
 func (rs *ResourceStore) getPartitionResourceMap(partition string) ResourceMap {
+	partitionConfig, ok := rs.ltmConfig[partition]
+	if !ok {
+		return nil
+	}
+	return partitionConfig.ResourceMap
+}
+
+// getPartitionResourceMapForUpdate returns the ResourceMap of the partition to be
+// updated, the partition is created if required and marked dirty
+func (rs *ResourceStore) getPartitionResourceMapForUpdate(partition string) ResourceMap {
+	rs.markPartitionDirty(partition)
 	_, ok := rs.ltmConfig[partition]
 	if !ok {
 		rs.ltmConfig[partition] = &PartitionConfig{make(ResourceMap), 0}
@@ -1091,37 +1213,55 @@ func (rs *ResourceStore) setResourceConfig(partition, name string, rsCfg *Resour
 		return fmt.Errorf("partition not available")
 	}
 	partitionConfig.ResourceMap[name] = rsCfg
+	rs.markPartitionDirty(partition)
 	return nil
 }
 
-// getSanitizedLTMConfigCopy is a Resource reference copy of LTMConfig
-func (rs *ResourceStore) getSanitizedLTMConfigCopy() LTMConfig {
-	ltmConfig := make(LTMConfig)
-	var deletePartitions []string
-	for prtn, partitionConfig := range rs.ltmConfig {
+// markPartitionDirty records the update of the partition, so that only the updated
+// partitions are compared with the caches and rebuilt by the Agent
+func (rs *ResourceStore) markPartitionDirty(partition string) {
+	rs.dirtyPartitions[partition] = struct{}{}
+}
+
+// resetLTMConfigCache clears the LTMConfig cache and marks all the partitions
+// dirty, so that all of them are posted by the next request
+func (rs *ResourceStore) resetLTMConfigCache() {
+	rs.ltmConfigCache = make(LTMConfig)
+	for partition := range rs.ltmConfig {
+		rs.markPartitionDirty(partition)
+	}
+}
+
+// updateLTMConfigCache updates the dirty partitions of ltmConfigCache with
+// Resource reference copies of LTMConfig
+func (rs *ResourceStore) updateLTMConfigCache() {
+	for prtn := range rs.dirtyPartitions {
+		partitionConfig, ok := rs.ltmConfig[prtn]
 		// copy only those partitions where virtual server exists otherwise remove from ltmConfig
-		if len(partitionConfig.ResourceMap) > 0 {
-			ltmConfig[prtn] = &PartitionConfig{make(ResourceMap), partitionConfig.Priority}
-			for rsName, res := range partitionConfig.ResourceMap {
-				ltmConfig[prtn].ResourceMap[rsName] = res
-			}
-		} else {
-			deletePartitions = append(deletePartitions, prtn)
+		if !ok || len(partitionConfig.ResourceMap) == 0 {
+			delete(rs.ltmConfig, prtn)
+			delete(rs.ltmConfigCache, prtn)
+			continue
+		}
+		rs.ltmConfigCache[prtn] = &PartitionConfig{make(ResourceMap), partitionConfig.Priority}
+		for rsName, res := range partitionConfig.ResourceMap {
+			rs.ltmConfigCache[prtn].ResourceMap[rsName] = res
 		}
 	}
-	// delete the partitions if there are no virtuals in that partition
-	for _, prtn := range deletePartitions {
-		delete(rs.ltmConfig, prtn)
-	}
-	return ltmConfig
 }
 
-// getLTMConfigDeepCopy is a Resource reference copy of LTMConfig
-func (rs *ResourceStore) getLTMConfigDeepCopy() LTMConfig {
+// getLTMConfigCopy is a copy of LTMConfig with deep copies of the dirty partitions,
+// the other partitions are Resource reference copies as they are unchanged
+func (rs *ResourceStore) getLTMConfigCopy(dirtyPartitions map[string]struct{}) LTMConfig {
 	ltmConfig := make(LTMConfig)
 	for prtn, partitionConfig := range rs.ltmConfig {
 		ltmConfig[prtn] = &PartitionConfig{make(ResourceMap), partitionConfig.Priority}
+		_, dirty := dirtyPartitions[prtn]
 		for rsName, res := range partitionConfig.ResourceMap {
+			if !dirty {
+				ltmConfig[prtn].ResourceMap[rsName] = res
+				continue
+			}
 			copyRes := &ResourceConfig{}
 			copyRes.copyConfig(res)
 			ltmConfig[prtn].ResourceMap[rsName] = copyRes
@@ -1147,24 +1287,66 @@ func (rs *ResourceStore) getGTMConfigCopy() GTMConfig {
 
 func (rs *ResourceStore) updateCaches() {
 	// No need to deep copy as each RsCfg will be framed in a fresh memory block while creating live ltmConfig
-	rs.ltmConfigCache = rs.getSanitizedLTMConfigCopy()
+	rs.updateLTMConfigCache()
 	rs.gtmConfigCache = rs.getGTMConfigCopy()
-}
-
-func (rs *ResourceStore) isConfigUpdated() bool {
-	return !reflect.DeepEqual(rs.ltmConfig, rs.ltmConfigCache) ||
-		!reflect.DeepEqual(rs.gtmConfig, rs.gtmConfigCache)
+	// overrides are replaced as a whole on every update
+	rs.as3TenantOverridesCache = rs.as3TenantOverrides
+	rs.dirtyPartitions = make(map[string]struct{})
+}
+
+// getUpdatedPartitions returns the partitions whose LTM config, GTM config or
+// AS3 override differ from the caches. Only the dirty partitions of LTMConfig
+// are compared, as the others are unchanged
+func (rs *ResourceStore) getUpdatedPartitions() map[string]struct{} {
+	updated := make(map[string]struct{})
+	for prtn := range rs.dirtyPartitions {
+		partitionConfig, ok := rs.ltmConfig[prtn]
+		if !ok {
+			if _, cached := rs.ltmConfigCache[prtn]; cached {
+				updated[prtn] = struct{}{}
+			}
+			continue
+		}
+		// Empty partitions are never cached, they are posted to be flushed or removed
+		if !reflect.DeepEqual(partitionConfig, rs.ltmConfigCache[prtn]) {
+			updated[prtn] = struct{}{}
+		}
+	}
+	for prtn, gtmPartitionConfig := range rs.gtmConfig {
+		if !reflect.DeepEqual(gtmPartitionConfig, rs.gtmConfigCache[prtn]) {
+			updated[prtn] = struct{}{}
+		}
+	}
+	for prtn := range rs.gtmConfigCache {
+		if _, ok := rs.gtmConfig[prtn]; !ok {
+			updated[prtn] = struct{}{}
+		}
+	}
+	for tenant, override := range rs.as3TenantOverrides {
+		if cachedOverride, ok := rs.as3TenantOverridesCache[tenant]; !ok || override != cachedOverride {
+			updated[tenant] = struct{}{}
+		}
+	}
+	for tenant := range rs.as3TenantOverridesCache {
+		if _, ok := rs.as3TenantOverrides[tenant]; !ok {
+			updated[tenant] = struct{}{}
+		}
+	}
+	return updated
 }
 
 // Deletes respective VirtualServer resource configuration from  ResourceStore
 func (rs *ResourceStore) deleteVirtualServer(partition, rsName string) {
-	delete(rs.getPartitionResourceMap(partition), rsName)
+	if _, ok := rs.getPartitionResourceMap(partition)[rsName]; ok {
+		delete(rs.getPartitionResourceMapForUpdate(partition), rsName)
+	}
 }
 
 // Update the tenant priority in ltmConfigCache
 func (rs *ResourceStore) updatePartitionPriority(partition string, priority int) {
 	if _, ok := rs.ltmConfig[partition]; ok {
 		rs.ltmConfig[partition].Priority = priority
+		rs.markPartitionDirty(partition)
 	}
 }
 
@@ -1193,6 +1375,8 @@ func copyGTMConfig(cfg WideIP) (rc WideIP) {
 	rc.UID = cfg.UID
 	rc.LBMethod = cfg.LBMethod
 	rc.RecordType = cfg.RecordType
+	rc.Persistence = cfg.Persistence
+	rc.LastResortPool = cfg.LastResortPool
 	// Pools
 	rc.Pools = make([]GSLBPool, len(cfg.Pools))
 	copy(rc.Pools, cfg.Pools)
@@ -1202,10 +1386,45 @@ func copyGTMConfig(cfg WideIP) (rc WideIP) {
 		copy(rc.Pools[i].Members, cfg.Pools[i].Members)
 		rc.Pools[i].Monitors = make([]Monitor, len(cfg.Pools[i].Monitors))
 		copy(rc.Pools[i].Monitors, cfg.Pools[i].Monitors)
+		rc.Pools[i].Targets = make([]cisapiv2.DNSTarget, len(cfg.Pools[i].Targets))
+		copy(rc.Pools[i].Targets, cfg.Pools[i].Targets)
 	}
 	return rc
 }
 
+// gtmDomainName returns the AS3 name of the GSLB domain of a domain name,
+// a wildcard domain is not a valid AS3 name
+func gtmDomainName(domainName string) string {
+	if strings.HasPrefix(domainName, "*.") {
+		return "wildcard_" + strings.TrimPrefix(domainName, "*.")
+	}
+	return domainName
+}
+
+// domainMatchesHost checks whether a host is served by a domain, which can
+// be a wildcard domain
+func domainMatchesHost(domainName, host string) bool {
+	if host == domainName {
+		return true
+	}
+	if strings.HasPrefix(domainName, "*.") {
+		suffix := strings.TrimPrefix(domainName, "*")
+		return strings.HasSuffix(host, suffix) && len(host) > len(suffix)
+	}
+	return false
+}
+
+// isIPv6Virtual checks whether the virtual of a resource config listens on an
+// IPv6 address
+func isIPv6Virtual(rsCfg *ResourceConfig) bool {
+	if rsCfg.Virtual.VirtualAddress == nil {
+		return false
+	}
+	ip, _ := split_ip_with_route_domain(rsCfg.Virtual.VirtualAddress.BindAddr)
+	addr := net.ParseIP(ip)
+	return addr != nil && addr.To4() == nil
+}
+
 // Copies from an existing config into our new config
 func (rc *ResourceConfig) copyConfig(cfg *ResourceConfig) {
 	// MetaData
@@ -1560,7 +1779,7 @@ func (ctlr *Controller) getVirtualServer(partition, rsName string) *ResourceConf
 // Prepares resource config based on VirtualServer resource config
 func (ctlr *Controller) prepareRSConfigFromTransportServer(
 	rsCfg *ResourceConfig,
-	vs *cisapiv1.TransportServer,
+	vs *cisapiv2.TransportServer,
 ) error {
 
 	poolName := ctlr.framePoolName(
@@ -1569,12 +1788,7 @@ func (ctlr *Controller) prepareRSConfigFromTransportServer(
 		"",
 	)
 	//check for custom monitor
-	var monitorName string
-	if vs.Spec.Pool.Monitor.Name != "" && vs.Spec.Pool.Monitor.Reference == BIGIP {
-		monitorName = vs.Spec.Pool.Monitor.Name
-	} else {
-		monitorName = poolName + "-monitor"
-	}
+	monitorName := poolName + "-monitor"
 	targetPort := ctlr.fetchTargetPort(vs.Namespace, vs.Spec.Pool.Service, vs.Spec.Pool.ServicePort)
 	if (intstr.IntOrString{}) == targetPort {
 		targetPort = intstr.IntOrString{IntVal: vs.Spec.Pool.ServicePort}
@@ -1591,26 +1805,7 @@ func (ctlr *Controller) prepareRSConfigFromTransportServer(
 		ReselectTries:     vs.Spec.Pool.ReselectTries,
 		ServiceDownAction: vs.Spec.Pool.ServiceDownAction,
 	}
-	if vs.Spec.Pool.Monitor.Name != "" && vs.Spec.Pool.Monitor.Reference == BIGIP {
-		pool.MonitorNames = append(pool.MonitorNames, MonitorName{Name: monitorName, Reference: vs.Spec.Pool.Monitor.Reference})
-	} else if vs.Spec.Pool.Monitor.Type != "" {
-		if vs.Spec.Pool.Name == "" {
-			monitorName = formatMonitorName(vs.ObjectMeta.Namespace, vs.Spec.Pool.Service, vs.Spec.Pool.Monitor.Type, vs.Spec.Pool.ServicePort, "", "")
-		}
-		pool.MonitorNames = append(pool.MonitorNames, MonitorName{Name: JoinBigipPath(rsCfg.Virtual.Partition, monitorName)})
-
-		monitor := Monitor{
-			Name:       monitorName,
-			Partition:  rsCfg.Virtual.Partition,
-			Type:       vs.Spec.Pool.Monitor.Type,
-			Interval:   vs.Spec.Pool.Monitor.Interval,
-			Send:       "",
-			Recv:       "",
-			Timeout:    vs.Spec.Pool.Monitor.Timeout,
-			TargetPort: vs.Spec.Pool.Monitor.TargetPort,
-		}
-		rsCfg.Monitors = append(rsCfg.Monitors, monitor)
-	} else if vs.Spec.Pool.Monitors != nil {
+	if vs.Spec.Pool.Monitors != nil {
 		pl := vs.Spec.Pool
 		for _, monitor := range pl.Monitors {
 			if monitor.Name != "" && monitor.Reference == BIGIP {
@@ -1640,6 +1835,13 @@ func (ctlr *Controller) prepareRSConfigFromTransportServer(
 				rsCfg.Monitors = append(rsCfg.Monitors, monitor)
 			}
 		}
+	} else if monitor := ctlr.getPodProbeMonitor(vs.ObjectMeta.Namespace, vs.Spec.Pool.Service, targetPort, rsCfg.Virtual.Partition); monitor != nil {
+		if vs.Spec.Pool.Name == "" {
+			monitorName = formatMonitorName(vs.ObjectMeta.Namespace, vs.Spec.Pool.Service, monitor.Type, vs.Spec.Pool.ServicePort, "", "")
+		}
+		monitor.Name = monitorName
+		pool.MonitorNames = append(pool.MonitorNames, MonitorName{Name: JoinBigipPath(rsCfg.Virtual.Partition, monitorName)})
+		rsCfg.Monitors = append(rsCfg.Monitors, *monitor)
 	}
 
 	rsCfg.Virtual.Mode = vs.Spec.Mode
@@ -1647,13 +1849,13 @@ func (ctlr *Controller) prepareRSConfigFromTransportServer(
 	rsCfg.Virtual.PoolName = pool.Name
 	rsCfg.Pools = append(rsCfg.Pools, pool)
 
-	if vs.Spec.ProfileL4 != "" {
-		rsCfg.Virtual.ProfileL4 = vs.Spec.ProfileL4
+	if vs.Spec.Profiles.ProfileL4 != "" {
+		rsCfg.Virtual.ProfileL4 = vs.Spec.Profiles.ProfileL4
 	}
 	// Replace SNAT set from policy CR to the one defined by user in the TS spec
 	if vs.Spec.SNAT == "" {
 		if rsCfg.Virtual.SNAT == "" {
-			rsCfg.Virtual.SNAT = DEFAULT_SNAT
+			rsCfg.Virtual.SNAT = ctlr.defaultSNAT()
 		}
 	} else {
 		rsCfg.Virtual.SNAT = vs.Spec.SNAT
@@ -1682,8 +1884,9 @@ func (ctlr *Controller) prepareRSConfigFromTransportServer(
 	if len(vs.Spec.AllowVLANs) > 0 {
 		rsCfg.Virtual.AllowVLANs = vs.Spec.AllowVLANs
 	}
-	if vs.Spec.PersistenceProfile != "" {
-		rsCfg.Virtual.PersistenceProfile = vs.Spec.PersistenceProfile
+	ctlr.setDeployConfigDefaults(rsCfg)
+	if vs.Spec.Profiles.PersistenceProfile != "" {
+		rsCfg.Virtual.PersistenceProfile = vs.Spec.Profiles.PersistenceProfile
 	}
 
 	// Attach user specified iRules
@@ -1743,12 +1946,56 @@ func (ctlr *Controller) prepareRSConfigFromLBService(
 	rsCfg.Virtual.Mode = "standard"
 	// Use default SNAT if not provided by user
 	if rsCfg.Virtual.SNAT == "" {
-		rsCfg.Virtual.SNAT = DEFAULT_SNAT
+		rsCfg.Virtual.SNAT = ctlr.defaultSNAT()
 	}
+	ctlr.setDeployConfigDefaults(rsCfg)
 
 	return nil
 }
 
+// defaultSNAT returns the SNAT of the virtuals not specifying one
+func (ctlr *Controller) defaultSNAT() string {
+	if ctlr.deployConfigSpec.SNAT != "" {
+		return ctlr.deployConfigSpec.SNAT
+	}
+	return DEFAULT_SNAT
+}
+
+// defaultHTTPPort returns the HTTP port of the virtuals not specifying one
+func (ctlr *Controller) defaultHTTPPort() int32 {
+	if ctlr.deployConfigSpec.HTTPPort != 0 {
+		return ctlr.deployConfigSpec.HTTPPort
+	}
+	return DEFAULT_HTTP_PORT
+}
+
+// defaultHTTPSPort returns the HTTPS port of the virtuals not specifying one
+func (ctlr *Controller) defaultHTTPSPort() int32 {
+	if ctlr.deployConfigSpec.HTTPSPort != 0 {
+		return ctlr.deployConfigSpec.HTTPSPort
+	}
+	return DEFAULT_HTTPS_PORT
+}
+
+// routeDomain returns the default route domain of the partitions
+func (ctlr *Controller) routeDomain() int {
+	if ctlr.deployConfigSpec.DefaultRouteDomain != 0 {
+		return int(ctlr.deployConfigSpec.DefaultRouteDomain)
+	}
+	return ctlr.defaultRouteDomain
+}
+
+// setDeployConfigDefaults sets the allowed VLANs and log profiles of the DeployConfig
+// when neither the resource nor its policy has set them
+func (ctlr *Controller) setDeployConfigDefaults(rsCfg *ResourceConfig) {
+	if len(rsCfg.Virtual.AllowVLANs) == 0 && len(ctlr.deployConfigSpec.AllowVlans) > 0 {
+		rsCfg.Virtual.AllowVLANs = append([]string{}, ctlr.deployConfigSpec.AllowVlans...)
+	}
+	if len(rsCfg.Virtual.LogProfiles) == 0 && len(ctlr.deployConfigSpec.LogProfiles) > 0 {
+		rsCfg.Virtual.LogProfiles = append([]string{}, ctlr.deployConfigSpec.LogProfiles...)
+	}
+}
+
 // Returns Partition and resourceName
 func getPartitionAndName(objectName string) (string, string) {
 	allParts := strings.Split(objectName, "/")
@@ -1760,7 +2007,7 @@ func getPartitionAndName(objectName string) (string, string) {
 
 func (ctlr *Controller) handleVSResourceConfigForPolicy(
 	rsCfg *ResourceConfig,
-	plc *cisapiv1.Policy,
+	plc *cisapiv2.Policy,
 ) error {
 	rsCfg.Virtual.WAF = plc.Spec.L7Policies.WAF
 	rsCfg.Virtual.Firewall = plc.Spec.L3Policies.FirewallPolicy
@@ -1815,13 +2062,60 @@ func (ctlr *Controller) handleVSResourceConfigForPolicy(
 	if plc.Spec.SNAT != "" {
 		rsCfg.Virtual.SNAT = plc.Spec.SNAT
 	}
+	rsCfg.Virtual.MaxConnections = plc.Spec.L3Policies.MaxConnections
+	rsCfg.Virtual.ConnectionRateLimit = plc.Spec.L3Policies.ConnectionRateLimit
+	rsCfg.handleRequestRateLimit(plc.Spec.RateLimit)
+	rsCfg.MetaData.autoExternalDNS = plc.Spec.AutoExternalDNS
 
 	return nil
 }
 
+// handleRequestRateLimit attaches an iRule enforcing the request rate limit of the policy
+func (rsCfg *ResourceConfig) handleRequestRateLimit(rateLimit cisapiv2.RateLimitSpec) {
+	if rateLimit.RequestsPerSecond <= 0 {
+		return
+	}
+	// The iRule inspects the HTTP requests, so it applies only to the HTTP virtuals
+	if rsCfg.MetaData.Protocol != HTTP && rsCfg.MetaData.Protocol != HTTPS {
+		log.Debugf("Skipping the rate limit of the non HTTP virtual %v", rsCfg.Virtual.Name)
+		return
+	}
+	rl := RequestRateLimit{
+		RequestsPerSecond: rateLimit.RequestsPerSecond,
+		Burst:             rateLimit.Burst,
+		ResponseCode:      rateLimit.ResponseCode,
+	}
+	if rateLimit.Key == "header" {
+		if rateLimit.Header == "" {
+			log.Warningf("Header not set for rate limit of virtual %v, using client IP", rsCfg.Virtual.Name)
+		} else if !httpTokenRegex.MatchString(rateLimit.Header) {
+			log.Warningf("Invalid header %q for rate limit of virtual %v, using client IP", rateLimit.Header, rsCfg.Virtual.Name)
+		} else {
+			rl.Header = rateLimit.Header
+		}
+	}
+	if rl.ResponseCode == 0 {
+		rl.ResponseCode = DefaultRateLimitResponseCode
+	}
+	rsCfg.Virtual.RequestRateLimit = rl
+	if rsCfg.IRulesMap == nil {
+		rsCfg.IRulesMap = make(IRulesMap)
+	}
+	ruleName := getRSCfgResName(rsCfg.Virtual.Name, RateLimitIRuleName)
+	ruleRef := JoinBigipPath(rsCfg.Virtual.Partition, ruleName)
+	rsCfg.addIRule(ruleName, rsCfg.Virtual.Partition, requestRateLimitIRule(ruleName, rl))
+	for _, irule := range rsCfg.Virtual.IRules {
+		if irule == ruleRef {
+			return
+		}
+	}
+	// Rate limit is evaluated ahead of the user defined iRules
+	rsCfg.Virtual.IRules = append([]string{ruleRef}, rsCfg.Virtual.IRules...)
+}
+
 func (ctlr *Controller) handleTSResourceConfigForPolicy(
 	rsCfg *ResourceConfig,
-	plc *cisapiv1.Policy,
+	plc *cisapiv2.Policy,
 ) error {
 	rsCfg.Virtual.WAF = plc.Spec.L7Policies.WAF
 	rsCfg.Virtual.Firewall = plc.Spec.L3Policies.FirewallPolicy
@@ -1856,12 +2150,14 @@ func (ctlr *Controller) handleTSResourceConfigForPolicy(
 			rsCfg.Virtual.IRules = append(rsCfg.Virtual.IRules, iRule)
 		}
 	}
+	rsCfg.Virtual.MaxConnections = plc.Spec.L3Policies.MaxConnections
+	rsCfg.Virtual.ConnectionRateLimit = plc.Spec.L3Policies.ConnectionRateLimit
 	// set snat as specified by user or else use auto as default
 	snat := plc.Spec.SNAT
 	if snat != "" {
 		rsCfg.Virtual.SNAT = snat
 	} else {
-		rsCfg.Virtual.SNAT = DEFAULT_SNAT
+		rsCfg.Virtual.SNAT = ctlr.defaultSNAT()
 	}
 	return nil
 }
@@ -2003,7 +2299,7 @@ func (ctlr *Controller) handleRouteTLS(
 		}
 	}
 
-	if rsCfg.Virtual.VirtualAddress.Port == DEFAULT_HTTPS_PORT {
+	if rsCfg.Virtual.VirtualAddress.Port == ctlr.defaultHTTPSPort() {
 		ctlr.updateDataGroupForABRoute(route,
 			getRSCfgResName(rsCfg.Virtual.Name, AbDeploymentDgName),
 			rsCfg.Virtual.Partition,
@@ -2023,7 +2319,7 @@ func (ctlr *Controller) handleRouteTLS(
 		Route,
 		tlsReferenceType,
 		route.Spec.Host,
-		DEFAULT_HTTPS_PORT,
+		ctlr.defaultHTTPSPort(),
 		vServerAddr,
 		string(route.Spec.TLS.Termination),
 		strings.ToLower(string(route.Spec.TLS.InsecureEdgeTerminationPolicy)),
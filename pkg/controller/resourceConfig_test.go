@@ -3,8 +3,9 @@ package controller
 import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sort"
+	"testing"
 
-	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v1"
+	cisapiv2 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v2"
 	crdfake "github.com/F5Networks/k8s-bigip-ctlr/config/client/clientset/versioned/fake"
 	"github.com/F5Networks/k8s-bigip-ctlr/pkg/test"
 	. "github.com/onsi/ginkgo"
@@ -17,7 +18,7 @@ var _ = Describe("Resource Config Tests", func() {
 	namespace := "default"
 	Describe("Virtual Ports", func() {
 		var mockCtlr *mockController
-		var vs *cisapiv1.VirtualServer
+		var vs *cisapiv2.VirtualServer
 
 		BeforeEach(func() {
 			mockCtlr = newMockController()
@@ -25,7 +26,7 @@ var _ = Describe("Resource Config Tests", func() {
 			vs = test.NewVirtualServer(
 				"SampleVS",
 				namespace,
-				cisapiv1.VirtualServerSpec{
+				cisapiv2.VirtualServerSpec{
 					Host: "test.com",
 				},
 			)
@@ -163,8 +164,8 @@ var _ = Describe("Resource Config Tests", func() {
 		//	tls := test.NewTLSProfile(
 		//		"SampleTLS",
 		//		namespace,
-		//		cisapiv1.TLSProfileSpec{
-		//			TLS: cisapiv1.TLS{
+		//		cisapiv2.TLSProfileSpec{
+		//			TLS: cisapiv2.TLS{
 		//				Termination: TLSEdge,
 		//				ClientSSL:   "clientssl",
 		//			},
@@ -176,8 +177,8 @@ var _ = Describe("Resource Config Tests", func() {
 		//	tls1 := test.NewTLSProfile(
 		//		"SampleTLS",
 		//		namespace,
-		//		cisapiv1.TLSProfileSpec{
-		//			TLS: cisapiv1.TLS{
+		//		cisapiv2.TLSProfileSpec{
+		//			TLS: cisapiv2.TLS{
 		//				Termination: TLSReencrypt,
 		//				ClientSSL:   "clientssl",
 		//				ServerSSL:   "serverssl",
@@ -224,29 +225,29 @@ var _ = Describe("Resource Config Tests", func() {
 			vs := test.NewVirtualServer(
 				"SampleVS",
 				namespace,
-				cisapiv1.VirtualServerSpec{
+				cisapiv2.VirtualServerSpec{
 					Host: "test.com",
-					Pools: []cisapiv1.Pool{
+					Pools: []cisapiv2.Pool{
 						{
 							Path:    "/foo",
 							Service: "svc1",
-							Monitor: cisapiv1.Monitor{
+							Monitors: []cisapiv2.Monitor{{
 								Type:     "http",
 								Send:     "GET /health",
 								Interval: 15,
 								Timeout:  10,
-							},
+							}},
 							Rewrite: "/bar",
 						},
 						{
 							Path:    "/",
 							Service: "svc2",
-							Monitor: cisapiv1.Monitor{
+							Monitors: []cisapiv2.Monitor{{
 								Type:     "http",
 								Send:     "GET /health",
 								Interval: 15,
 								Timeout:  10,
-							},
+							}},
 						},
 					},
 					RewriteAppRoot: "/home",
@@ -268,13 +269,13 @@ var _ = Describe("Resource Config Tests", func() {
 			vs := test.NewVirtualServer(
 				"SampleVS",
 				namespace,
-				cisapiv1.VirtualServerSpec{
+				cisapiv2.VirtualServerSpec{
 					Host: "test.com",
-					Pools: []cisapiv1.Pool{
+					Pools: []cisapiv2.Pool{
 						{
 							Path:    "/foo",
 							Service: "svc1",
-							Monitors: []cisapiv1.Monitor{
+							Monitors: []cisapiv2.Monitor{
 								{
 									Type:       "http",
 									Send:       "GET /health",
@@ -299,12 +300,12 @@ var _ = Describe("Resource Config Tests", func() {
 						{
 							Path:    "/",
 							Service: "svc2",
-							Monitor: cisapiv1.Monitor{
+							Monitors: []cisapiv2.Monitor{{
 								Type:     "http",
 								Send:     "GET /health",
 								Interval: 15,
 								Timeout:  10,
-							},
+							}},
 						},
 					},
 					RewriteAppRoot: "/home",
@@ -321,15 +322,15 @@ var _ = Describe("Resource Config Tests", func() {
 			ts := test.NewTransportServer(
 				"SampleTS",
 				namespace,
-				cisapiv1.TransportServerSpec{
-					Pool: cisapiv1.Pool{
+				cisapiv2.TransportServerSpec{
+					Pool: cisapiv2.Pool{
 						Service:     "svc1",
 						ServicePort: 80,
-						Monitor: cisapiv1.Monitor{
+						Monitors: []cisapiv2.Monitor{{
 							Type:     "tcp",
 							Timeout:  10,
 							Interval: 10,
-						},
+						}},
 					},
 				},
 			)
@@ -341,11 +342,11 @@ var _ = Describe("Resource Config Tests", func() {
 			ts := test.NewTransportServer(
 				"SampleTS",
 				namespace,
-				cisapiv1.TransportServerSpec{
-					Pool: cisapiv1.Pool{
+				cisapiv2.TransportServerSpec{
+					Pool: cisapiv2.Pool{
 						Service:     "svc1",
 						ServicePort: 80,
-						Monitors: []cisapiv1.Monitor{
+						Monitors: []cisapiv2.Monitor{
 							{
 								Type:       "tcp",
 								Timeout:    10,
@@ -453,6 +454,126 @@ var _ = Describe("Resource Config Tests", func() {
 		})
 	})
 
+	Describe("Pod probe health monitors", func() {
+		var rsCfg *ResourceConfig
+		var mockCtlr *mockController
+		var pod *v1.Pod
+
+		BeforeEach(func() {
+			mockCtlr = newMockController()
+			mockCtlr.mode = CustomResourceMode
+			mockCtlr.podProbeHealthMonitor = true
+			mockCtlr.kubeCRClient = crdfake.NewSimpleClientset()
+			mockCtlr.kubeClient = k8sfake.NewSimpleClientset()
+			mockCtlr.crInformers = make(map[string]*CRInformer)
+			mockCtlr.comInformers = make(map[string]*CommonInformer)
+			mockCtlr.nativeResourceSelector, _ = createLabelSelector(DefaultCustomResourceLabel)
+			_ = mockCtlr.addNamespacedInformers(namespace, false)
+
+			selector := map[string]string{"app": "web"}
+			mockCtlr.addService(test.NewServicewithselectors("svc1", "1", namespace, selector,
+				v1.ServiceTypeClusterIP, []v1.ServicePort{{Port: 80, TargetPort: intstr.FromInt(8080)}}))
+			pod = test.NewPod("web-1", namespace, 8080, selector)
+			pod.Spec.Containers[0].ReadinessProbe = &v1.Probe{
+				Handler: v1.Handler{
+					HTTPGet: &v1.HTTPGetAction{
+						Path:   "/healthz",
+						Port:   intstr.FromInt(8081),
+						Scheme: v1.URISchemeHTTPS,
+					},
+				},
+				PeriodSeconds:    5,
+				TimeoutSeconds:   2,
+				FailureThreshold: 3,
+			}
+			comInf, _ := mockCtlr.getNamespacedCommonInformer(namespace)
+			Expect(comInf.podInformer).NotTo(BeNil(), "Pod informer not enabled")
+			_ = comInf.podInformer.GetStore().Add(pod)
+
+			rsCfg = &ResourceConfig{}
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.Virtual.SetVirtualAddress("1.2.3.4", 80)
+		})
+
+		It("Derives an http monitor from the readiness probe of a VirtualServer pool", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv2.VirtualServerSpec{
+					Host: "test.com",
+					Pools: []cisapiv2.Pool{
+						{Path: "/", Service: "svc1", ServicePort: 80},
+					},
+				},
+			)
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false)
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(len(rsCfg.Monitors)).To(Equal(1))
+			monitor := rsCfg.Monitors[0]
+			Expect(monitor.Type).To(Equal("https"))
+			Expect(monitor.Path).To(Equal("/healthz"))
+			Expect(monitor.Send).To(Equal("GET /healthz HTTP/1.0\r\n\r\n"))
+			Expect(monitor.Interval).To(Equal(5))
+			Expect(monitor.Timeout).To(Equal(17))
+			Expect(monitor.TargetPort).To(Equal(int32(8081)))
+			Expect(rsCfg.Pools[0].MonitorNames).To(Equal([]MonitorName{{Name: "/test/" + monitor.Name}}))
+		})
+
+		It("Falls back to a tcp monitor for exec probes", func() {
+			pod.Spec.Containers[0].ReadinessProbe = &v1.Probe{
+				Handler: v1.Handler{
+					Exec: &v1.ExecAction{Command: []string{"cat", "/tmp/healthy"}},
+				},
+				PeriodSeconds: 10,
+			}
+			comInf, _ := mockCtlr.getNamespacedCommonInformer(namespace)
+			_ = comInf.podInformer.GetStore().Update(pod)
+
+			ts := test.NewTransportServer(
+				"SampleTS",
+				namespace,
+				cisapiv2.TransportServerSpec{
+					Pool: cisapiv2.Pool{
+						Service:     "svc1",
+						ServicePort: 80,
+					},
+				},
+			)
+			err := mockCtlr.prepareRSConfigFromTransportServer(rsCfg, ts)
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from TransportServer")
+			Expect(len(rsCfg.Monitors)).To(Equal(1))
+			Expect(rsCfg.Monitors[0].Type).To(Equal("tcp"))
+			Expect(rsCfg.Monitors[0].Send).To(BeEmpty())
+			Expect(rsCfg.Monitors[0].TargetPort).To(BeZero())
+		})
+
+		It("Prefers the monitor defined in the custom resource", func() {
+			ts := test.NewTransportServer(
+				"SampleTS",
+				namespace,
+				cisapiv2.TransportServerSpec{
+					Pool: cisapiv2.Pool{
+						Service:     "svc1",
+						ServicePort: 80,
+						Monitors: []cisapiv2.Monitor{{
+							Type:     "tcp",
+							Timeout:  10,
+							Interval: 10,
+						}},
+					},
+				},
+			)
+			err := mockCtlr.prepareRSConfigFromTransportServer(rsCfg, ts)
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from TransportServer")
+			Expect(len(rsCfg.Monitors)).To(Equal(1))
+			Expect(rsCfg.Monitors[0].Interval).To(Equal(10))
+		})
+	})
+
 	Describe("Profile Reference", func() {
 
 		It("Frame Profile Reference", func() {
@@ -565,12 +686,12 @@ var _ = Describe("Resource Config Tests", func() {
 		tlsRenc := test.NewTLSProfile(
 			"sampleTLS",
 			namespace,
-			cisapiv1.TLSProfileSpec{
+			cisapiv2.TLSProfileSpec{
 				//Hosts: "test.com",
-				TLS: cisapiv1.TLS{
+				TLS: cisapiv2.TLS{
 					Termination: TLSReencrypt,
-					ClientSSL:   "clientssl",
-					ServerSSL:   "serverssl",
+					ClientSSLs:  []string{"clientssl"},
+					ServerSSLs:  []string{"serverssl"},
 				},
 			},
 		)
@@ -578,11 +699,11 @@ var _ = Describe("Resource Config Tests", func() {
 		tlsEdge := test.NewTLSProfile(
 			"sampleTLS",
 			namespace,
-			cisapiv1.TLSProfileSpec{
+			cisapiv2.TLSProfileSpec{
 				//Hosts: "test.com",
-				TLS: cisapiv1.TLS{
+				TLS: cisapiv2.TLS{
 					Termination: TLSEdge,
-					ClientSSL:   "clientssl",
+					ClientSSLs:  []string{"clientssl"},
 				},
 			},
 		)
@@ -590,9 +711,9 @@ var _ = Describe("Resource Config Tests", func() {
 		tlsPst := test.NewTLSProfile(
 			"sampleTLS",
 			namespace,
-			cisapiv1.TLSProfileSpec{
+			cisapiv2.TLSProfileSpec{
 				//Hosts: "test.com",
-				TLS: cisapiv1.TLS{
+				TLS: cisapiv2.TLS{
 					Termination: TLSPassthrough,
 				},
 			},
@@ -630,9 +751,9 @@ var _ = Describe("Resource Config Tests", func() {
 		tlsRenc := test.NewTLSProfile(
 			"sampleTLS",
 			namespace,
-			cisapiv1.TLSProfileSpec{
+			cisapiv2.TLSProfileSpec{
 				//Hosts: "test.com",
-				TLS: cisapiv1.TLS{
+				TLS: cisapiv2.TLS{
 					Termination: TLSReencrypt,
 					ClientSSLs:  []string{"clientssl", "foo-clientssl"},
 					ServerSSLs:  []string{"serverssl", "foo-serverssl"},
@@ -643,12 +764,11 @@ var _ = Describe("Resource Config Tests", func() {
 		tlsRencComb := test.NewTLSProfile(
 			"sampleTLS",
 			namespace,
-			cisapiv1.TLSProfileSpec{
+			cisapiv2.TLSProfileSpec{
 				//Hosts: "test.com",
-				TLS: cisapiv1.TLS{
+				TLS: cisapiv2.TLS{
 					Termination: TLSReencrypt,
 					ClientSSLs:  []string{"clientssl", "foo-clientssl"},
-					ServerSSL:   "serverssl",
 				},
 			},
 		)
@@ -656,9 +776,9 @@ var _ = Describe("Resource Config Tests", func() {
 		tlsEdge := test.NewTLSProfile(
 			"sampleTLS",
 			namespace,
-			cisapiv1.TLSProfileSpec{
+			cisapiv2.TLSProfileSpec{
 				//Hosts: "test.com",
-				TLS: cisapiv1.TLS{
+				TLS: cisapiv2.TLS{
 					Termination: TLSEdge,
 					ClientSSLs:  []string{"clientssl", "foo-clientssl"},
 				},
@@ -668,9 +788,9 @@ var _ = Describe("Resource Config Tests", func() {
 		tlsPst := test.NewTLSProfile(
 			"sampleTLS",
 			namespace,
-			cisapiv1.TLSProfileSpec{
+			cisapiv2.TLSProfileSpec{
 				//Hosts: "test.com",
-				TLS: cisapiv1.TLS{
+				TLS: cisapiv2.TLS{
 					Termination: TLSPassthrough,
 				},
 			},
@@ -715,6 +835,9 @@ var _ = Describe("Resource Config Tests", func() {
 
 		It("Get Partition Resource Map", func() {
 			rsMap := rs.getPartitionResourceMap("default")
+			Expect(rsMap).To(BeNil())
+			Expect(rs.ltmConfig).ToNot(HaveKey("default"))
+			rsMap = rs.getPartitionResourceMapForUpdate("default")
 			Expect(len(rsMap)).To(Equal(0))
 			rsMap["default"] = &ResourceConfig{}
 			rsMap = rs.getPartitionResourceMap("default")
@@ -758,16 +881,59 @@ var _ = Describe("Resource Config Tests", func() {
 				},
 			}
 
-			ltmCfg := rs.getLTMConfigDeepCopy()
+			ltmCfg := rs.getLTMConfigCopy(map[string]struct{}{"default": {}})
 			Expect(len(ltmCfg)).To(Equal(1), "Wrong number of Partitions")
 			Expect(len(ltmCfg["default"].ResourceMap)).To(Equal(2), "Wrong number of ResourceConfigs")
+			Expect(ltmCfg["default"].ResourceMap["virtualServer1"]).ToNot(BeIdenticalTo(
+				rs.ltmConfig["default"].ResourceMap["virtualServer1"]), "Dirty partition should be deep copied")
+		})
+
+		It("Tracks the updated partitions", func() {
+			rsMap := rs.getPartitionResourceMapForUpdate("default")
+			rsMap["virtualServer1"] = &ResourceConfig{Virtual: Virtual{Name: "VirtualServer1"}}
+			rs.getPartitionResourceMapForUpdate("test")["virtualServer2"] = &ResourceConfig{
+				Virtual: Virtual{Name: "VirtualServer2"},
+			}
+			Expect(rs.getUpdatedPartitions()).To(Equal(map[string]struct{}{"default": {}, "test": {}}))
+			rs.updateCaches()
+			Expect(rs.getUpdatedPartitions()).To(BeEmpty())
+
+			// Resources are replaced, unchanged partitions are not compared again
+			Expect(rs.setResourceConfig("default", "virtualServer1",
+				&ResourceConfig{Virtual: Virtual{Name: "VirtualServer1", Enabled: true}})).To(BeNil())
+			Expect(rs.dirtyPartitions).To(Equal(map[string]struct{}{"default": {}}))
+			Expect(rs.getUpdatedPartitions()).To(Equal(map[string]struct{}{"default": {}}))
+			ltmCfg := rs.getLTMConfigCopy(rs.getUpdatedPartitions())
+			Expect(ltmCfg["test"].ResourceMap["virtualServer2"]).To(BeIdenticalTo(
+				rs.ltmConfig["test"].ResourceMap["virtualServer2"]), "Clean partition should be reference copied")
+			rs.updateCaches()
+
+			// Reading a partition does not mark it dirty
+			rs.getPartitionResourceMap("test")
+			rs.deleteVirtualServer("test", "virtualServer3")
+			Expect(rs.dirtyPartitions).To(BeEmpty())
+
+			// A partition marked dirty without changes is not posted
+			rs.getPartitionResourceMapForUpdate("test")
+			Expect(rs.getUpdatedPartitions()).To(BeEmpty())
+
+			// Deleted partition is posted once and removed from the caches
+			rs.deleteVirtualServer("test", "virtualServer2")
+			Expect(rs.getUpdatedPartitions()).To(Equal(map[string]struct{}{"test": {}}))
+			rs.updateCaches()
+			Expect(rs.ltmConfig).ToNot(HaveKey("test"))
+			Expect(rs.ltmConfigCache).ToNot(HaveKey("test"))
+			Expect(rs.getUpdatedPartitions()).To(BeEmpty())
+
+			rs.updatePartitionPriority("default", 1)
+			Expect(rs.getUpdatedPartitions()).To(Equal(map[string]struct{}{"default": {}}))
 		})
 	})
 
 	Describe("Handle Virtual Server TLS", func() {
 		var mockCtlr *mockController
-		var vs *cisapiv1.VirtualServer
-		var tlsProf *cisapiv1.TLSProfile
+		var vs *cisapiv2.VirtualServer
+		var tlsProf *cisapiv2.TLSProfile
 		var rsCfg, inSecRsCfg *ResourceConfig
 		var ip string
 
@@ -784,10 +950,10 @@ var _ = Describe("Resource Config Tests", func() {
 			vs = test.NewVirtualServer(
 				"SampleVS",
 				namespace,
-				cisapiv1.VirtualServerSpec{
+				cisapiv2.VirtualServerSpec{
 					Host: "test.com",
-					Pools: []cisapiv1.Pool{
-						cisapiv1.Pool{
+					Pools: []cisapiv2.Pool{
+						cisapiv2.Pool{
 							Path:    "/path",
 							Service: "svc1",
 						},
@@ -817,8 +983,8 @@ var _ = Describe("Resource Config Tests", func() {
 			inSecRsCfg.IntDgMap = make(InternalDataGroupMap)
 			inSecRsCfg.IRulesMap = make(IRulesMap)
 
-			tlsProf = test.NewTLSProfile("SampleTLS", namespace, cisapiv1.TLSProfileSpec{
-				TLS: cisapiv1.TLS{},
+			tlsProf = test.NewTLSProfile("SampleTLS", namespace, cisapiv2.TLSProfileSpec{
+				TLS: cisapiv2.TLS{},
 			})
 		})
 
@@ -848,7 +1014,7 @@ var _ = Describe("Resource Config Tests", func() {
 			vs.Spec.TLSProfileName = "SampleTLS"
 			tlsProf.Spec.TLS.Termination = TLSEdge
 			tlsProf.Spec.TLS.Reference = BIGIP
-			tlsProf.Spec.TLS.ClientSSL = "/Common/clientssl"
+			tlsProf.Spec.TLS.ClientSSLs = []string{"/Common/clientssl"}
 
 			profRef := ProfileRef{
 				Name:         "clientssl",
@@ -870,8 +1036,8 @@ var _ = Describe("Resource Config Tests", func() {
 			vs.Spec.TLSProfileName = "SampleTLS"
 			tlsProf.Spec.TLS.Termination = TLSReencrypt
 			tlsProf.Spec.TLS.Reference = BIGIP
-			tlsProf.Spec.TLS.ClientSSL = "/Common/clientssl"
-			tlsProf.Spec.TLS.ServerSSL = "/Common/serverssl"
+			tlsProf.Spec.TLS.ClientSSLs = []string{"/Common/clientssl"}
+			tlsProf.Spec.TLS.ServerSSLs = []string{"/Common/serverssl"}
 
 			clProfRef := ProfileRef{
 				Name:         "clientssl",
@@ -901,8 +1067,8 @@ var _ = Describe("Resource Config Tests", func() {
 			vs.Spec.HTTPTraffic = TLSAllowInsecure
 			tlsProf.Spec.TLS.Termination = TLSReencrypt
 			tlsProf.Spec.TLS.Reference = BIGIP
-			tlsProf.Spec.TLS.ClientSSL = "/Common/clientssl"
-			tlsProf.Spec.TLS.ServerSSL = "/Common/serverssl"
+			tlsProf.Spec.TLS.ClientSSLs = []string{"/Common/clientssl"}
+			tlsProf.Spec.TLS.ServerSSLs = []string{"/Common/serverssl"}
 
 			ok := mockCtlr.handleVirtualServerTLS(rsCfg, vs, tlsProf, ip)
 			Expect(ok).To(BeFalse(), "Failed to Validate TLS Termination: Reencrypt with AllowInsecure")
@@ -913,8 +1079,8 @@ var _ = Describe("Resource Config Tests", func() {
 			vs.Spec.HTTPTraffic = TLSRedirectInsecure
 			tlsProf.Spec.TLS.Termination = TLSReencrypt
 			tlsProf.Spec.TLS.Reference = BIGIP
-			tlsProf.Spec.TLS.ClientSSL = "/Common/clientssl"
-			tlsProf.Spec.TLS.ServerSSL = "/Common/serverssl"
+			tlsProf.Spec.TLS.ClientSSLs = []string{"/Common/clientssl"}
+			tlsProf.Spec.TLS.ServerSSLs = []string{"/Common/serverssl"}
 
 			ok := mockCtlr.handleVirtualServerTLS(inSecRsCfg, vs, tlsProf, ip)
 			Expect(ok).To(BeTrue(), "Failed to Handle insecure virtual with Redirect config")
@@ -928,8 +1094,8 @@ var _ = Describe("Resource Config Tests", func() {
 			vs.Spec.HTTPTraffic = TLSRedirectInsecure
 			tlsProf.Spec.TLS.Termination = TLSReencrypt
 			tlsProf.Spec.TLS.Reference = BIGIP
-			tlsProf.Spec.TLS.ClientSSL = "/Common/clientssl"
-			tlsProf.Spec.TLS.ServerSSL = "/Common/serverssl"
+			tlsProf.Spec.TLS.ClientSSLs = []string{"/Common/clientssl"}
+			tlsProf.Spec.TLS.ServerSSLs = []string{"/Common/serverssl"}
 
 			ok := mockCtlr.handleVirtualServerTLS(inSecRsCfg, vs, tlsProf, ip)
 			Expect(ok).To(BeTrue(), "Failed to Handle insecure virtual with Redirect config")
@@ -942,7 +1108,7 @@ var _ = Describe("Resource Config Tests", func() {
 			vs.Spec.HTTPTraffic = TLSAllowInsecure
 			tlsProf.Spec.TLS.Termination = TLSEdge
 			tlsProf.Spec.TLS.Reference = BIGIP
-			tlsProf.Spec.TLS.ClientSSL = "/Common/clientssl"
+			tlsProf.Spec.TLS.ClientSSLs = []string{"/Common/clientssl"}
 
 			ok := mockCtlr.handleVirtualServerTLS(inSecRsCfg, vs, tlsProf, ip)
 			Expect(ok).To(BeTrue(), "Failed to Process TLS Termination: Edge")
@@ -955,7 +1121,7 @@ var _ = Describe("Resource Config Tests", func() {
 			vs.Spec.HTTPTraffic = TLSNoInsecure
 			tlsProf.Spec.TLS.Termination = TLSEdge
 			tlsProf.Spec.TLS.Reference = BIGIP
-			tlsProf.Spec.TLS.ClientSSL = "/Common/clientssl"
+			tlsProf.Spec.TLS.ClientSSLs = []string{"/Common/clientssl"}
 
 			ok := mockCtlr.handleVirtualServerTLS(inSecRsCfg, vs, tlsProf, ip)
 			Expect(ok).To(BeTrue(), "Failed to Process TLS Termination: Edge")
@@ -967,8 +1133,8 @@ var _ = Describe("Resource Config Tests", func() {
 			vs.Spec.TLSProfileName = "SampleTLS"
 			tlsProf.Spec.TLS.Termination = TLSReencrypt
 			tlsProf.Spec.TLS.Reference = Secret
-			tlsProf.Spec.TLS.ClientSSL = "clientsecret"
-			tlsProf.Spec.TLS.ServerSSL = "serversecret"
+			tlsProf.Spec.TLS.ClientSSLs = []string{"clientsecret"}
+			tlsProf.Spec.TLS.ServerSSLs = []string{"serversecret"}
 
 			rsCfg.customProfiles = make(map[SecretKey]CustomProfile)
 
@@ -992,7 +1158,7 @@ var _ = Describe("Resource Config Tests", func() {
 	Describe("SNAT in policy CRD", func() {
 		var rsCfg *ResourceConfig
 		var mockCtlr *mockController
-		var plc *cisapiv1.Policy
+		var plc *cisapiv2.Policy
 
 		BeforeEach(func() {
 			mockCtlr = newMockController()
@@ -1004,7 +1170,7 @@ var _ = Describe("Resource Config Tests", func() {
 				80,
 			)
 
-			plc = test.NewPolicy("plc1", namespace, cisapiv1.PolicySpec{})
+			plc = test.NewPolicy("plc1", namespace, cisapiv2.PolicySpec{})
 		})
 
 		It("Verifies SNAT whether is set properly for VirtualServer", func() {
@@ -1027,7 +1193,7 @@ var _ = Describe("Resource Config Tests", func() {
 			vs := test.NewVirtualServer(
 				"SamplevS",
 				namespace,
-				cisapiv1.VirtualServerSpec{},
+				cisapiv2.VirtualServerSpec{},
 			)
 			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false)
 			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
@@ -1074,7 +1240,7 @@ var _ = Describe("Resource Config Tests", func() {
 			ts := test.NewTransportServer(
 				"SampleTS",
 				namespace,
-				cisapiv1.TransportServerSpec{},
+				cisapiv2.TransportServerSpec{},
 			)
 			err = mockCtlr.prepareRSConfigFromTransportServer(rsCfg, ts)
 			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from TransportServer")
@@ -1099,4 +1265,285 @@ var _ = Describe("Resource Config Tests", func() {
 				"to automap")
 		})
 	})
+
+	Describe("Rate limit in policy CRD", func() {
+		var rsCfg *ResourceConfig
+		var mockCtlr *mockController
+		var plc *cisapiv2.Policy
+
+		BeforeEach(func() {
+			mockCtlr = newMockController()
+			mockCtlr.mode = CustomResourceMode
+
+			rsCfg = &ResourceConfig{}
+			rsCfg.Virtual.Name = "crd_vs_1.2.3.4_80"
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IRulesMap = make(IRulesMap)
+			rsCfg.Virtual.SetVirtualAddress(
+				"1.2.3.4",
+				80,
+			)
+
+			plc = test.NewPolicy("plc1", namespace, cisapiv2.PolicySpec{
+				L3Policies: cisapiv2.L3PolicySpec{
+					MaxConnections:      1000,
+					ConnectionRateLimit: 50,
+				},
+			})
+		})
+
+		It("Sets connection limits for VirtualServer and TransportServer", func() {
+			err := mockCtlr.handleVSResourceConfigForPolicy(rsCfg, plc)
+			Expect(err).To(BeNil(), "Failed to handle VirtualServer for policy")
+			Expect(rsCfg.Virtual.MaxConnections).To(Equal(int32(1000)))
+			Expect(rsCfg.Virtual.ConnectionRateLimit).To(Equal(int32(50)))
+			Expect(rsCfg.Virtual.IRules).To(BeEmpty(), "Rate limit iRule should not be attached")
+
+			rsCfg = &ResourceConfig{}
+			err = mockCtlr.handleTSResourceConfigForPolicy(rsCfg, plc)
+			Expect(err).To(BeNil(), "Failed to handle TransportServer for policy")
+			Expect(rsCfg.Virtual.MaxConnections).To(Equal(int32(1000)))
+			Expect(rsCfg.Virtual.ConnectionRateLimit).To(Equal(int32(50)))
+		})
+
+		It("Attaches the request rate limit iRule ahead of the policy iRules", func() {
+			plc.Spec.IRules.InSecure = "/Common/custom_irule"
+			plc.Spec.IRules.Priority = "override"
+			plc.Spec.RateLimit = cisapiv2.RateLimitSpec{
+				RequestsPerSecond: 10,
+				Burst:             5,
+				Key:               "header",
+				Header:            "X-Api-Key",
+			}
+			rsCfg.MetaData.Protocol = "http"
+			err := mockCtlr.handleVSResourceConfigForPolicy(rsCfg, plc)
+			Expect(err).To(BeNil(), "Failed to handle VirtualServer for policy")
+
+			ruleName := getRSCfgResName(rsCfg.Virtual.Name, RateLimitIRuleName)
+			Expect(rsCfg.Virtual.IRules).To(Equal([]string{"/test/" + ruleName, "/Common/custom_irule"}))
+			Expect(rsCfg.Virtual.RequestRateLimit).To(Equal(RequestRateLimit{
+				RequestsPerSecond: 10,
+				Burst:             5,
+				Header:            "X-Api-Key",
+				ResponseCode:      DefaultRateLimitResponseCode,
+			}))
+			irule, ok := rsCfg.IRulesMap[NameRef{Name: ruleName, Partition: "test"}]
+			Expect(ok).To(BeTrue(), "Rate limit iRule not created")
+			Expect(irule.Code).To(ContainSubstring(`[HTTP::header value {X-Api-Key}]`))
+			Expect(irule.Code).To(ContainSubstring("if {$count > 15}"))
+			Expect(irule.Code).To(ContainSubstring("HTTP::respond 429"))
+		})
+
+		It("Skips the request rate limit of the non HTTP virtuals and invalid headers", func() {
+			plc.Spec.RateLimit = cisapiv2.RateLimitSpec{RequestsPerSecond: 10, Key: "header", Header: "X-Api-Key"}
+			rsCfg.MetaData.Protocol = "tcp"
+			Expect(mockCtlr.handleVSResourceConfigForPolicy(rsCfg, plc)).To(BeNil())
+			Expect(rsCfg.Virtual.IRules).To(BeEmpty())
+			Expect(rsCfg.IRulesMap).To(BeEmpty())
+
+			plc.Spec.RateLimit.Header = `X"] [exec ls] ["`
+			rsCfg.MetaData.Protocol = "https"
+			Expect(mockCtlr.handleVSResourceConfigForPolicy(rsCfg, plc)).To(BeNil())
+			Expect(rsCfg.Virtual.RequestRateLimit.Header).To(BeEmpty(), "Invalid header should not be used")
+		})
+	})
+
+	Describe("DeployConfig defaults", func() {
+		var mockCtlr *mockController
+		var dc *cisapiv2.DeployConfig
+
+		BeforeEach(func() {
+			mockCtlr = newMockController()
+			mockCtlr.mode = CustomResourceMode
+			mockCtlr.resources = NewResourceStore()
+			dc = test.NewDeployConfig("cis-deploy-config", cisapiv2.DeployConfigSpec{
+				TLS: cisapiv2.DefaultTLSSpec{
+					TLSVersion:  "1.3",
+					CipherGroup: "/Common/f5-secure",
+				},
+				SNAT:        "/Common/snatpool",
+				HTTPPort:    8080,
+				HTTPSPort:   8443,
+				AllowVlans:  []string{"/Common/external"},
+				LogProfiles: []string{"/Common/local-dos"},
+			})
+		})
+
+		It("Uses the built-in defaults without DeployConfig", func() {
+			Expect(mockCtlr.defaultSNAT()).To(Equal(DEFAULT_SNAT))
+			Expect(mockCtlr.defaultHTTPPort()).To(Equal(DEFAULT_HTTP_PORT))
+			Expect(mockCtlr.defaultHTTPSPort()).To(Equal(DEFAULT_HTTPS_PORT))
+		})
+
+		It("Applies the DeployConfig defaults to VirtualServer", func() {
+			Expect(mockCtlr.processDeployConfig(dc, false)).To(BeTrue(), "Defaults should be updated")
+			Expect(mockCtlr.processDeployConfig(dc, false)).To(BeFalse(), "Defaults should not change")
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv2.VirtualServerSpec{
+					Host:           "test.com",
+					TLSProfileName: "SampleTLS",
+				},
+			)
+			portStructs := mockCtlr.virtualPorts(vs)
+			sort.SliceStable(portStructs, func(i, j int) bool {
+				return portStructs[i].port > portStructs[j].port
+			})
+			Expect(portStructs).To(Equal([]portStruct{
+				{protocol: "https", port: 8443},
+				{protocol: "http", port: 8080},
+			}), "Invalid Ports")
+			Expect(mockCtlr.getEffectiveHTTPPort(vs)).To(Equal(int32(8080)))
+			vs.Spec.VirtualServerHTTPSPort = 443
+			Expect(mockCtlr.getEffectiveHTTPSPort(vs)).To(Equal(int32(443)))
+
+			rsCfg := &ResourceConfig{}
+			rsCfg.Virtual.SetVirtualAddress("1.2.3.4", 8080)
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false)
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.SNAT).To(Equal("/Common/snatpool"))
+			Expect(rsCfg.Virtual.AllowVLANs).To(Equal([]string{"/Common/external"}))
+			Expect(rsCfg.Virtual.LogProfiles).To(Equal([]string{"/Common/local-dos"}))
+
+			Expect(mockCtlr.resources.baseRouteConfig.TLSCipher).To(Equal(TLSCipher{
+				TLSVersion:  "1.3",
+				Ciphers:     "DEFAULT",
+				CipherGroup: "/Common/f5-secure",
+			}), "Invalid TLS baseline")
+		})
+
+		It("Keeps the values set by the resource and its policy", func() {
+			mockCtlr.processDeployConfig(dc, false)
+			plc := test.NewPolicy("plc1", namespace, cisapiv2.PolicySpec{
+				SNAT: "none",
+				L3Policies: cisapiv2.L3PolicySpec{
+					AllowVlans: []string{"/Common/internal"},
+				},
+			})
+			rsCfg := &ResourceConfig{}
+			rsCfg.Virtual.SetVirtualAddress("1.2.3.4", 1600)
+			err := mockCtlr.handleTSResourceConfigForPolicy(rsCfg, plc)
+			Expect(err).To(BeNil(), "Failed to handle TransportServer for policy")
+			ts := test.NewTransportServer(
+				"SampleTS",
+				namespace,
+				cisapiv2.TransportServerSpec{
+					VirtualServerPort: 1600,
+					Pool: cisapiv2.Pool{
+						Service:     "svc1",
+						ServicePort: 80,
+					},
+				},
+			)
+			err = mockCtlr.prepareRSConfigFromTransportServer(rsCfg, ts)
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from TransportServer")
+			Expect(rsCfg.Virtual.SNAT).To(Equal("none"))
+			Expect(rsCfg.Virtual.AllowVLANs).To(Equal([]string{"/Common/internal"}))
+			Expect(rsCfg.Virtual.LogProfiles).To(Equal([]string{"/Common/local-dos"}))
+		})
+
+		It("Falls back to the built-in defaults when DeployConfig is deleted", func() {
+			mockCtlr.processDeployConfig(dc, false)
+			Expect(mockCtlr.processDeployConfig(dc, true)).To(BeTrue(), "Defaults should be updated")
+			Expect(mockCtlr.defaultSNAT()).To(Equal(DEFAULT_SNAT))
+			Expect(mockCtlr.defaultHTTPPort()).To(Equal(DEFAULT_HTTP_PORT))
+			Expect(mockCtlr.defaultHTTPSPort()).To(Equal(DEFAULT_HTTPS_PORT))
+			Expect(mockCtlr.resources.baseRouteConfig).To(Equal(BaseRouteConfig{}))
+		})
+
+		It("Gives precedence to the route spec configmap over DeployConfig", func() {
+			mockCtlr.mode = OpenShiftMode
+			dc.Spec.TLS.ClientSSL = "/Common/clientssl"
+			mockCtlr.processDeployConfig(dc, false)
+			Expect(mockCtlr.resources.baseRouteConfig.DefaultTLS).To(Equal(DefaultSSLProfile{
+				ClientSSL: "/Common/clientssl",
+				Reference: BIGIP,
+			}))
+
+			mockCtlr.readBaseRouteConfigFromGlobalCM(BaseRouteConfig{
+				TLSCipher: TLSCipher{TLSVersion: "1.2"},
+			})
+			Expect(mockCtlr.resources.baseRouteConfig.TLSCipher).To(Equal(TLSCipher{
+				TLSVersion:  "1.2",
+				Ciphers:     "DEFAULT",
+				CipherGroup: "/Common/f5-secure",
+			}), "Invalid TLS baseline")
+			Expect(mockCtlr.resources.baseRouteConfig.DefaultTLS.ClientSSL).To(Equal("/Common/clientssl"))
+		})
+
+		It("Sets the client and server SSL profiles independently", func() {
+			dc.Spec.TLS.ServerSSL = "/Common/serverssl"
+			mockCtlr.processDeployConfig(dc, false)
+			Expect(mockCtlr.resources.baseRouteConfig.DefaultTLS).To(Equal(DefaultSSLProfile{
+				ServerSSL: "/Common/serverssl",
+				Reference: BIGIP,
+			}))
+
+			mockCtlr.readBaseRouteConfigFromGlobalCM(BaseRouteConfig{
+				DefaultTLS: DefaultSSLProfile{ClientSSL: "/Common/clientssl", Reference: BIGIP},
+			})
+			Expect(mockCtlr.resources.baseRouteConfig.DefaultTLS).To(Equal(DefaultSSLProfile{
+				ClientSSL: "/Common/clientssl",
+				ServerSSL: "/Common/serverssl",
+				Reference: BIGIP,
+			}), "Server SSL profile of the DeployConfig should be kept")
+		})
+
+		It("Posts all the partitions when the default route domain changes", func() {
+			mockCtlr.defaultRouteDomain = 1
+			rsMap := mockCtlr.resources.getPartitionResourceMapForUpdate("test")
+			rsMap["vs"] = &ResourceConfig{Virtual: Virtual{Name: "vs"}}
+			mockCtlr.resources.updateCaches()
+
+			mockCtlr.processDeployConfig(dc, false)
+			Expect(mockCtlr.routeDomain()).To(Equal(1))
+			Expect(mockCtlr.resources.getUpdatedPartitions()).To(BeEmpty())
+
+			dc.Spec.DefaultRouteDomain = 2
+			mockCtlr.processDeployConfig(dc, false)
+			Expect(mockCtlr.routeDomain()).To(Equal(2))
+			Expect(mockCtlr.resources.getUpdatedPartitions()).To(Equal(map[string]struct{}{"test": {}}))
+
+			mockCtlr.resources.updateCaches()
+			mockCtlr.processDeployConfig(dc, true)
+			Expect(mockCtlr.routeDomain()).To(Equal(1), "Should fall back to the route domain of CIS")
+			Expect(mockCtlr.resources.getUpdatedPartitions()).To(HaveKey("test"))
+		})
+	})
 })
+
+func benchmarkUpdatedPartitions(b *testing.B, tenants, virtuals int) {
+	var rs ResourceStore
+	rs.Init()
+	for partition, partitionConfig := range newBenchmarkConfig(tenants, virtuals).ltmConfig {
+		rs.ltmConfig[partition] = partitionConfig
+		rs.markPartitionDirty(partition)
+	}
+	rs.updateCaches()
+	rsCfg := rs.ltmConfig["tenant_0"].ResourceMap["crd_vs_10.0.0.1"]
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		freshRsCfg := &ResourceConfig{}
+		freshRsCfg.copyConfig(rsCfg)
+		freshRsCfg.Virtual.Enabled = i%2 == 0
+		_ = rs.setResourceConfig("tenant_0", "crd_vs_10.0.0.1", freshRsCfg)
+		// The partitions read, as by the wide IPs, are not compared
+		for _, partition := range rs.GetLTMPartitions() {
+			_ = rs.getPartitionResourceMap(partition)
+		}
+		if len(rs.getUpdatedPartitions()) != 1 {
+			b.Fatal("Only the changed partition should be updated")
+		}
+		rs.updateCaches()
+	}
+}
+
+func BenchmarkUpdatedPartitionsOneChanged50x20(b *testing.B) {
+	benchmarkUpdatedPartitions(b, 50, 20)
+}
+
+func BenchmarkUpdatedPartitionsOneChanged200x20(b *testing.B) {
+	benchmarkUpdatedPartitions(b, 200, 20)
+}
@@ -0,0 +1,243 @@
+/*-
+* Copyright (c) 2016-2021, F5 Networks, Inc.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	ficV1 "github.com/F5Networks/f5-ipam-controller/pkg/ipamapis/apis/fic/v1"
+	cisapiv2 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v2"
+	log "github.com/F5Networks/k8s-bigip-ctlr/pkg/vlogger"
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// storeLock serializes the access of the resource workers to the ResourceStore
+// and its supplementContextCache. The Kubernetes API calls whose results are not
+// needed by the holder, such as the status updates and the IPAM CR updates, are
+// run after the lock is released, so that the other workers make progress meanwhile
+type storeLock struct {
+	sync.Mutex
+	// apiCalls are added by the holder of the lock
+	apiCalls []func()
+}
+
+func (sl *storeLock) lock() {
+	sl.Lock()
+}
+
+// unlock releases the lock and runs the API calls added by the holder
+func (sl *storeLock) unlock() {
+	apiCalls := sl.apiCalls
+	sl.apiCalls = nil
+	sl.Unlock()
+	for _, apiCall := range apiCalls {
+		apiCall()
+	}
+}
+
+// afterUnlock adds an API call to be run once the holder releases the lock
+func (sl *storeLock) afterUnlock(apiCall func()) {
+	sl.apiCalls = append(sl.apiCalls, apiCall)
+}
+
+// ipamRetryInterval is the interval between the retries of a failed IPAM CR update
+const ipamRetryInterval = 5 * time.Second
+
+// ipamWriter writes the IPAM CR updated by the workers to the API server. The
+// writes are serialized and only the latest IPAM CR pending is written
+type ipamWriter struct {
+	sync.Mutex
+	pending *ficV1.IPAM
+	// writeLock is held during the writes
+	writeLock sync.Mutex
+}
+
+// set replaces the IPAM CR pending
+func (iw *ipamWriter) set(ipamCR *ficV1.IPAM) {
+	iw.Lock()
+	defer iw.Unlock()
+	iw.pending = ipamCR
+}
+
+// take returns the IPAM CR pending, if any
+func (iw *ipamWriter) take() *ficV1.IPAM {
+	iw.Lock()
+	defer iw.Unlock()
+	ipamCR := iw.pending
+	iw.pending = nil
+	return ipamCR
+}
+
+// restore sets back an IPAM CR that failed to be written, unless a later one is pending
+func (iw *ipamWriter) restore(ipamCR *ficV1.IPAM) {
+	iw.Lock()
+	defer iw.Unlock()
+	if iw.pending == nil {
+		iw.pending = ipamCR
+	}
+}
+
+// updateIPAMCR updates the IPAM CR of the workers, which is written to the
+// API server once the store lock is released
+func (ctlr *Controller) updateIPAMCR(ipamCR *ficV1.IPAM) {
+	ctlr.resources.ipamCR = ipamCR
+	ctlr.ipamWriter.set(ipamCR.DeepCopy())
+	ctlr.resources.afterUnlock(ctlr.writeIPAMCR)
+}
+
+// writeIPAMCR writes the IPAM CR pending to the API server, a failed write is
+// retried after ipamRetryInterval
+func (ctlr *Controller) writeIPAMCR() {
+	ctlr.ipamWriter.writeLock.Lock()
+	defer ctlr.ipamWriter.writeLock.Unlock()
+	ipamCR := ctlr.ipamWriter.take()
+	if ipamCR == nil {
+		return
+	}
+	if _, err := ctlr.ipamCli.Update(ipamCR); err != nil {
+		log.Errorf("[ipam] Error updating IPAM CR, retrying in %v: %v", ipamRetryInterval, err)
+		ctlr.ipamWriter.restore(ipamCR)
+		time.AfterFunc(ipamRetryInterval, ctlr.writeIPAMCR)
+		return
+	}
+	log.Debugf("[ipam] Updated IPAM CR.")
+}
+
+// keyOrdering guarantees that the keys of the same resource are processed in
+// the order of the events. A key whose resource is being processed by a worker
+// is parked and processed by that worker after the earlier keys
+type keyOrdering struct {
+	sync.Mutex
+	parked map[string][]interface{}
+}
+
+// acquire returns true when the worker can process the key, otherwise the key
+// is parked for the worker processing the resource
+func (ko *keyOrdering) acquire(orderKey string, key interface{}) bool {
+	ko.Lock()
+	defer ko.Unlock()
+	if ko.parked == nil {
+		ko.parked = make(map[string][]interface{})
+	}
+	if keys, ok := ko.parked[orderKey]; ok {
+		ko.parked[orderKey] = append(keys, key)
+		return false
+	}
+	ko.parked[orderKey] = nil
+	return true
+}
+
+// parkedKeys returns the count of the keys parked for the workers
+func (ko *keyOrdering) parkedKeys() int {
+	ko.Lock()
+	defer ko.Unlock()
+	count := 0
+	for _, keys := range ko.parked {
+		count += len(keys)
+	}
+	return count
+}
+
+// next returns the next parked key of the resource, the resource is released
+// when there are no parked keys
+func (ko *keyOrdering) next(orderKey string) (interface{}, bool) {
+	ko.Lock()
+	defer ko.Unlock()
+	keys := ko.parked[orderKey]
+	if len(keys) == 0 {
+		delete(ko.parked, orderKey)
+		return nil, false
+	}
+	ko.parked[orderKey] = keys[1:]
+	return keys[0], true
+}
+
+// orderingKey returns the key of the resource processed in order. The keys
+// of the virtuals sharing a host or a hostGroup, and those of a Service and its
+// Endpoints, are processed in order as they affect the same virtuals
+func (rKey *rqKey) orderingKey() string {
+	switch rKey.kind {
+	case VirtualServer:
+		if vs, ok := rKey.rsc.(*cisapiv2.VirtualServer); ok {
+			if vs.Spec.HostGroup != "" {
+				return "hostGroup/" + vs.Spec.HostGroup
+			}
+			if vs.Spec.Host != "" {
+				return "host/" + vs.Spec.Host
+			}
+		}
+	case TransportServer:
+		if ts, ok := rKey.rsc.(*cisapiv2.TransportServer); ok && ts.Spec.HostGroup != "" {
+			return "hostGroup/" + ts.Spec.HostGroup
+		}
+	case Endpoints:
+		return Service + "/" + rKey.namespace + "/" + rKey.rscName
+	}
+	return rKey.kind + "/" + rKey.namespace + "/" + rKey.rscName
+}
+
+// newResourceRateLimiter is the rate limiter of the resources queue, with a
+// per key exponential backoff and an overall token bucket of qps and burst
+func newResourceRateLimiter(qps, burst int) workqueue.RateLimiter {
+	if qps <= 0 || burst <= 0 {
+		return workqueue.DefaultControllerRateLimiter()
+	}
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(5*time.Millisecond, 1000*time.Second),
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(qps), burst)},
+	)
+}
+
+// runResourceWorkers processes the resources queue with the resource workers
+// until the queue is shut down
+func (ctlr *Controller) runResourceWorkers() {
+	workers := ctlr.resourceWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctlr.processResources() {
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// processResources gets a key from the resourceQueue and processes it along
+// with the keys of the resource parked meanwhile
+func (ctlr *Controller) processResources() bool {
+	key, quit := ctlr.resourceQueue.Get()
+	if quit {
+		// The controller is shutting down.
+		log.Debugf("Resource Queue is empty, Going to StandBy Mode")
+		return false
+	}
+	orderKey := key.(*rqKey).orderingKey()
+	if !ctlr.keyOrdering.acquire(orderKey, key) {
+		return true
+	}
+	for ok := true; ok; key, ok = ctlr.keyOrdering.next(orderKey) {
+		ctlr.processResource(key)
+	}
+	return true
+}
@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	ipamFake "github.com/F5Networks/f5-ipam-controller/pkg/ipamapis/client/clientset/versioned/fake"
+	"github.com/F5Networks/f5-ipam-controller/pkg/ipammachinery"
+	cisapiv2 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v2"
+	crdfake "github.com/F5Networks/k8s-bigip-ctlr/config/client/clientset/versioned/fake"
+	"github.com/F5Networks/k8s-bigip-ctlr/pkg/teem"
+	"github.com/F5Networks/k8s-bigip-ctlr/pkg/test"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/util/workqueue"
+)
+
+var _ = Describe("Resource Workers Tests", func() {
+	It("Orders the keys of the resources affecting the same virtuals", func() {
+		vs := test.NewVirtualServer("SampleVS", "default", cisapiv2.VirtualServerSpec{Host: "test.com"})
+		Expect((&rqKey{kind: VirtualServer, namespace: "default", rscName: "SampleVS", rsc: vs}).orderingKey()).To(
+			Equal("host/test.com"))
+		vs.Spec.HostGroup = "hg"
+		Expect((&rqKey{kind: VirtualServer, namespace: "default", rscName: "SampleVS", rsc: vs}).orderingKey()).To(
+			Equal("hostGroup/hg"))
+		ts := test.NewTransportServer("SampleTS", "default", cisapiv2.TransportServerSpec{HostGroup: "hg"})
+		Expect((&rqKey{kind: TransportServer, namespace: "default", rscName: "SampleTS", rsc: ts}).orderingKey()).To(
+			Equal("hostGroup/hg"))
+		Expect((&rqKey{kind: Endpoints, namespace: "default", rscName: "svc1"}).orderingKey()).To(
+			Equal((&rqKey{kind: Service, namespace: "default", rscName: "svc1"}).orderingKey()))
+		Expect((&rqKey{kind: Service, namespace: "default", rscName: "svc2"}).orderingKey()).To(
+			Equal("Service/default/svc2"))
+	})
+
+	It("Processes the parked keys of a resource in order", func() {
+		var ko keyOrdering
+		Expect(ko.acquire("Service/default/svc1", 1)).To(BeTrue())
+		Expect(ko.acquire("Service/default/svc1", 2)).To(BeFalse())
+		Expect(ko.acquire("Service/default/svc2", 3)).To(BeTrue())
+		Expect(ko.acquire("Service/default/svc1", 4)).To(BeFalse())
+
+		key, ok := ko.next("Service/default/svc1")
+		Expect(ok).To(BeTrue())
+		Expect(key).To(Equal(2))
+		key, ok = ko.next("Service/default/svc1")
+		Expect(ok).To(BeTrue())
+		Expect(key).To(Equal(4))
+		_, ok = ko.next("Service/default/svc1")
+		Expect(ok).To(BeFalse())
+		Expect(ko.acquire("Service/default/svc1", 5)).To(BeTrue(), "Resource should be released")
+	})
+
+	It("Runs the API calls after releasing the store lock", func() {
+		rs := NewResourceStore()
+		called := make(chan struct{})
+		rs.lock()
+		rs.afterUnlock(func() {
+			// The lock is available to the other workers
+			rs.lock()
+			rs.unlock()
+			close(called)
+		})
+		Consistently(called).ShouldNot(BeClosed(), "API call should wait for the lock to be released")
+		rs.unlock()
+		Expect(called).To(BeClosed())
+		Expect(rs.apiCalls).To(BeEmpty())
+	})
+
+	It("Counts the parked keys", func() {
+		var ko keyOrdering
+		Expect(ko.parkedKeys()).To(BeZero())
+		ko.acquire("Service/default/svc1", 1)
+		ko.acquire("Service/default/svc1", 2)
+		ko.acquire("Service/default/svc1", 3)
+		Expect(ko.parkedKeys()).To(Equal(2))
+		ko.next("Service/default/svc1")
+		Expect(ko.parkedKeys()).To(Equal(1))
+	})
+
+	It("Processes the queued keys with the resource workers", func() {
+		mockCtlr := newMockController()
+		mockCtlr.resources = NewResourceStore()
+		mockCtlr.resourceWorkers = 4
+		mockCtlr.resourceQueue = workqueue.NewNamedRateLimitingQueue(
+			newResourceRateLimiter(10, 100), "resource-workers-test")
+		for i := 0; i < 50; i++ {
+			mockCtlr.resourceQueue.Add(&rqKey{kind: "Unknown", namespace: "default", rscName: "rsc"})
+		}
+		mockCtlr.resourceQueue.ShutDown()
+
+		done := make(chan struct{})
+		go func() {
+			mockCtlr.runResourceWorkers()
+			close(done)
+		}()
+		Eventually(done, 5*time.Second).Should(BeClosed())
+		Expect(mockCtlr.resourceQueue.Len()).To(BeZero())
+		Expect(mockCtlr.processingKeys).To(BeZero())
+		Expect(mockCtlr.keyOrdering.parked).To(BeEmpty())
+	})
+
+	It("Processes the other keys while the IPAM CR is being updated", func() {
+		mockCtlr := newMockController()
+		mockCtlr.resources = NewResourceStore()
+		mockCtlr.mode = CustomResourceMode
+		mockCtlr.kubeCRClient = crdfake.NewSimpleClientset()
+		mockCtlr.kubeClient = k8sfake.NewSimpleClientset()
+		mockCtlr.crInformers = make(map[string]*CRInformer)
+		mockCtlr.comInformers = make(map[string]*CommonInformer)
+		_ = mockCtlr.addNamespacedInformers("default", false)
+		mockCtlr.TeemData = &teem.TeemsData{
+			ResourceType: teem.ResourceTypes{TransportServer: make(map[string]int)},
+		}
+		mockCtlr.Agent = &Agent{PostManager: &PostManager{PostParams: PostParams{BIGIPURL: "10.10.10.1"}}}
+
+		// The first update of the IPAM CR blocks until it is released
+		ipamClient := ipamFake.NewSimpleClientset()
+		updating := make(chan struct{}, 1)
+		release := make(chan struct{})
+		ipamClient.PrependReactor("update", "ipams", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			select {
+			case updating <- struct{}{}:
+			default:
+			}
+			<-release
+			return false, nil, nil
+		})
+		mockCtlr.ipamCli = ipammachinery.NewFakeIPAMClient(ipamClient, nil, nil)
+		Expect(mockCtlr.createIPAMResource()).To(Succeed())
+
+		mockCtlr.resourceWorkers = 2
+		mockCtlr.resourceQueue = workqueue.NewNamedRateLimitingQueue(
+			workqueue.DefaultControllerRateLimiter(), "resource-workers-test")
+		enqueue := func(name string) {
+			ts := test.NewTransportServer(name, "default", cisapiv2.TransportServerSpec{
+				IPAMLabel:         "test",
+				VirtualServerPort: 80,
+				Pool:              cisapiv2.Pool{Service: "svc1", ServicePort: 80},
+			})
+			_ = mockCtlr.crInformers["default"].tsInformer.GetIndexer().Add(ts)
+			mockCtlr.resourceQueue.Add(&rqKey{
+				namespace: "default", kind: TransportServer, rscName: name, rsc: ts, event: Create})
+		}
+		pendingHostSpecs := func() int {
+			mockCtlr.ipamWriter.Lock()
+			defer mockCtlr.ipamWriter.Unlock()
+			if mockCtlr.ipamWriter.pending == nil {
+				return 0
+			}
+			return len(mockCtlr.ipamWriter.pending.Spec.HostSpecs)
+		}
+
+		done := make(chan struct{})
+		go func() {
+			mockCtlr.runResourceWorkers()
+			close(done)
+		}()
+		enqueue("ts1")
+		Eventually(updating).Should(Receive(), "IPAM CR should be updated")
+
+		// The second key is processed while the update of the first one is in flight
+		enqueue("ts2")
+		Eventually(pendingHostSpecs).Should(Equal(2))
+		Consistently(release).ShouldNot(BeClosed())
+
+		close(release)
+		ipamCR := mockCtlr.getIPAMCR()
+		Eventually(func() int {
+			cr, err := ipamClient.K8sV1().IPAMs(ipamCR.Namespace).Get(context.TODO(), ipamCR.Name, metav1.GetOptions{})
+			if err != nil {
+				return 0
+			}
+			return len(cr.Spec.HostSpecs)
+		}).Should(Equal(2), "Latest IPAM CR should be written")
+
+		mockCtlr.resourceQueue.ShutDown()
+		Eventually(done, 5*time.Second).Should(BeClosed())
+	})
+})
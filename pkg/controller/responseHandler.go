@@ -8,12 +8,13 @@ import (
 	log "github.com/F5Networks/k8s-bigip-ctlr/pkg/vlogger"
 	v1 "k8s.io/api/core/v1"
 
-	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v1"
+	cisapiv2 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v2"
 )
 
 func (ctlr *Controller) enqueueReq(config ResourceConfigRequest) int {
 	rm := requestMeta{
-		meta: make(map[string]string, len(config.ltmConfig)),
+		meta:    make(map[string]string, len(config.ltmConfig)),
+		tenants: make(map[string]string),
 	}
 	if ctlr.requestQueue.Len() == 0 {
 		rm.id = 1
@@ -25,6 +26,7 @@ func (ctlr *Controller) enqueueReq(config ResourceConfigRequest) int {
 		for _, cfg := range partitionConfig.ResourceMap {
 			for key, val := range cfg.MetaData.baseResources {
 				rm.meta[key] = val
+				rm.tenants[key] = partition
 				rm.partition = partition
 			}
 		}
@@ -41,7 +43,8 @@ func (ctlr *Controller) responseHandler(respChan chan resourceStatusMeta) {
 	// todo: update only when there is a change(success to fail or vice versa) in tenant status
 	ctlr.requestQueue = &requestQueue{sync.Mutex{}, list.New()}
 	for rscUpdateMeta := range respChan {
-
+		// The ResourceStore and the informers are shared with the resource workers
+		ctlr.resources.lock()
 		rm := ctlr.dequeueReq(rscUpdateMeta.id, len(rscUpdateMeta.failedTenants))
 		partition := rm.partition
 		for rscKey, kind := range rm.meta {
@@ -63,9 +66,10 @@ func (ctlr *Controller) responseHandler(respChan chan resourceStatusMeta) {
 					log.Debugf("VirtualServer Not Found: %v", rscKey)
 					continue
 				}
-				virtual := obj.(*cisapiv1.VirtualServer)
+				virtual := obj.(*cisapiv2.VirtualServer)
 				if virtual.Namespace+"/"+virtual.Name == rscKey {
-					ctlr.updateVirtualServerStatus(virtual, virtual.Status.VSAddress, "Ok")
+					ctlr.updateVirtualServerStatus(virtual, virtual.Status.VSAddress,
+						ctlr.getResourceStatus(rscKey, rm.tenants[rscKey], rscUpdateMeta.invalidTenants))
 				}
 				// Update Corresponding Service Status of Type LB
 				for _, pool := range virtual.Spec.Pools {
@@ -90,12 +94,15 @@ func (ctlr *Controller) responseHandler(respChan chan resourceStatusMeta) {
 					log.Debugf("TransportServer Not Found: %v", rscKey)
 					continue
 				}
-				virtual := obj.(*cisapiv1.TransportServer)
+				virtual := obj.(*cisapiv2.TransportServer)
 				if virtual.Namespace+"/"+virtual.Name == rscKey {
-					ctlr.updateTransportServerStatus(virtual, virtual.Status.VSAddress, "Ok")
+					ctlr.updateTransportServerStatus(virtual, virtual.Status.VSAddress,
+						ctlr.getResourceStatus(rscKey, rm.tenants[rscKey], rscUpdateMeta.invalidTenants))
 				}
 			case Route:
-				if _, found := rscUpdateMeta.failedTenants[partition]; found {
+				if _, found := rscUpdateMeta.invalidTenants[partition]; found {
+					go ctlr.updateRouteAdmitStatus(rscKey, "Invalid AS3 declaration", rscUpdateMeta.invalidTenants[partition], v1.ConditionFalse)
+				} else if _, found := rscUpdateMeta.failedTenants[partition]; found {
 					// TODO : distinguish between a 503 and an actual failure
 					go ctlr.updateRouteAdmitStatus(rscKey, "Failure while updating config", "Please check logs for more information", v1.ConditionFalse)
 				} else {
@@ -105,6 +112,7 @@ func (ctlr *Controller) responseHandler(respChan chan resourceStatusMeta) {
 				}
 			}
 		}
+		ctlr.resources.unlock()
 	}
 }
 
@@ -30,13 +30,13 @@ import (
 
 	"github.com/F5Networks/k8s-bigip-ctlr/pkg/resource"
 
-	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v1"
+	cisapiv2 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v2"
 	log "github.com/F5Networks/k8s-bigip-ctlr/pkg/vlogger"
 )
 
 // prepareVirtualServerRules prepares LTM Policy rules for VirtualServer
 func (ctlr *Controller) prepareVirtualServerRules(
-	vs *cisapiv1.VirtualServer,
+	vs *cisapiv2.VirtualServer,
 	rsCfg *ResourceConfig,
 ) *Rules {
 	rlMap := make(ruleMap)
@@ -65,7 +65,7 @@ func (ctlr *Controller) prepareVirtualServerRules(
 		uri := vs.Spec.Host + pl.Path
 
 		path := pl.Path
-		var tls *cisapiv1.TLSProfile
+		var tls *cisapiv2.TLSProfile
 		if vs.Spec.TLSProfileName != "" {
 			tls = ctlr.getTLSProfileForVirtualServer(vs, vs.Namespace)
 
@@ -460,6 +460,34 @@ func (rules Rules) Swap(i, j int) {
 	rules[i], rules[j] = rules[j], rules[i]
 }
 
+// requestRateLimitIRule rejects the requests of a client exceeding
+// requestsPerSecond + burst within a one second window.
+// Clients are identified by the configured header, or by their IP address.
+func requestRateLimitIRule(ruleName string, rl RequestRateLimit) string {
+	clientKey := "[IP::client_addr]"
+	if rl.Header != "" {
+		// Braces keep the header name from being substituted by Tcl
+		clientKey = fmt.Sprintf(`[HTTP::header value {%s}]`, rl.Header)
+	}
+	iRuleCode := fmt.Sprintf(`
+		when HTTP_REQUEST {
+			set client %[1]s
+			if {$client eq ""} {
+				set client [IP::client_addr]
+			}
+			set window "%[2]s_[clock seconds]"
+			set count [table incr -notouch -subtable $window $client]
+			if {$count == 1} {
+				table timeout -subtable $window $client 2
+			}
+			if {$count > %[3]d} {
+				HTTP::respond %[4]d content "Too Many Requests" "Retry-After" 1 "Connection" "close"
+				return
+			}
+		}`, clientKey, ruleName, rl.RequestsPerSecond+rl.Burst, rl.ResponseCode)
+	return iRuleCode
+}
+
 // httpRedirectIRuleNoHost redirects traffic to BIG-IP https vs
 // for hostLess CRDs.
 func httpRedirectIRuleNoHost(port int32) string {
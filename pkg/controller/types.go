@@ -21,6 +21,7 @@ import (
 	ficV1 "github.com/F5Networks/f5-ipam-controller/pkg/ipamapis/apis/fic/v1"
 	"net/http"
 	"sync"
+	"time"
 
 	"k8s.io/apimachinery/pkg/util/intstr"
 
@@ -31,6 +32,7 @@ import (
 	"github.com/F5Networks/k8s-bigip-ctlr/pkg/teem"
 
 	"github.com/F5Networks/f5-ipam-controller/pkg/ipammachinery"
+	cisapiv2 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v2"
 	"github.com/F5Networks/k8s-bigip-ctlr/config/client/clientset/versioned"
 	apm "github.com/F5Networks/k8s-bigip-ctlr/pkg/appmanager"
 	"github.com/F5Networks/k8s-bigip-ctlr/pkg/pollers"
@@ -38,10 +40,13 @@ import (
 	v1 "k8s.io/api/core/v1"
 	extClient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
+
+	"github.com/xeipuuv/gojsonschema"
 )
 
 type (
@@ -52,6 +57,7 @@ type (
 		kubeCRClient           versioned.Interface
 		kubeClient             kubernetes.Interface
 		kubeAPIClient          *extClient.Clientset
+		dynamicClient          dynamic.Interface
 		eventNotifier          *apm.EventNotifier
 		nativeResourceSelector labels.Selector
 		customResourceSelector labels.Selector
@@ -65,16 +71,51 @@ type (
 		nodePoller             pollers.Poller
 		oldNodes               []Node
 		UseNodeInternal        bool
+		staticRoutingMode      bool
+		staticRouteAnnotation  string
+		orchestrationCNI       string
 		initState              bool
 		dgPath                 string
 		shareNodes             bool
 		ipamCli                *ipammachinery.IPAMClient
 		ipamCR                 string
+		ipamWriter             ipamWriter
 		defaultRouteDomain     int
 		TeemData               *teem.TeemsData
 		requestQueue           *requestQueue
 		namespaceLabel         string
 		ipamHostSpecEmpty      bool
+		podProbeHealthMonitor  bool
+		deployConfig           string
+		// pool member weights of the local cluster when pool members of
+		// remote clusters are merged into the pools
+		localClusterRatio    int32
+		localClusterPriority int32
+		remoteClusters       map[string]*RemoteClusterInformer
+		remoteClustersLock   sync.RWMutex
+		mcSecretInformer     *MultiClusterSecretInformer
+		as3OverrideCM        string
+		// errors of the invalid AS3 overrides, reported in the resource status
+		as3OverrideErrors as3OverrideErrors
+		// webhook validating the custom resources on admission
+		webhook *admissionWebhook
+		// nodes excluded from the NodePort pools are drained for nodeDrainTimeout
+		nodeExcludeTaint string
+		nodeExcludeLabel string
+		nodeDrainTimeout time.Duration
+		nodeDrainStart   map[string]time.Time
+		// lastNodeUpdate holds the latest nodes, processed again when a drain times out
+		lastNodeUpdate interface{}
+		nodeUpdateLock sync.Mutex
+		// resources are processed by resourceWorkers in parallel, in order per resource
+		resourceWorkers int
+		keyOrdering     keyOrdering
+		// processingKeys is the count of keys being processed by the workers
+		processingKeys int
+		// postEvents is the count of the events processed since the last post,
+		// priorityPost bypasses the debouncing of the next post
+		postEvents   int
+		priorityPost bool
 		resourceContext
 	}
 	resourceContext struct {
@@ -84,10 +125,17 @@ type (
 		nrInformers        map[string]*NRInformer
 		crInformers        map[string]*CRInformer
 		nsInformers        map[string]*NSInformer
+		nsLabelInformer    *NSInformer
+		dcInformer         *DCInformer
+		overrideCMInformer *OverrideCMInformer
 		routeSpecCMKey     string
 		routeLabel         string
 		namespaceLabelMode bool
 		processedHostPath  *ProcessedHostPath
+		deployConfigSpec   cisapiv2.DeployConfigSpec
+
+		// Guards the creation of nsLabelInformer on its first use
+		nsLabelInformerLock sync.Mutex
 	}
 
 	// Params defines parameters
@@ -102,6 +150,7 @@ type (
 		VXLANMode          string
 		UseNodeInternal    bool
 		NodePollInterval   int
+		NodeUpdateDebounce int
 		NodeLabelSelector  string
 		ShareNodes         bool
 		IPAM               bool
@@ -109,6 +158,41 @@ type (
 		Mode               ControllerMode
 		RouteSpecConfigmap string
 		RouteLabel         string
+		// PodProbeHealthMonitor derives pool health monitors from the
+		// readinessProbe of the pods backing a pool
+		PodProbeHealthMonitor bool
+		// DeployConfig is the name of the cluster scoped DeployConfig holding
+		// the defaults of the virtuals
+		DeployConfig string
+		// MultiClusterSecrets are the <namespace>/<name> of the secrets holding
+		// the kubeconfig of the remote clusters serving the same services
+		MultiClusterSecrets  []string
+		LocalClusterRatio    int32
+		LocalClusterPriority int32
+		// OverrideAS3ConfigMap is the <namespace>/<name> of the ConfigMap
+		// holding the AS3 overrides of the tenants
+		OverrideAS3ConfigMap string
+		// WebhookServerAddress is the address of the validating admission webhook,
+		// served over TLS with WebhookCertFile and WebhookKeyFile
+		WebhookServerAddress string
+		WebhookCertFile      string
+		WebhookKeyFile       string
+		// NodeExcludeTaint and NodeExcludeLabel (<key> or <key>=<value>) exclude
+		// the nodes from the NodePort pools after draining for NodeDrainTimeout seconds
+		NodeExcludeTaint string
+		NodeExcludeLabel string
+		NodeDrainTimeout int
+		// StaticRoutingMode maintains static routes on BIG-IP to the pod CIDRs of
+		// the nodes, read from StaticRouteNodeCIDRAnnotation as well if set
+		StaticRoutingMode             bool
+		StaticRouteNodeCIDRAnnotation string
+		// OrchestrationCNI is the CNI of the VXLAN overlay, flannel and OpenShift SDN by default
+		OrchestrationCNI string
+		// ResourceWorkers process the resources in parallel, the resources queue
+		// is rate limited to ResourceQueueQPS with ResourceQueueBurst
+		ResourceWorkers    int
+		ResourceQueueQPS   int
+		ResourceQueueBurst int
 	}
 
 	// CRInformer defines the structure of Custom Resource Informer
@@ -144,6 +228,72 @@ type (
 		stopCh     chan struct{}
 		nsInformer cache.SharedIndexInformer
 	}
+
+	// DCInformer is informer context for the cluster scoped DeployConfig
+	DCInformer struct {
+		stopCh     chan struct{}
+		dcInformer cache.SharedIndexInformer
+	}
+
+	// OverrideCMInformer is informer context for the AS3 override ConfigMap
+	OverrideCMInformer struct {
+		stopCh     chan struct{}
+		cmInformer cache.SharedIndexInformer
+	}
+
+	// MultiClusterSecretInformer watches the kubeconfig secrets of the remote clusters
+	MultiClusterSecretInformer struct {
+		stopCh          chan struct{}
+		secretInformers []cache.SharedIndexInformer
+	}
+
+	// admissionWebhook is the HTTPS server of the validating admission webhook
+	admissionWebhook struct {
+		server   *http.Server
+		certFile string
+		keyFile  string
+	}
+
+	// as3OverrideErrors holds the validation errors of the AS3 overrides
+	// keyed by <namespace>/<name> of the resource
+	as3OverrideErrors struct {
+		sync.RWMutex
+		errs map[string]string
+	}
+
+	// as3SchemaValidator validates AS3 declarations against the bundled schema
+	as3SchemaValidator struct {
+		sync.Mutex
+		schemaURL     string
+		schemaVersion string
+		schema        *gojsonschema.Schema
+		loadErr       error
+		// results holds the latest validation result of each tenant or virtual
+		results map[string]as3ValidationResult
+	}
+
+	as3ValidationResult struct {
+		// hash is the md5 of the validated declaration
+		hash string
+		err  error
+	}
+
+	// RemoteClusterInformer is informer context of a remote cluster whose
+	// pool members are merged into the pools of the local virtuals
+	RemoteClusterInformer struct {
+		sync.RWMutex
+		name         string
+		ratio        int32
+		priority     int32
+		up           bool
+		kubeClient   kubernetes.Interface
+		stopCh       chan struct{}
+		svcInformer  cache.SharedIndexInformer
+		epsInformer  cache.SharedIndexInformer
+		nodeInformer cache.SharedIndexInformer
+		// secretVersion is the resource version of the kubeconfig secret
+		secretVersion string
+	}
 	rqKey struct {
 		namespace string
 		kind      string
@@ -161,6 +311,8 @@ type (
 		hosts         []string
 		Protocol      string
 		httpTraffic   string
+		// autoExternalDNS of the policy synthesizes the wide IPs of the hosts
+		autoExternalDNS *cisapiv2.AutoExternalDNSSpec
 	}
 
 	// Virtual Server Key - unique server is Name + Port
@@ -201,10 +353,23 @@ type (
 		PersistenceProfile     string                `json:"persistenceProfile,omitempty"`
 		TLSTermination         string                `json:"-"`
 		AllowSourceRange       []string              `json:"allowSourceRange,omitempty"`
+		MaxConnections         int32                 `json:"maxConnections,omitempty"`
+		ConnectionRateLimit    int32                 `json:"rateLimit,omitempty"`
+		RequestRateLimit       RequestRateLimit      `json:"requestRateLimit,omitempty"`
+		// AS3Override is the JSON merged into the AS3 Service of the virtual
+		AS3Override string `json:"as3Override,omitempty"`
 	}
 	// Virtuals is slice of virtuals
 	Virtuals []Virtual
 
+	// RequestRateLimit limits the HTTP requests per second from a single client
+	RequestRateLimit struct {
+		RequestsPerSecond int32  `json:"requestsPerSecond,omitempty"`
+		Burst             int32  `json:"burst,omitempty"`
+		Header            string `json:"header,omitempty"`
+		ResponseCode      int32  `json:"responseCode,omitempty"`
+	}
+
 	ProfileTCP struct {
 		Client string `json:"client,omitempty"`
 		Server string `json:"server,omitempty"`
@@ -259,7 +424,13 @@ type (
 		gtmConfig      GTMConfig
 		gtmConfigCache GTMConfig
 		nplStore       NPLStore
+		// AS3 overrides of the tenants keyed by tenant name
+		as3TenantOverrides      map[string]string
+		as3TenantOverridesCache map[string]string
+		// dirtyPartitions are the partitions updated since the caches were updated
+		dirtyPartitions map[string]struct{}
 		supplementContextCache
+		storeLock
 	}
 
 	// LTMConfig contain partition based ResourceMap
@@ -299,11 +470,13 @@ type (
 	}
 
 	WideIP struct {
-		DomainName string     `json:"name"`
-		RecordType string     `json:"recordType"`
-		LBMethod   string     `json:"LoadBalancingMode"`
-		Pools      []GSLBPool `json:"pools"`
-		UID        string
+		DomainName     string                  `json:"name"`
+		RecordType     string                  `json:"recordType"`
+		LBMethod       string                  `json:"LoadBalancingMode"`
+		Pools          []GSLBPool              `json:"pools"`
+		Persistence    cisapiv2.DNSPersistence `json:"persistence,omitempty"`
+		LastResortPool string                  `json:"lastResortPool,omitempty"`
+		UID            string
 	}
 
 	GSLBPool struct {
@@ -314,6 +487,10 @@ type (
 		Members       []string  `json:"members"`
 		Monitors      []Monitor `json:"monitors,omitempty"`
 		DataServer    string
+		FallbackIP    string `json:"fallbackIP,omitempty"`
+		TTL           int32  `json:"ttl,omitempty"`
+		// Targets are the domain names of CNAME and MX pools
+		Targets []cisapiv2.DNSTarget `json:"targets,omitempty"`
 	}
 
 	ResourceConfigRequest struct {
@@ -322,11 +499,21 @@ type (
 		gtmConfig          GTMConfig
 		defaultRouteDomain int
 		reqId              int
+		as3TenantOverrides map[string]string
+		// dirtyPartitions are the partitions changed since the previous request,
+		// only their tenants are rebuilt. nil for all the partitions
+		dirtyPartitions map[string]struct{}
+		// events is the count of the resource events coalesced in the request,
+		// a priority request is posted without waiting for the quiet period
+		events   int
+		priority bool
 	}
 
 	resourceStatusMeta struct {
 		id            int
 		failedTenants map[string]struct{}
+		// invalidTenants holds the schema errors of the quarantined tenants
+		invalidTenants map[string]string
 	}
 
 	resourceRef struct {
@@ -360,6 +547,9 @@ type (
 		svcType   v1.ServiceType
 		portSpec  []v1.ServicePort
 		memberMap map[portRef][]PoolMember
+		// localNodes are the nodes hosting a ready endpoint of a service
+		// with externalTrafficPolicy Local, nil for the other services
+		localNodes map[string]struct{}
 	}
 
 	// Monitor is Pool health monitor
@@ -384,6 +574,7 @@ type (
 
 	supplementContextCache struct {
 		baseRouteConfig           BaseRouteConfig
+		globalBaseRouteConfig     BaseRouteConfig
 		poolMemCache              PoolMemberCache
 		sslContext                map[string]*v1.Secret
 		extdSpecMap               extendedSpecMap
@@ -392,6 +583,9 @@ type (
 		// key of the map is IPSpec.Key
 		ipamContext              map[string]ficV1.IPSpec
 		processedNativeResources map[resourceRef]struct{}
+		// ipamCR is the IPAM CR updated by the workers, written to the API
+		// server by the ipamWriter once the store lock is released
+		ipamCR *ficV1.IPAM
 	}
 
 	// key is group identifier
@@ -567,13 +761,17 @@ type (
 	requestMeta struct {
 		meta      map[string]string
 		partition string
-		id        int
+		// tenants holds the tenant of each resource
+		tenants map[string]string
+		id      int
 	}
 
 	Node struct {
 		Name   string
 		Addr   string
 		Labels map[string]string
+		// Draining nodes are disabled in the pools before their removal
+		Draining bool
 	}
 	//NPL information from pod annotation
 	NPLAnnotation struct {
@@ -604,8 +802,10 @@ type (
 type (
 	Agent struct {
 		*PostManager
-		Partition       string
-		ConfigWriter    writer.Writer
+		Partition    string
+		ConfigWriter writer.Writer
+		// NetWriter receives the VXLAN FDB records and ARP entries
+		NetWriter       writer.Writer
 		postChan        chan ResourceConfigRequest
 		EventChan       chan interface{}
 		retryChan       chan struct{}
@@ -624,6 +824,41 @@ type (
 		// retryTenantDeclMap holds tenant name and its agent Config,tenant details
 		retryTenantDeclMap map[string]*tenantParams
 		ccclGTMAgent       bool
+		as3Validator       *as3SchemaValidator
+		// quarantinedTenants holds the schema errors of the tenants held back from BIG-IP
+		quarantinedTenants map[string]string
+		// GTMAgent posts the GTM configuration to the GTM BIG-IP, nil when GTM is posted along with LTM
+		GTMAgent *GTMAgent
+		// The config requests are posted after postQuietPeriod without newer
+		// requests, and at most postMaxDelay after the first of them
+		postQuietPeriod time.Duration
+		postMaxDelay    time.Duration
+		// declarationCache persists the tenant declarations posted to BIG-IP
+		// across the restarts, nil when disabled
+		declarationCache *declarationCache
+		// The tenants drifted on BIG-IP are checked every driftCheckInterval,
+		// and reported or reposted according to the driftPolicy
+		driftCheckInterval time.Duration
+		driftPolicy        string
+	}
+
+	// GTMAgent posts the GTM configuration to the GTM BIG-IP through AS3
+	GTMAgent struct {
+		*PostManager
+		AS3VersionInfo as3VersionInfo
+		userAgent      string
+		// dataCenter and server are declared in /Common/Shared of the GTM BIG-IP
+		dataCenter    string
+		serverName    string
+		serverAddress string
+		postChan      chan as3ADC
+		retryChan     chan struct{}
+		declUpdate    sync.Mutex
+		// cachedTenantDeclMap,incomingTenantDeclMap hold tenant names and corresponding AS3 config
+		cachedTenantDeclMap   map[string]as3Tenant
+		incomingTenantDeclMap map[string]as3Tenant
+		// retryTenantDeclMap holds tenant name and its agent Config,tenant details
+		retryTenantDeclMap map[string]*tenantParams
 	}
 
 	AgentParams struct {
@@ -640,6 +875,18 @@ type (
 		EnableIPV6     bool
 		DisableARP     bool
 		CCCLGTMAgent   bool
+		// SchemaLocal is the base directory of the AS3 schema
+		SchemaLocal string
+		// PostQuietPeriod (milliseconds) and PostMaxDelay (seconds) debounce
+		// the posting of the declarations
+		PostQuietPeriod int
+		PostMaxDelay    int
+		// DeclarationCacheDir is the directory persisting the tenant declarations
+		DeclarationCacheDir string
+		// DriftCheckInterval (seconds) and DriftPolicy configure the detection
+		// of the tenants drifted on BIG-IP, disabled with no interval
+		DriftCheckInterval int
+		DriftPolicy        string
 	}
 
 	PostManager struct {
@@ -655,7 +902,6 @@ type (
 		BIGIPURL      string
 		TrustedCerts  string
 		SSLInsecure   bool
-		AS3PostDelay  int
 		//Log the AS3 response body in Controller logs
 		LogResponse bool
 	}
@@ -664,6 +910,10 @@ type (
 		GTMBigIpUsername string
 		GTMBigIpPassword string
 		GTMBigIpUrl      string
+		// Data center and server of the LTM BIG-IP to be declared on the GTM BIG-IP
+		DataCenterName string
+		ServerName     string
+		ServerAddress  string
 	}
 
 	tenantResponse struct {
@@ -813,6 +1063,9 @@ type (
 		ServerAddresses  []string `json:"serverAddresses,omitempty"`
 		ServicePort      int32    `json:"servicePort,omitempty"`
 		ShareNodes       bool     `json:"shareNodes,omitempty"`
+		Ratio            int32    `json:"ratio,omitempty"`
+		PriorityGroup    int32    `json:"priorityGroup,omitempty"`
+		AdminState       string   `json:"adminState,omitempty"`
 	}
 
 	// as3ResourcePointer maps to following in AS3 Resources
@@ -859,6 +1112,8 @@ type (
 		ProfileMultiplex       as3MultiTypeParam    `json:"profileMultiplex,omitempty"`
 		ProfileDOS             as3MultiTypeParam    `json:"profileDOS,omitempty"`
 		ProfileBotDefense      as3MultiTypeParam    `json:"profileBotDefense,omitempty"`
+		MaxConnections         int32                `json:"maxConnections,omitempty"`
+		RateLimit              int32                `json:"rateLimit,omitempty"`
 	}
 
 	// as3ServiceAddress maps to VirtualAddress in AS3 Resources
@@ -950,10 +1205,13 @@ type (
 	}
 
 	PoolMember struct {
-		Address string `json:"address"`
-		Port    int32  `json:"port"`
-		SvcPort int32  `json:"svcPort,omitempty"`
-		Session string `json:"session,omitempty"`
+		Address       string `json:"address"`
+		Port          int32  `json:"port"`
+		SvcPort       int32  `json:"svcPort,omitempty"`
+		Session       string `json:"session,omitempty"`
+		Ratio         int32  `json:"ratio,omitempty"`
+		PriorityGroup int32  `json:"priorityGroup,omitempty"`
+		AdminState    string `json:"adminState,omitempty"`
 	}
 )
 
@@ -962,11 +1220,17 @@ type (
 
 	// as3GLSBDomain maps to GSLB_Domain in AS3 Resources
 	as3GLSBDomain struct {
-		Class      string              `json:"class"`
-		DomainName string              `json:"domainName"`
-		RecordType string              `json:"resourceRecordType"`
-		LBMode     string              `json:"poolLbMode"`
-		Pools      []as3GSLBDomainPool `json:"pools"`
+		Class              string              `json:"class"`
+		DomainName         string              `json:"domainName"`
+		RecordType         string              `json:"resourceRecordType"`
+		LBMode             string              `json:"poolLbMode"`
+		Pools              []as3GSLBDomainPool `json:"pools"`
+		PersistenceEnabled bool                `json:"persistenceEnabled,omitempty"`
+		TTLPersistence     int32               `json:"ttlPersistence,omitempty"`
+		PersistCidrIPv4    int32               `json:"persistCidrIpv4,omitempty"`
+		PersistCidrIPv6    int32               `json:"persistCidrIpv6,omitempty"`
+		LastResortPool     *as3ResourcePointer `json:"lastResortPool,omitempty"`
+		LastResortPoolType string              `json:"lastResortPoolType,omitempty"`
 	}
 
 	as3GSLBDomainPool struct {
@@ -975,18 +1239,26 @@ type (
 
 	// as3GSLBPool maps to GSLB_Pool in AS3 Resources
 	as3GSLBPool struct {
-		Class      string               `json:"class"`
-		RecordType string               `json:"resourceRecordType"`
-		LBMode     string               `json:"lbModeAlternate"`
-		Members    []as3GSLBPoolMemberA `json:"members"`
-		Monitors   []as3ResourcePointer `json:"monitors"`
-	}
-
-	// as3GSLBPoolMemberA maps to GSLB_Pool_Member_A in AS3 Resources
-	as3GSLBPoolMemberA struct {
-		Enabled       bool               `json:"enabled"`
-		Server        as3ResourcePointer `json:"server"`
-		VirtualServer string             `json:"virtualServer"`
+		Class          string               `json:"class"`
+		RecordType     string               `json:"resourceRecordType"`
+		LBMode         string               `json:"lbModeAlternate"`
+		LBModeFallback string               `json:"lbModeFallback,omitempty"`
+		FallbackIP     string               `json:"fallbackIP,omitempty"`
+		TTL            int32                `json:"ttl,omitempty"`
+		Members        []as3GSLBPoolMember  `json:"members"`
+		Monitors       []as3ResourcePointer `json:"monitors,omitempty"`
+	}
+
+	// as3GSLBPoolMember maps to GSLB_Pool_Member_A, GSLB_Pool_Member_AAAA,
+	// GSLB_Pool_Member_CNAME and GSLB_Pool_Member_MX in AS3 Resources
+	as3GSLBPoolMember struct {
+		Enabled       bool                `json:"enabled"`
+		Server        *as3ResourcePointer `json:"server,omitempty"`
+		VirtualServer string              `json:"virtualServer,omitempty"`
+		// DomainName is a static domain name for CNAME and a pointer for MX
+		DomainName         interface{} `json:"domainName,omitempty"`
+		IsDomainNameStatic bool        `json:"isDomainNameStatic,omitempty"`
+		Priority           int32       `json:"priority,omitempty"`
 	}
 
 	as3GSLBMonitor struct {
@@ -996,33 +1268,27 @@ type (
 		Send     string `json:"send"`
 		Receive  string `json:"receive"`
 		Timeout  int    `json:"timeout"`
+		// Target defaults to the address and port of each pool member
+		Target string `json:"target,omitempty"`
+	}
+
+	// as3GSLBDataCenter maps to GSLB_Data_Center in AS3 Resources
+	as3GSLBDataCenter struct {
+		Class string `json:"class"`
 	}
 
 	// as3GSLBServer maps to GSLB_Server in AS3 Resources
-	//as3GSLBServer struct {
-	//	Class                     string `json:"class"`
-	//	VSDiscoveryMode           string `json:"virtualServerDiscoveryMode"`
-	//	ExposeRouteDomainsEnabled string `json:"exposeRouteDomainsEnabled"`
-	//
-	//	DataCenter as3ResourcePointer `json:"dataCenter"`
-	//
-	//	//VirtualServers  []as3GSLBVirtualServer `json:"virtualServers"`
-	//	//Devices         []as3GSLBServerDevice `json:"devices"`
-	//
-	//}
+	as3GSLBServer struct {
+		Class           string                `json:"class"`
+		DataCenter      as3ResourcePointer    `json:"dataCenter"`
+		Devices         []as3GSLBServerDevice `json:"devices"`
+		VSDiscoveryMode string                `json:"virtualServerDiscoveryMode"`
+	}
 
 	// as3GSLBServerDevice maps to GSLB_Server_Device in AS3 Resources
-	//as3GSLBServerDevice struct {
-	//	Address string `json:"address"`
-	//}
-
-	// as3GSLBVirtualServer maps to GSLB_Virtual_Server in AS3 Resources
-	//as3GSLBVirtualServer struct {
-	//	Address string               `json:"address"`
-	//	Port    int                  `json:"port"`
-	//	Name    string               `json:"name"`
-	//	Montors []as3ResourcePointer `json:"montors"`
-	//}
+	as3GSLBServerDevice struct {
+		Address string `json:"address"`
+	}
 )
 
 type (
@@ -18,13 +18,14 @@ package controller
 
 import (
 	"fmt"
+	"strings"
 
-	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v1"
+	cisapiv2 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v2"
 	log "github.com/F5Networks/k8s-bigip-ctlr/pkg/vlogger"
 )
 
 func (ctlr *Controller) checkValidVirtualServer(
-	vsResource *cisapiv1.VirtualServer,
+	vsResource *cisapiv2.VirtualServer,
 ) bool {
 
 	vsNamespace := vsResource.ObjectMeta.Namespace
@@ -42,28 +43,41 @@ func (ctlr *Controller) checkValidVirtualServer(
 		log.Infof("VirtualServer %s is invalid", vsName)
 		return false
 	}
+	if err := ctlr.validateVirtualServerSpec(vsResource); err != nil {
+		log.Infof("%v", err)
+		return false
+	}
+
+	return true
+}
+
+// validateVirtualServerSpec validates the spec of the VirtualServer on its own
+func (ctlr *Controller) validateVirtualServerSpec(vsResource *cisapiv2.VirtualServer) error {
+	vsName := vsResource.ObjectMeta.Name
 	bindAddr := vsResource.Spec.VirtualServerAddress
 	if ctlr.ipamCli == nil {
 
 		// This ensures that pool-only mode only logs the message below the first
 		// time we see a config.
 		if bindAddr == "" {
-			log.Infof("No IP was specified for the virtual server %s", vsName)
-			return false
+			return fmt.Errorf("No IP was specified for the virtual server %s", vsName)
 		}
 	} else {
 		ipamLabel := vsResource.Spec.IPAMLabel
 		if ipamLabel == "" && bindAddr == "" {
-			log.Infof("No ipamLabel was specified for the virtual server %s", vsName)
-			return false
+			return fmt.Errorf("No ipamLabel was specified for the virtual server %s", vsName)
 		}
 	}
-
-	return true
+	for _, pool := range vsResource.Spec.Pools {
+		if err := validateMonitors(pool.Monitors, []string{"http", "https", "tcp"}, false); err != nil {
+			return fmt.Errorf("Invalid monitor of pool %v of the virtual server %s: %v", pool.Path, vsName, err)
+		}
+	}
+	return nil
 }
 
 func (ctlr *Controller) checkValidTransportServer(
-	tsResource *cisapiv1.TransportServer,
+	tsResource *cisapiv2.TransportServer,
 ) bool {
 
 	vsNamespace := tsResource.ObjectMeta.Namespace
@@ -82,35 +96,78 @@ func (ctlr *Controller) checkValidTransportServer(
 		return false
 	}
 
+	if err := ctlr.validateTransportServerSpec(tsResource); err != nil {
+		log.Errorf("%v", err)
+		return false
+	}
+
+	if tsResource.Spec.Type == "" {
+		tsResource.Spec.Type = "tcp"
+	}
+
+	return true
+}
+
+// validateTransportServerSpec validates the spec of the TransportServer on its own
+func (ctlr *Controller) validateTransportServerSpec(tsResource *cisapiv2.TransportServer) error {
+	vsName := tsResource.ObjectMeta.Name
 	bindAddr := tsResource.Spec.VirtualServerAddress
 
 	if ctlr.ipamCli == nil {
 		// This ensures that pool-only mode only logs the message below the first
 		// time we see a config.
 		if bindAddr == "" {
-			log.Infof("No IP was specified for the transport server %s", vsName)
-			return false
+			return fmt.Errorf("No IP was specified for the transport server %s", vsName)
 		}
 	} else {
 		ipamLabel := tsResource.Spec.IPAMLabel
 		if ipamLabel == "" && bindAddr == "" {
-			log.Infof("No ipamLabel was specified for the transport server %s", vsName)
-			return false
+			return fmt.Errorf("No ipamLabel was specified for the transport server %s", vsName)
 		}
 	}
 
-	if tsResource.Spec.Type == "" {
-		tsResource.Spec.Type = "tcp"
-	} else if !(tsResource.Spec.Type == "udp" || tsResource.Spec.Type == "tcp" || tsResource.Spec.Type == "sctp") {
-		log.Errorf("Invalid type value for transport server %s. Supported values are tcp, udp and sctp only", vsName)
-		return false
+	if !(tsResource.Spec.Type == "" || tsResource.Spec.Type == "udp" || tsResource.Spec.Type == "tcp" || tsResource.Spec.Type == "sctp") {
+		return fmt.Errorf("Invalid type value for transport server %s. Supported values are tcp, udp and sctp only", vsName)
 	}
+	if tsResource.Spec.Pool.Service == "" || tsResource.Spec.Pool.ServicePort == 0 {
+		return fmt.Errorf("No service or servicePort was specified for the pool of transport server %s", vsName)
+	}
+	if err := validateMonitors(tsResource.Spec.Pool.Monitors, []string{"tcp", "udp"}, false); err != nil {
+		return fmt.Errorf("Invalid monitor of the transport server %s: %v", vsName, err)
+	}
+	return nil
+}
 
-	return true
+// validateMonitors validates the monitors against the monitor types supported
+// by the resource. The Monitor type is shared by the resources, so the CRD
+// schema can not constrain these fields
+func validateMonitors(monitors []cisapiv2.Monitor, types []string, typeRequired bool) error {
+	for _, monitor := range monitors {
+		if monitor.Type == "" {
+			if typeRequired {
+				return fmt.Errorf("type and interval are required")
+			}
+			continue
+		}
+		if typeRequired && monitor.Interval == 0 {
+			return fmt.Errorf("type and interval are required")
+		}
+		supported := false
+		for _, monitorType := range types {
+			if monitor.Type == monitorType {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return fmt.Errorf("unsupported type %v, supported values are %v", monitor.Type, strings.Join(types, ", "))
+		}
+	}
+	return nil
 }
 
 func (ctlr *Controller) checkValidIngressLink(
-	il *cisapiv1.IngressLink,
+	il *cisapiv2.IngressLink,
 ) bool {
 
 	ilNamespace := il.ObjectMeta.Namespace
@@ -0,0 +1,474 @@
+/*-
+* Copyright (c) 2016-2021, F5 Networks, Inc.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"regexp"
+	"time"
+
+	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v1"
+	cisapiv2 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v2"
+	log "github.com/F5Networks/k8s-bigip-ctlr/pkg/vlogger"
+	admissionv1 "k8s.io/api/admission/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+)
+
+const (
+	// ValidatingWebhookPath is the path served by the validating admission webhook
+	ValidatingWebhookPath = "/validate"
+	// ConversionWebhookPath is the path served by the conversion webhook of the
+	// cis.f5.com resources
+	ConversionWebhookPath = "/convert"
+)
+
+// httpTokenRegex matches the RFC 7230 tokens, which header names are
+var httpTokenRegex = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
+// crScheme holds the versions of the cis.f5.com resources served by the webhooks
+var crScheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(cisapiv1.AddToScheme(crScheme))
+	utilruntime.Must(cisapiv2.AddToScheme(crScheme))
+}
+
+func newAdmissionWebhook(ctlr *Controller, params Params) *admissionWebhook {
+	mux := http.NewServeMux()
+	wh := &admissionWebhook{
+		server: &http.Server{
+			Addr:    params.WebhookServerAddress,
+			Handler: mux,
+		},
+		certFile: params.WebhookCertFile,
+		keyFile:  params.WebhookKeyFile,
+	}
+	mux.HandleFunc(ValidatingWebhookPath, ctlr.serveValidatingWebhook)
+	mux.HandleFunc(ConversionWebhookPath, serveConversionWebhook)
+	return wh
+}
+
+func (wh *admissionWebhook) start() {
+	go func() {
+		log.Infof("Starting validating admission webhook on %v", wh.server.Addr)
+		err := wh.server.ListenAndServeTLS(wh.certFile, wh.keyFile)
+		if err != nil && err != http.ErrServerClosed {
+			log.Errorf("Validating admission webhook failed: %v", err)
+		}
+	}()
+}
+
+func (wh *admissionWebhook) stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = wh.server.Shutdown(ctx)
+}
+
+// serveValidatingWebhook handles the AdmissionReview requests of the API server
+func (ctlr *Controller) serveValidatingWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	review := admissionv1.AdmissionReview{}
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		http.Error(w, "Invalid AdmissionReview", http.StatusBadRequest)
+		return
+	}
+	review.Response = ctlr.admit(review.Request)
+	review.Request = nil
+	resp, err := json.Marshal(review)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(resp)
+}
+
+// admit validates the object of the admission request
+func (ctlr *Controller) admit(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	resp := &admissionv1.AdmissionResponse{UID: req.UID, Allowed: true}
+	if req.Operation == admissionv1.Delete {
+		return resp
+	}
+	obj, err := decodeAdmissionObject(req)
+	if err == nil {
+		switch cr := obj.(type) {
+		case *cisapiv2.VirtualServer:
+			err = ctlr.validateVirtualServerAdmission(cr)
+		case *cisapiv2.TransportServer:
+			err = ctlr.validateTransportServerAdmission(cr)
+		case *cisapiv2.TLSProfile:
+			err = validateTLSProfileSpec(cr)
+		case *cisapiv2.Policy:
+			err = validatePolicySpec(cr)
+		case *cisapiv2.ExternalDNS:
+			err = ctlr.validateExternalDNSAdmission(cr)
+		}
+	}
+	if err != nil {
+		log.Debugf("Rejecting %v %v/%v: %v", req.Kind.Kind, req.Namespace, req.Name, err)
+		resp.Allowed = false
+		resp.Result = &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Message: err.Error(),
+			Reason:  metav1.StatusReasonInvalid,
+			Code:    http.StatusUnprocessableEntity,
+		}
+	}
+	return resp
+}
+
+// decodeAdmissionObject decodes the custom resource of the admission request,
+// the v1 resources are converted to v2 as CIS validates the v2 resources only
+func decodeAdmissionObject(req *admissionv1.AdmissionRequest) (runtime.Object, error) {
+	gvk := schema.GroupVersionKind{Group: req.Kind.Group, Version: req.Kind.Version, Kind: req.Kind.Kind}
+	if gvk.Group == "" {
+		gvk.Group = cisapiv2.SchemeGroupVersion.Group
+	}
+	if gvk.Version == "" {
+		gvk.Version = cisapiv1.SchemeGroupVersion.Version
+	}
+	if !crScheme.Recognizes(gvk) {
+		return nil, nil
+	}
+	obj, err := decodeCustomResource(gvk, req.Object.Raw)
+	if err != nil || gvk.Version == cisapiv2.SchemeGroupVersion.Version {
+		return obj, err
+	}
+	return cisapiv2.ConvertFromV1(obj)
+}
+
+// decodeCustomResource decodes the custom resource of the given version and kind
+func decodeCustomResource(gvk schema.GroupVersionKind, raw []byte) (runtime.Object, error) {
+	obj, err := crScheme.New(gvk)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// serveConversionWebhook handles the ConversionReview requests of the API server
+func serveConversionWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	review := apiextensionsv1.ConversionReview{}
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		http.Error(w, "Invalid ConversionReview", http.StatusBadRequest)
+		return
+	}
+	review.Response = convertCustomResources(review.Request)
+	review.Request = nil
+	resp, err := json.Marshal(review)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(resp)
+}
+
+// convertCustomResources converts the custom resources of the conversion request
+// to the desired version, the request fails as a whole if any of them fails
+func convertCustomResources(req *apiextensionsv1.ConversionRequest) *apiextensionsv1.ConversionResponse {
+	resp := &apiextensionsv1.ConversionResponse{
+		UID:    req.UID,
+		Result: metav1.Status{Status: metav1.StatusSuccess},
+	}
+	for _, obj := range req.Objects {
+		converted, err := convertCustomResource(obj.Raw, req.DesiredAPIVersion)
+		if err != nil {
+			log.Errorf("Failed to convert custom resource to %v: %v", req.DesiredAPIVersion, err)
+			resp.ConvertedObjects = nil
+			resp.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+			return resp
+		}
+		resp.ConvertedObjects = append(resp.ConvertedObjects, converted)
+	}
+	return resp
+}
+
+// convertCustomResource converts the custom resource to the desired API version
+func convertCustomResource(raw []byte, desiredAPIVersion string) (runtime.RawExtension, error) {
+	typeMeta := metav1.TypeMeta{}
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		return runtime.RawExtension{}, err
+	}
+	if typeMeta.APIVersion == desiredAPIVersion {
+		return runtime.RawExtension{Raw: raw}, nil
+	}
+	gv, err := schema.ParseGroupVersion(typeMeta.APIVersion)
+	if err != nil {
+		return runtime.RawExtension{}, err
+	}
+	obj, err := decodeCustomResource(gv.WithKind(typeMeta.Kind), raw)
+	if err != nil {
+		return runtime.RawExtension{}, err
+	}
+	var converted runtime.Object
+	switch desiredAPIVersion {
+	case cisapiv1.SchemeGroupVersion.String():
+		converted, err = cisapiv2.ConvertToV1(obj)
+	case cisapiv2.SchemeGroupVersion.String():
+		converted, err = cisapiv2.ConvertFromV1(obj)
+	default:
+		err = fmt.Errorf("unsupported API version %v", desiredAPIVersion)
+	}
+	if err != nil {
+		return runtime.RawExtension{}, err
+	}
+	return runtime.RawExtension{Object: converted}, nil
+}
+
+// validateVirtualServerAdmission validates the VirtualServer against itself and
+// the VirtualServers, TransportServers, TLSProfiles and Policies in the cache
+func (ctlr *Controller) validateVirtualServerAdmission(vs *cisapiv2.VirtualServer) error {
+	crInf, ok := ctlr.getNamespacedCRInformer(vs.Namespace)
+	if !ok {
+		// Resources of the namespaces not watched are ignored by CIS
+		return nil
+	}
+	if err := ctlr.validateVirtualServerSpec(vs); err != nil {
+		return err
+	}
+	if vs.Spec.TLSProfileName != "" {
+		_, found, _ := crInf.tlsInformer.GetIndexer().GetByKey(vs.Namespace + "/" + vs.Spec.TLSProfileName)
+		if !found {
+			return fmt.Errorf("TLSProfile %v/%v does not exist", vs.Namespace, vs.Spec.TLSProfileName)
+		}
+	}
+	if err := ctlr.checkPolicyReference(vs.Namespace, vs.Spec.PolicyName); err != nil {
+		return err
+	}
+
+	vsPorts := ctlr.getVirtualServerPorts(vs)
+	for _, inf := range ctlr.crInformers {
+		for _, obj := range inf.vsInformer.GetIndexer().List() {
+			other := obj.(*cisapiv2.VirtualServer)
+			if other.Namespace == vs.Namespace && other.Name == vs.Name {
+				continue
+			}
+			if vs.Spec.Host == "" || other.Spec.Host != vs.Spec.Host || !sharePort(vsPorts, ctlr.getVirtualServerPorts(other)) {
+				continue
+			}
+			for _, pool := range vs.Spec.Pools {
+				for _, otherPool := range other.Spec.Pools {
+					if pool.Path == otherPool.Path {
+						return fmt.Errorf("host %v and path %v are already used by VirtualServer %v/%v",
+							vs.Spec.Host, pool.Path, other.Namespace, other.Name)
+					}
+				}
+			}
+		}
+		if vs.Spec.VirtualServerAddress == "" {
+			continue
+		}
+		for _, obj := range inf.tsInformer.GetIndexer().List() {
+			ts := obj.(*cisapiv2.TransportServer)
+			if ts.Spec.VirtualServerAddress == vs.Spec.VirtualServerAddress && sharePort(vsPorts, []int32{ts.Spec.VirtualServerPort}) {
+				return fmt.Errorf("address %v and port %v are already used by TransportServer %v/%v",
+					ts.Spec.VirtualServerAddress, ts.Spec.VirtualServerPort, ts.Namespace, ts.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// validateTransportServerAdmission validates the TransportServer against itself and
+// the VirtualServers, TransportServers and Policies in the cache
+func (ctlr *Controller) validateTransportServerAdmission(ts *cisapiv2.TransportServer) error {
+	if _, ok := ctlr.getNamespacedCRInformer(ts.Namespace); !ok {
+		// Resources of the namespaces not watched are ignored by CIS
+		return nil
+	}
+	if err := ctlr.validateTransportServerSpec(ts); err != nil {
+		return err
+	}
+	if err := ctlr.checkPolicyReference(ts.Namespace, ts.Spec.PolicyName); err != nil {
+		return err
+	}
+	if ts.Spec.VirtualServerAddress == "" {
+		return nil
+	}
+
+	tsType := getTransportServerType(ts)
+	for _, inf := range ctlr.crInformers {
+		for _, obj := range inf.tsInformer.GetIndexer().List() {
+			other := obj.(*cisapiv2.TransportServer)
+			if other.Namespace == ts.Namespace && other.Name == ts.Name {
+				continue
+			}
+			// TCP and UDP virtuals can share the address and port
+			if other.Spec.VirtualServerAddress == ts.Spec.VirtualServerAddress &&
+				other.Spec.VirtualServerPort == ts.Spec.VirtualServerPort &&
+				getTransportServerType(other) == tsType {
+				return fmt.Errorf("address %v and port %v are already used by TransportServer %v/%v",
+					ts.Spec.VirtualServerAddress, ts.Spec.VirtualServerPort, other.Namespace, other.Name)
+			}
+		}
+		for _, obj := range inf.vsInformer.GetIndexer().List() {
+			vs := obj.(*cisapiv2.VirtualServer)
+			if vs.Spec.VirtualServerAddress == ts.Spec.VirtualServerAddress &&
+				sharePort(ctlr.getVirtualServerPorts(vs), []int32{ts.Spec.VirtualServerPort}) {
+				return fmt.Errorf("address %v and port %v are already used by VirtualServer %v/%v",
+					ts.Spec.VirtualServerAddress, ts.Spec.VirtualServerPort, vs.Namespace, vs.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// validateExternalDNSAdmission validates that the domain of the ExternalDNS is unique
+func (ctlr *Controller) validateExternalDNSAdmission(edns *cisapiv2.ExternalDNS) error {
+	if _, ok := ctlr.getNamespacedCommonInformer(edns.Namespace); !ok {
+		// Resources of the namespaces not watched are ignored by CIS
+		return nil
+	}
+	if edns.Spec.DomainName == "" {
+		return fmt.Errorf("domainName is not specified in ExternalDNS %v", edns.Name)
+	}
+	recordType := edns.Spec.DNSRecordType
+	if recordType == "" {
+		recordType = "A"
+	}
+	for _, pl := range edns.Spec.Pools {
+		if pl.DNSRecordType != "" && pl.DNSRecordType != recordType {
+			return fmt.Errorf("dnsRecordType %v of the pool of %v does not match the dnsRecordType %v of ExternalDNS %v",
+				pl.DNSRecordType, pl.DataServerName, recordType, edns.Name)
+		}
+		if (recordType == "CNAME" || recordType == "MX") && len(pl.Targets) == 0 {
+			return fmt.Errorf("%v pool of %v requires targets in ExternalDNS %v", recordType, pl.DataServerName, edns.Name)
+		}
+		if err := validateMonitors(pl.Monitors, []string{"http", "https", "tcp"}, true); err != nil {
+			return fmt.Errorf("invalid monitor of the pool of %v in ExternalDNS %v: %v", pl.DataServerName, edns.Name, err)
+		}
+	}
+	for _, inf := range ctlr.comInformers {
+		if inf.ednsInformer == nil {
+			continue
+		}
+		for _, obj := range inf.ednsInformer.GetIndexer().List() {
+			other := obj.(*cisapiv2.ExternalDNS)
+			if other.Namespace == edns.Namespace && other.Name == edns.Name {
+				continue
+			}
+			if other.Spec.DomainName == edns.Spec.DomainName {
+				return fmt.Errorf("domain %v is already used by ExternalDNS %v/%v",
+					edns.Spec.DomainName, other.Namespace, other.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// validatePolicySpec validates the spec of the Policy on its own
+func validatePolicySpec(plc *cisapiv2.Policy) error {
+	if sel := plc.Spec.TargetSelector; sel != nil {
+		if _, err := metav1.LabelSelectorAsSelector(sel.NamespaceSelector); err != nil {
+			return fmt.Errorf("invalid namespaceSelector: %v", err)
+		}
+		if _, err := metav1.LabelSelectorAsSelector(sel.ObjectSelector); err != nil {
+			return fmt.Errorf("invalid objectSelector: %v", err)
+		}
+	}
+	for _, srcRange := range plc.Spec.L3Policies.AllowSourceRange {
+		if _, _, err := net.ParseCIDR(srcRange); err != nil {
+			return fmt.Errorf("invalid allowSourceRange %v: %v", srcRange, err)
+		}
+	}
+	if autoEDNS := plc.Spec.AutoExternalDNS; autoEDNS != nil {
+		if err := validateMonitors(autoEDNS.Monitors, []string{"http", "https", "tcp"}, true); err != nil {
+			return fmt.Errorf("invalid autoExternalDNS monitor: %v", err)
+		}
+	}
+	rateLimit := plc.Spec.RateLimit
+	switch rateLimit.Key {
+	case "", "clientIP":
+	case "header":
+		if rateLimit.Header == "" {
+			return fmt.Errorf("header is required for the rateLimit key header")
+		}
+		if !httpTokenRegex.MatchString(rateLimit.Header) {
+			return fmt.Errorf("invalid rateLimit header %q, should be a valid HTTP header name", rateLimit.Header)
+		}
+	default:
+		return fmt.Errorf("invalid rateLimit key %v, supported values are clientIP and header", rateLimit.Key)
+	}
+	if rateLimit.ResponseCode != 0 && (rateLimit.ResponseCode < 100 || rateLimit.ResponseCode > 599) {
+		return fmt.Errorf("invalid rateLimit responseCode %v", rateLimit.ResponseCode)
+	}
+	return nil
+}
+
+// checkPolicyReference validates that the Policy referred by name exists
+func (ctlr *Controller) checkPolicyReference(namespace, plcName string) error {
+	if plcName == "" {
+		return nil
+	}
+	comInf, ok := ctlr.getNamespacedCommonInformer(namespace)
+	if !ok {
+		return nil
+	}
+	_, found, _ := comInf.plcInformer.GetIndexer().GetByKey(namespace + "/" + plcName)
+	if !found {
+		return fmt.Errorf("Policy %v/%v does not exist", namespace, plcName)
+	}
+	return nil
+}
+
+// getVirtualServerPorts returns the ports of the virtuals created for the VirtualServer
+func (ctlr *Controller) getVirtualServerPorts(vs *cisapiv2.VirtualServer) []int32 {
+	if vs.Spec.TLSProfileName == "" {
+		return []int32{ctlr.getEffectiveHTTPPort(vs)}
+	}
+	return []int32{ctlr.getEffectiveHTTPPort(vs), ctlr.getEffectiveHTTPSPort(vs)}
+}
+
+func getTransportServerType(ts *cisapiv2.TransportServer) string {
+	if ts.Spec.Type == "" {
+		return "tcp"
+	}
+	return ts.Spec.Type
+}
+
+func sharePort(ports, otherPorts []int32) bool {
+	for _, port := range ports {
+		for _, otherPort := range otherPorts {
+			if port == otherPort {
+				return true
+			}
+		}
+	}
+	return false
+}
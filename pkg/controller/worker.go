@@ -22,6 +22,7 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"sort"
 	"strings"
 	"time"
@@ -29,17 +30,24 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	ficV1 "github.com/F5Networks/f5-ipam-controller/pkg/ipamapis/apis/fic/v1"
-	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v1"
+	cisapiv2 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v2"
 	log "github.com/F5Networks/k8s-bigip-ctlr/pkg/vlogger"
 	routeapi "github.com/openshift/api/route/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
 )
 
 const nginxMonitorPort int32 = 8081
 
+// autoWideIPUID marks the wide IPs synthesized for the hosts of the virtuals
+const autoWideIPUID = "auto-external-dns"
+
+// nsLabelInformerSyncTimeout bounds the wait for the namespace labels informer to sync
+const nsLabelInformerSyncTimeout = 30 * time.Second
+
 const (
 	NotEnabled = iota
 	InvalidInput
@@ -52,12 +60,13 @@ const (
 func (ctlr *Controller) nextGenResourceWorker() {
 	log.Debugf("Starting Custom Resource Worker")
 	ctlr.setInitialServiceCount()
+	ctlr.resources.lock()
 	ctlr.migrateIPAM()
+	ctlr.resources.unlock()
 	if ctlr.mode == OpenShiftMode {
 		ctlr.processGlobalExtendedRouteConfig()
 	}
-	for ctlr.processResources() {
-	}
+	ctlr.runResourceWorkers()
 }
 
 func (ctlr *Controller) setInitialServiceCount() {
@@ -80,27 +89,24 @@ func (ctlr *Controller) setInitialServiceCount() {
 	ctlr.initialSvcCount = svcCount
 }
 
-// processResources gets resources from the resourceQueue and processes the resource
+// processResource processes the resource of the key from the resourceQueue
 // depending  on its kind.
-func (ctlr *Controller) processResources() bool {
-
-	key, quit := ctlr.resourceQueue.Get()
-	if quit {
-		// The controller is shutting down.
-		log.Debugf("Resource Queue is empty, Going to StandBy Mode")
-		return false
-	}
+func (ctlr *Controller) processResource(key interface{}) {
 	var isRetryableError bool
 
 	defer ctlr.resourceQueue.Done(key)
+	ctlr.resources.lock()
+	defer ctlr.resources.unlock()
+	ctlr.processingKeys++
 	rKey := key.(*rqKey)
 	log.Debugf("Processing Key: %v", rKey)
 
 	// During Init time, just accumulate all the poolMembers by processing only services
 	if ctlr.initState && rKey.kind != Namespace {
 		if rKey.kind != Service {
+			ctlr.processingKeys--
 			ctlr.resourceQueue.AddRateLimited(key)
-			return true
+			return
 		}
 		ctlr.initialSvcCount--
 		if ctlr.initialSvcCount <= 0 {
@@ -112,6 +118,11 @@ func (ctlr *Controller) processResources() bool {
 	if rKey.event == Delete {
 		rscDelete = true
 	}
+	ctlr.postEvents++
+	// Deletions and TLS changes are posted without debouncing
+	if rscDelete || rKey.kind == TLSProfile || rKey.kind == K8sSecret {
+		ctlr.priorityPost = true
+	}
 
 	// Check the type of resource and process accordingly.
 	switch rKey.kind {
@@ -168,7 +179,7 @@ func (ctlr *Controller) processResources() bool {
 		if ctlr.mode == OpenShiftMode || ctlr.mode == KubernetesMode {
 			break
 		}
-		virtual := rKey.rsc.(*cisapiv1.VirtualServer)
+		virtual := rKey.rsc.(*cisapiv2.VirtualServer)
 		rscRefKey := resourceRef{
 			kind:      VirtualServer,
 			name:      virtual.Name,
@@ -182,6 +193,9 @@ func (ctlr *Controller) processResources() bool {
 				delete(ctlr.resources.processedNativeResources, rscRefKey)
 			}
 		}
+		if rscDelete {
+			ctlr.as3OverrideErrors.delete(virtual.Namespace + "/" + virtual.Name)
+		}
 
 		err := ctlr.processVirtualServers(virtual, rscDelete)
 		if err != nil {
@@ -193,7 +207,7 @@ func (ctlr *Controller) processResources() bool {
 		if ctlr.mode == OpenShiftMode || ctlr.mode == KubernetesMode {
 			break
 		}
-		tlsProfile := rKey.rsc.(*cisapiv1.TLSProfile)
+		tlsProfile := rKey.rsc.(*cisapiv2.TLSProfile)
 		virtuals := ctlr.getVirtualsForTLSProfile(tlsProfile)
 		// No Virtuals are effected with the change in TLSProfile.
 		if nil == virtuals {
@@ -238,7 +252,10 @@ func (ctlr *Controller) processResources() bool {
 		if ctlr.mode == OpenShiftMode || ctlr.mode == KubernetesMode {
 			break
 		}
-		virtual := rKey.rsc.(*cisapiv1.TransportServer)
+		virtual := rKey.rsc.(*cisapiv2.TransportServer)
+		if rscDelete {
+			ctlr.as3OverrideErrors.delete(virtual.Namespace + "/" + virtual.Name)
+		}
 		err := ctlr.processTransportServers(virtual, rscDelete)
 		if err != nil {
 			// TODO
@@ -249,7 +266,7 @@ func (ctlr *Controller) processResources() bool {
 		if ctlr.mode == OpenShiftMode || ctlr.mode == KubernetesMode {
 			break
 		}
-		ingLink := rKey.rsc.(*cisapiv1.IngressLink)
+		ingLink := rKey.rsc.(*cisapiv2.IngressLink)
 		log.Infof("Worker got IngressLink: %v\n", ingLink)
 		log.Infof("IngressLink Selector: %v\n", ingLink.Spec.Selector.String())
 		err := ctlr.processIngressLink(ingLink, rscDelete)
@@ -262,14 +279,14 @@ func (ctlr *Controller) processResources() bool {
 		if ctlr.mode == KubernetesMode {
 			break
 		}
-		edns := rKey.rsc.(*cisapiv1.ExternalDNS)
+		edns := rKey.rsc.(*cisapiv2.ExternalDNS)
 		ctlr.processExternalDNS(edns, rscDelete)
 	case IPAM:
 		ipam := rKey.rsc.(*ficV1.IPAM)
 		_ = ctlr.processIPAM(ipam)
 
 	case CustomPolicy:
-		cp := rKey.rsc.(*cisapiv1.Policy)
+		cp := rKey.rsc.(*cisapiv2.Policy)
 		switch ctlr.mode {
 		case OpenShiftMode:
 			routeGroups := ctlr.getRouteGroupForCustomPolicy(cp.Namespace + "/" + cp.Name)
@@ -399,6 +416,21 @@ func (ctlr *Controller) processResources() bool {
 			ctlr.updatePoolMembersForVirtuals(svc)
 		}
 
+	case RemoteService:
+		svc := ctlr.getLocalService(rKey.namespace, rKey.rscName)
+		// Only the services of the local cluster have pools to merge into
+		if nil == svc {
+			break
+		}
+		ctlr.updatePoolMembersForService(svc)
+
+	case RemoteCluster:
+		ctlr.processRemoteCluster(rKey.rscName)
+
+	case MultiClusterSecret:
+		secret := rKey.rsc.(*v1.Secret)
+		ctlr.processMultiClusterSecret(secret, rscDelete)
+
 	case Pod:
 		pod := rKey.rsc.(*v1.Pod)
 		_ = ctlr.processPod(pod, rscDelete)
@@ -526,6 +558,59 @@ func (ctlr *Controller) processResources() bool {
 				log.Debugf("Added Namespace: '%v' to CIS scope", nsName)
 			}
 		}
+	case OverrideAS3ConfigMap:
+		cm := rKey.rsc.(*v1.ConfigMap)
+		ctlr.processAS3OverrideConfigMap(cm, rscDelete)
+
+	case DeployConfig:
+		dc := rKey.rsc.(*cisapiv2.DeployConfig)
+		if !ctlr.processDeployConfig(dc, rscDelete) {
+			break
+		}
+		// Reprocess all the resources with the updated defaults
+		if ctlr.mode == OpenShiftMode {
+			for routeGroup := range ctlr.resources.extdSpecMap {
+				err := ctlr.processRoutes(routeGroup, false)
+				if err != nil {
+					utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+					isRetryableError = true
+				}
+			}
+		}
+		if ctlr.mode == CustomResourceMode {
+			for _, virtual := range ctlr.getAllVSFromMonitoredNamespaces() {
+				err := ctlr.processVirtualServers(virtual, false)
+				if err != nil {
+					utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+					isRetryableError = true
+				}
+			}
+			for _, virtual := range ctlr.getAllTSFromMonitoredNamespaces() {
+				err := ctlr.processTransportServers(virtual, false)
+				if err != nil {
+					utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+					isRetryableError = true
+				}
+			}
+			for _, ingLink := range ctlr.getAllIngLinkFromMonitoredNamespaces() {
+				err := ctlr.processIngressLink(ingLink, false)
+				if err != nil {
+					utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+					isRetryableError = true
+				}
+			}
+		}
+		// Services of type LoadBalancer are processed in all the modes
+		for _, svc := range ctlr.getAllServicesFromMonitoredNamespaces() {
+			if svc.Spec.Type != v1.ServiceTypeLoadBalancer {
+				continue
+			}
+			err := ctlr.processLBServices(svc, false)
+			if err != nil {
+				utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+				isRetryableError = true
+			}
+		}
 	default:
 		log.Errorf("Unknown resource Kind: %v", rKey.kind)
 	}
@@ -536,22 +621,116 @@ func (ctlr *Controller) processResources() bool {
 		ctlr.resourceQueue.Forget(key)
 	}
 
-	if ctlr.resourceQueue.Len() == 0 && ctlr.resources.isConfigUpdated() {
-		config := ResourceConfigRequest{
-			ltmConfig:          ctlr.resources.getLTMConfigDeepCopy(),
-			shareNodes:         ctlr.shareNodes,
-			gtmConfig:          ctlr.resources.getGTMConfigCopy(),
-			defaultRouteDomain: ctlr.defaultRouteDomain,
+	ctlr.processingKeys--
+	// The config is posted once the other workers are done with their keys
+	if ctlr.resourceQueue.Len() == 0 && ctlr.processingKeys == 0 && ctlr.keyOrdering.parkedKeys() == 0 {
+		// Only the partitions updated since the previous request are rebuilt by the Agent
+		if updatedPartitions := ctlr.resources.getUpdatedPartitions(); len(updatedPartitions) > 0 {
+			config := ResourceConfigRequest{
+				ltmConfig:          ctlr.resources.getLTMConfigCopy(updatedPartitions),
+				shareNodes:         ctlr.shareNodes,
+				gtmConfig:          ctlr.resources.getGTMConfigCopy(),
+				defaultRouteDomain: ctlr.routeDomain(),
+				as3TenantOverrides: ctlr.resources.as3TenantOverrides,
+				dirtyPartitions:    updatedPartitions,
+				events:             ctlr.postEvents,
+				priority:           ctlr.priorityPost,
+			}
+			go ctlr.TeemData.PostTeemsData()
+			config.reqId = ctlr.enqueueReq(config)
+			ctlr.Agent.PostConfig(config)
+			ctlr.initState = false
 		}
-		go ctlr.TeemData.PostTeemsData()
-		config.reqId = ctlr.enqueueReq(config)
-		ctlr.Agent.PostConfig(config)
-		ctlr.initState = false
+		ctlr.postEvents = 0
+		ctlr.priorityPost = false
 		ctlr.resources.updateCaches()
 	}
+}
+
+// processDeployConfig updates the defaults of the virtuals from the DeployConfig,
+// returns true when the defaults have changed
+func (ctlr *Controller) processDeployConfig(dc *cisapiv2.DeployConfig, isDelete bool) bool {
+	spec := cisapiv2.DeployConfigSpec{}
+	if !isDelete {
+		spec = dc.Spec
+	}
+	if reflect.DeepEqual(ctlr.deployConfigSpec, spec) {
+		return false
+	}
+	if isDelete {
+		log.Infof("DeployConfig %v deleted, falling back to the built-in defaults", dc.Name)
+	} else {
+		log.Infof("Updating the defaults of the virtuals from DeployConfig %v", dc.Name)
+	}
+	if ctlr.deployConfigSpec.HTTPPort != spec.HTTPPort || ctlr.deployConfigSpec.HTTPSPort != spec.HTTPSPort {
+		// Virtuals are named after their ports, delete the ones on the old default ports
+		ctlr.deleteVirtualsOnDefaultPorts()
+	}
+	routeDomain := ctlr.routeDomain()
+	ctlr.deployConfigSpec = spec
+	if ctlr.routeDomain() != routeDomain {
+		// The route domain is declared by every partition, all of them are posted again
+		ctlr.resources.resetLTMConfigCache()
+	}
+	if ctlr.mode == OpenShiftMode || spec.TLS != (cisapiv2.DefaultTLSSpec{}) {
+		ctlr.updateBaseRouteConfig()
+	} else {
+		// Without the route spec configmap only the DeployConfig sets a TLS baseline
+		ctlr.resources.baseRouteConfig = BaseRouteConfig{}
+	}
 	return true
 }
 
+// deleteVirtualsOnDefaultPorts deletes the virtuals of the resources using the default ports,
+// it is called before the defaults change and the caller reprocesses the resources afterwards
+func (ctlr *Controller) deleteVirtualsOnDefaultPorts() {
+	switch ctlr.mode {
+	case OpenShiftMode:
+		for routeGroup := range ctlr.resources.extdSpecMap {
+			_ = ctlr.processRoutes(routeGroup, true)
+		}
+	case CustomResourceMode:
+		// The virtuals are looked up by name instead of processing the VirtualServers as deleted,
+		// which would release their IPAM addresses and keep the virtuals shared with other VirtualServers
+		virtuals := make(map[string]*cisapiv2.VirtualServer)
+		for _, virtual := range ctlr.getAllVSFromMonitoredNamespaces() {
+			virtuals[virtual.Namespace+"/"+virtual.Name] = virtual
+		}
+		defaultPorts := map[string]int32{
+			HTTP:  ctlr.defaultHTTPPort(),
+			HTTPS: ctlr.defaultHTTPSPort(),
+		}
+		rsMap := ctlr.resources.getPartitionResourceMap(ctlr.Partition)
+		for rsName, rsCfg := range rsMap {
+			if rsCfg.MetaData.ResourceType != VirtualServer || rsCfg.Virtual.VirtualAddress == nil ||
+				rsCfg.Virtual.VirtualAddress.Port != defaultPorts[rsCfg.MetaData.Protocol] {
+				continue
+			}
+			onDefaultPort := false
+			for rscKey, kind := range rsCfg.MetaData.baseResources {
+				virtual, ok := virtuals[rscKey]
+				if kind != VirtualServer || !ok {
+					continue
+				}
+				if (rsCfg.MetaData.Protocol == HTTP && virtual.Spec.VirtualServerHTTPPort == 0) ||
+					(rsCfg.MetaData.Protocol == HTTPS && virtual.Spec.VirtualServerHTTPSPort == 0) {
+					onDefaultPort = true
+					break
+				}
+			}
+			if !onDefaultPort {
+				continue
+			}
+			hostnames := rsCfg.MetaData.hosts
+			ctlr.deleteSvcDepResource(rsName, rsCfg)
+			ctlr.deleteVirtualServer(ctlr.Partition, rsName)
+			if len(hostnames) > 0 {
+				ctlr.ProcessAssociatedExternalDNS(hostnames)
+			}
+		}
+	}
+}
+
 // getServiceForEndpoints returns the service associated with endpoints.
 func (ctlr *Controller) getServiceForEndpoints(ep *v1.Endpoints) *v1.Service {
 
@@ -604,7 +783,7 @@ func (ctlr *Controller) updatePoolMembersForVirtuals(svc *v1.Service) {
 
 // getVirtualServersForService gets the List of VirtualServers which are effected
 // by the addition/deletion/updation of service.
-func (ctlr *Controller) getVirtualServersForService(svc *v1.Service) []*cisapiv1.VirtualServer {
+func (ctlr *Controller) getVirtualServersForService(svc *v1.Service) []*cisapiv2.VirtualServer {
 
 	allVirtuals := ctlr.getAllVirtualServers(svc.ObjectMeta.Namespace)
 	if nil == allVirtuals {
@@ -625,7 +804,7 @@ func (ctlr *Controller) getVirtualServersForService(svc *v1.Service) []*cisapiv1
 
 // getVirtualsForTLSProfile gets the List of VirtualServers which are effected
 // by the addition/deletion/updation of TLSProfile.
-func (ctlr *Controller) getVirtualsForTLSProfile(tls *cisapiv1.TLSProfile) []*cisapiv1.VirtualServer {
+func (ctlr *Controller) getVirtualsForTLSProfile(tls *cisapiv2.TLSProfile) []*cisapiv2.VirtualServer {
 
 	allVirtuals := ctlr.getAllVirtualServers(tls.ObjectMeta.Namespace)
 	if nil == allVirtuals {
@@ -644,20 +823,15 @@ func (ctlr *Controller) getVirtualsForTLSProfile(tls *cisapiv1.TLSProfile) []*ci
 	return virtualsForTLSProfile
 }
 
-func (ctlr *Controller) getVirtualsForCustomPolicy(plc *cisapiv1.Policy) []*cisapiv1.VirtualServer {
-	nsVirtuals := ctlr.getAllVirtualServers(plc.Namespace)
-	if nil == nsVirtuals {
-		log.Infof("No VirtualServers found in namespace %s",
-			plc.Namespace)
-		return nil
-	}
-
-	var plcVSs []*cisapiv1.VirtualServer
+func (ctlr *Controller) getVirtualsForCustomPolicy(plc *cisapiv2.Policy) []*cisapiv2.VirtualServer {
+	var plcVSs []*cisapiv2.VirtualServer
 	var plcVSNames []string
-	for _, vs := range nsVirtuals {
-		if vs.Spec.PolicyName == plc.Name {
-			plcVSs = append(plcVSs, vs)
-			plcVSNames = append(plcVSNames, vs.Name)
+	for _, ns := range ctlr.getPolicyTargetNamespaces(plc) {
+		for _, vs := range ctlr.getAllVirtualServers(ns) {
+			if ctlr.policyAppliesTo(plc, vs.Namespace, vs.Spec.PolicyName, vs.Labels) {
+				plcVSs = append(plcVSs, vs)
+				plcVSNames = append(plcVSNames, vs.Name)
+			}
 		}
 	}
 
@@ -667,20 +841,15 @@ func (ctlr *Controller) getVirtualsForCustomPolicy(plc *cisapiv1.Policy) []*cisa
 	return plcVSs
 }
 
-func (ctlr *Controller) getTransportServersForCustomPolicy(plc *cisapiv1.Policy) []*cisapiv1.TransportServer {
-	nsVirtuals := ctlr.getAllTransportServers(plc.Namespace)
-	if nil == nsVirtuals {
-		log.Infof("No VirtualServers found in namespace %s",
-			plc.Namespace)
-		return nil
-	}
-
-	var plcVSs []*cisapiv1.TransportServer
+func (ctlr *Controller) getTransportServersForCustomPolicy(plc *cisapiv2.Policy) []*cisapiv2.TransportServer {
+	var plcVSs []*cisapiv2.TransportServer
 	var plcVSNames []string
-	for _, vs := range nsVirtuals {
-		if vs.Spec.PolicyName == plc.Name {
-			plcVSs = append(plcVSs, vs)
-			plcVSNames = append(plcVSNames, vs.Name)
+	for _, ns := range ctlr.getPolicyTargetNamespaces(plc) {
+		for _, vs := range ctlr.getAllTransportServers(ns) {
+			if ctlr.policyAppliesTo(plc, vs.Namespace, vs.Spec.PolicyName, vs.Labels) {
+				plcVSs = append(plcVSs, vs)
+				plcVSNames = append(plcVSNames, vs.Name)
+			}
 		}
 	}
 
@@ -691,20 +860,15 @@ func (ctlr *Controller) getTransportServersForCustomPolicy(plc *cisapiv1.Policy)
 }
 
 // getLBServicesForCustomPolicy gets all services of type LB affected by the policy
-func (ctlr *Controller) getLBServicesForCustomPolicy(plc *cisapiv1.Policy) []*v1.Service {
-	LBServices := ctlr.getAllLBServices(plc.Namespace)
-	if nil == LBServices {
-		log.Infof("No LB service found in namespace %s",
-			plc.Namespace)
-		return nil
-	}
-
+func (ctlr *Controller) getLBServicesForCustomPolicy(plc *cisapiv2.Policy) []*v1.Service {
 	var plcSvcs []*v1.Service
 	var plcSvcNames []string
-	for _, svc := range LBServices {
-		if plcName, found := svc.Annotations[LBServicePolicyNameAnnotation]; found && plcName == plc.Name {
-			plcSvcs = append(plcSvcs, svc)
-			plcSvcNames = append(plcSvcNames, svc.Name)
+	for _, ns := range ctlr.getPolicyTargetNamespaces(plc) {
+		for _, svc := range ctlr.getAllLBServices(ns) {
+			if ctlr.policyAppliesTo(plc, svc.Namespace, svc.Annotations[LBServicePolicyNameAnnotation], svc.Labels) {
+				plcSvcs = append(plcSvcs, svc)
+				plcSvcNames = append(plcSvcNames, svc.Name)
+			}
 		}
 	}
 
@@ -714,9 +878,40 @@ func (ctlr *Controller) getLBServicesForCustomPolicy(plc *cisapiv1.Policy) []*v1
 	return plcSvcs
 }
 
+// getPolicyTargetNamespaces returns the namespaces of the resources the policy may be attached to
+func (ctlr *Controller) getPolicyTargetNamespaces(plc *cisapiv2.Policy) []string {
+	if plc.Spec.TargetSelector == nil && !ctlr.isDefaultPolicy(plc) {
+		return []string{plc.Namespace}
+	}
+	if ctlr.watchingAllNamespaces() {
+		return []string{""}
+	}
+	var namespaces []string
+	for ns := range ctlr.comInformers {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// policyAppliesTo returns true if the policy is attached to a resource either by name,
+// by its targetSelector or as the default policy.
+// A resource naming a policy is never attached to any other policy.
+func (ctlr *Controller) policyAppliesTo(
+	plc *cisapiv2.Policy,
+	namespace string,
+	plcName string,
+	rscLabels map[string]string,
+) bool {
+	if plcName != "" {
+		return plc.Namespace == namespace && plc.Name == plcName
+	}
+	return ctlr.isDefaultPolicy(plc) || ctlr.policySelects(plc, namespace, rscLabels)
+}
+
 // getAllVirtualServers returns list of all valid VirtualServers in rkey namespace.
-func (ctlr *Controller) getAllVirtualServers(namespace string) []*cisapiv1.VirtualServer {
-	var allVirtuals []*cisapiv1.VirtualServer
+func (ctlr *Controller) getAllVirtualServers(namespace string) []*cisapiv2.VirtualServer {
+	var allVirtuals []*cisapiv2.VirtualServer
 
 	crInf, ok := ctlr.getNamespacedCRInformer(namespace)
 	if !ok {
@@ -739,7 +934,7 @@ func (ctlr *Controller) getAllVirtualServers(namespace string) []*cisapiv1.Virtu
 	}
 
 	for _, obj := range orderedVSs {
-		vs := obj.(*cisapiv1.VirtualServer)
+		vs := obj.(*cisapiv2.VirtualServer)
 		// TODO: Validate the VirtualServers List to check if all the vs are valid.
 		allVirtuals = append(allVirtuals, vs)
 	}
@@ -748,8 +943,8 @@ func (ctlr *Controller) getAllVirtualServers(namespace string) []*cisapiv1.Virtu
 }
 
 // getAllVirtualServers returns list of all valid VirtualServers in rkey namespace.
-func (ctlr *Controller) getAllVSFromMonitoredNamespaces() []*cisapiv1.VirtualServer {
-	var allVirtuals []*cisapiv1.VirtualServer
+func (ctlr *Controller) getAllVSFromMonitoredNamespaces() []*cisapiv2.VirtualServer {
+	var allVirtuals []*cisapiv2.VirtualServer
 	if ctlr.watchingAllNamespaces() {
 		return ctlr.getAllVirtualServers("")
 	}
@@ -762,10 +957,10 @@ func (ctlr *Controller) getAllVSFromMonitoredNamespaces() []*cisapiv1.VirtualSer
 
 // filterVirtualServersForService returns list of VirtualServers that are
 // affected by the service under process.
-func filterVirtualServersForService(allVirtuals []*cisapiv1.VirtualServer,
-	svc *v1.Service) []*cisapiv1.VirtualServer {
+func filterVirtualServersForService(allVirtuals []*cisapiv2.VirtualServer,
+	svc *v1.Service) []*cisapiv2.VirtualServer {
 
-	var result []*cisapiv1.VirtualServer
+	var result []*cisapiv2.VirtualServer
 	svcName := svc.ObjectMeta.Name
 	svcNamespace := svc.ObjectMeta.Namespace
 
@@ -793,10 +988,10 @@ func filterVirtualServersForService(allVirtuals []*cisapiv1.VirtualServer,
 
 // getVirtualServersForTLS returns list of VirtualServers that are
 // affected by the TLSProfile under process.
-func getVirtualServersForTLSProfile(allVirtuals []*cisapiv1.VirtualServer,
-	tls *cisapiv1.TLSProfile) []*cisapiv1.VirtualServer {
+func getVirtualServersForTLSProfile(allVirtuals []*cisapiv2.VirtualServer,
+	tls *cisapiv2.TLSProfile) []*cisapiv2.VirtualServer {
 
-	var result []*cisapiv1.VirtualServer
+	var result []*cisapiv2.VirtualServer
 	tlsName := tls.ObjectMeta.Name
 	tlsNamespace := tls.ObjectMeta.Namespace
 
@@ -820,8 +1015,8 @@ func getVirtualServersForTLSProfile(allVirtuals []*cisapiv1.VirtualServer,
 }
 
 func (ctlr *Controller) getTLSProfileForVirtualServer(
-	vs *cisapiv1.VirtualServer,
-	namespace string) *cisapiv1.TLSProfile {
+	vs *cisapiv2.VirtualServer,
+	namespace string) *cisapiv2.TLSProfile {
 	tlsName := vs.Spec.TLSProfileName
 	tlsKey := fmt.Sprintf("%s/%s", namespace, tlsName)
 
@@ -841,29 +1036,22 @@ func (ctlr *Controller) getTLSProfileForVirtualServer(
 	}
 
 	// validate TLSProfile
-	validation := validateTLSProfile(obj.(*cisapiv1.TLSProfile))
+	validation := validateTLSProfile(obj.(*cisapiv2.TLSProfile))
 	if validation == false {
 		return nil
 	}
 
-	tlsProfile := obj.(*cisapiv1.TLSProfile)
+	tlsProfile := obj.(*cisapiv2.TLSProfile)
 
 	if tlsProfile.Spec.TLS.Reference == "secret" {
 		var match bool
-		if len(tlsProfile.Spec.TLS.ClientSSLs) > 0 {
-			for _, secret := range tlsProfile.Spec.TLS.ClientSSLs {
-				clientSecret, _ := ctlr.kubeClient.CoreV1().Secrets(namespace).Get(context.TODO(), secret, metav1.GetOptions{})
-				//validate at least one clientSSL certificates matches the VS hostname
-				if checkCertificateHost(vs.Spec.Host, clientSecret.Data["tls.crt"], clientSecret.Data["tls.key"]) {
-					match = true
-					break
-				}
+		for _, secret := range tlsProfile.Spec.TLS.ClientSSLs {
+			clientSecret := ctlr.getSecret(namespace, secret)
+			//validate at least one clientSSL certificates matches the VS hostname
+			if checkCertificateHost(vs.Spec.Host, clientSecret.Data["tls.crt"], clientSecret.Data["tls.key"]) {
+				match = true
+				break
 			}
-
-		} else {
-			clientSecret, _ := ctlr.kubeClient.CoreV1().Secrets(namespace).Get(context.TODO(), tlsProfile.Spec.TLS.ClientSSL, metav1.GetOptions{})
-			//validate clientSSL certificates and hostname
-			match = checkCertificateHost(vs.Spec.Host, clientSecret.Data["tls.crt"], clientSecret.Data["tls.key"])
 		}
 		if match == false {
 			return nil
@@ -896,11 +1084,11 @@ func (ctlr *Controller) getTLSProfileForVirtualServer(
 
 }
 
-func isTLSVirtualServer(vrt *cisapiv1.VirtualServer) bool {
+func isTLSVirtualServer(vrt *cisapiv2.VirtualServer) bool {
 	return len(vrt.Spec.TLSProfileName) != 0
 }
 
-func doesVSHandleHTTP(vrt *cisapiv1.VirtualServer) bool {
+func doesVSHandleHTTP(vrt *cisapiv2.VirtualServer) bool {
 	if !isTLSVirtualServer(vrt) {
 		// If it is not TLS VirtualServer(HTTPS), then it is HTTP server
 		return true
@@ -911,10 +1099,10 @@ func doesVSHandleHTTP(vrt *cisapiv1.VirtualServer) bool {
 }
 
 // doVSHandleHTTP checks if any of the associated vituals handle HTTP traffic and use same port
-func doVSHandleHTTP(virtuals []*cisapiv1.VirtualServer, virtual *cisapiv1.VirtualServer) bool {
-	effectiveHTTPPort := getEffectiveHTTPPort(virtual)
+func (ctlr *Controller) doVSHandleHTTP(virtuals []*cisapiv2.VirtualServer, virtual *cisapiv2.VirtualServer) bool {
+	effectiveHTTPPort := ctlr.getEffectiveHTTPPort(virtual)
 	for _, vrt := range virtuals {
-		if doesVSHandleHTTP(vrt) && effectiveHTTPPort == getEffectiveHTTPPort(vrt) {
+		if doesVSHandleHTTP(vrt) && effectiveHTTPPort == ctlr.getEffectiveHTTPPort(vrt) {
 			return true
 		}
 	}
@@ -925,7 +1113,7 @@ func doVSHandleHTTP(virtuals []*cisapiv1.VirtualServer, virtual *cisapiv1.Virtua
 // associated VirtualServers to create a resource config(Internal DataStructure)
 // or to update if exists already.
 func (ctlr *Controller) processVirtualServers(
-	virtual *cisapiv1.VirtualServer,
+	virtual *cisapiv2.VirtualServer,
 	isVSDeleted bool,
 ) error {
 
@@ -948,7 +1136,7 @@ func (ctlr *Controller) processVirtualServers(
 		}
 	}
 
-	var allVirtuals []*cisapiv1.VirtualServer
+	var allVirtuals []*cisapiv2.VirtualServer
 	if virtual.Spec.HostGroup != "" {
 		// grouping by hg across all namespaces
 		allVirtuals = ctlr.getAllVSFromMonitoredNamespaces()
@@ -1052,8 +1240,8 @@ func (ctlr *Controller) processVirtualServers(
 		// Delete rsCfg if no corresponding virtuals exist
 		// Delete rsCfg if it is HTTP rsCfg and the CR VirtualServer does not handle HTTPTraffic
 		if (len(virtuals) == 0) ||
-			(portStruct.protocol == HTTP && !doVSHandleHTTP(virtuals, virtual)) ||
-			(isVSDeleted && portStruct.protocol == HTTPS && !doVSUseSameHTTPSPort(virtuals, virtual)) {
+			(portStruct.protocol == HTTP && !ctlr.doVSHandleHTTP(virtuals, virtual)) ||
+			(isVSDeleted && portStruct.protocol == HTTPS && !ctlr.doVSUseSameHTTPSPort(virtuals, virtual)) {
 			var hostnames []string
 			rsMap := ctlr.resources.getPartitionResourceMap(ctlr.Partition)
 
@@ -1101,7 +1289,7 @@ func (ctlr *Controller) processVirtualServers(
 
 		for _, vrt := range virtuals {
 			passthroughVS := false
-			var tlsProf *cisapiv1.TLSProfile
+			var tlsProf *cisapiv2.TLSProfile
 			if isTLSVirtualServer(vrt) {
 				// Handle TLS configuration for VirtualServer Custom Resource
 				tlsProf = ctlr.getTLSProfileForVirtualServer(vrt, vrt.Namespace)
@@ -1142,6 +1330,10 @@ func (ctlr *Controller) processVirtualServers(
 					vrt.ObjectMeta.Name, vrt.Spec.TLSProfileName)
 			}
 
+			if ctlr.handleAS3Override(rsCfg, vrt.Namespace+"/"+vrt.Name, vrt.Spec.AS3Override) {
+				ctlr.updateVirtualServerStatus(vrt, ip, ctlr.as3OverrideErrors.status(vrt.Namespace+"/"+vrt.Name))
+			}
+
 			ctlr.updateSvcDepResources(rsName, rsCfg)
 
 			ctlr.resources.processedNativeResources[resourceRef{
@@ -1172,7 +1364,7 @@ func (ctlr *Controller) processVirtualServers(
 
 	if !processingError {
 		var hostnames []string
-		rsMap := ctlr.resources.getPartitionResourceMap(ctlr.Partition)
+		rsMap := ctlr.resources.getPartitionResourceMapForUpdate(ctlr.Partition)
 
 		// Update ltmConfig with ResourceConfigs created for the current virtuals
 		for rsName, rsCfg := range vsMap {
@@ -1184,6 +1376,9 @@ func (ctlr *Controller) processVirtualServers(
 
 		if len(hostnames) > 0 {
 			ctlr.ProcessAssociatedExternalDNS(hostnames)
+		} else {
+			// The hosts of the existing virtuals may have changed
+			ctlr.syncAutoWideIPs()
 		}
 	}
 
@@ -1191,8 +1386,8 @@ func (ctlr *Controller) processVirtualServers(
 }
 
 // getEffectiveHTTPPort returns the final HTTP port considered for virtual server
-func getEffectiveHTTPSPort(vrt *cisapiv1.VirtualServer) int32 {
-	effectiveHTTPSPort := DEFAULT_HTTPS_PORT
+func (ctlr *Controller) getEffectiveHTTPSPort(vrt *cisapiv2.VirtualServer) int32 {
+	effectiveHTTPSPort := ctlr.defaultHTTPSPort()
 	if vrt.Spec.VirtualServerHTTPSPort != 0 {
 		effectiveHTTPSPort = vrt.Spec.VirtualServerHTTPSPort
 	}
@@ -1200,8 +1395,8 @@ func getEffectiveHTTPSPort(vrt *cisapiv1.VirtualServer) int32 {
 }
 
 // getEffectiveHTTPPort returns the final HTTP port considered for virtual server
-func getEffectiveHTTPPort(vrt *cisapiv1.VirtualServer) int32 {
-	effectiveHTTPPort := DEFAULT_HTTP_PORT
+func (ctlr *Controller) getEffectiveHTTPPort(vrt *cisapiv2.VirtualServer) int32 {
+	effectiveHTTPPort := ctlr.defaultHTTPPort()
 	if vrt.Spec.VirtualServerHTTPPort != 0 {
 		effectiveHTTPPort = vrt.Spec.VirtualServerHTTPPort
 	}
@@ -1209,10 +1404,10 @@ func getEffectiveHTTPPort(vrt *cisapiv1.VirtualServer) int32 {
 }
 
 func (ctlr *Controller) getAssociatedVirtualServers(
-	currentVS *cisapiv1.VirtualServer,
-	allVirtuals []*cisapiv1.VirtualServer,
+	currentVS *cisapiv2.VirtualServer,
+	allVirtuals []*cisapiv2.VirtualServer,
 	isVSDeleted bool,
-) []*cisapiv1.VirtualServer {
+) []*cisapiv2.VirtualServer {
 	// Associated VirutalServers are grouped based on "hostGroup" parameter
 	// if hostGroup parameter is not available, they will be grouped on "host" parameter
 	// if "host" parameter is not available, they will be grouped on "VirtualServerAddress"
@@ -1230,7 +1425,7 @@ func (ctlr *Controller) getAssociatedVirtualServers(
 	// If one (or multiple) of the above parameters are specified in wrong manner in any VirtualServer,
 	// that particular VirtualServer will be skipped.
 
-	var virtuals []*cisapiv1.VirtualServer
+	var virtuals []*cisapiv2.VirtualServer
 	// {hostname: {path: <empty_struct>}}
 	uniqueHostPathMap := make(map[string]map[string]struct{})
 
@@ -1275,7 +1470,7 @@ func (ctlr *Controller) getAssociatedVirtualServers(
 		}
 
 		// skip the virtuals with different custom HTTP/HTTPS ports
-		if skipVirtual(currentVS, vrt) {
+		if ctlr.skipVirtual(currentVS, vrt) {
 			continue
 		}
 
@@ -1303,7 +1498,7 @@ func (ctlr *Controller) getAssociatedVirtualServers(
 	return virtuals
 }
 
-func (ctlr *Controller) getPolicyFromVirtuals(virtuals []*cisapiv1.VirtualServer) (*cisapiv1.Policy, error) {
+func (ctlr *Controller) getPolicyFromVirtuals(virtuals []*cisapiv2.VirtualServer) (*cisapiv2.Policy, error) {
 
 	if len(virtuals) == 0 {
 		log.Errorf("No virtuals to extract policy from")
@@ -1321,7 +1516,17 @@ func (ctlr *Controller) getPolicyFromVirtuals(virtuals []*cisapiv1.VirtualServer
 		}
 	}
 	if plcName == "" {
-		return nil, nil
+		var selected *cisapiv2.Policy
+		for _, vrt := range virtuals {
+			plc := ctlr.getSelectorPolicy(vrt.Namespace, vrt.Labels)
+			if plc != nil && (selected == nil || policyHasPrecedence(plc, selected, ns)) {
+				selected = plc
+			}
+		}
+		if selected == nil {
+			selected = ctlr.getDefaultPolicy()
+		}
+		return selected, nil
 	}
 	crInf, ok := ctlr.getNamespacedCommonInformer(ns)
 	if !ok {
@@ -1339,10 +1544,10 @@ func (ctlr *Controller) getPolicyFromVirtuals(virtuals []*cisapiv1.VirtualServer
 		return nil, fmt.Errorf("Policy Not Found: %v", key)
 	}
 
-	return obj.(*cisapiv1.Policy), nil
+	return obj.(*cisapiv2.Policy), nil
 }
 
-func (ctlr *Controller) getPolicyFromTransportServer(virtual *cisapiv1.TransportServer) (*cisapiv1.Policy, error) {
+func (ctlr *Controller) getPolicyFromTransportServer(virtual *cisapiv2.TransportServer) (*cisapiv2.Policy, error) {
 
 	if virtual == nil {
 		log.Errorf("No virtuals to extract policy from")
@@ -1351,14 +1556,14 @@ func (ctlr *Controller) getPolicyFromTransportServer(virtual *cisapiv1.Transport
 
 	plcName := virtual.Spec.PolicyName
 	if plcName == "" {
-		return nil, nil
+		return ctlr.getSelectedPolicy(virtual.Namespace, virtual.Labels), nil
 	}
 	ns := virtual.Namespace
 	return ctlr.getPolicy(ns, plcName)
 }
 
 // getPolicy fetches the policy CR
-func (ctlr *Controller) getPolicy(ns string, plcName string) (*cisapiv1.Policy, error) {
+func (ctlr *Controller) getPolicy(ns string, plcName string) (*cisapiv2.Policy, error) {
 	crInf, ok := ctlr.getNamespacedCommonInformer(ns)
 	if !ok {
 		log.Errorf("Informer not found for namespace: %v", ns)
@@ -1377,10 +1582,157 @@ func (ctlr *Controller) getPolicy(ns string, plcName string) (*cisapiv1.Policy,
 		log.Errorf("Policy Not Found: %v", key)
 		return nil, fmt.Errorf("Policy Not Found: %v", key)
 	}
-	return obj.(*cisapiv1.Policy), nil
+	return obj.(*cisapiv2.Policy), nil
 }
 
-func getIPAMLabel(virtuals []*cisapiv1.VirtualServer) string {
+// getSelectedPolicy returns the policy attached to a resource which does not name a policy.
+// The policy with the highest precedence selecting the resource wins, otherwise
+// the default policy applies.
+func (ctlr *Controller) getSelectedPolicy(namespace string, rscLabels map[string]string) *cisapiv2.Policy {
+	if plc := ctlr.getSelectorPolicy(namespace, rscLabels); plc != nil {
+		return plc
+	}
+	return ctlr.getDefaultPolicy()
+}
+
+// getSelectorPolicy returns the policy with the highest precedence selecting the resource
+func (ctlr *Controller) getSelectorPolicy(namespace string, rscLabels map[string]string) *cisapiv2.Policy {
+	var selected *cisapiv2.Policy
+	for _, comInf := range ctlr.comInformers {
+		if comInf.plcInformer == nil {
+			continue
+		}
+		for _, obj := range comInf.plcInformer.GetIndexer().List() {
+			plc := obj.(*cisapiv2.Policy)
+			if !ctlr.policySelects(plc, namespace, rscLabels) {
+				continue
+			}
+			if selected == nil || policyHasPrecedence(plc, selected, namespace) {
+				selected = plc
+			}
+		}
+	}
+	return selected
+}
+
+// policyHasPrecedence returns true if plc takes precedence over cur for a resource in the namespace.
+// Ties are broken in favour of the policy in the namespace of the resource, then by name.
+func policyHasPrecedence(plc, cur *cisapiv2.Policy, namespace string) bool {
+	if plc.Spec.Precedence != cur.Spec.Precedence {
+		return plc.Spec.Precedence > cur.Spec.Precedence
+	}
+	if (plc.Namespace == namespace) != (cur.Namespace == namespace) {
+		return plc.Namespace == namespace
+	}
+	return plc.Namespace+"/"+plc.Name < cur.Namespace+"/"+cur.Name
+}
+
+// policySelects returns true if the targetSelector of the policy matches the resource
+func (ctlr *Controller) policySelects(plc *cisapiv2.Policy, namespace string, rscLabels map[string]string) bool {
+	targetSelector := plc.Spec.TargetSelector
+	if targetSelector == nil {
+		return false
+	}
+	if targetSelector.NamespaceSelector == nil {
+		if plc.Namespace != namespace {
+			return false
+		}
+	} else {
+		selector, err := metav1.LabelSelectorAsSelector(targetSelector.NamespaceSelector)
+		if err != nil {
+			log.Errorf("Invalid namespaceSelector in Policy %v/%v: %v", plc.Namespace, plc.Name, err)
+			return false
+		}
+		nsLabels, found := ctlr.getNamespaceLabels(namespace)
+		if !found || !selector.Matches(labels.Set(nsLabels)) {
+			return false
+		}
+	}
+	if targetSelector.ObjectSelector == nil {
+		return true
+	}
+	selector, err := metav1.LabelSelectorAsSelector(targetSelector.ObjectSelector)
+	if err != nil {
+		log.Errorf("Invalid objectSelector in Policy %v/%v: %v", plc.Namespace, plc.Name, err)
+		return false
+	}
+	return selector.Matches(labels.Set(rscLabels))
+}
+
+// getNamespaceLabels returns the labels of the namespace from the namespace informers
+// when the namespaces are watched by their label, or else from the namespace labels informer
+func (ctlr *Controller) getNamespaceLabels(namespace string) (map[string]string, bool) {
+	if len(ctlr.nsInformers) != 0 {
+		for _, nsInf := range ctlr.nsInformers {
+			if obj, found, _ := nsInf.nsInformer.GetIndexer().GetByKey(namespace); found {
+				return obj.(*v1.Namespace).Labels, true
+			}
+		}
+		log.Debugf("Namespace %v not found", namespace)
+		return nil, false
+	}
+	nsLabelInformer := ctlr.getNamespaceLabelsInformer()
+	if nsLabelInformer == nil {
+		return nil, false
+	}
+	obj, found, _ := nsLabelInformer.nsInformer.GetIndexer().GetByKey(namespace)
+	if !found {
+		log.Debugf("Namespace %v not found", namespace)
+		return nil, false
+	}
+	return obj.(*v1.Namespace).Labels, true
+}
+
+// getNamespaceLabelsInformer returns the namespace labels informer. As it requires to
+// list and watch all the namespaces, it is only created once a Policy selects the
+// namespaces by their labels, and waited to sync before its labels are matched
+func (ctlr *Controller) getNamespaceLabelsInformer() *NSInformer {
+	ctlr.nsLabelInformerLock.Lock()
+	defer ctlr.nsLabelInformerLock.Unlock()
+	if ctlr.nsLabelInformer == nil {
+		if ctlr.kubeClient == nil {
+			return nil
+		}
+		ctlr.createNamespaceLabelsInformer()
+		ctlr.nsLabelInformer.start()
+		timeoutCh := make(chan struct{})
+		timer := time.AfterFunc(nsLabelInformerSyncTimeout, func() { close(timeoutCh) })
+		defer timer.Stop()
+		if !cache.WaitForCacheSync(timeoutCh, ctlr.nsLabelInformer.nsInformer.HasSynced) {
+			log.Errorf("Namespace labels informer did not sync in %v, ensure CIS can list and watch the namespaces",
+				nsLabelInformerSyncTimeout)
+		}
+	}
+	return ctlr.nsLabelInformer
+}
+
+// getDefaultPolicy fetches the policy of the DeployConfig applied to the
+// resources without any other policy
+func (ctlr *Controller) getDefaultPolicy() *cisapiv2.Policy {
+	defaultPolicy := ctlr.deployConfigSpec.DefaultPolicy
+	if defaultPolicy == "" {
+		return nil
+	}
+	ns := strings.Split(defaultPolicy, "/")[0]
+	comInf, ok := ctlr.getNamespacedCommonInformer(ns)
+	if !ok {
+		log.Debugf("Informer not found for namespace of default Policy: %v", defaultPolicy)
+		return nil
+	}
+	obj, exist, err := comInf.plcInformer.GetIndexer().GetByKey(defaultPolicy)
+	if err != nil || !exist {
+		log.Debugf("Default Policy %v not found", defaultPolicy)
+		return nil
+	}
+	return obj.(*cisapiv2.Policy)
+}
+
+func (ctlr *Controller) isDefaultPolicy(plc *cisapiv2.Policy) bool {
+	defaultPolicy := ctlr.deployConfigSpec.DefaultPolicy
+	return defaultPolicy != "" && defaultPolicy == plc.Namespace+"/"+plc.Name
+}
+
+func getIPAMLabel(virtuals []*cisapiv2.VirtualServer) string {
 	for _, vrt := range virtuals {
 		if vrt.Spec.IPAMLabel != "" {
 			return vrt.Spec.IPAMLabel
@@ -1389,7 +1741,7 @@ func getIPAMLabel(virtuals []*cisapiv1.VirtualServer) string {
 	return ""
 }
 
-func getVirtualServerAddress(virtuals []*cisapiv1.VirtualServer) (string, error) {
+func getVirtualServerAddress(virtuals []*cisapiv2.VirtualServer) (string, error) {
 	vsa := ""
 	for _, vrt := range virtuals {
 		if vrt.Spec.VirtualServerAddress != "" {
@@ -1406,18 +1758,24 @@ func getVirtualServerAddress(virtuals []*cisapiv1.VirtualServer) (string, error)
 	return vsa, nil
 }
 
+// getIPAMCR returns a copy of the IPAM CR of the workers, which is read from
+// the API server only the first time
 func (ctlr *Controller) getIPAMCR() *ficV1.IPAM {
 	cr := strings.Split(ctlr.ipamCR, "/")
 	if len(cr) != 2 {
 		log.Errorf("[ipam] error while retrieving IPAM namespace and name.")
 		return nil
 	}
+	if ipamCR := ctlr.resources.ipamCR; ipamCR != nil && ipamCR.Namespace == cr[0] && ipamCR.Name == cr[1] {
+		return ipamCR.DeepCopy()
+	}
 	ipamCR, err := ctlr.ipamCli.Get(cr[0], cr[1])
 	if err != nil {
 		log.Errorf("[ipam] error while retrieving IPAM custom resource.")
 		return nil
 	}
-	return ipamCR
+	ctlr.resources.ipamCR = ipamCR
+	return ipamCR.DeepCopy()
 }
 
 func (ctlr *Controller) migrateIPAM() {
@@ -1556,13 +1914,7 @@ func (ctlr *Controller) requestIP(ipamLabel string, host string, key string) (st
 		return "", InvalidInput
 	}
 
-	_, err := ctlr.ipamCli.Update(ipamCR)
-	if err != nil {
-		log.Errorf("[ipam] Error updating IPAM CR : %v", err)
-		return "", NotRequested
-	}
-
-	log.Debugf("[ipam] Updated IPAM CR.")
+	ctlr.updateIPAMCR(ipamCR)
 	return "", Requested
 
 }
@@ -1595,7 +1947,8 @@ func (ctlr *Controller) RemoveIPAMCRHostSpec(ipamCR *ficV1.IPAM, key string, ind
 		delete(ctlr.resources.ipamContext, key)
 		ipamCR.Spec.HostSpecs = append(ipamCR.Spec.HostSpecs[:index], ipamCR.Spec.HostSpecs[index+1:]...)
 		ipamCR.SetResourceVersion(ipamCR.ResourceVersion)
-		return ctlr.ipamCli.Update(ipamCR)
+		ctlr.updateIPAMCR(ipamCR)
+		return ipamCR, nil
 	}
 	return res, err
 }
@@ -1683,7 +2036,7 @@ func (ctlr *Controller) updatePoolMembersForNodePort(
 
 		poolMemInfo, ok := ctlr.resources.poolMemCache[svcKey]
 		if (!ok || len(poolMemInfo.memberMap) == 0) && pool.ServiceNamespace == namespace {
-			rsCfg.Pools[index].Members = []PoolMember{}
+			rsCfg.Pools[index].Members = ctlr.withRemotePoolMembers(rsCfg, pool, []PoolMember{})
 			continue
 		}
 
@@ -1696,8 +2049,8 @@ func (ctlr *Controller) updatePoolMembersForNodePort(
 		for _, svcPort := range poolMemInfo.portSpec {
 			if svcPort.TargetPort == pool.ServicePort {
 				rsCfg.MetaData.Active = true
-				rsCfg.Pools[index].Members =
-					ctlr.getEndpointsForNodePort(svcPort.NodePort, pool.NodeMemberLabel)
+				rsCfg.Pools[index].Members = ctlr.withRemotePoolMembers(rsCfg, pool,
+					ctlr.getEndpointsForNodePort(svcPort.NodePort, pool.NodeMemberLabel, poolMemInfo.localNodes))
 			}
 		}
 		//check if endpoints are found
@@ -1720,7 +2073,7 @@ func (ctlr *Controller) updatePoolMembersForCluster(
 		poolMemInfo, ok := ctlr.resources.poolMemCache[svcKey]
 
 		if (!ok || len(poolMemInfo.memberMap) == 0) && pool.ServiceNamespace == namespace {
-			rsCfg.Pools[index].Members = []PoolMember{}
+			rsCfg.Pools[index].Members = ctlr.withRemotePoolMembers(rsCfg, pool, []PoolMember{})
 			continue
 		}
 
@@ -1729,7 +2082,7 @@ func (ctlr *Controller) updatePoolMembersForCluster(
 				continue
 			}
 			rsCfg.MetaData.Active = true
-			rsCfg.Pools[index].Members = mems
+			rsCfg.Pools[index].Members = ctlr.withRemotePoolMembers(rsCfg, pool, mems)
 		}
 		//check if endpoints are found
 		if rsCfg.Pools[index].Members == nil {
@@ -1775,9 +2128,12 @@ func (ctlr *Controller) updatePoolMembersForNPL(
 }
 
 // getEndpointsForNodePort returns members.
+// If localNodes is not nil, only those nodes are members, as the services
+// with externalTrafficPolicy Local are only served by the nodes with endpoints
 func (ctlr *Controller) getEndpointsForNodePort(
 	nodePort int32,
 	nodeMemberLabel string,
+	localNodes map[string]struct{},
 ) []PoolMember {
 	var nodes []Node
 	if nodeMemberLabel == "" {
@@ -1787,11 +2143,20 @@ func (ctlr *Controller) getEndpointsForNodePort(
 	}
 	var members []PoolMember
 	for _, v := range nodes {
+		if localNodes != nil {
+			if _, ok := localNodes[v.Name]; !ok {
+				continue
+			}
+		}
 		member := PoolMember{
 			Address: v.Addr,
 			Port:    nodePort,
 			Session: "user-enabled",
 		}
+		if v.Draining {
+			// Only the existing connections are served by a draining node
+			member.AdminState = "disable"
+		}
 		members = append(members, member)
 	}
 
@@ -1854,7 +2219,7 @@ func containsNode(nodes []Node, name string) bool {
 // associated TransportServers to create a resource config(Internal DataStructure)
 // or to update if exists already.
 func (ctlr *Controller) processTransportServers(
-	virtual *cisapiv1.TransportServer,
+	virtual *cisapiv2.TransportServer,
 	isTSDeleted bool,
 ) error {
 	startTime := time.Now()
@@ -1981,6 +2346,11 @@ func (ctlr *Controller) processTransportServers(
 		return nil
 	}
 
+	rscKey := virtual.ObjectMeta.Namespace + "/" + virtual.ObjectMeta.Name
+	if ctlr.handleAS3Override(rsCfg, rscKey, virtual.Spec.AS3Override) {
+		ctlr.updateTransportServerStatus(virtual, ip, ctlr.as3OverrideErrors.status(rscKey))
+	}
+
 	ctlr.updateSvcDepResources(rsName, rsCfg)
 
 	if ctlr.PoolMemberType == NodePort {
@@ -1989,15 +2359,15 @@ func (ctlr *Controller) processTransportServers(
 		ctlr.updatePoolMembersForCluster(rsCfg, virtual.ObjectMeta.Namespace)
 	}
 
-	rsMap := ctlr.resources.getPartitionResourceMap(ctlr.Partition)
+	rsMap := ctlr.resources.getPartitionResourceMapForUpdate(ctlr.Partition)
 	rsMap[rsName] = rsCfg
 
 	return nil
 }
 
 // getAllTSFromMonitoredNamespaces returns list of all valid TransportServers in monitored namespaces.
-func (ctlr *Controller) getAllTSFromMonitoredNamespaces() []*cisapiv1.TransportServer {
-	var allVirtuals []*cisapiv1.TransportServer
+func (ctlr *Controller) getAllTSFromMonitoredNamespaces() []*cisapiv2.TransportServer {
+	var allVirtuals []*cisapiv2.TransportServer
 	if ctlr.watchingAllNamespaces() {
 		return ctlr.getAllTransportServers("")
 	}
@@ -2008,8 +2378,8 @@ func (ctlr *Controller) getAllTSFromMonitoredNamespaces() []*cisapiv1.TransportS
 }
 
 // getAllTransportServers returns list of all valid TransportServers in rkey namespace.
-func (ctlr *Controller) getAllTransportServers(namespace string) []*cisapiv1.TransportServer {
-	var allVirtuals []*cisapiv1.TransportServer
+func (ctlr *Controller) getAllTransportServers(namespace string) []*cisapiv2.TransportServer {
+	var allVirtuals []*cisapiv2.TransportServer
 
 	crInf, ok := ctlr.getNamespacedCRInformer(namespace)
 	if !ok {
@@ -2030,7 +2400,7 @@ func (ctlr *Controller) getAllTransportServers(namespace string) []*cisapiv1.Tra
 		}
 	}
 	for _, obj := range orderedTSs {
-		vs := obj.(*cisapiv1.TransportServer)
+		vs := obj.(*cisapiv2.TransportServer)
 		// TODO Validate the TransportServers List to check if all the vs are valid.
 		allVirtuals = append(allVirtuals, vs)
 	}
@@ -2072,7 +2442,7 @@ func (ctlr *Controller) getAllLBServices(namespace string) []*v1.Service {
 
 // getTransportServersForService gets the List of VirtualServers which are effected
 // by the addition/deletion/updation of service.
-func (ctlr *Controller) getTransportServersForService(svc *v1.Service) []*cisapiv1.TransportServer {
+func (ctlr *Controller) getTransportServersForService(svc *v1.Service) []*cisapiv2.TransportServer {
 
 	allVirtuals := ctlr.getAllTransportServers(svc.ObjectMeta.Namespace)
 	if nil == allVirtuals {
@@ -2093,10 +2463,10 @@ func (ctlr *Controller) getTransportServersForService(svc *v1.Service) []*cisapi
 
 // filterTransportServersForService returns list of VirtualServers that are
 // affected by the service under process.
-func filterTransportServersForService(allVirtuals []*cisapiv1.TransportServer,
-	svc *v1.Service) []*cisapiv1.TransportServer {
+func filterTransportServersForService(allVirtuals []*cisapiv2.TransportServer,
+	svc *v1.Service) []*cisapiv2.TransportServer {
 
-	var result []*cisapiv1.TransportServer
+	var result []*cisapiv2.TransportServer
 	svcName := svc.ObjectMeta.Name
 	svcNamespace := svc.ObjectMeta.Namespace
 
@@ -2139,9 +2509,9 @@ func (ctlr *Controller) getAllServicesFromMonitoredNamespaces() []*v1.Service {
 }
 
 // Get List of VirtualServers associated with the IPAM resource
-func (ctlr *Controller) getVirtualServersForIPAM(ipam *ficV1.IPAM) []*cisapiv1.VirtualServer {
+func (ctlr *Controller) getVirtualServersForIPAM(ipam *ficV1.IPAM) []*cisapiv2.VirtualServer {
 	log.Debug("[ipam] Syncing IPAM dependent virtual servers")
-	var allVS, vss []*cisapiv1.VirtualServer
+	var allVS, vss []*cisapiv2.VirtualServer
 	allVS = ctlr.getAllVSFromMonitoredNamespaces()
 	for _, status := range ipam.Status.IPStatus {
 		for _, vs := range allVS {
@@ -2156,9 +2526,9 @@ func (ctlr *Controller) getVirtualServersForIPAM(ipam *ficV1.IPAM) []*cisapiv1.V
 }
 
 // Get List of TransportServers associated with the IPAM resource
-func (ctlr *Controller) getTransportServersForIPAM(ipam *ficV1.IPAM) []*cisapiv1.TransportServer {
+func (ctlr *Controller) getTransportServersForIPAM(ipam *ficV1.IPAM) []*cisapiv2.TransportServer {
 	log.Debug("[ipam] Syncing IPAM dependent transport servers")
-	var allTS, tss []*cisapiv1.TransportServer
+	var allTS, tss []*cisapiv2.TransportServer
 	allTS = ctlr.getAllTSFromMonitoredNamespaces()
 	for _, status := range ipam.Status.IPStatus {
 		for _, ts := range allTS {
@@ -2173,8 +2543,8 @@ func (ctlr *Controller) getTransportServersForIPAM(ipam *ficV1.IPAM) []*cisapiv1
 }
 
 // Get List of ingLink associated with the IPAM resource
-func (ctlr *Controller) getIngressLinkForIPAM(ipam *ficV1.IPAM) []*cisapiv1.IngressLink {
-	var allIngLinks, ils []*cisapiv1.IngressLink
+func (ctlr *Controller) getIngressLinkForIPAM(ipam *ficV1.IPAM) []*cisapiv2.IngressLink {
+	var allIngLinks, ils []*cisapiv2.IngressLink
 	allIngLinks = ctlr.getAllIngLinkFromMonitoredNamespaces()
 	if allIngLinks == nil {
 		return nil
@@ -2325,7 +2695,7 @@ func (ctlr *Controller) processLBServices(
 			ctlr.updatePoolMembersForCluster(rsCfg, svc.Namespace)
 		}
 
-		rsMap := ctlr.resources.getPartitionResourceMap(ctlr.Partition)
+		rsMap := ctlr.resources.getPartitionResourceMapForUpdate(ctlr.Partition)
 
 		rsMap[rsName] = rsCfg
 	}
@@ -2365,8 +2735,19 @@ func (ctlr *Controller) processService(
 		memberMap: make(map[portRef][]PoolMember),
 	}
 
+	if svc.Spec.ExternalTrafficPolicy == v1.ServiceExternalTrafficPolicyTypeLocal {
+		pmi.localNodes = make(map[string]struct{})
+	}
+
 	nodes := ctlr.getNodesFromCache()
 	for _, subset := range eps.Subsets {
+		if pmi.localNodes != nil {
+			for _, addr := range subset.Addresses {
+				if addr.NodeName != nil {
+					pmi.localNodes[*addr.NodeName] = struct{}{}
+				}
+			}
+		}
 		for _, p := range subset.Ports {
 			var members []PoolMember
 			for _, addr := range subset.Addresses {
@@ -2390,11 +2771,12 @@ func (ctlr *Controller) processService(
 	return nil
 }
 
-func (ctlr *Controller) processExternalDNS(edns *cisapiv1.ExternalDNS, isDelete bool) {
+func (ctlr *Controller) processExternalDNS(edns *cisapiv2.ExternalDNS, isDelete bool) {
 
 	if gtmPartitionConfig, ok := ctlr.resources.gtmConfig[DEFAULT_PARTITION]; ok {
 		if processedWIP, ok := gtmPartitionConfig.WideIPs[edns.Spec.DomainName]; ok {
-			if processedWIP.UID != string(edns.UID) {
+			// The ExternalDNS of a host takes precedence over the synthesized wide IP
+			if processedWIP.UID != string(edns.UID) && processedWIP.UID != autoWideIPUID {
 				log.Errorf("EDNS with same domain name %s present", edns.Spec.DomainName)
 				return
 			}
@@ -2410,6 +2792,8 @@ func (ctlr *Controller) processExternalDNS(edns *cisapiv1.ExternalDNS, isDelete
 		ctlr.TeemData.Lock()
 		ctlr.TeemData.ResourceType.ExternalDNS[edns.Namespace]--
 		ctlr.TeemData.Unlock()
+		// Synthesize the wide IP of the host again if the policy of its virtual enables it
+		ctlr.syncAutoWideIPs()
 		return
 	}
 
@@ -2417,16 +2801,38 @@ func (ctlr *Controller) processExternalDNS(edns *cisapiv1.ExternalDNS, isDelete
 	ctlr.TeemData.ResourceType.ExternalDNS[edns.Namespace] = len(ctlr.getAllExternalDNS(edns.Namespace))
 	ctlr.TeemData.Unlock()
 
-	wip := WideIP{
-		DomainName: edns.Spec.DomainName,
-		RecordType: edns.Spec.DNSRecordType,
-		LBMethod:   edns.Spec.LoadBalanceMethod,
-		UID:        string(edns.UID),
+	wip := ctlr.newWideIP(edns)
+	if wip == nil {
+		return
+	}
+	if _, ok := ctlr.resources.gtmConfig[DEFAULT_PARTITION]; !ok {
+		ctlr.resources.gtmConfig[DEFAULT_PARTITION] = GTMPartitionConfig{
+			WideIPs: make(map[string]WideIP),
+		}
+	}
+
+	ctlr.resources.gtmConfig[DEFAULT_PARTITION].WideIPs[wip.DomainName] = *wip
+}
+
+// newWideIP builds the wide IP of an ExternalDNS with the virtuals of its domain
+func (ctlr *Controller) newWideIP(edns *cisapiv2.ExternalDNS) *WideIP {
+	wip := &WideIP{
+		DomainName:     edns.Spec.DomainName,
+		RecordType:     edns.Spec.DNSRecordType,
+		LBMethod:       edns.Spec.LoadBalanceMethod,
+		Persistence:    edns.Spec.Persistence,
+		LastResortPool: edns.Spec.LastResortPool,
+		UID:            string(edns.UID),
 	}
 
 	if edns.Spec.DNSRecordType == "" {
 		wip.RecordType = "A"
 	}
+	if ctlr.Agent.ccclGTMAgent && wip.RecordType != "A" {
+		log.Errorf("DNS record type %v of ExternalDNS %v/%v is supported only with the AS3 GTM agent",
+			wip.RecordType, edns.Namespace, edns.Name)
+		return nil
+	}
 	if edns.Spec.LoadBalanceMethod == "" {
 		wip.LBMethod = "round-robin"
 	}
@@ -2442,7 +2848,12 @@ func (ctlr *Controller) processExternalDNS(edns *cisapiv1.ExternalDNS, isDelete
 	}
 
 	for _, pl := range edns.Spec.Pools {
-		UniquePoolName := edns.Spec.DomainName + "_" + AS3NameFormatter(strings.TrimPrefix(ctlr.Agent.BIGIPURL, "https://")) + "_" + ctlr.Partition
+		// Pools of other data centers are named after their data center
+		site := AS3NameFormatter(strings.TrimPrefix(ctlr.Agent.BIGIPURL, "https://"))
+		if pl.DataCenter != "" {
+			site = AS3NameFormatter(pl.DataCenter)
+		}
+		UniquePoolName := gtmDomainName(edns.Spec.DomainName) + "_" + site + "_" + ctlr.Partition
 		log.Debugf("Processing WideIP Pool: %v", UniquePoolName)
 		pool := GSLBPool{
 			Name:          UniquePoolName,
@@ -2450,41 +2861,61 @@ func (ctlr *Controller) processExternalDNS(edns *cisapiv1.ExternalDNS, isDelete
 			LBMethod:      pl.LoadBalanceMethod,
 			PriorityOrder: pl.PriorityOrder,
 			DataServer:    pl.DataServerName,
+			FallbackIP:    pl.FallbackIP,
+			TTL:           pl.TTL,
 		}
 
 		if pl.DNSRecordType == "" {
-			pool.RecordType = "A"
+			pool.RecordType = wip.RecordType
 		}
 		if pl.LoadBalanceMethod == "" {
 			pool.LBMethod = "round-robin"
 		}
+		if pool.RecordType == "CNAME" || pool.RecordType == "MX" {
+			// CNAME and MX pools answer with domain names instead of virtuals
+			pool.Targets = pl.Targets
+			wip.Pools = append(wip.Pools, pool)
+			continue
+		}
+		preGTMServerName := ""
+		if ctlr.Agent.ccclGTMAgent {
+			preGTMServerName = fmt.Sprintf("%v:", pl.DataServerName)
+		}
+		// The virtuals of other CIS instances are not known locally
+		for _, vsPath := range pl.VirtualServers {
+			log.Debugf("Adding WideIP Pool Member: %v", vsPath)
+			pool.Members = append(pool.Members, preGTMServerName+vsPath)
+		}
 		for _, partition := range partitions {
+			if len(pl.VirtualServers) > 0 {
+				break
+			}
 			rsMap := ctlr.resources.getPartitionResourceMap(partition)
 
 			for vsName, vs := range rsMap {
 				var found bool
 				for _, host := range vs.MetaData.hosts {
-					if host == edns.Spec.DomainName {
+					if domainMatchesHost(edns.Spec.DomainName, host) {
 						found = true
 						break
 					}
 				}
+				if found && isIPv6Virtual(vs) != (pool.RecordType == "AAAA") {
+					// A pools answer with the IPv4 virtuals and AAAA pools with the IPv6 ones
+					found = false
+				}
 				if found {
 					//No need to add insecure VS into wideIP pool if VS configured with httpTraffic as redirect
 					if vs.MetaData.Protocol == "http" && (vs.MetaData.httpTraffic == TLSRedirectInsecure || vs.MetaData.httpTraffic == TLSAllowInsecure) {
 						continue
 					}
-					preGTMServerName := ""
-					if ctlr.Agent.ccclGTMAgent {
-						preGTMServerName = fmt.Sprintf("%v:", pl.DataServerName)
-					}
 					// add only one VS member to pool.
 					if len(pool.Members) > 0 && strings.HasPrefix(vsName, "ingress_link_") {
 						if strings.HasSuffix(vsName, "_443") {
 							pool.Members[0] = fmt.Sprintf("%v/%v/Shared/%v", preGTMServerName, partition, vsName)
 							if partition != ctlr.Partition {
 								// Modify pool name to partition containing VS
-								pool.Name = edns.Spec.DomainName + "_" + AS3NameFormatter(strings.TrimPrefix(ctlr.Agent.BIGIPURL, "https://")) + "_" + partition
+								pool.Name = gtmDomainName(edns.Spec.DomainName) + "_" + site + "_" + partition
 							}
 						}
 						continue
@@ -2494,7 +2925,7 @@ func (ctlr *Controller) processExternalDNS(edns *cisapiv1.ExternalDNS, isDelete
 					// Modify pool name to partition containing VS
 					if partition != ctlr.Partition {
 						// Modify pool name to partition containing VS
-						pool.Name = edns.Spec.DomainName + "_" + AS3NameFormatter(strings.TrimPrefix(ctlr.Agent.BIGIPURL, "https://")) + "_" + partition
+						pool.Name = gtmDomainName(edns.Spec.DomainName) + "_" + site + "_" + partition
 					}
 					pool.Members = append(
 						pool.Members,
@@ -2508,57 +2939,92 @@ func (ctlr *Controller) processExternalDNS(edns *cisapiv1.ExternalDNS, isDelete
 			for i, monitor := range pl.Monitors {
 				monitors = append(monitors,
 					Monitor{
-						Name:      fmt.Sprintf("%s_monitor%d", UniquePoolName, i),
-						Partition: "Common",
-						Type:      monitor.Type,
-						Interval:  monitor.Interval,
-						Send:      monitor.Send,
-						Recv:      monitor.Recv,
-						Timeout:   monitor.Timeout})
-			}
-			pool.Monitors = monitors
-
-		} else if pl.Monitor.Type != "" {
-			// TODO: Need to change to DEFAULT_PARTITION from Common, once Agent starts to support DEFAULT_PARTITION
-			var monitors []Monitor
-
-			if pl.Monitor.Type == "http" || pl.Monitor.Type == "https" {
-				monitors = append(monitors,
-					Monitor{
-						Name:      UniquePoolName + "_monitor",
-						Partition: "Common",
-						Type:      pl.Monitor.Type,
-						Interval:  pl.Monitor.Interval,
-						Send:      pl.Monitor.Send,
-						Recv:      pl.Monitor.Recv,
-						Timeout:   pl.Monitor.Timeout,
-					})
-			} else {
-				monitors = append(monitors,
-					Monitor{
-						Name:      UniquePoolName + "_monitor",
-						Partition: "Common",
-						Type:      pl.Monitor.Type,
-						Interval:  pl.Monitor.Interval,
-						Timeout:   pl.Monitor.Timeout,
-					})
+						Name:       fmt.Sprintf("%s_monitor%d", UniquePoolName, i),
+						Partition:  "Common",
+						Type:       monitor.Type,
+						Interval:   monitor.Interval,
+						Send:       monitor.Send,
+						Recv:       monitor.Recv,
+						Timeout:    monitor.Timeout,
+						TargetPort: monitor.TargetPort})
 			}
 			pool.Monitors = monitors
 		}
 		wip.Pools = append(wip.Pools, pool)
 	}
-	if _, ok := ctlr.resources.gtmConfig[DEFAULT_PARTITION]; !ok {
-		ctlr.resources.gtmConfig[DEFAULT_PARTITION] = GTMPartitionConfig{
-			WideIPs: make(map[string]WideIP),
+	// global-availability answers with the pools in their order
+	sort.SliceStable(wip.Pools, func(i, j int) bool {
+		return wip.Pools[i].PriorityOrder < wip.Pools[j].PriorityOrder
+	})
+	return wip
+}
+
+// syncAutoWideIPs synthesizes a wide IP for each host of the virtuals whose
+// policy enables autoExternalDNS, and deletes the wide IPs of the hosts gone
+func (ctlr *Controller) syncAutoWideIPs() {
+	autoEDNS := make(map[string]*cisapiv2.AutoExternalDNSSpec)
+	partitions := ctlr.resources.GetLTMPartitions()
+	sort.Strings(partitions)
+	for _, partition := range partitions {
+		rsMap := ctlr.resources.getPartitionResourceMap(partition)
+		rsNames := make([]string, 0, len(rsMap))
+		for rsName := range rsMap {
+			rsNames = append(rsNames, rsName)
+		}
+		// The first virtual of a host decides its wide IP
+		sort.Strings(rsNames)
+		for _, rsName := range rsNames {
+			rsCfg := rsMap[rsName]
+			if rsCfg.MetaData.autoExternalDNS == nil {
+				continue
+			}
+			for _, host := range rsCfg.MetaData.hosts {
+				if _, ok := autoEDNS[host]; !ok && host != "" {
+					autoEDNS[host] = rsCfg.MetaData.autoExternalDNS
+				}
+			}
 		}
 	}
 
-	ctlr.resources.gtmConfig[DEFAULT_PARTITION].WideIPs[wip.DomainName] = wip
-	return
+	gtmPartitionConfig, ok := ctlr.resources.gtmConfig[DEFAULT_PARTITION]
+	if !ok {
+		if len(autoEDNS) == 0 {
+			return
+		}
+		gtmPartitionConfig = GTMPartitionConfig{WideIPs: make(map[string]WideIP)}
+		ctlr.resources.gtmConfig[DEFAULT_PARTITION] = gtmPartitionConfig
+	}
+	for domainName, wip := range gtmPartitionConfig.WideIPs {
+		if _, ok := autoEDNS[domainName]; !ok && wip.UID == autoWideIPUID {
+			log.Debugf("Deleting the synthesized WideIP: %v", domainName)
+			delete(gtmPartitionConfig.WideIPs, domainName)
+		}
+	}
+	for host, spec := range autoEDNS {
+		if wip, ok := gtmPartitionConfig.WideIPs[host]; ok && wip.UID != autoWideIPUID {
+			// Declared by an ExternalDNS
+			continue
+		}
+		edns := &cisapiv2.ExternalDNS{
+			ObjectMeta: metav1.ObjectMeta{Name: host},
+			Spec: cisapiv2.ExternalDNSSpec{
+				DomainName:        host,
+				LoadBalanceMethod: spec.LoadBalanceMethod,
+				Pools: []cisapiv2.DNSPool{{
+					DataServerName: spec.DataServerName,
+					Monitors:       spec.Monitors,
+				}},
+			},
+		}
+		if wip := ctlr.newWideIP(edns); wip != nil {
+			wip.UID = autoWideIPUID
+			gtmPartitionConfig.WideIPs[host] = *wip
+		}
+	}
 }
 
-func (ctlr *Controller) getAllExternalDNS(namespace string) []*cisapiv1.ExternalDNS {
-	var allEDNS []*cisapiv1.ExternalDNS
+func (ctlr *Controller) getAllExternalDNS(namespace string) []*cisapiv2.ExternalDNS {
+	var allEDNS []*cisapiv2.ExternalDNS
 	comInf, ok := ctlr.getNamespacedCommonInformer(namespace)
 	if !ok {
 		log.Errorf("Informer not found for namespace: %v", namespace)
@@ -2579,7 +3045,7 @@ func (ctlr *Controller) getAllExternalDNS(namespace string) []*cisapiv1.External
 	}
 
 	for _, obj := range orderedEDNSs {
-		edns := obj.(*cisapiv1.ExternalDNS)
+		edns := obj.(*cisapiv2.ExternalDNS)
 		allEDNS = append(allEDNS, edns)
 	}
 
@@ -2601,7 +3067,7 @@ func (ctlr *Controller) ProcessRouteEDNS(hosts []string) {
 }
 
 func (ctlr *Controller) ProcessAssociatedExternalDNS(hostnames []string) {
-	var allEDNS []*cisapiv1.ExternalDNS
+	var allEDNS []*cisapiv2.ExternalDNS
 	if ctlr.watchingAllNamespaces() {
 		allEDNS = ctlr.getAllExternalDNS("")
 	} else {
@@ -2611,11 +3077,13 @@ func (ctlr *Controller) ProcessAssociatedExternalDNS(hostnames []string) {
 	}
 	for _, edns := range allEDNS {
 		for _, hostname := range hostnames {
-			if edns.Spec.DomainName == hostname {
+			if domainMatchesHost(edns.Spec.DomainName, hostname) {
 				ctlr.processExternalDNS(edns, false)
+				break
 			}
 		}
 	}
+	ctlr.syncAutoWideIPs()
 }
 
 // Validate certificate hostname
@@ -2640,9 +3108,14 @@ func checkCertificateHost(host string, certificate []byte, key []byte) bool {
 func (ctlr *Controller) processIPAM(ipam *ficV1.IPAM) error {
 	var keysToProcess []string
 
+	// The IPAM controller updates the status, the spec is updated by the workers
+	if ipamCR := ctlr.resources.ipamCR; ipamCR != nil && ipamCR.Namespace == ipam.Namespace && ipamCR.Name == ipam.Name {
+		ipamCR.Status = *ipam.Status.DeepCopy()
+		ipamCR.SetResourceVersion(ipam.ResourceVersion)
+	}
+
 	if ctlr.ipamHostSpecEmpty {
-		ipamRes, _ := ctlr.ipamCli.Get(ipam.Namespace, ipam.Name)
-		if len(ipamRes.Spec.HostSpecs) > 0 {
+		if ipamRes := ctlr.getIPAMCR(); ipamRes != nil && len(ipamRes.Spec.HostSpecs) > 0 {
 			ctlr.ipamHostSpecEmpty = false
 		}
 	}
@@ -2684,7 +3157,7 @@ func (ctlr *Controller) processIPAM(ipam *ficV1.IPAM) error {
 		switch rscKind {
 		case "hg":
 			// For Virtual Server
-			var vss []*cisapiv1.VirtualServer
+			var vss []*cisapiv2.VirtualServer
 			vss = ctlr.getAllVSFromMonitoredNamespaces()
 			for _, vs := range vss {
 				key := vs.Spec.HostGroup + "_hg"
@@ -2700,7 +3173,7 @@ func (ctlr *Controller) processIPAM(ipam *ficV1.IPAM) error {
 				}
 			}
 			// For Transport Server
-			var tss []*cisapiv1.TransportServer
+			var tss []*cisapiv2.TransportServer
 			tss = ctlr.getAllTSFromMonitoredNamespaces()
 			for _, ts := range tss {
 				key := ts.Spec.HostGroup + "_hg"
@@ -2716,7 +3189,7 @@ func (ctlr *Controller) processIPAM(ipam *ficV1.IPAM) error {
 				}
 			}
 		case "host":
-			var vss []*cisapiv1.VirtualServer
+			var vss []*cisapiv2.VirtualServer
 			vss = ctlr.getAllVirtualServers(ns)
 			for _, vs := range vss {
 				key := vs.Namespace + "/" + vs.Spec.Host + "_host"
@@ -2740,7 +3213,7 @@ func (ctlr *Controller) processIPAM(ipam *ficV1.IPAM) error {
 			ctlr.TeemData.Lock()
 			ctlr.TeemData.ResourceType.IPAMTS[ns]++
 			ctlr.TeemData.Unlock()
-			ts := item.(*cisapiv1.TransportServer)
+			ts := item.(*cisapiv2.TransportServer)
 			err = ctlr.processTransportServers(ts, false)
 			if err != nil {
 				log.Errorf("Unable to process IPAM entry: %v", pKey)
@@ -2751,7 +3224,7 @@ func (ctlr *Controller) processIPAM(ipam *ficV1.IPAM) error {
 				log.Errorf("Unable to process IPAM entry: %v", pKey)
 				continue
 			}
-			il := item.(*cisapiv1.IngressLink)
+			il := item.(*cisapiv2.IngressLink)
 			err = ctlr.processIngressLink(il, false)
 			if err != nil {
 				log.Errorf("Unable to process IPAM entry: %v", pKey)
@@ -2779,7 +3252,7 @@ func (ctlr *Controller) processIPAM(ipam *ficV1.IPAM) error {
 }
 
 func (ctlr *Controller) processIngressLink(
-	ingLink *cisapiv1.IngressLink,
+	ingLink *cisapiv2.IngressLink,
 	isILDeleted bool,
 ) error {
 
@@ -2899,7 +3372,7 @@ func (ctlr *Controller) processIngressLink(
 		}
 	}
 
-	rsMap := ctlr.resources.getPartitionResourceMap(ctlr.Partition)
+	rsMap := ctlr.resources.getPartitionResourceMapForUpdate(ctlr.Partition)
 	for _, port := range svc.Spec.Ports {
 		//for nginx health monitor port skip vs creation
 		if port.Port == nginxMonitorPort {
@@ -2920,7 +3393,7 @@ func (ctlr *Controller) processIngressLink(
 		rsCfg.Virtual.Source = "0.0.0.0/0"
 		rsCfg.Virtual.Enabled = true
 		rsCfg.Virtual.Name = rsName
-		rsCfg.Virtual.SNAT = DEFAULT_SNAT
+		rsCfg.Virtual.SNAT = ctlr.defaultSNAT()
 		if len(ingLink.Spec.IRules) > 0 {
 			rsCfg.Virtual.IRules = ingLink.Spec.IRules
 		}
@@ -2973,8 +3446,8 @@ func (ctlr *Controller) processIngressLink(
 	return nil
 }
 
-func (ctlr *Controller) getAllIngressLinks(namespace string) []*cisapiv1.IngressLink {
-	var allIngLinks []*cisapiv1.IngressLink
+func (ctlr *Controller) getAllIngressLinks(namespace string) []*cisapiv2.IngressLink {
+	var allIngLinks []*cisapiv2.IngressLink
 
 	crInf, ok := ctlr.getNamespacedCRInformer(namespace)
 	if !ok {
@@ -2995,7 +3468,7 @@ func (ctlr *Controller) getAllIngressLinks(namespace string) []*cisapiv1.Ingress
 		}
 	}
 	for _, obj := range orderedIngLinks {
-		ingLink := obj.(*cisapiv1.IngressLink)
+		ingLink := obj.(*cisapiv2.IngressLink)
 		// TODO
 		// Validate the IngressLink List to check if all the vs are valid.
 
@@ -3006,7 +3479,7 @@ func (ctlr *Controller) getAllIngressLinks(namespace string) []*cisapiv1.Ingress
 
 // getIngressLinksForService gets the List of ingressLink which are effected
 // by the addition/deletion/updation of service.
-func (ctlr *Controller) getIngressLinksForService(svc *v1.Service) []*cisapiv1.IngressLink {
+func (ctlr *Controller) getIngressLinksForService(svc *v1.Service) []*cisapiv2.IngressLink {
 	ingLinks := ctlr.getAllIngressLinks(svc.ObjectMeta.Namespace)
 	ctlr.TeemData.Lock()
 	ctlr.TeemData.ResourceType.IngressLink[svc.ObjectMeta.Namespace] = len(ingLinks)
@@ -3039,10 +3512,10 @@ func (ctlr *Controller) getIngressLinksForService(svc *v1.Service) []*cisapiv1.I
 
 // filterIngressLinkForService returns list of ingressLinks that are
 // affected by the service under process.
-func filterIngressLinkForService(allIngressLinks []*cisapiv1.IngressLink,
-	svc *v1.Service) []*cisapiv1.IngressLink {
+func filterIngressLinkForService(allIngressLinks []*cisapiv2.IngressLink,
+	svc *v1.Service) []*cisapiv2.IngressLink {
 
-	var result []*cisapiv1.IngressLink
+	var result []*cisapiv2.IngressLink
 	svcNamespace := svc.ObjectMeta.Namespace
 
 	// find IngressLinks which reference the service
@@ -3061,8 +3534,8 @@ func filterIngressLinkForService(allIngressLinks []*cisapiv1.IngressLink,
 }
 
 // get returns list of all ingressLink
-func (ctlr *Controller) getAllIngLinkFromMonitoredNamespaces() []*cisapiv1.IngressLink {
-	var allInglink []*cisapiv1.IngressLink
+func (ctlr *Controller) getAllIngLinkFromMonitoredNamespaces() []*cisapiv2.IngressLink {
+	var allInglink []*cisapiv2.IngressLink
 	if ctlr.watchingAllNamespaces() {
 		return ctlr.getAllIngressLinks("")
 	}
@@ -3072,23 +3545,39 @@ func (ctlr *Controller) getAllIngLinkFromMonitoredNamespaces() []*cisapiv1.Ingre
 	return allInglink
 }
 
-func (ctlr *Controller) getKICServiceOfIngressLink(ingLink *cisapiv1.IngressLink) (*v1.Service, error) {
+func (ctlr *Controller) getKICServiceOfIngressLink(ingLink *cisapiv2.IngressLink) (*v1.Service, error) {
 	selector := ""
 	for k, v := range ingLink.Spec.Selector.MatchLabels {
 		selector += fmt.Sprintf("%v=%v,", k, v)
 	}
 	selector = selector[:len(selector)-1]
 
-	svcListOptions := metav1.ListOptions{
-		LabelSelector: selector,
-	}
-
-	// Identify services that matches the given label
-	serviceList, err := ctlr.kubeClient.CoreV1().Services(ingLink.ObjectMeta.Namespace).List(context.TODO(), svcListOptions)
-
-	if err != nil {
-		log.Errorf("Error getting service list From IngressLink. Error: %v", err)
-		return nil, err
+	// Identify services that matches the given label, from the service
+	// informer of the namespace when it is set up
+	serviceList := &v1.ServiceList{}
+	if comInf, ok := ctlr.getNamespacedCommonInformer(ingLink.ObjectMeta.Namespace); ok {
+		objs, err := comInf.svcInformer.GetIndexer().ByIndex(cache.NamespaceIndex, ingLink.ObjectMeta.Namespace)
+		if err != nil {
+			log.Errorf("Error getting service list From IngressLink. Error: %v", err)
+			return nil, err
+		}
+		svcSelector := labels.SelectorFromSet(ingLink.Spec.Selector.MatchLabels)
+		for _, obj := range objs {
+			svc := obj.(*v1.Service)
+			if svcSelector.Matches(labels.Set(svc.Labels)) {
+				serviceList.Items = append(serviceList.Items, *svc)
+			}
+		}
+	} else {
+		svcListOptions := metav1.ListOptions{
+			LabelSelector: selector,
+		}
+		var err error
+		serviceList, err = ctlr.kubeClient.CoreV1().Services(ingLink.ObjectMeta.Namespace).List(context.TODO(), svcListOptions)
+		if err != nil {
+			log.Errorf("Error getting service list From IngressLink. Error: %v", err)
+			return nil, err
+		}
 	}
 
 	if len(serviceList.Items) == 0 {
@@ -3116,89 +3605,100 @@ func (ctlr *Controller) setLBServiceIngressStatus(
 		svc.Status.LoadBalancer.Ingress[0] = lbIngress
 	}
 
-	_, updateErr := ctlr.kubeClient.CoreV1().Services(svc.ObjectMeta.Namespace).UpdateStatus(context.TODO(), svc, metav1.UpdateOptions{})
-	if nil != updateErr {
-		// Multi-service causes the controller to try to update the status multiple times
-		// at once. Ignore this error.
-		if strings.Contains(updateErr.Error(), "object has been modified") {
-			return
+	// The status is updated once the store lock is released
+	svc = svc.DeepCopy()
+	ctlr.resources.afterUnlock(func() {
+		_, updateErr := ctlr.kubeClient.CoreV1().Services(svc.ObjectMeta.Namespace).UpdateStatus(context.TODO(), svc, metav1.UpdateOptions{})
+		if nil != updateErr {
+			// Multi-service causes the controller to try to update the status multiple times
+			// at once. Ignore this error.
+			if strings.Contains(updateErr.Error(), "object has been modified") {
+				return
+			}
+			warning := fmt.Sprintf(
+				"Error when setting Service LB Ingress status IP: %v", updateErr)
+			log.Warning(warning)
+			ctlr.recordLBServiceIngressEvent(svc, v1.EventTypeWarning, "StatusIPError", warning)
+		} else {
+			message := fmt.Sprintf("F5 CIS assigned LoadBalancer IP: %v", ip)
+			ctlr.recordLBServiceIngressEvent(svc, v1.EventTypeNormal, "ExternalIP", message)
 		}
-		warning := fmt.Sprintf(
-			"Error when setting Service LB Ingress status IP: %v", updateErr)
-		log.Warning(warning)
-		ctlr.recordLBServiceIngressEvent(svc, v1.EventTypeWarning, "StatusIPError", warning)
-	} else {
-		message := fmt.Sprintf("F5 CIS assigned LoadBalancer IP: %v", ip)
-		ctlr.recordLBServiceIngressEvent(svc, v1.EventTypeNormal, "ExternalIP", message)
-	}
+	})
 }
 
 func (ctlr *Controller) unSetLBServiceIngressStatus(
 	svc *v1.Service,
 	ip string,
 ) {
+	// The status is updated once the store lock is released
+	namespace, name := svc.Namespace, svc.Name
+	ctlr.resources.afterUnlock(func() {
+		svcName := namespace + "/" + name
+		svc, err := ctlr.kubeClient.CoreV1().Services(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			log.Debugf("Unable to Update Status of Service: %v due to unavailability", svcName)
+			return
+		}
 
-	svcName := svc.Namespace + "/" + svc.Name
-	svc, err := ctlr.kubeClient.CoreV1().Services(svc.Namespace).Get(context.TODO(), svc.Name, metav1.GetOptions{})
-	if err != nil {
-		log.Debugf("Unable to Update Status of Service: %v due to unavailability", svcName)
-		return
-	}
+		index := -1
+		for i, lbIng := range svc.Status.LoadBalancer.Ingress {
+			if lbIng.IP == ip {
+				index = i
+				break
+			}
+		}
 
-	index := -1
-	for i, lbIng := range svc.Status.LoadBalancer.Ingress {
-		if lbIng.IP == ip {
-			index = i
-			break
+		if index != -1 {
+			svc.Status.LoadBalancer.Ingress = append(svc.Status.LoadBalancer.Ingress[:index],
+				svc.Status.LoadBalancer.Ingress[index+1:]...)
+
+			_, updateErr := ctlr.kubeClient.CoreV1().Services(svc.ObjectMeta.Namespace).UpdateStatus(
+				context.TODO(), svc, metav1.UpdateOptions{})
+			if nil != updateErr {
+				// Multi-service causes the controller to try to update the status multiple times
+				// at once. Ignore this error.
+				if strings.Contains(updateErr.Error(), "object has been modified") {
+					log.Debugf("Error while updating service: %v. %v", svcName, updateErr.Error())
+					return
+				}
+				warning := fmt.Sprintf(
+					"Error when unsetting Service LB Ingress status IP: %v", updateErr)
+				log.Warning(warning)
+				ctlr.recordLBServiceIngressEvent(svc, v1.EventTypeWarning, "StatusIPError", warning)
+			} else {
+				message := fmt.Sprintf("F5 CIS unassigned LoadBalancer IP: %v", ip)
+				ctlr.recordLBServiceIngressEvent(svc, v1.EventTypeNormal, "ExternalIP", message)
+			}
 		}
-	}
+	})
+}
 
-	if index != -1 {
-		svc.Status.LoadBalancer.Ingress = append(svc.Status.LoadBalancer.Ingress[:index],
-			svc.Status.LoadBalancer.Ingress[index+1:]...)
+func (ctlr *Controller) eraseLBServiceIngressStatus(
+	svc *v1.Service,
+) {
+	svc.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{}
 
+	// The status is updated once the store lock is released
+	svc = svc.DeepCopy()
+	ctlr.resources.afterUnlock(func() {
 		_, updateErr := ctlr.kubeClient.CoreV1().Services(svc.ObjectMeta.Namespace).UpdateStatus(
 			context.TODO(), svc, metav1.UpdateOptions{})
 		if nil != updateErr {
 			// Multi-service causes the controller to try to update the status multiple times
 			// at once. Ignore this error.
 			if strings.Contains(updateErr.Error(), "object has been modified") {
-				log.Debugf("Error while updating service: %v. %v", svcName, updateErr.Error())
+				log.Debugf("Error while updating service: %v/%v. %v", svc.Namespace, svc.Name, updateErr.Error())
 				return
 			}
 			warning := fmt.Sprintf(
-				"Error when unsetting Service LB Ingress status IP: %v", updateErr)
+				"Error when erasing Service LB Ingress status IP: %v", updateErr)
 			log.Warning(warning)
 			ctlr.recordLBServiceIngressEvent(svc, v1.EventTypeWarning, "StatusIPError", warning)
 		} else {
-			message := fmt.Sprintf("F5 CIS unassigned LoadBalancer IP: %v", ip)
+			message := fmt.Sprintf("F5 CIS erased LoadBalancer IP in Status")
 			ctlr.recordLBServiceIngressEvent(svc, v1.EventTypeNormal, "ExternalIP", message)
 		}
-	}
-}
-
-func (ctlr *Controller) eraseLBServiceIngressStatus(
-	svc *v1.Service,
-) {
-	svc.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{}
-
-	_, updateErr := ctlr.kubeClient.CoreV1().Services(svc.ObjectMeta.Namespace).UpdateStatus(
-		context.TODO(), svc, metav1.UpdateOptions{})
-	if nil != updateErr {
-		// Multi-service causes the controller to try to update the status multiple times
-		// at once. Ignore this error.
-		if strings.Contains(updateErr.Error(), "object has been modified") {
-			log.Debugf("Error while updating service: %v/%v. %v", svc.Namespace, svc.Name, updateErr.Error())
-			return
-		}
-		warning := fmt.Sprintf(
-			"Error when erasing Service LB Ingress status IP: %v", updateErr)
-		log.Warning(warning)
-		ctlr.recordLBServiceIngressEvent(svc, v1.EventTypeWarning, "StatusIPError", warning)
-	} else {
-		message := fmt.Sprintf("F5 CIS erased LoadBalancer IP in Status")
-		ctlr.recordLBServiceIngressEvent(svc, v1.EventTypeNormal, "ExternalIP", message)
-	}
+	})
 }
 
 func (ctlr *Controller) recordLBServiceIngressEvent(
@@ -3239,45 +3739,69 @@ func getNodeport(svc *v1.Service, servicePort int32) int32 {
 }
 
 // Update virtual server status with virtual server address
-func (ctlr *Controller) updateVirtualServerStatus(vs *cisapiv1.VirtualServer, ip string, statusOk string) {
+func (ctlr *Controller) updateVirtualServerStatus(vs *cisapiv2.VirtualServer, ip string, statusOk string) {
 	// Set the vs status to include the virtual IP address
-	vsStatus := cisapiv1.VirtualServerStatus{VSAddress: ip, StatusOk: statusOk}
+	vsStatus := cisapiv2.VirtualServerStatus{VSAddress: ip, StatusOk: statusOk}
 	log.Debugf("Updating VirtualServer Status with %v for resource name:%v , namespace: %v", vsStatus, vs.Name, vs.Namespace)
 	vs.Status = vsStatus
 	vs.Status.VSAddress = ip
 	vs.Status.StatusOk = statusOk
-	_, updateErr := ctlr.kubeCRClient.CisV1().VirtualServers(vs.ObjectMeta.Namespace).UpdateStatus(context.TODO(), vs, metav1.UpdateOptions{})
-	if nil != updateErr {
-		log.Debugf("Error while updating virtual server status:%v", updateErr)
+	// The status is updated on the stored v1 resource
+	vsV1, err := cisapiv2.ConvertVirtualServerToV1(vs)
+	if err != nil {
+		log.Debugf("Error while converting virtual server %v/%v to v1:%v", vs.Namespace, vs.Name, err)
 		return
 	}
+	// The status is updated once the store lock is released
+	ctlr.resources.afterUnlock(func() {
+		_, updateErr := ctlr.kubeCRClient.CisV1().VirtualServers(vsV1.ObjectMeta.Namespace).UpdateStatus(context.TODO(), vsV1, metav1.UpdateOptions{})
+		if nil != updateErr {
+			log.Debugf("Error while updating virtual server status:%v", updateErr)
+		}
+	})
 }
 
 // Update Transport server status with virtual server address
-func (ctlr *Controller) updateTransportServerStatus(ts *cisapiv1.TransportServer, ip string, statusOk string) {
+func (ctlr *Controller) updateTransportServerStatus(ts *cisapiv2.TransportServer, ip string, statusOk string) {
 	// Set the vs status to include the virtual IP address
-	tsStatus := cisapiv1.TransportServerStatus{VSAddress: ip, StatusOk: statusOk}
+	tsStatus := cisapiv2.TransportServerStatus{VSAddress: ip, StatusOk: statusOk}
 	log.Debugf("Updating VirtualServer Status with %v for resource name:%v , namespace: %v", tsStatus, ts.Name, ts.Namespace)
 	ts.Status = tsStatus
 	ts.Status.VSAddress = ip
 	ts.Status.StatusOk = statusOk
-	_, updateErr := ctlr.kubeCRClient.CisV1().TransportServers(ts.ObjectMeta.Namespace).UpdateStatus(context.TODO(), ts, metav1.UpdateOptions{})
-	if nil != updateErr {
-		log.Debugf("Error while updating Transport server status:%v", updateErr)
+	// The status is updated on the stored v1 resource
+	tsV1, err := cisapiv2.ConvertTransportServerToV1(ts)
+	if err != nil {
+		log.Debugf("Error while converting Transport server %v/%v to v1:%v", ts.Namespace, ts.Name, err)
 		return
 	}
+	// The status is updated once the store lock is released
+	ctlr.resources.afterUnlock(func() {
+		_, updateErr := ctlr.kubeCRClient.CisV1().TransportServers(tsV1.ObjectMeta.Namespace).UpdateStatus(context.TODO(), tsV1, metav1.UpdateOptions{})
+		if nil != updateErr {
+			log.Debugf("Error while updating Transport server status:%v", updateErr)
+		}
+	})
 }
 
 // Update ingresslink status with virtual server address
-func (ctlr *Controller) updateIngressLinkStatus(il *cisapiv1.IngressLink, ip string) {
+func (ctlr *Controller) updateIngressLinkStatus(il *cisapiv2.IngressLink, ip string) {
 	// Set the vs status to include the virtual IP address
-	ilStatus := cisapiv1.IngressLinkStatus{VSAddress: ip}
+	ilStatus := cisapiv2.IngressLinkStatus{VSAddress: ip}
 	il.Status = ilStatus
-	_, updateErr := ctlr.kubeCRClient.CisV1().IngressLinks(il.ObjectMeta.Namespace).UpdateStatus(context.TODO(), il, metav1.UpdateOptions{})
-	if nil != updateErr {
-		log.Debugf("Error while updating ingresslink status:%v", updateErr)
+	// The status is updated on the stored v1 resource
+	ilV1, err := cisapiv2.ConvertIngressLinkToV1(il)
+	if err != nil {
+		log.Debugf("Error while converting ingresslink %v/%v to v1:%v", il.Namespace, il.Name, err)
 		return
 	}
+	// The status is updated once the store lock is released
+	ctlr.resources.afterUnlock(func() {
+		_, updateErr := ctlr.kubeCRClient.CisV1().IngressLinks(ilV1.ObjectMeta.Namespace).UpdateStatus(context.TODO(), ilV1, metav1.UpdateOptions{})
+		if nil != updateErr {
+			log.Debugf("Error while updating ingresslink status:%v", updateErr)
+		}
+	})
 }
 
 //returns service obj with servicename
@@ -3300,6 +3824,24 @@ func (ctlr *Controller) GetService(namespace, serviceName string) *v1.Service {
 	return svc.(*v1.Service)
 }
 
+// getSecret returns the secret from the secrets informer of the namespace when
+// it is set up, otherwise from the API server
+func (ctlr *Controller) getSecret(namespace, name string) *v1.Secret {
+	if comInf, ok := ctlr.getNamespacedCommonInformer(namespace); ok && comInf.secretsInformer != nil {
+		obj, found, err := comInf.secretsInformer.GetIndexer().GetByKey(namespace + "/" + name)
+		if err != nil || !found {
+			log.Debugf("Secret %v/%v not found in the informer store: %v", namespace, name, err)
+			return &v1.Secret{}
+		}
+		return obj.(*v1.Secret)
+	}
+	secret, err := ctlr.kubeClient.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		log.Debugf("Unable to get secret %v/%v: %v", namespace, name, err)
+	}
+	return secret
+}
+
 // GetPodsForService returns podList with labels set to svc selector
 func (ctlr *Controller) GetPodsForService(namespace, serviceName string, nplAnnotationRequired bool) *v1.PodList {
 	svcKey := namespace + "/" + serviceName
@@ -3333,6 +3875,26 @@ func (ctlr *Controller) GetPodsForService(namespace, serviceName string, nplAnno
 	if err != nil {
 		return nil
 	}
+	// The pods are read from the pod informer when it is set up
+	if comInf.podInformer != nil {
+		objs, err := comInf.podInformer.GetIndexer().ByIndex(cache.NamespaceIndex, namespace)
+		if err != nil {
+			log.Debugf("Unable to fetch pods in namespace %v: %v", namespace, err)
+			return nil
+		}
+		podSelector := labels.SelectorFromSet(labelmap)
+		podList := &v1.PodList{}
+		for _, obj := range objs {
+			pod := obj.(*v1.Pod)
+			if podSelector.Matches(labels.Set(pod.Labels)) {
+				podList.Items = append(podList.Items, *pod)
+			}
+		}
+		if len(podList.Items) == 0 {
+			return nil
+		}
+		return podList
+	}
 	podListOptions := metav1.ListOptions{
 		LabelSelector: labels.SelectorFromSet(labelmap).String(),
 	}
@@ -3397,21 +3959,21 @@ func (ctlr *Controller) processPod(pod *v1.Pod, ispodDeleted bool) error {
 }
 
 // getPolicyFromLBService gets the policy attached to the service and returns it
-func (ctlr *Controller) getPolicyFromLBService(svc *v1.Service) (*cisapiv1.Policy, error) {
+func (ctlr *Controller) getPolicyFromLBService(svc *v1.Service) (*cisapiv2.Policy, error) {
 	plcName, found := svc.Annotations[LBServicePolicyNameAnnotation]
 	if !found || plcName == "" {
-		return nil, nil
+		return ctlr.getSelectedPolicy(svc.Namespace, svc.Labels), nil
 	}
 	ns := svc.Namespace
 	return ctlr.getPolicy(ns, plcName)
 }
 
 // skipVirtual return true if virtuals don't have any common HTTP/HTTPS ports, else returns false
-func skipVirtual(currentVS *cisapiv1.VirtualServer, vrt *cisapiv1.VirtualServer) bool {
-	effectiveCurrentVSHTTPSPort := getEffectiveHTTPSPort(currentVS)
-	effectiveVrtVSHTTPSPort := getEffectiveHTTPSPort(vrt)
-	effectiveCurrentVSHTTPPort := getEffectiveHTTPPort(currentVS)
-	effectiveVrtVSHTTPPort := getEffectiveHTTPPort(vrt)
+func (ctlr *Controller) skipVirtual(currentVS *cisapiv2.VirtualServer, vrt *cisapiv2.VirtualServer) bool {
+	effectiveCurrentVSHTTPSPort := ctlr.getEffectiveHTTPSPort(currentVS)
+	effectiveVrtVSHTTPSPort := ctlr.getEffectiveHTTPSPort(vrt)
+	effectiveCurrentVSHTTPPort := ctlr.getEffectiveHTTPPort(currentVS)
+	effectiveVrtVSHTTPPort := ctlr.getEffectiveHTTPPort(vrt)
 	if effectiveCurrentVSHTTPSPort == effectiveVrtVSHTTPSPort && effectiveCurrentVSHTTPPort == effectiveVrtVSHTTPPort {
 		// both virtuals use same ports
 		return false
@@ -3462,10 +4024,10 @@ func skipVirtual(currentVS *cisapiv1.VirtualServer, vrt *cisapiv1.VirtualServer)
 }
 
 // doVSUseSameHTTPSPort checks if any of the associated secured VS uses the same HTTPS port that the current VS does
-func doVSUseSameHTTPSPort(virtuals []*cisapiv1.VirtualServer, currentVirtual *cisapiv1.VirtualServer) bool {
-	effectiveCurrentVSHTTPSPort := getEffectiveHTTPSPort(currentVirtual)
+func (ctlr *Controller) doVSUseSameHTTPSPort(virtuals []*cisapiv2.VirtualServer, currentVirtual *cisapiv2.VirtualServer) bool {
+	effectiveCurrentVSHTTPSPort := ctlr.getEffectiveHTTPSPort(currentVirtual)
 	for _, virtual := range virtuals {
-		if virtual.Spec.TLSProfileName != "" && effectiveCurrentVSHTTPSPort == getEffectiveHTTPSPort(virtual) {
+		if virtual.Spec.TLSProfileName != "" && effectiveCurrentVSHTTPSPort == ctlr.getEffectiveHTTPSPort(virtual) {
 			return true
 		}
 	}
@@ -3483,8 +4045,8 @@ func fetchPortString(port intstr.IntOrString) string {
 }
 
 // fetch list of tls profiles for given secret.
-func (ctlr *Controller) getTLSProfilesForSecret(secret *v1.Secret) []*cisapiv1.TLSProfile {
-	var allTLSProfiles []*cisapiv1.TLSProfile
+func (ctlr *Controller) getTLSProfilesForSecret(secret *v1.Secret) []*cisapiv2.TLSProfile {
+	var allTLSProfiles []*cisapiv2.TLSProfile
 
 	crInf, ok := ctlr.getNamespacedCRInformer(secret.Namespace)
 	if !ok {
@@ -3502,7 +4064,7 @@ func (ctlr *Controller) getTLSProfilesForSecret(secret *v1.Secret) []*cisapiv1.T
 	}
 
 	for _, obj := range orderedTLS {
-		tlsProfile := obj.(*cisapiv1.TLSProfile)
+		tlsProfile := obj.(*cisapiv2.TLSProfile)
 		if tlsProfile.Spec.TLS.Reference == Secret {
 			if len(tlsProfile.Spec.TLS.ClientSSLs) > 0 {
 				for _, name := range tlsProfile.Spec.TLS.ClientSSLs {
@@ -3512,8 +4074,13 @@ func (ctlr *Controller) getTLSProfilesForSecret(secret *v1.Secret) []*cisapiv1.T
 				}
 			}
 			allTLSProfiles = append(allTLSProfiles, tlsProfile)
-		} else if tlsProfile.Spec.TLS.ClientSSL == secret.Name {
-			allTLSProfiles = append(allTLSProfiles, tlsProfile)
+		} else {
+			for _, name := range tlsProfile.Spec.TLS.ClientSSLs {
+				if name == secret.Name {
+					allTLSProfiles = append(allTLSProfiles, tlsProfile)
+					break
+				}
+			}
 		}
 	}
 	return allTLSProfiles
@@ -1,10 +1,14 @@
 package controller
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"github.com/F5Networks/k8s-bigip-ctlr/pkg/teem"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"os"
 	"reflect"
 	"sort"
 	"time"
@@ -12,22 +16,26 @@ import (
 	ficV1 "github.com/F5Networks/f5-ipam-controller/pkg/ipamapis/apis/fic/v1"
 	"github.com/F5Networks/f5-ipam-controller/pkg/ipammachinery"
 	crdfake "github.com/F5Networks/k8s-bigip-ctlr/config/client/clientset/versioned/fake"
-	cisinfv1 "github.com/F5Networks/k8s-bigip-ctlr/config/client/informers/externalversions/cis/v1"
 	apm "github.com/F5Networks/k8s-bigip-ctlr/pkg/appmanager"
 	k8sfake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 
 	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v1"
+	cisapiv2 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v2"
 	"github.com/F5Networks/k8s-bigip-ctlr/pkg/test"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 var _ = Describe("Worker Tests", func() {
 	var mockCtlr *mockController
-	var vrt1 *cisapiv1.VirtualServer
+	var vrt1 *cisapiv2.VirtualServer
 	var svc1 *v1.Service
 	namespace := "default"
 
@@ -49,15 +57,15 @@ var _ = Describe("Worker Tests", func() {
 		vrt1 = test.NewVirtualServer(
 			"SampleVS",
 			namespace,
-			cisapiv1.VirtualServerSpec{
+			cisapiv2.VirtualServerSpec{
 				Host:                   "test.com",
 				VirtualServerAddress:   "1.2.3.4",
 				IPAMLabel:              "",
 				VirtualServerName:      "",
 				VirtualServerHTTPPort:  0,
 				VirtualServerHTTPSPort: 0,
-				Pools: []cisapiv1.Pool{
-					cisapiv1.Pool{
+				Pools: []cisapiv2.Pool{
+					cisapiv2.Pool{
 						Path:    "/path",
 						Service: "svc1",
 					},
@@ -79,7 +87,8 @@ var _ = Describe("Worker Tests", func() {
 				},
 			},
 		}
-		mockCtlr.kubeCRClient = crdfake.NewSimpleClientset(vrt1)
+		vrt1V1, _ := cisapiv2.ConvertVirtualServerToV1(vrt1)
+		mockCtlr.kubeCRClient = crdfake.NewSimpleClientset(vrt1V1)
 		mockCtlr.kubeClient = k8sfake.NewSimpleClientset(svc1)
 		mockCtlr.mode = CustomResourceMode
 		mockCtlr.crInformers = make(map[string]*CRInformer)
@@ -87,7 +96,7 @@ var _ = Describe("Worker Tests", func() {
 		mockCtlr.nativeResourceSelector, _ = createLabelSelector(DefaultCustomResourceLabel)
 		_ = mockCtlr.addNamespacedInformers("default", false)
 		mockCtlr.resources = NewResourceStore()
-		mockCtlr.crInformers["default"].vsInformer = cisinfv1.NewFilteredVirtualServerInformer(
+		mockCtlr.crInformers["default"].vsInformer = newVirtualServerInformer(
 			mockCtlr.kubeCRClient,
 			namespace,
 			0,
@@ -96,7 +105,7 @@ var _ = Describe("Worker Tests", func() {
 				options.LabelSelector = mockCtlr.nativeResourceSelector.String()
 			},
 		)
-		mockCtlr.crInformers["default"].ilInformer = cisinfv1.NewFilteredIngressLinkInformer(
+		mockCtlr.crInformers["default"].ilInformer = newIngressLinkInformer(
 			mockCtlr.kubeCRClient,
 			namespace,
 			0,
@@ -133,18 +142,18 @@ var _ = Describe("Worker Tests", func() {
 			)
 			var iRules []string
 			IngressLink1 := test.NewIngressLink("ingresslink1", namespace, "1",
-				cisapiv1.IngressLinkSpec{
+				cisapiv2.IngressLinkSpec{
 					VirtualServerAddress: "",
 					Selector:             selctor,
 					IRules:               iRules,
 				})
 			IngressLink2 := test.NewIngressLink("ingresslink2", "dummy", "1",
-				cisapiv1.IngressLinkSpec{
+				cisapiv2.IngressLinkSpec{
 					VirtualServerAddress: "",
 					Selector:             selctor,
 					IRules:               iRules,
 				})
-			var IngressLinks []*cisapiv1.IngressLink
+			var IngressLinks []*cisapiv2.IngressLink
 			IngressLinks = append(IngressLinks, IngressLink1, IngressLink2)
 			ingresslinksForService := filterIngressLinkForService(IngressLinks, foo)
 			Expect(ingresslinksForService[0]).To(Equal(IngressLink1), "Should return the Ingresslink1 object")
@@ -250,6 +259,7 @@ var _ = Describe("Worker Tests", func() {
 					},
 				}
 				ipamCR, _ = mockCtlr.ipamCli.Update(ipamCR)
+				mockCtlr.resources.ipamCR = ipamCR
 				ip, status = mockCtlr.requestIP("test", host, key)
 				Expect(ip).To(Equal("10.10.10.1"), errHint+"Invalid IP")
 				Expect(status).To(Equal(Allocated), "Failed to fetch Allocated IP")
@@ -284,6 +294,7 @@ var _ = Describe("Worker Tests", func() {
 					},
 				}
 				ipamCR, _ = mockCtlr.ipamCli.Update(ipamCR)
+				mockCtlr.resources.ipamCR = ipamCR
 
 				ip, status = mockCtlr.requestIP("old", host, key)
 				Expect(ip).To(Equal(""), errHint+"Invalid IP")
@@ -329,6 +340,7 @@ var _ = Describe("Worker Tests", func() {
 					},
 				}
 				ipamCR, _ = mockCtlr.ipamCli.Update(ipamCR)
+				mockCtlr.resources.ipamCR = ipamCR
 
 				ip = mockCtlr.releaseIP("test", host, key)
 				ipamCR = mockCtlr.getIPAMCR()
@@ -341,10 +353,10 @@ var _ = Describe("Worker Tests", func() {
 			vrt2 := test.NewVirtualServer(
 				"SampleVS2",
 				namespace,
-				cisapiv1.VirtualServerSpec{
+				cisapiv2.VirtualServerSpec{
 					Host: "test.com",
-					Pools: []cisapiv1.Pool{
-						cisapiv1.Pool{
+					Pools: []cisapiv2.Pool{
+						cisapiv2.Pool{
 							Path:    "/path",
 							Service: "svc1",
 						},
@@ -353,19 +365,19 @@ var _ = Describe("Worker Tests", func() {
 			vrt3 := test.NewVirtualServer(
 				"SampleVS3",
 				namespace,
-				cisapiv1.VirtualServerSpec{
+				cisapiv2.VirtualServerSpec{
 					Host: "test.com",
-					Pools: []cisapiv1.Pool{
-						cisapiv1.Pool{
+					Pools: []cisapiv2.Pool{
+						cisapiv2.Pool{
 							Path:    "/path2",
 							Service: "svc2",
 						},
 					},
 				})
-			label := getIPAMLabel([]*cisapiv1.VirtualServer{vrt2, vrt3})
+			label := getIPAMLabel([]*cisapiv2.VirtualServer{vrt2, vrt3})
 			Expect(label).To(BeEmpty())
 			vrt3.Spec.IPAMLabel = "test"
-			label = getIPAMLabel([]*cisapiv1.VirtualServer{vrt2, vrt3})
+			label = getIPAMLabel([]*cisapiv2.VirtualServer{vrt2, vrt3})
 			Expect(label).To(Equal("test"))
 		})
 	})
@@ -377,11 +389,11 @@ var _ = Describe("Worker Tests", func() {
 			vrt2 := test.NewVirtualServer(
 				"SampleVS2",
 				ns,
-				cisapiv1.VirtualServerSpec{
+				cisapiv2.VirtualServerSpec{
 					Host:                 "test2.com",
 					VirtualServerAddress: "1.2.3.5",
-					Pools: []cisapiv1.Pool{
-						cisapiv1.Pool{
+					Pools: []cisapiv2.Pool{
+						cisapiv2.Pool{
 							Path:    "/path",
 							Service: "svc",
 						},
@@ -390,17 +402,17 @@ var _ = Describe("Worker Tests", func() {
 			vrt3 := test.NewVirtualServer(
 				"SampleVS",
 				ns,
-				cisapiv1.VirtualServerSpec{
+				cisapiv2.VirtualServerSpec{
 					Host:                 "test3.com",
 					VirtualServerAddress: "1.2.3.6",
-					Pools: []cisapiv1.Pool{
-						cisapiv1.Pool{
+					Pools: []cisapiv2.Pool{
+						cisapiv2.Pool{
 							Path:    "/path",
 							Service: "svc",
 						},
 					},
 				})
-			res := filterVirtualServersForService([]*cisapiv1.VirtualServer{vrt1, vrt2, vrt3}, svc)
+			res := filterVirtualServersForService([]*cisapiv2.VirtualServer{vrt1, vrt2, vrt3}, svc)
 			Expect(len(res)).To(Equal(2), "Wrong list of Virtual Servers")
 			Expect(res[0]).To(Equal(vrt2), "Wrong list of Virtual Servers")
 			Expect(res[1]).To(Equal(vrt3), "Wrong list of Virtual Servers")
@@ -412,8 +424,8 @@ var _ = Describe("Worker Tests", func() {
 			ts1 := test.NewTransportServer(
 				"SampleTS1",
 				namespace,
-				cisapiv1.TransportServerSpec{
-					Pool: cisapiv1.Pool{
+				cisapiv2.TransportServerSpec{
+					Pool: cisapiv2.Pool{
 						Path:    "/path",
 						Service: "svc",
 					},
@@ -422,8 +434,8 @@ var _ = Describe("Worker Tests", func() {
 			ts2 := test.NewTransportServer(
 				"SampleTS1",
 				ns,
-				cisapiv1.TransportServerSpec{
-					Pool: cisapiv1.Pool{
+				cisapiv2.TransportServerSpec{
+					Pool: cisapiv2.Pool{
 						Path:    "/path",
 						Service: "svc",
 					},
@@ -432,27 +444,27 @@ var _ = Describe("Worker Tests", func() {
 			ts3 := test.NewTransportServer(
 				"SampleTS1",
 				ns,
-				cisapiv1.TransportServerSpec{
-					Pool: cisapiv1.Pool{
+				cisapiv2.TransportServerSpec{
+					Pool: cisapiv2.Pool{
 						Path:    "/path",
 						Service: "svc1",
 					},
 				},
 			)
 
-			res := filterTransportServersForService([]*cisapiv1.TransportServer{ts1, ts2, ts3}, svc)
+			res := filterTransportServersForService([]*cisapiv2.TransportServer{ts1, ts2, ts3}, svc)
 			Expect(len(res)).To(Equal(1), "Wrong list of Transport Servers")
 			Expect(res[0]).To(Equal(ts2), "Wrong list of Transport Servers")
 		})
 
 		It("Filter VS for TLSProfile", func() {
-			tlsProf := test.NewTLSProfile("sampleTLS", namespace, cisapiv1.TLSProfileSpec{
+			tlsProf := test.NewTLSProfile("sampleTLS", namespace, cisapiv2.TLSProfileSpec{
 				Hosts: []string{"test2.com"},
 			})
 			vrt2 := test.NewVirtualServer(
 				"SampleVS2",
 				namespace,
-				cisapiv1.VirtualServerSpec{
+				cisapiv2.VirtualServerSpec{
 					Host:                 "test2.com",
 					VirtualServerAddress: "1.2.3.5",
 					TLSProfileName:       "sampleTLS",
@@ -460,12 +472,12 @@ var _ = Describe("Worker Tests", func() {
 			vrt3 := test.NewVirtualServer(
 				"SampleVS",
 				namespace,
-				cisapiv1.VirtualServerSpec{
+				cisapiv2.VirtualServerSpec{
 					Host:                 "test2.com",
 					VirtualServerAddress: "1.2.3.5",
 					TLSProfileName:       "sampleTLS",
 				})
-			res := getVirtualServersForTLSProfile([]*cisapiv1.VirtualServer{vrt1, vrt2, vrt3}, tlsProf)
+			res := getVirtualServersForTLSProfile([]*cisapiv2.VirtualServer{vrt1, vrt2, vrt3}, tlsProf)
 			Expect(len(res)).To(Equal(2), "Wrong list of Virtual Servers")
 			Expect(res[0]).To(Equal(vrt2), "Wrong list of Virtual Servers")
 			Expect(res[1]).To(Equal(vrt3), "Wrong list of Virtual Servers")
@@ -480,16 +492,16 @@ var _ = Describe("Worker Tests", func() {
 		})
 
 		Describe("Filter Associated VirtualServers", func() {
-			var vrt2, vrt3, vrt4 *cisapiv1.VirtualServer
+			var vrt2, vrt3, vrt4 *cisapiv2.VirtualServer
 			BeforeEach(func() {
 				vrt2 = test.NewVirtualServer(
 					"SampleVS2",
 					namespace,
-					cisapiv1.VirtualServerSpec{
+					cisapiv2.VirtualServerSpec{
 						Host:                 "test2.com",
 						VirtualServerAddress: "1.2.3.5",
-						Pools: []cisapiv1.Pool{
-							cisapiv1.Pool{
+						Pools: []cisapiv2.Pool{
+							cisapiv2.Pool{
 								Path:    "/path",
 								Service: "svc",
 							},
@@ -498,11 +510,11 @@ var _ = Describe("Worker Tests", func() {
 				vrt3 = test.NewVirtualServer(
 					"SampleVS3",
 					namespace,
-					cisapiv1.VirtualServerSpec{
+					cisapiv2.VirtualServerSpec{
 						Host:                 "test2.com",
 						VirtualServerAddress: "1.2.3.5",
-						Pools: []cisapiv1.Pool{
-							cisapiv1.Pool{
+						Pools: []cisapiv2.Pool{
+							cisapiv2.Pool{
 								Path:    "/path3",
 								Service: "svc",
 							},
@@ -511,11 +523,11 @@ var _ = Describe("Worker Tests", func() {
 				vrt4 = test.NewVirtualServer(
 					"SampleVS4",
 					namespace,
-					cisapiv1.VirtualServerSpec{
+					cisapiv2.VirtualServerSpec{
 						Host:                 "test2.com",
 						VirtualServerAddress: "1.2.3.5",
-						Pools: []cisapiv1.Pool{
-							cisapiv1.Pool{
+						Pools: []cisapiv2.Pool{
+							cisapiv2.Pool{
 								Path:    "/path4",
 								Service: "svc",
 							},
@@ -525,7 +537,7 @@ var _ = Describe("Worker Tests", func() {
 			It("Duplicate Paths", func() {
 				vrt3.Spec.Pools[0].Path = "/path"
 				virts := mockCtlr.getAssociatedVirtualServers(vrt2,
-					[]*cisapiv1.VirtualServer{vrt2, vrt3},
+					[]*cisapiv2.VirtualServer{vrt2, vrt3},
 					false)
 				Expect(len(virts)).To(Equal(1), "Wrong number of Virtual Servers")
 				Expect(virts[0].Name).To(Equal("SampleVS2"), "Wrong Virtual Server")
@@ -535,7 +547,7 @@ var _ = Describe("Worker Tests", func() {
 				vrt4.Spec.Host = "new.com"
 				vrt4.Spec.VirtualServerAddress = "1.2.3.6"
 				virts := mockCtlr.getAssociatedVirtualServers(vrt2,
-					[]*cisapiv1.VirtualServer{vrt2, vrt4},
+					[]*cisapiv2.VirtualServer{vrt2, vrt4},
 					false)
 				Expect(len(virts)).To(Equal(1), "Wrong number of Virtual Servers")
 				Expect(virts[0].Name).To(Equal("SampleVS2"), "Wrong Virtual Server")
@@ -544,7 +556,7 @@ var _ = Describe("Worker Tests", func() {
 			It("Unique Paths", func() {
 				//vrt3.Spec.Pools[0].Path = "/path3"
 				virts := mockCtlr.getAssociatedVirtualServers(vrt2,
-					[]*cisapiv1.VirtualServer{vrt2, vrt3},
+					[]*cisapiv2.VirtualServer{vrt2, vrt3},
 					false)
 				Expect(len(virts)).To(Equal(2), "Wrong number of Virtual Servers")
 				Expect(virts[0].Name).To(Equal("SampleVS2"), "Wrong Virtual Server")
@@ -554,7 +566,7 @@ var _ = Describe("Worker Tests", func() {
 			It("Deletion", func() {
 				//vrt3.Spec.Pools[0].Path = "/path3"
 				virts := mockCtlr.getAssociatedVirtualServers(vrt2,
-					[]*cisapiv1.VirtualServer{vrt2, vrt3},
+					[]*cisapiv2.VirtualServer{vrt2, vrt3},
 					true)
 				Expect(len(virts)).To(Equal(1), "Wrong number of Virtual Servers")
 				Expect(virts[0].Name).To(Equal("SampleVS3"), "Wrong Virtual Server")
@@ -564,18 +576,18 @@ var _ = Describe("Worker Tests", func() {
 				vrt2 = test.NewVirtualServer(
 					"SampleVS2",
 					namespace,
-					cisapiv1.VirtualServerSpec{
+					cisapiv2.VirtualServerSpec{
 						Host:                 "test2.com",
 						VirtualServerAddress: "1.2.3.5",
-						Pools: []cisapiv1.Pool{
-							cisapiv1.Pool{
+						Pools: []cisapiv2.Pool{
+							cisapiv2.Pool{
 								Path:    "/path",
 								Service: "svc",
 							},
 						},
 					})
 				virts := mockCtlr.getAssociatedVirtualServers(vrt2,
-					[]*cisapiv1.VirtualServer{vrt2, vrt3},
+					[]*cisapiv2.VirtualServer{vrt2, vrt3},
 					false)
 				Expect(len(virts)).To(Equal(2), "Wrong number of Virtual Servers")
 				Expect(virts[0].Name).To(Equal("SampleVS2"), "Wrong Virtual Server")
@@ -585,7 +597,7 @@ var _ = Describe("Worker Tests", func() {
 			It("Re-Deletion of VS", func() {
 				//vrt3.Spec.Pools[0].Path = "/path3"
 				virts := mockCtlr.getAssociatedVirtualServers(vrt2,
-					[]*cisapiv1.VirtualServer{vrt2, vrt3},
+					[]*cisapiv2.VirtualServer{vrt2, vrt3},
 					true)
 				Expect(len(virts)).To(Equal(1), "Wrong number of Virtual Servers")
 				Expect(virts[0].Name).To(Equal("SampleVS3"), "Wrong Virtual Server")
@@ -595,7 +607,7 @@ var _ = Describe("Worker Tests", func() {
 				vrt3.Spec.Host = ""
 				//vrt3.Spec.Pools[0].Path = "/path3"
 				virts := mockCtlr.getAssociatedVirtualServers(vrt2,
-					[]*cisapiv1.VirtualServer{vrt2, vrt3},
+					[]*cisapiv2.VirtualServer{vrt2, vrt3},
 					false)
 				Expect(len(virts)).To(Equal(1), "Wrong number of Virtual Servers")
 				Expect(virts[0].Name).To(Equal("SampleVS2"), "Wrong Virtual Server")
@@ -607,7 +619,7 @@ var _ = Describe("Worker Tests", func() {
 				vrt4.Spec.Host = ""
 
 				virts := mockCtlr.getAssociatedVirtualServers(vrt3,
-					[]*cisapiv1.VirtualServer{vrt2, vrt3, vrt4},
+					[]*cisapiv2.VirtualServer{vrt2, vrt3, vrt4},
 					false)
 				Expect(len(virts)).To(Equal(2), "Wrong number of Virtual Servers")
 				Expect(virts[0].Name).To(Equal("SampleVS3"), "Wrong Virtual Server")
@@ -621,7 +633,7 @@ var _ = Describe("Worker Tests", func() {
 				vrt4.Spec.VirtualServerAddress = "1.2.3.6"
 
 				virts := mockCtlr.getAssociatedVirtualServers(vrt3,
-					[]*cisapiv1.VirtualServer{vrt2, vrt3, vrt4},
+					[]*cisapiv2.VirtualServer{vrt2, vrt3, vrt4},
 					false)
 				Expect(len(virts)).To(Equal(1), "Wrong number of Virtual Servers")
 				Expect(virts[0].Name).To(Equal("SampleVS3"), "Wrong Virtual Server")
@@ -632,7 +644,7 @@ var _ = Describe("Worker Tests", func() {
 				vrt4.Spec.VirtualServerAddress = "1.2.3.6"
 
 				virts := mockCtlr.getAssociatedVirtualServers(vrt2,
-					[]*cisapiv1.VirtualServer{vrt2, vrt4},
+					[]*cisapiv2.VirtualServer{vrt2, vrt4},
 					false)
 				Expect(virts).To(BeNil(), "Wrong Number of Virtual Servers")
 			})
@@ -643,7 +655,7 @@ var _ = Describe("Worker Tests", func() {
 				vrt3.Spec.Host = "test3.com"
 
 				virts := mockCtlr.getAssociatedVirtualServers(vrt2,
-					[]*cisapiv1.VirtualServer{vrt2, vrt3, vrt4},
+					[]*cisapiv2.VirtualServer{vrt2, vrt3, vrt4},
 					false)
 				Expect(len(virts)).To(Equal(2), "Wrong number of Virtual Servers")
 				Expect(virts[0].Spec.Host).To(Equal("test2.com"), "Wrong Virtual Server Host")
@@ -657,7 +669,7 @@ var _ = Describe("Worker Tests", func() {
 				vrt3.Spec.VirtualServerAddress = ""
 
 				virts := mockCtlr.getAssociatedVirtualServers(vrt2,
-					[]*cisapiv1.VirtualServer{vrt2, vrt3, vrt4},
+					[]*cisapiv2.VirtualServer{vrt2, vrt3, vrt4},
 					false)
 
 				Expect(len(virts)).To(Equal(2), "Wrong number of Virtual Servers")
@@ -678,7 +690,7 @@ var _ = Describe("Worker Tests", func() {
 				vrt4.Spec.VirtualServerHTTPPort = 8080
 
 				virts := mockCtlr.getAssociatedVirtualServers(vrt2,
-					[]*cisapiv1.VirtualServer{vrt2, vrt3, vrt4},
+					[]*cisapiv2.VirtualServer{vrt2, vrt3, vrt4},
 					false)
 				Expect(len(virts)).To(Equal(2), "Wrong number of Virtual Servers")
 				Expect(virts[0].Name).To(Equal("SampleVS2"), "Wrong Virtual Server")
@@ -698,7 +710,7 @@ var _ = Describe("Worker Tests", func() {
 				vrt4.Spec.Pools[0].Path = "/path"
 
 				virts := mockCtlr.getAssociatedVirtualServers(vrt2,
-					[]*cisapiv1.VirtualServer{vrt2, vrt3, vrt4},
+					[]*cisapiv2.VirtualServer{vrt2, vrt3, vrt4},
 					false)
 				Expect(len(virts)).To(Equal(3), "Wrong number of Virtual Servers")
 				Expect(virts[0].Name).To(Equal("SampleVS2"), "Wrong Virtual Server")
@@ -712,7 +724,7 @@ var _ = Describe("Worker Tests", func() {
 				vrt3.Spec.IPAMLabel = "test"
 				vrt4.Spec.IPAMLabel = "test"
 				virts := mockCtlr.getAssociatedVirtualServers(vrt2,
-					[]*cisapiv1.VirtualServer{vrt2, vrt3, vrt4},
+					[]*cisapiv2.VirtualServer{vrt2, vrt3, vrt4},
 					false)
 				Expect(len(virts)).To(Equal(3), "Wrong number of Virtual Servers")
 				Expect(virts[0].Name).To(Equal("SampleVS2"), "Wrong Virtual Server")
@@ -726,7 +738,7 @@ var _ = Describe("Worker Tests", func() {
 				vrt3.Spec.IPAMLabel = "test"
 				vrt4.Spec.IPAMLabel = ""
 				virts := mockCtlr.getAssociatedVirtualServers(vrt2,
-					[]*cisapiv1.VirtualServer{vrt2, vrt3, vrt4},
+					[]*cisapiv2.VirtualServer{vrt2, vrt3, vrt4},
 					false)
 				Expect(len(virts)).To(Equal(0), "Wrong number of Virtual Servers")
 			})
@@ -735,7 +747,7 @@ var _ = Describe("Worker Tests", func() {
 				vrt4.Spec.IPAMLabel = "test"
 				vrt4.Spec.Host = ""
 				virts := mockCtlr.getAssociatedVirtualServers(vrt4,
-					[]*cisapiv1.VirtualServer{vrt4},
+					[]*cisapiv2.VirtualServer{vrt4},
 					false)
 				Expect(len(virts)).To(Equal(0), "Wrong number of Virtual Servers")
 			})
@@ -785,11 +797,11 @@ var _ = Describe("Worker Tests", func() {
 				},
 			}
 
-			mems := mockCtlr.getEndpointsForNodePort(nodePort, "")
+			mems := mockCtlr.getEndpointsForNodePort(nodePort, "", nil)
 			Expect(mems).To(Equal(members), "Wrong set of Endpoints for NodePort")
-			mems = mockCtlr.getEndpointsForNodePort(nodePort, "worker=true")
+			mems = mockCtlr.getEndpointsForNodePort(nodePort, "worker=true", nil)
 			Expect(mems).To(Equal(members[:2]), "Wrong set of Endpoints for NodePort")
-			mems = mockCtlr.getEndpointsForNodePort(nodePort, "invalid label")
+			mems = mockCtlr.getEndpointsForNodePort(nodePort, "invalid label", nil)
 			Expect(len(mems)).To(Equal(0), "Wrong set of Endpoints for NodePort")
 		})
 
@@ -848,6 +860,7 @@ var _ = Describe("Worker Tests", func() {
 				},
 			}
 			ipamCR, _ = mockCtlr.ipamCli.Update(ipamCR)
+			mockCtlr.resources.ipamCR = ipamCR
 
 			_ = mockCtlr.processLBServices(svc1, false)
 			Expect(len(mockCtlr.resources.ltmConfig)).To(Equal(1), "Invalid Resource Configs")
@@ -873,17 +886,17 @@ var _ = Describe("Worker Tests", func() {
 			newEDNS := test.NewExternalDNS(
 				"SampleEDNS",
 				namespace,
-				cisapiv1.ExternalDNSSpec{
+				cisapiv2.ExternalDNSSpec{
 					DomainName: "test.com",
-					Pools: []cisapiv1.DNSPool{
+					Pools: []cisapiv2.DNSPool{
 						{
 							DataServerName: "DataServer",
-							Monitor: cisapiv1.Monitor{
+							Monitors: []cisapiv2.Monitor{{
 								Type:     "http",
 								Send:     "GET /health",
 								Interval: 10,
 								Timeout:  10,
-							},
+							}},
 						},
 					},
 				})
@@ -910,6 +923,176 @@ var _ = Describe("Worker Tests", func() {
 			Expect(len(gtmConfig)).To(Equal(0))
 		})
 
+		It("Processing External DNS across data centers", func() {
+			mockCtlr.resources.Init()
+			DEFAULT_PARTITION = "default"
+			mockCtlr.TeemData = &teem.TeemsData{
+				ResourceType: teem.ResourceTypes{
+					ExternalDNS: make(map[string]int),
+				},
+			}
+			mockCtlr.Partition = "default"
+			mockCtlr.resources.ltmConfig["default"] = &PartitionConfig{make(ResourceMap), 0}
+			mockCtlr.resources.ltmConfig["default"].ResourceMap["SampleVS"] = &ResourceConfig{
+				MetaData: metaData{
+					hosts: []string{"test.com"},
+				},
+			}
+
+			newEDNS := test.NewExternalDNS(
+				"SampleEDNS",
+				namespace,
+				cisapiv2.ExternalDNSSpec{
+					DomainName:        "test.com",
+					LoadBalanceMethod: "global-availability",
+					Pools: []cisapiv2.DNSPool{
+						{
+							DataServerName: "/Common/DC2Server",
+							DataCenter:     "dc2",
+							PriorityOrder:  1,
+							VirtualServers: []string{"/default/Shared/crd_10_9_0_4_443"},
+							FallbackIP:     "10.9.0.10",
+						},
+						{
+							DataServerName: "/Common/DC1Server",
+							DataCenter:     "dc1",
+							TTL:            60,
+							Monitors: []cisapiv2.Monitor{{
+								Type:       "https",
+								Send:       "GET /health",
+								Interval:   10,
+								TargetPort: 8443,
+							}},
+						},
+					},
+				})
+			mockCtlr.processExternalDNS(newEDNS, false)
+			pools := mockCtlr.resources.gtmConfig[DEFAULT_PARTITION].WideIPs["test.com"].Pools
+			Expect(len(pools)).To(Equal(2))
+			Expect(pools[0].Name).To(Equal("test.com_dc1_default"), "Pools should follow their order")
+			Expect(pools[0].DataServer).To(Equal("/Common/DC1Server"))
+			Expect(pools[0].Members).To(Equal([]string{"/default/Shared/SampleVS"}))
+			Expect(pools[0].TTL).To(Equal(int32(60)))
+			Expect(pools[0].Monitors[0].Name).To(Equal("test.com_dc1_default_monitor0"))
+			Expect(pools[0].Monitors[0].TargetPort).To(Equal(int32(8443)))
+			Expect(pools[1].Name).To(Equal("test.com_dc2_default"))
+			Expect(pools[1].Members).To(Equal([]string{"/default/Shared/crd_10_9_0_4_443"}),
+				"Pools of other data centers should have their virtuals only")
+			Expect(pools[1].FallbackIP).To(Equal("10.9.0.10"))
+		})
+
+		It("Synthesizes the wide IPs of the hosts with autoExternalDNS", func() {
+			mockCtlr.resources.Init()
+			DEFAULT_PARTITION = "default"
+			mockCtlr.TeemData = &teem.TeemsData{
+				ResourceType: teem.ResourceTypes{
+					ExternalDNS: make(map[string]int),
+				},
+			}
+			mockCtlr.Partition = "default"
+			plc := test.NewPolicy("plc", namespace, cisapiv2.PolicySpec{
+				AutoExternalDNS: &cisapiv2.AutoExternalDNSSpec{
+					DataServerName: "/Common/GSLBServer",
+					Monitors:       []cisapiv2.Monitor{{Type: "tcp", Interval: 10}},
+				},
+			})
+			rsCfg := &ResourceConfig{MetaData: metaData{hosts: []string{"foo.com", "bar.com"}}}
+			Expect(mockCtlr.handleVSResourceConfigForPolicy(rsCfg, plc)).To(BeNil())
+			mockCtlr.resources.ltmConfig["default"] = &PartitionConfig{make(ResourceMap), 0}
+			mockCtlr.resources.ltmConfig["default"].ResourceMap["SampleVS"] = rsCfg
+			mockCtlr.resources.ltmConfig["default"].ResourceMap["OtherVS"] = &ResourceConfig{
+				MetaData: metaData{hosts: []string{"other.com"}},
+			}
+
+			mockCtlr.syncAutoWideIPs()
+			wideIPs := mockCtlr.resources.gtmConfig[DEFAULT_PARTITION].WideIPs
+			Expect(wideIPs).To(HaveLen(2), "Only the hosts of the policy should have a wide IP")
+			Expect(wideIPs["foo.com"].UID).To(Equal(autoWideIPUID))
+			Expect(wideIPs["foo.com"].LBMethod).To(Equal("round-robin"))
+			Expect(wideIPs["foo.com"].Pools[0].DataServer).To(Equal("/Common/GSLBServer"))
+			Expect(wideIPs["foo.com"].Pools[0].Members).To(Equal([]string{"/default/Shared/SampleVS"}))
+			Expect(wideIPs["foo.com"].Pools[0].Monitors).To(HaveLen(1))
+
+			// An ExternalDNS of the host takes precedence
+			edns := test.NewExternalDNS("edns", namespace, cisapiv2.ExternalDNSSpec{
+				DomainName: "foo.com",
+				Pools:      []cisapiv2.DNSPool{{DataServerName: "/Common/OtherServer"}},
+			})
+			edns.UID = "edns-uid"
+			mockCtlr.processExternalDNS(edns, false)
+			mockCtlr.syncAutoWideIPs()
+			Expect(wideIPs["foo.com"].UID).To(Equal("edns-uid"))
+			Expect(wideIPs["foo.com"].Pools[0].DataServer).To(Equal("/Common/OtherServer"))
+			mockCtlr.processExternalDNS(edns, true)
+			Expect(wideIPs["foo.com"].UID).To(Equal(autoWideIPUID), "The wide IP should be synthesized again")
+
+			// The wide IPs follow the hosts of the virtual
+			rsCfg.MetaData.hosts = []string{"foo.com"}
+			mockCtlr.syncAutoWideIPs()
+			Expect(wideIPs).To(HaveLen(1))
+			Expect(wideIPs).To(HaveKey("foo.com"))
+			delete(mockCtlr.resources.ltmConfig["default"].ResourceMap, "SampleVS")
+			mockCtlr.syncAutoWideIPs()
+			Expect(wideIPs).To(BeEmpty())
+		})
+
+		It("Processing External DNS with AAAA, CNAME and wildcard domains", func() {
+			mockCtlr.resources.Init()
+			DEFAULT_PARTITION = "default"
+			mockCtlr.TeemData = &teem.TeemsData{
+				ResourceType: teem.ResourceTypes{
+					ExternalDNS: make(map[string]int),
+				},
+			}
+			mockCtlr.Partition = "default"
+			mockCtlr.resources.ltmConfig["default"] = &PartitionConfig{make(ResourceMap), 0}
+			v4VS := &ResourceConfig{MetaData: metaData{hosts: []string{"foo.apps.example.com"}}}
+			v4VS.Virtual.SetVirtualAddress("10.1.1.1", 443)
+			v6VS := &ResourceConfig{MetaData: metaData{hosts: []string{"bar.apps.example.com"}}}
+			v6VS.Virtual.SetVirtualAddress("2001:db8::1", 443)
+			otherVS := &ResourceConfig{MetaData: metaData{hosts: []string{"apps.example.com"}}}
+			mockCtlr.resources.ltmConfig["default"].ResourceMap["v4VS"] = v4VS
+			mockCtlr.resources.ltmConfig["default"].ResourceMap["v6VS"] = v6VS
+			mockCtlr.resources.ltmConfig["default"].ResourceMap["otherVS"] = otherVS
+
+			edns := test.NewExternalDNS("wildcard", namespace, cisapiv2.ExternalDNSSpec{
+				DomainName:     "*.apps.example.com",
+				Persistence:    cisapiv2.DNSPersistence{Enabled: true, TTL: 300},
+				LastResortPool: "/Common/sorry",
+				Pools:          []cisapiv2.DNSPool{{DataServerName: "/Common/GSLBServer"}},
+			})
+			mockCtlr.processExternalDNS(edns, false)
+			wip := mockCtlr.resources.gtmConfig[DEFAULT_PARTITION].WideIPs["*.apps.example.com"]
+			Expect(wip.Persistence).To(Equal(cisapiv2.DNSPersistence{Enabled: true, TTL: 300}))
+			Expect(wip.LastResortPool).To(Equal("/Common/sorry"))
+			Expect(wip.Pools[0].Name).To(HavePrefix("wildcard_apps.example.com_"))
+			Expect(wip.Pools[0].RecordType).To(Equal("A"))
+			Expect(wip.Pools[0].Members).To(Equal([]string{"/default/Shared/v4VS"}),
+				"The wildcard domain should match the IPv4 virtuals of its subdomains")
+
+			edns.Spec.DNSRecordType = "AAAA"
+			mockCtlr.processExternalDNS(edns, false)
+			wip = mockCtlr.resources.gtmConfig[DEFAULT_PARTITION].WideIPs["*.apps.example.com"]
+			Expect(wip.Pools[0].RecordType).To(Equal("AAAA"))
+			Expect(wip.Pools[0].Members).To(Equal([]string{"/default/Shared/v6VS"}))
+
+			cname := test.NewExternalDNS("cname", namespace, cisapiv2.ExternalDNSSpec{
+				DomainName:    "www.example.com",
+				DNSRecordType: "CNAME",
+				Pools: []cisapiv2.DNSPool{{
+					DataServerName: "/Common/GSLBServer",
+					Targets:        []cisapiv2.DNSTarget{{DomainName: "app.cdn.net"}},
+					Monitors:       []cisapiv2.Monitor{{Type: "tcp", Interval: 10}},
+				}},
+			})
+			mockCtlr.processExternalDNS(cname, false)
+			pool := mockCtlr.resources.gtmConfig[DEFAULT_PARTITION].WideIPs["www.example.com"].Pools[0]
+			Expect(pool.RecordType).To(Equal("CNAME"))
+			Expect(pool.Targets).To(Equal([]cisapiv2.DNSTarget{{DomainName: "app.cdn.net"}}))
+			Expect(pool.Members).To(BeEmpty())
+			Expect(pool.Monitors).To(BeEmpty())
+		})
+
 		It("Processing IngressLink", func() {
 			// Creation of IngressLink
 			fooPorts := []v1.ServicePort{
@@ -929,7 +1112,7 @@ var _ = Describe("Worker Tests", func() {
 			)
 			var iRules []string
 			IngressLink1 := test.NewIngressLink("ingresslink1", namespace, "1",
-				cisapiv1.IngressLinkSpec{
+				cisapiv2.IngressLinkSpec{
 					VirtualServerAddress: "1.2.3.4",
 					Selector:             selctor,
 					IRules:               iRules,
@@ -940,8 +1123,7 @@ var _ = Describe("Worker Tests", func() {
 					IngressLink: make(map[string]int),
 				},
 			}
-			_, _ = mockCtlr.kubeClient.CoreV1().Services("default").Create(context.Background(), foo,
-				metav1.CreateOptions{})
+			_ = mockCtlr.comInformers["default"].svcInformer.GetIndexer().Add(foo)
 			err := mockCtlr.processIngressLink(IngressLink1, false)
 			Expect(err).To(BeNil(), "Failed to process IngressLink while creation")
 			Expect(len(mockCtlr.resources.ltmConfig)).To(Equal(1), "Invalid LTM Config")
@@ -1038,9 +1220,9 @@ var _ = Describe("Worker Tests", func() {
 				//Policy CR
 				namespace = "default"
 				plc := test.NewPolicy("plc1", namespace,
-					cisapiv1.PolicySpec{
-						Profiles: cisapiv1.ProfileSpec{
-							TCP: cisapiv1.ProfileTCP{
+					cisapiv2.PolicySpec{
+						Profiles: cisapiv2.ProfileSpec{
+							TCP: cisapiv2.ProfileTCP{
 								Client: "/Common/f5-tcp-wan",
 							},
 							ProfileL4:          "/Common/security-fastL4",
@@ -1096,13 +1278,14 @@ var _ = Describe("Worker Tests", func() {
 					},
 				}
 				ipamCR, _ = mockCtlr.ipamCli.Update(ipamCR)
+				mockCtlr.resources.ipamCR = ipamCR
 
 				// Policy CRD not found
 				_ = mockCtlr.processLBServices(svc1, false)
 				Expect(len(mockCtlr.resources.ltmConfig)).To(Equal(0),
 					"Resource Config should be empty")
 
-				mockCtlr.comInformers[namespace].plcInformer = cisinfv1.NewFilteredPolicyInformer(
+				mockCtlr.comInformers[namespace].plcInformer = newPolicyInformer(
 					mockCtlr.kubeCRClient,
 					namespace,
 					0,
@@ -1147,102 +1330,276 @@ var _ = Describe("Worker Tests", func() {
 	})
 
 	Describe("Getting Associated virtuals", func() {
-		var vrt1 *cisapiv1.VirtualServer
-		var vrt2 *cisapiv1.VirtualServer
+		var vrt1 *cisapiv2.VirtualServer
+		var vrt2 *cisapiv2.VirtualServer
 		BeforeEach(func() {
 			vrt1 = test.NewVirtualServer(
 				"vrt1",
 				namespace,
-				cisapiv1.VirtualServerSpec{
+				cisapiv2.VirtualServerSpec{
 					TLSProfileName: "tls-profile-1",
 				},
 			)
 			vrt2 = test.NewVirtualServer(
 				"vrt2",
 				namespace,
-				cisapiv1.VirtualServerSpec{
+				cisapiv2.VirtualServerSpec{
 					TLSProfileName: "tls-profile-2",
 				},
 			)
 		})
 		It("Correctly skips adding the virtuals to associated virtuals if ports are not common", func() {
 			// Virtuals with common HTTP AND HTTPS ports
-			Expect(skipVirtual(vrt1, vrt2)).To(Equal(false), "Should not skip adding it to "+
+			Expect(mockCtlr.skipVirtual(vrt1, vrt2)).To(Equal(false), "Should not skip adding it to "+
 				"associated virtuals")
 			// Virtuals without any common HTTP AND HTTPS ports
 			vrt2.Spec.VirtualServerHTTPSPort = 8443
 			vrt2.Spec.VirtualServerHTTPPort = 8080
-			Expect(skipVirtual(vrt1, vrt2)).To(Equal(true), "Should skip adding it to "+
+			Expect(mockCtlr.skipVirtual(vrt1, vrt2)).To(Equal(true), "Should skip adding it to "+
 				"associated virtuals")
 			// Secured virtuals with common HTTPS ports
 			vrt1.Spec.VirtualServerHTTPSPort = 8443
-			Expect(skipVirtual(vrt1, vrt2)).To(Equal(false), "Should not skip adding it to "+
+			Expect(mockCtlr.skipVirtual(vrt1, vrt2)).To(Equal(false), "Should not skip adding it to "+
 				"associated virtuals")
 			// Virtuals with common HTTPS ports(default 443) but one of them is unsecured vs
 			vrt2.Spec.VirtualServerHTTPSPort = 0
 			vrt1.Spec.VirtualServerHTTPSPort = 0
 			vrt2.Spec.TLSProfileName = ""
-			Expect(skipVirtual(vrt1, vrt2)).To(Equal(true), "Should skip adding it to "+
+			Expect(mockCtlr.skipVirtual(vrt1, vrt2)).To(Equal(true), "Should skip adding it to "+
 				"associated virtuals")
 			// Secured virtuals with common HTTP ports, but HTTPTraffic is not allowed
 			vrt2.Spec.VirtualServerHTTPPort = 0
 			vrt2.Spec.VirtualServerHTTPSPort = 8443
 			vrt2.Spec.TLSProfileName = "tls-profile-2"
-			Expect(skipVirtual(vrt1, vrt2)).To(Equal(true), "Should skip adding it to "+
+			Expect(mockCtlr.skipVirtual(vrt1, vrt2)).To(Equal(true), "Should skip adding it to "+
 				"associated virtuals")
 			// Both secured virtuals with common HTTP ports, and handle HTTPTraffic
 			vrt1.Spec.HTTPTraffic = TLSAllowInsecure
 			vrt2.Spec.HTTPTraffic = TLSRedirectInsecure
-			Expect(skipVirtual(vrt1, vrt2)).To(Equal(false), "Should not skip adding it to "+
+			Expect(mockCtlr.skipVirtual(vrt1, vrt2)).To(Equal(false), "Should not skip adding it to "+
 				"associated virtuals")
 			// Both secured virtuals with common HTTP ports, and one of them doesn't handle HTTPTraffic
 			vrt1.Spec.HTTPTraffic = "none"
 			vrt2.Spec.HTTPTraffic = TLSRedirectInsecure
-			Expect(skipVirtual(vrt1, vrt2)).To(Equal(true), "Should skip adding it to "+
+			Expect(mockCtlr.skipVirtual(vrt1, vrt2)).To(Equal(true), "Should skip adding it to "+
 				"associated virtuals")
 			// One secured and one unsecured vs with common HTTP ports, and the secured one doesn't handle HTTPTraffic
 			vrt2.Spec.TLSProfileName = ""
-			Expect(skipVirtual(vrt1, vrt2)).To(Equal(true), "Should skip adding it to "+
+			Expect(mockCtlr.skipVirtual(vrt1, vrt2)).To(Equal(true), "Should skip adding it to "+
 				"associated virtuals")
 			// One secured and one unsecured vs with common HTTP ports, and the secured one handles HTTPTraffic
 			vrt1.Spec.HTTPTraffic = TLSAllowInsecure
-			Expect(skipVirtual(vrt1, vrt2)).To(Equal(false), "Should not skip adding it to "+
+			Expect(mockCtlr.skipVirtual(vrt1, vrt2)).To(Equal(false), "Should not skip adding it to "+
 				"associated virtuals")
 			// Both unsecured virtuals with common HTTP ports
 			vrt1.Spec.TLSProfileName = ""
-			Expect(skipVirtual(vrt1, vrt2)).To(Equal(false), "Should not skip adding it to "+
+			Expect(mockCtlr.skipVirtual(vrt1, vrt2)).To(Equal(false), "Should not skip adding it to "+
 				"associated virtuals")
 		})
 		It("Verifies whether correct effective HTTPS port is evaluated for the virtual server", func() {
-			Expect(getEffectiveHTTPSPort(vrt1)).To(Equal(DEFAULT_HTTPS_PORT), "Incorrect HTTPS port "+
+			Expect(mockCtlr.getEffectiveHTTPSPort(vrt1)).To(Equal(DEFAULT_HTTPS_PORT), "Incorrect HTTPS port "+
 				"value evaluated")
 			vrt1.Spec.VirtualServerHTTPSPort = 8443
-			Expect(getEffectiveHTTPSPort(vrt1)).To(Equal(vrt1.Spec.VirtualServerHTTPSPort), "Incorrect "+
+			Expect(mockCtlr.getEffectiveHTTPSPort(vrt1)).To(Equal(vrt1.Spec.VirtualServerHTTPSPort), "Incorrect "+
 				" HTTPS port value evaluated")
 			vrt1.Spec.VirtualServerHTTPSPort = DEFAULT_HTTPS_PORT
-			Expect(getEffectiveHTTPSPort(vrt1)).To(Equal(DEFAULT_HTTPS_PORT), "Incorrect HTTPS "+
+			Expect(mockCtlr.getEffectiveHTTPSPort(vrt1)).To(Equal(DEFAULT_HTTPS_PORT), "Incorrect HTTPS "+
 				"port value evaluated")
 		})
 		It("Verifies whether correct effective HTTP port is evaluated for the virtual server", func() {
-			Expect(getEffectiveHTTPPort(vrt1)).To(Equal(DEFAULT_HTTP_PORT), "Incorrect HTTP port value "+
+			Expect(mockCtlr.getEffectiveHTTPPort(vrt1)).To(Equal(DEFAULT_HTTP_PORT), "Incorrect HTTP port value "+
 				"evaluated")
 			vrt1.Spec.VirtualServerHTTPPort = 8080
-			Expect(getEffectiveHTTPPort(vrt1)).To(Equal(vrt1.Spec.VirtualServerHTTPPort), "Incorrect "+
+			Expect(mockCtlr.getEffectiveHTTPPort(vrt1)).To(Equal(vrt1.Spec.VirtualServerHTTPPort), "Incorrect "+
 				"HTTP port value evaluated")
 			vrt1.Spec.VirtualServerHTTPPort = DEFAULT_HTTP_PORT
-			Expect(getEffectiveHTTPPort(vrt1)).To(Equal(DEFAULT_HTTP_PORT), "Incorrect HTTP "+
+			Expect(mockCtlr.getEffectiveHTTPPort(vrt1)).To(Equal(DEFAULT_HTTP_PORT), "Incorrect HTTP "+
 				"port value evaluated")
 		})
 	})
 
+	Describe("DeployConfig port changes", func() {
+		It("Deletes the virtuals on the old default port shared by VirtualServers", func() {
+			mockCtlr.TeemData = &teem.TeemsData{
+				ResourceType: teem.ResourceTypes{
+					VirtualServer: make(map[string]int),
+				},
+			}
+			mockCtlr.namespaces = map[string]bool{namespace: true}
+			vs1 := test.NewVirtualServer("vs1", namespace, cisapiv2.VirtualServerSpec{
+				VirtualServerAddress: "1.2.3.5",
+				Pools:                []cisapiv2.Pool{{Path: "/foo", Service: "svc1", ServicePort: 80}},
+			})
+			vs2 := test.NewVirtualServer("vs2", namespace, cisapiv2.VirtualServerSpec{
+				VirtualServerAddress: "1.2.3.5",
+				Pools:                []cisapiv2.Pool{{Path: "/bar", Service: "svc1", ServicePort: 80}},
+			})
+			vs3 := test.NewVirtualServer("vs3", namespace, cisapiv2.VirtualServerSpec{
+				VirtualServerAddress:  "1.2.3.6",
+				VirtualServerHTTPPort: DEFAULT_HTTP_PORT,
+				Pools:                 []cisapiv2.Pool{{Path: "/foo", Service: "svc1", ServicePort: 80}},
+			})
+			for _, vs := range []*cisapiv2.VirtualServer{vs1, vs2, vs3} {
+				_ = mockCtlr.crInformers[namespace].vsInformer.GetStore().Add(vs)
+				Expect(mockCtlr.processVirtualServers(vs, false)).To(BeNil())
+			}
+			rsMap := mockCtlr.resources.getPartitionResourceMap(mockCtlr.Partition)
+			Expect(rsMap).To(HaveKey("crd_1_2_3_5_80"))
+			Expect(rsMap["crd_1_2_3_5_80"].MetaData.baseResources).To(HaveLen(2), "Virtual should be shared")
+			Expect(mockCtlr.resources.svcResourceCache).To(HaveKey(namespace + "_svc1"))
+
+			dc := test.NewDeployConfig("cis-deploy-config", cisapiv2.DeployConfigSpec{HTTPPort: 8080})
+			Expect(mockCtlr.processDeployConfig(dc, false)).To(BeTrue())
+			Expect(rsMap).NotTo(HaveKey("crd_1_2_3_5_80"), "Virtual on the old default port should be deleted")
+			Expect(mockCtlr.resources.svcResourceCache[namespace+"_svc1"]).NotTo(HaveKey("crd_1_2_3_5_80"))
+			Expect(rsMap).To(HaveKey("crd_1_2_3_6_80"), "Virtual on an explicit port should be kept")
+			Expect(vs1.Status.VSAddress).To(BeEmpty())
+
+			for _, vs := range []*cisapiv2.VirtualServer{vs1, vs2, vs3} {
+				Expect(mockCtlr.processVirtualServers(vs, false)).To(BeNil())
+			}
+			Expect(rsMap).To(HaveKey("crd_1_2_3_5_8080"))
+			Expect(rsMap["crd_1_2_3_5_8080"].MetaData.baseResources).To(HaveLen(2))
+			Expect(rsMap).NotTo(HaveKey("crd_1_2_3_5_80"))
+		})
+	})
+
+	Describe("Policy attachment by targetSelector", func() {
+		var vrt *cisapiv2.VirtualServer
+		var central, fallback, defaultPlc *cisapiv2.Policy
+		appsNamespace := "apps"
+
+		BeforeEach(func() {
+			mockCtlr.kubeClient = k8sfake.NewSimpleClientset()
+			mockCtlr.createNamespaceLabelsInformer()
+			_ = mockCtlr.nsLabelInformer.nsInformer.GetStore().Add(
+				&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace, Labels: map[string]string{"env": "prod"}}})
+			_ = mockCtlr.nsLabelInformer.nsInformer.GetStore().Add(
+				&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: appsNamespace}})
+			_ = mockCtlr.addNamespacedInformers(appsNamespace, false)
+
+			vrt = test.NewVirtualServer("vrt", namespace, cisapiv2.VirtualServerSpec{Host: "test.com"})
+			vrt.Labels = map[string]string{"team": "a"}
+			_ = mockCtlr.crInformers[namespace].vsInformer.GetStore().Add(vrt)
+
+			central = test.NewPolicy("central", appsNamespace, cisapiv2.PolicySpec{
+				Precedence: 10,
+				TargetSelector: &cisapiv2.PolicyTargetSelector{
+					NamespaceSelector: &metav1.LabelSelector{},
+					ObjectSelector:    &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+				},
+			})
+			fallback = test.NewPolicy("fallback", appsNamespace, cisapiv2.PolicySpec{
+				Precedence: 1,
+				TargetSelector: &cisapiv2.PolicyTargetSelector{
+					NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+				},
+			})
+			defaultPlc = test.NewPolicy("default-policy", appsNamespace, cisapiv2.PolicySpec{})
+			for _, plc := range []*cisapiv2.Policy{central, fallback, defaultPlc} {
+				_ = mockCtlr.comInformers[appsNamespace].plcInformer.GetStore().Add(plc)
+			}
+		})
+
+		It("Resolves the policy with the highest precedence", func() {
+			plc, err := mockCtlr.getPolicyFromVirtuals([]*cisapiv2.VirtualServer{vrt})
+			Expect(err).To(BeNil())
+			Expect(plc).To(Equal(central), "Policy with highest precedence should be attached")
+
+			vrt.Labels = nil
+			plc, err = mockCtlr.getPolicyFromVirtuals([]*cisapiv2.VirtualServer{vrt})
+			Expect(err).To(BeNil())
+			Expect(plc).To(Equal(fallback), "Policy selecting the namespace should be attached")
+
+			vrt.Namespace = appsNamespace
+			plc, err = mockCtlr.getPolicyFromVirtuals([]*cisapiv2.VirtualServer{vrt})
+			Expect(err).To(BeNil())
+			Expect(plc).To(BeNil(), "No policy should be attached")
+
+			mockCtlr.deployConfigSpec.DefaultPolicy = appsNamespace + "/" + defaultPlc.Name
+			plc, err = mockCtlr.getPolicyFromVirtuals([]*cisapiv2.VirtualServer{vrt})
+			Expect(err).To(BeNil())
+			Expect(plc).To(Equal(defaultPlc), "Default policy should be attached")
+		})
+
+		It("Prefers the policy named by the VirtualServer", func() {
+			named := test.NewPolicy("named", namespace, cisapiv2.PolicySpec{})
+			_ = mockCtlr.comInformers[namespace].plcInformer.GetStore().Add(named)
+			vrt.Spec.PolicyName = named.Name
+
+			plc, err := mockCtlr.getPolicyFromVirtuals([]*cisapiv2.VirtualServer{vrt})
+			Expect(err).To(BeNil())
+			Expect(plc).To(Equal(named), "Named policy should be attached")
+			Expect(mockCtlr.getVirtualsForCustomPolicy(central)).To(BeEmpty(),
+				"VirtualServer naming a policy should not be affected by selector policies")
+			Expect(mockCtlr.getVirtualsForCustomPolicy(named)).To(Equal([]*cisapiv2.VirtualServer{vrt}))
+		})
+
+		It("Finds the VirtualServers selected by a policy", func() {
+			Expect(mockCtlr.getVirtualsForCustomPolicy(central)).To(Equal([]*cisapiv2.VirtualServer{vrt}))
+			Expect(mockCtlr.getVirtualsForCustomPolicy(fallback)).To(Equal([]*cisapiv2.VirtualServer{vrt}))
+			Expect(mockCtlr.getVirtualsForCustomPolicy(defaultPlc)).To(BeEmpty())
+
+			mockCtlr.deployConfigSpec.DefaultPolicy = appsNamespace + "/" + defaultPlc.Name
+			Expect(mockCtlr.getVirtualsForCustomPolicy(defaultPlc)).To(Equal([]*cisapiv2.VirtualServer{vrt}))
+		})
+
+		It("Creates the namespace labels informer on its first use", func() {
+			mockCtlr.nsLabelInformer = nil
+			mockCtlr.kubeClient = k8sfake.NewSimpleClientset(
+				&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace, Labels: map[string]string{"env": "prod"}}})
+			nsLabels, found := mockCtlr.getNamespaceLabels(namespace)
+			Expect(found).To(BeTrue(), "Informer should be synced before the labels are read")
+			Expect(nsLabels).To(Equal(map[string]string{"env": "prod"}))
+			Expect(mockCtlr.nsLabelInformer).NotTo(BeNil())
+			mockCtlr.nsLabelInformer.stop()
+		})
+
+		It("Reads the labels from the namespace informers when watching the namespaces by label", func() {
+			mockCtlr.nsLabelInformer = nil
+			nsInf := &NSInformer{
+				stopCh:     make(chan struct{}),
+				nsInformer: cache.NewSharedIndexInformer(&cache.ListWatch{}, &v1.Namespace{}, 0, cache.Indexers{}),
+			}
+			_ = nsInf.nsInformer.GetStore().Add(
+				&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace, Labels: map[string]string{"cis": "true"}}})
+			mockCtlr.nsInformers = map[string]*NSInformer{"cis=true": nsInf}
+			nsLabels, found := mockCtlr.getNamespaceLabels(namespace)
+			Expect(found).To(BeTrue())
+			Expect(nsLabels).To(Equal(map[string]string{"cis": "true"}))
+			_, found = mockCtlr.getNamespaceLabels(appsNamespace)
+			Expect(found).To(BeFalse())
+			Expect(mockCtlr.nsLabelInformer).To(BeNil(), "Namespace labels informer should not be created")
+		})
+
+		It("Enqueues the resources of a namespace when its labels change", func() {
+			mockCtlr.resourceQueue = workqueue.NewNamedRateLimitingQueue(
+				workqueue.DefaultControllerRateLimiter(), "custom-resource-controller")
+			defer mockCtlr.resourceQueue.ShutDown()
+			oldNS := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace, Labels: map[string]string{"env": "prod"}}}
+			newNS := oldNS.DeepCopy()
+			mockCtlr.enqueueUpdatedNamespaceLabels(oldNS, newNS)
+			Expect(mockCtlr.resourceQueue.Len()).To(BeZero(), "Unchanged labels should be ignored")
+
+			newNS.Labels = nil
+			_ = mockCtlr.nsLabelInformer.nsInformer.GetStore().Update(newNS)
+			mockCtlr.enqueueUpdatedNamespaceLabels(oldNS, newNS)
+			Expect(mockCtlr.resourceQueue.Len()).To(Equal(1))
+			key, _ := mockCtlr.resourceQueue.Get()
+			Expect(key.(*rqKey).rscName).To(Equal(vrt.Name))
+			Expect(mockCtlr.getVirtualsForCustomPolicy(fallback)).To(BeEmpty(),
+				"Namespace should no longer be selected")
+		})
+	})
+
 	Describe("Deletion of virtuals", func() {
-		var vrts []*cisapiv1.VirtualServer
-		var vrt2 *cisapiv1.VirtualServer
+		var vrts []*cisapiv2.VirtualServer
+		var vrt2 *cisapiv2.VirtualServer
 		BeforeEach(func() {
-			vrts = []*cisapiv1.VirtualServer{test.NewVirtualServer(
+			vrts = []*cisapiv2.VirtualServer{test.NewVirtualServer(
 				"vrt1",
 				namespace,
-				cisapiv1.VirtualServerSpec{
+				cisapiv2.VirtualServerSpec{
 					TLSProfileName: "tls-profile-1",
 					HTTPTraffic:    TLSAllowInsecure,
 				},
@@ -1250,7 +1607,7 @@ var _ = Describe("Worker Tests", func() {
 			vrt2 = test.NewVirtualServer(
 				"vrt2",
 				namespace,
-				cisapiv1.VirtualServerSpec{
+				cisapiv2.VirtualServerSpec{
 					TLSProfileName:         "tls-profile-2",
 					HTTPTraffic:            TLSAllowInsecure,
 					VirtualServerHTTPSPort: 8443,
@@ -1259,27 +1616,27 @@ var _ = Describe("Worker Tests", func() {
 		})
 		It("Verifies whether any of the associated virtuals handle HTTP traffic", func() {
 			// Check doVSHandleHTTP when associated virtuals handle HTTP traffic
-			Expect(doVSHandleHTTP(vrts, vrt2)).To(Equal(true), "Invalid value")
+			Expect(mockCtlr.doVSHandleHTTP(vrts, vrt2)).To(Equal(true), "Invalid value")
 			// Check doVSHandleHTTP when associated virtuals don't handle HTTP traffic
 			vrts[0].Spec.HTTPTraffic = ""
-			Expect(doVSHandleHTTP(vrts, vrt2)).To(Equal(false), "Invalid value")
+			Expect(mockCtlr.doVSHandleHTTP(vrts, vrt2)).To(Equal(false), "Invalid value")
 			// Check doVSHandleHTTP when associated unsecured virtual uses the same port that the current virtual does
 			vrts[0].Spec.TLSProfileName = ""
-			Expect(doVSHandleHTTP(vrts, vrt2)).To(Equal(true), "Invalid value")
+			Expect(mockCtlr.doVSHandleHTTP(vrts, vrt2)).To(Equal(true), "Invalid value")
 			// Check doVSHandleHTTP when associated unsecured virtual uses a different port
 			vrts[0].Spec.VirtualServerHTTPPort = 8080
-			Expect(doVSHandleHTTP(vrts, vrt2)).To(Equal(false), "Invalid value")
+			Expect(mockCtlr.doVSHandleHTTP(vrts, vrt2)).To(Equal(false), "Invalid value")
 		})
 		It("Verifies whether any of the associated virtuals uses the same HTTPS port", func() {
 			// Check when associated secured virtuals use same HTTPS port
 			vrts[0].Spec.VirtualServerHTTPSPort = 8443
-			Expect(doVSUseSameHTTPSPort(vrts, vrt2)).To(Equal(true), "Invalid value")
+			Expect(mockCtlr.doVSUseSameHTTPSPort(vrts, vrt2)).To(Equal(true), "Invalid value")
 			// Check when none of the associated secured virtuals uses same HTTPS port
 			vrts[0].Spec.VirtualServerHTTPSPort = 443
-			Expect(doVSUseSameHTTPSPort(vrts, vrt2)).To(Equal(false), "Invalid value")
+			Expect(mockCtlr.doVSUseSameHTTPSPort(vrts, vrt2)).To(Equal(false), "Invalid value")
 			// Check when associated virtuals has an unsecured virtual
 			vrts[0].Spec.TLSProfileName = ""
-			Expect(doVSUseSameHTTPSPort(vrts, vrt2)).To(Equal(false), "Invalid value")
+			Expect(mockCtlr.doVSUseSameHTTPSPort(vrts, vrt2)).To(Equal(false), "Invalid value")
 
 		})
 	})
@@ -1335,5 +1692,544 @@ var _ = Describe("Worker Tests", func() {
 			mockCtlr.updatePoolMembersForNodePort(rsCfg, "default")
 			Expect(len(rsCfg.Pools[0].Members)).To(Equal(2), "Members should be reduced")
 		})
+		It("verify pool members of service with externalTrafficPolicy Local", func() {
+			svc := test.NewService("svc-1", "1", "default", v1.ServiceTypeNodePort,
+				[]v1.ServicePort{{Name: "https", Port: 443, NodePort: 32443, TargetPort: intstr.FromInt(443)}})
+			svc.Spec.ExternalTrafficPolicy = v1.ServiceExternalTrafficPolicyTypeLocal
+			eps := test.NewEndpoints("svc-1", "1", "node-2", "default",
+				[]string{"10.1.1.1"}, nil, []v1.EndpointPort{{Name: "https", Port: 443}})
+			Expect(mockCtlr.processService(svc, eps, false)).To(BeNil())
+
+			pool := Pool{ServiceNamespace: "default",
+				ServiceName: "svc-1",
+				ServicePort: intstr.FromInt(443)}
+			rsCfg := &ResourceConfig{Pools: []Pool{pool}}
+			mockCtlr.updatePoolMembersForNodePort(rsCfg, "default")
+			Expect(rsCfg.Pools[0].Members).To(Equal([]PoolMember{
+				{Address: "10.10.10.2", Port: 32443, Session: "user-enabled"},
+			}), "Only the nodes with endpoints should be members")
+
+			// Nodes with NotReady endpoints are not members
+			eps = test.NewEndpoints("svc-1", "2", "node-1", "default",
+				[]string{"10.1.1.1"}, []string{"10.1.1.2"}, []v1.EndpointPort{{Name: "https", Port: 443}})
+			eps.Subsets[0].NotReadyAddresses[0].NodeName = &mockCtlr.oldNodes[1].Name
+			Expect(mockCtlr.processService(svc, eps, false)).To(BeNil())
+			mockCtlr.updatePoolMembersForNodePort(rsCfg, "default")
+			Expect(rsCfg.Pools[0].Members).To(Equal([]PoolMember{
+				{Address: "10.10.10.1", Port: 32443, Session: "user-enabled"},
+			}), "Only the nodes with ready endpoints should be members")
+
+			// All the nodes are members with externalTrafficPolicy Cluster
+			svc.Spec.ExternalTrafficPolicy = v1.ServiceExternalTrafficPolicyTypeCluster
+			Expect(mockCtlr.processService(svc, eps, false)).To(BeNil())
+			mockCtlr.updatePoolMembersForNodePort(rsCfg, "default")
+			Expect(rsCfg.Pools[0].Members).To(HaveLen(2))
+		})
+	})
+	Describe("Multi cluster pool members", func() {
+		var rc *RemoteClusterInformer
+		var pool Pool
+		var localMembers []PoolMember
+
+		BeforeEach(func() {
+			mockCtlr.PoolMemberType = "cluster"
+			mockCtlr.localClusterRatio = 3
+			mockCtlr.resources.poolMemCache = make(map[string]poolMembersInfo)
+			rc = mockCtlr.newRemoteClusterInformer("cluster-2", k8sfake.NewSimpleClientset())
+			rc.ratio = 1
+			rc.priority = 5
+			rc.setUp(true)
+			mockCtlr.remoteClusters = map[string]*RemoteClusterInformer{rc.name: rc}
+			localMembers = []PoolMember{{Address: "10.1.0.1", Port: 8080, Session: "user-enabled"}}
+			mockCtlr.resources.poolMemCache["default/svc-1"] = poolMembersInfo{
+				svcType:   v1.ServiceTypeClusterIP,
+				portSpec:  []v1.ServicePort{{Name: "http", Port: 80, NodePort: 30080, TargetPort: intstr.FromInt(8080)}},
+				memberMap: map[portRef][]PoolMember{{name: "http", port: 8080}: localMembers},
+			}
+			pool = Pool{
+				Name:             "svc_1_pool",
+				ServiceNamespace: "default",
+				ServiceName:      "svc-1",
+				ServicePort:      intstr.FromInt(8080),
+			}
+			_ = rc.epsInformer.GetIndexer().Add(&v1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{Name: "svc-1", Namespace: "default"},
+				Subsets: []v1.EndpointSubset{{
+					Addresses: []v1.EndpointAddress{{IP: "10.2.0.1"}, {IP: "10.2.0.2"}},
+					Ports:     []v1.EndpointPort{{Name: "http", Port: 8080}},
+				}},
+			})
+		})
+
+		It("merges the endpoints of the remote cluster with their weights", func() {
+			rsCfg := &ResourceConfig{Pools: []Pool{pool}}
+			mockCtlr.updatePoolMembersForCluster(rsCfg, "default")
+			Expect(rsCfg.Pools[0].Members).To(Equal([]PoolMember{
+				{Address: "10.1.0.1", Port: 8080, Session: "user-enabled", Ratio: 3},
+				{Address: "10.2.0.1", Port: 8080, Session: "user-enabled", Ratio: 1, PriorityGroup: 5},
+				{Address: "10.2.0.2", Port: 8080, Session: "user-enabled", Ratio: 1, PriorityGroup: 5},
+			}))
+			Expect(localMembers[0].Ratio).To(BeZero(), "Pool member cache should not be modified")
+
+			sharedApp := as3Application{}
+			createPoolDecl(rsCfg, sharedApp, false, "test")
+			as3Pl := sharedApp["svc_1_pool"].(*as3Pool)
+			Expect(as3Pl.Members[1].Ratio).To(Equal(int32(1)))
+			Expect(as3Pl.Members[1].PriorityGroup).To(Equal(int32(5)))
+
+			// pool members of the local cluster are kept when it has no endpoints
+			delete(mockCtlr.resources.poolMemCache, "default/svc-1")
+			mockCtlr.updatePoolMembersForCluster(rsCfg, "default")
+			Expect(len(rsCfg.Pools[0].Members)).To(Equal(2), "Remote members should remain")
+			Expect(rsCfg.MetaData.Active).To(BeTrue())
+		})
+
+		It("withdraws the members of an unreachable remote cluster only", func() {
+			rsCfg := &ResourceConfig{Pools: []Pool{pool}}
+			rc.setUp(false)
+			mockCtlr.updatePoolMembersForCluster(rsCfg, "default")
+			Expect(rsCfg.Pools[0].Members).To(Equal([]PoolMember{
+				{Address: "10.1.0.1", Port: 8080, Session: "user-enabled", Ratio: 3},
+			}))
+			Expect(rc.setUp(true)).To(BeTrue(), "Reachability change should be reported")
+			mockCtlr.updatePoolMembersForCluster(rsCfg, "default")
+			Expect(len(rsCfg.Pools[0].Members)).To(Equal(3), "Remote members should be restored")
+		})
+
+		It("merges the nodes of the remote cluster in nodeport mode", func() {
+			mockCtlr.PoolMemberType = NodePort
+			mockCtlr.UseNodeInternal = true
+			mockCtlr.oldNodes = []Node{{Name: "node-1", Addr: "10.10.10.1"}}
+			mockCtlr.crInformers["default"] = &CRInformer{}
+			_ = rc.svcInformer.GetIndexer().Add(&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "svc-1", Namespace: "default"},
+				Spec: v1.ServiceSpec{
+					Type:  v1.ServiceTypeNodePort,
+					Ports: []v1.ServicePort{{Name: "http", Port: 80, NodePort: 31080, TargetPort: intstr.FromInt(8080)}},
+				},
+			})
+			for _, node := range []*v1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "remote-2"},
+					Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.20.0.2"}}},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "remote-1"},
+					Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.20.0.1"}}},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "remote-3"},
+					Spec:       v1.NodeSpec{Unschedulable: true},
+					Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.20.0.3"}}},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "remote-4"},
+					Status: v1.NodeStatus{
+						Addresses:  []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.20.0.4"}},
+						Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionFalse}},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "remote-5", Labels: map[string]string{v1.LabelNodeExcludeBalancers: ""}},
+					Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.20.0.5"}}},
+				},
+			} {
+				_ = rc.nodeInformer.GetIndexer().Add(node)
+			}
+			rsCfg := &ResourceConfig{Pools: []Pool{pool}}
+			mockCtlr.updatePoolMembersForNodePort(rsCfg, "default")
+			Expect(rsCfg.Pools[0].Members).To(Equal([]PoolMember{
+				{Address: "10.10.10.1", Port: 30080, Session: "user-enabled", Ratio: 3},
+				{Address: "10.20.0.1", Port: 31080, Session: "user-enabled", Ratio: 1, PriorityGroup: 5},
+				{Address: "10.20.0.2", Port: 31080, Session: "user-enabled", Ratio: 1, PriorityGroup: 5},
+			}))
+
+			// Only the remote nodes with endpoints serve the services with externalTrafficPolicy Local
+			_ = rc.svcInformer.GetIndexer().Update(&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "svc-1", Namespace: "default"},
+				Spec: v1.ServiceSpec{
+					Type:                  v1.ServiceTypeNodePort,
+					ExternalTrafficPolicy: v1.ServiceExternalTrafficPolicyTypeLocal,
+					Ports:                 []v1.ServicePort{{Name: "http", Port: 80, NodePort: 31080, TargetPort: intstr.FromInt(8080)}},
+				},
+			})
+			nodeName := "remote-2"
+			_ = rc.epsInformer.GetIndexer().Update(&v1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{Name: "svc-1", Namespace: "default"},
+				Subsets: []v1.EndpointSubset{{
+					Addresses: []v1.EndpointAddress{{IP: "10.2.0.2", NodeName: &nodeName}},
+					Ports:     []v1.EndpointPort{{Name: "http", Port: 8080}},
+				}},
+			})
+			mockCtlr.updatePoolMembersForNodePort(rsCfg, "default")
+			Expect(rsCfg.Pools[0].Members).To(Equal([]PoolMember{
+				{Address: "10.10.10.1", Port: 30080, Session: "user-enabled", Ratio: 3},
+				{Address: "10.20.0.2", Port: 31080, Session: "user-enabled", Ratio: 1, PriorityGroup: 5},
+			}))
+		})
+
+		It("marks the remote cluster up once its informers are synced", func() {
+			mockCtlr.resourceQueue = workqueue.NewNamedRateLimitingQueue(
+				workqueue.DefaultControllerRateLimiter(), "custom-resource-controller")
+			defer mockCtlr.resourceQueue.ShutDown()
+			remoteSvc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc-1", Namespace: "default"}}
+			rc = mockCtlr.newRemoteClusterInformer("cluster-3", k8sfake.NewSimpleClientset(remoteSvc))
+			Expect(rc.isUp()).To(BeFalse(), "Remote cluster should not be up before its informers are synced")
+			mockCtlr.startRemoteCluster(rc)
+			defer rc.stop()
+			Eventually(rc.isUp).Should(BeTrue())
+			Expect(rc.svcInformer.HasSynced()).To(BeTrue())
+			Expect(rc.epsInformer.HasSynced()).To(BeTrue())
+			_, found, _ := rc.svcInformer.GetIndexer().GetByKey("default/svc-1")
+			Expect(found).To(BeTrue())
+		})
+
+		It("matches the remote endpoints by the name or the number of the port", func() {
+			_ = rc.epsInformer.GetIndexer().Update(&v1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{Name: "svc-1", Namespace: "default"},
+				Subsets: []v1.EndpointSubset{
+					{
+						Addresses: []v1.EndpointAddress{{IP: "10.2.0.1"}},
+						Ports:     []v1.EndpointPort{{Name: "http", Port: 8080}},
+					},
+					{
+						Addresses: []v1.EndpointAddress{{IP: "10.2.0.2"}},
+						Ports:     []v1.EndpointPort{{Port: 9090}},
+					},
+				},
+			})
+			Expect(getRemoteClusterMembers(rc, pool)).To(Equal([]PoolMember{
+				{Address: "10.2.0.1", Port: 8080, Session: "user-enabled"},
+			}), "Unnamed port of another number should not match")
+
+			pool.ServicePort = intstr.FromString("http")
+			Expect(getRemoteClusterMembers(rc, pool)).To(Equal([]PoolMember{
+				{Address: "10.2.0.1", Port: 8080, Session: "user-enabled"},
+			}))
+			pool.ServicePort = intstr.FromInt(9090)
+			Expect(getRemoteClusterMembers(rc, pool)).To(Equal([]PoolMember{
+				{Address: "10.2.0.2", Port: 9090, Session: "user-enabled"},
+			}))
+		})
+
+		It("reconnects to the remote cluster when its kubeconfig secret is updated", func() {
+			mockCtlr.resourceQueue = workqueue.NewNamedRateLimitingQueue(
+				workqueue.DefaultControllerRateLimiter(), "custom-resource-controller")
+			defer mockCtlr.resourceQueue.ShutDown()
+			secret := &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "cluster-2",
+					Namespace:       "kube-system",
+					ResourceVersion: "1",
+					Annotations:     map[string]string{ClusterRatioAnnotation: "2"},
+				},
+				Data: map[string][]byte{MultiClusterKubeConfigKey: []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: cluster-2
+  cluster:
+    server: https://127.0.0.1:1
+contexts:
+- name: cluster-2
+  context:
+    cluster: cluster-2
+current-context: cluster-2
+`)},
+			}
+			rc.secretVersion = "1"
+			mockCtlr.processMultiClusterSecret(secret, false)
+			Expect(mockCtlr.remoteClusters["cluster-2"]).To(BeIdenticalTo(rc), "Unchanged secret should be ignored")
+
+			secret.ResourceVersion = "2"
+			mockCtlr.processMultiClusterSecret(secret, false)
+			newRC := mockCtlr.remoteClusters["cluster-2"]
+			Expect(newRC).ToNot(BeIdenticalTo(rc))
+			Expect(newRC.ratio).To(Equal(int32(2)))
+			Expect(rc.stopCh).To(BeClosed(), "Informers of the old kubeconfig should be stopped")
+
+			secret.ResourceVersion = "3"
+			delete(secret.Data, MultiClusterKubeConfigKey)
+			mockCtlr.processMultiClusterSecret(secret, false)
+			Expect(mockCtlr.remoteClusters["cluster-2"]).To(BeIdenticalTo(newRC), "Invalid secret should be ignored")
+
+			mockCtlr.processMultiClusterSecret(secret, true)
+			Expect(mockCtlr.remoteClusters).To(BeEmpty())
+			Expect(newRC.stopCh).To(BeClosed())
+		})
+	})
+
+	Describe("AS3 overrides", func() {
+		var rsCfg *ResourceConfig
+		BeforeEach(func() {
+			workingDir, _ := os.Getwd()
+			mockCtlr.Agent.as3Validator = newAS3SchemaValidator("file://"+workingDir+"/../../schemas/", as3VersionInfo{})
+			rsCfg = &ResourceConfig{}
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Name = "crd_vs_10.1.1.1_80"
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.Virtual.Destination = "/test/10.1.1.1:80"
+			rsCfg.Virtual.SNAT = "auto"
+		})
+
+		It("Validates the overrides of the virtuals", func() {
+			Expect(mockCtlr.handleAS3Override(rsCfg, "default/vs1", `{"maxConnections": "many"}`)).To(BeTrue())
+			Expect(mockCtlr.as3OverrideErrors.status("default/vs1")).To(HavePrefix("Invalid AS3 override"))
+			Expect(rsCfg.Virtual.AS3Override).To(BeEmpty(), "Invalid override should be discarded")
+			Expect(mockCtlr.handleAS3Override(rsCfg, "default/vs1", `{"maxConnections": "many"}`)).To(BeFalse(),
+				"Unchanged validation result should not be reported again")
+			Expect(mockCtlr.handleAS3Override(rsCfg, "default/vs1", `{"maxConnections": `)).To(BeTrue())
+
+			Expect(mockCtlr.handleAS3Override(rsCfg, "default/vs1", `{"maxConnections": 100}`)).To(BeTrue())
+			Expect(mockCtlr.as3OverrideErrors.status("default/vs1")).To(Equal("Ok"))
+			Expect(mockCtlr.handleAS3Override(rsCfg, "default/vs2", `{"remark": "vs2"}`)).To(BeFalse())
+			Expect(rsCfg.Virtual.AS3Override).To(MatchJSON(`{"maxConnections": 100, "remark": "vs2"}`))
+
+			mockCtlr.as3OverrideErrors.delete("default/vs1")
+			Expect(mockCtlr.as3OverrideErrors.status("default/vs1")).To(Equal("Ok"))
+		})
+
+		It("Processes the AS3 override ConfigMap of the tenants", func() {
+			cm := test.NewConfigMap("as3-override", "1", "kube-system", map[string]string{
+				OverrideAS3TemplateKey: `{"declaration": {"test": {"Shared": {"vs": {"remark": "x"}}}}}`,
+			})
+			mockCtlr.processAS3OverrideConfigMap(cm, false)
+			Expect(mockCtlr.resources.as3TenantOverrides).To(HaveKey("test"))
+			Expect(mockCtlr.resources.as3TenantOverrides["test"]).To(MatchJSON(`{"Shared": {"vs": {"remark": "x"}}}`))
+			Expect(mockCtlr.resources.getUpdatedPartitions()).To(HaveKey("test"), "Override should be posted")
+			mockCtlr.resources.updateCaches()
+			Expect(mockCtlr.resources.getUpdatedPartitions()).To(BeEmpty())
+
+			cm.Labels = map[string]string{OverrideAS3Label: "false"}
+			mockCtlr.processAS3OverrideConfigMap(cm, false)
+			Expect(mockCtlr.resources.as3TenantOverrides).To(BeEmpty(), "Staged override should not be applied")
+		})
+	})
+
+	Describe("Validating admission webhook", func() {
+		var crInf *CRInformer
+		var comInf *CommonInformer
+		BeforeEach(func() {
+			crInf, _ = mockCtlr.getNamespacedCRInformer(namespace)
+			comInf, _ = mockCtlr.getNamespacedCommonInformer(namespace)
+			_ = crInf.vsInformer.GetStore().Add(vrt1)
+		})
+
+		It("Validates VirtualServers against the cached resources", func() {
+			vs := test.NewVirtualServer("vs2", namespace, cisapiv2.VirtualServerSpec{
+				Host:  "test.com",
+				Pools: []cisapiv2.Pool{{Path: "/path", Service: "svc1"}},
+			})
+			Expect(mockCtlr.validateVirtualServerAdmission(vs)).To(MatchError(ContainSubstring("No IP was specified")))
+
+			vs.Spec.VirtualServerAddress = "1.2.3.4"
+			Expect(mockCtlr.validateVirtualServerAdmission(vs)).To(
+				MatchError("host test.com and path /path are already used by VirtualServer default/SampleVS"))
+			Expect(mockCtlr.validateVirtualServerAdmission(vrt1)).To(BeNil(), "Update of the same VirtualServer should be allowed")
+
+			vs.Spec.VirtualServerHTTPPort = 8080
+			Expect(mockCtlr.validateVirtualServerAdmission(vs)).To(BeNil(), "Same host and path on another port should be allowed")
+
+			vs.Spec.TLSProfileName = "tls"
+			Expect(mockCtlr.validateVirtualServerAdmission(vs)).To(MatchError("TLSProfile default/tls does not exist"))
+			_ = crInf.tlsInformer.GetStore().Add(test.NewTLSProfile("tls", namespace, cisapiv2.TLSProfileSpec{}))
+			vs.Spec.PolicyName = "plc"
+			Expect(mockCtlr.validateVirtualServerAdmission(vs)).To(MatchError("Policy default/plc does not exist"))
+			_ = comInf.plcInformer.GetStore().Add(test.NewPolicy("plc", namespace, cisapiv2.PolicySpec{}))
+			Expect(mockCtlr.validateVirtualServerAdmission(vs)).To(BeNil())
+
+			_ = crInf.tsInformer.GetStore().Add(test.NewTransportServer("ts", namespace, cisapiv2.TransportServerSpec{
+				VirtualServerAddress: "1.2.3.4",
+				VirtualServerPort:    443,
+			}))
+			Expect(mockCtlr.validateVirtualServerAdmission(vs)).To(
+				MatchError("address 1.2.3.4 and port 443 are already used by TransportServer default/ts"))
+		})
+
+		It("Validates TransportServers against the cached resources", func() {
+			_ = crInf.tsInformer.GetStore().Add(test.NewTransportServer("ts", namespace, cisapiv2.TransportServerSpec{
+				VirtualServerAddress: "1.2.3.5",
+				VirtualServerPort:    8000,
+			}))
+			ts := test.NewTransportServer("ts2", namespace, cisapiv2.TransportServerSpec{
+				VirtualServerAddress: "1.2.3.5",
+				VirtualServerPort:    8000,
+				Type:                 "tcp",
+			})
+			Expect(mockCtlr.validateTransportServerAdmission(ts)).To(
+				MatchError("No service or servicePort was specified for the pool of transport server ts2"))
+			ts.Spec.Pool = cisapiv2.Pool{
+				Service:     "svc1",
+				ServicePort: 80,
+				Monitors:    []cisapiv2.Monitor{{Type: "http", Interval: 10}},
+			}
+			Expect(mockCtlr.validateTransportServerAdmission(ts)).To(
+				MatchError(ContainSubstring("unsupported type http, supported values are tcp, udp")))
+			ts.Spec.Pool.Monitors[0].Type = "tcp"
+			Expect(mockCtlr.validateTransportServerAdmission(ts)).To(
+				MatchError("address 1.2.3.5 and port 8000 are already used by TransportServer default/ts"))
+			ts.Spec.Type = "udp"
+			Expect(mockCtlr.validateTransportServerAdmission(ts)).To(BeNil(), "UDP and TCP virtuals should share the port")
+			ts.Spec.Type = "http"
+			Expect(mockCtlr.validateTransportServerAdmission(ts)).To(MatchError(ContainSubstring("Invalid type value")))
+
+			ts.Spec.Type = ""
+			ts.Spec.VirtualServerAddress = "1.2.3.4"
+			ts.Spec.VirtualServerPort = 80
+			Expect(mockCtlr.validateTransportServerAdmission(ts)).To(
+				MatchError("address 1.2.3.4 and port 80 are already used by VirtualServer default/SampleVS"))
+
+			// The virtuals not specifying a port use the default port of the DeployConfig
+			mockCtlr.deployConfigSpec.HTTPPort = 8081
+			Expect(mockCtlr.validateTransportServerAdmission(ts)).To(BeNil())
+			ts.Spec.VirtualServerPort = 8081
+			Expect(mockCtlr.validateTransportServerAdmission(ts)).To(
+				MatchError("address 1.2.3.4 and port 8081 are already used by VirtualServer default/SampleVS"))
+		})
+
+		It("Validates TLSProfiles, Policies and ExternalDNS", func() {
+			tls := test.NewTLSProfile("tls", namespace, cisapiv2.TLSProfileSpec{
+				TLS: cisapiv2.TLS{Termination: "edge", ClientSSLs: []string{"/Common/clientssl"}, ServerSSLs: []string{"/Common/serverssl"}},
+			})
+			Expect(validateTLSProfileSpec(tls)).To(MatchError(ContainSubstring("should NOT contain ServerSSLs")))
+
+			plc := test.NewPolicy("plc", namespace, cisapiv2.PolicySpec{
+				RateLimit: cisapiv2.RateLimitSpec{RequestsPerSecond: 10, Key: "header"},
+			})
+			Expect(validatePolicySpec(plc)).To(MatchError("header is required for the rateLimit key header"))
+			plc.Spec.RateLimit.Header = `X-Client"]`
+			Expect(validatePolicySpec(plc)).To(MatchError(ContainSubstring("invalid rateLimit header")))
+			plc.Spec.RateLimit.Header = "X-Client"
+			plc.Spec.L3Policies.AllowSourceRange = []string{"10.1.0.0/16", "10.2.0.0"}
+			Expect(validatePolicySpec(plc)).To(MatchError(ContainSubstring("invalid allowSourceRange 10.2.0.0")))
+
+			_ = comInf.ednsInformer.GetStore().Add(test.NewExternalDNS("edns", namespace, cisapiv2.ExternalDNSSpec{
+				DomainName: "test.com",
+			}))
+			edns := test.NewExternalDNS("edns2", "default", cisapiv2.ExternalDNSSpec{DomainName: "test.com"})
+			Expect(mockCtlr.validateExternalDNSAdmission(edns)).To(
+				MatchError("domain test.com is already used by ExternalDNS default/edns"))
+			edns.Spec.DomainName = "other.test.com"
+			Expect(mockCtlr.validateExternalDNSAdmission(edns)).To(BeNil())
+
+			edns.Spec.DNSRecordType = "CNAME"
+			edns.Spec.Pools = []cisapiv2.DNSPool{{DataServerName: "/Common/GSLBServer", DNSRecordType: "A"}}
+			Expect(mockCtlr.validateExternalDNSAdmission(edns)).To(MatchError(ContainSubstring("does not match the dnsRecordType CNAME")))
+			edns.Spec.Pools[0].DNSRecordType = ""
+			Expect(mockCtlr.validateExternalDNSAdmission(edns)).To(MatchError(ContainSubstring("CNAME pool of /Common/GSLBServer requires targets")))
+			edns.Spec.Pools[0].Targets = []cisapiv2.DNSTarget{{DomainName: "app.cdn.net"}}
+			Expect(mockCtlr.validateExternalDNSAdmission(edns)).To(BeNil())
+			edns.Spec.Pools[0].Monitors = []cisapiv2.Monitor{{Type: "http"}}
+			Expect(mockCtlr.validateExternalDNSAdmission(edns)).To(MatchError(ContainSubstring("type and interval are required")))
+			edns.Spec.Pools[0].Monitors[0].Interval = 10
+			Expect(mockCtlr.validateExternalDNSAdmission(edns)).To(BeNil())
+		})
+
+		It("Serves AdmissionReviews", func() {
+			vs := test.NewVirtualServer("vs2", namespace, cisapiv2.VirtualServerSpec{
+				Host:                 "test.com",
+				VirtualServerAddress: "1.2.3.4",
+				Pools:                []cisapiv2.Pool{{Path: "/path", Service: "svc1"}},
+			})
+			raw, _ := json.Marshal(vs)
+			review := admissionv1.AdmissionReview{
+				TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+				Request: &admissionv1.AdmissionRequest{
+					UID:       "uid-1",
+					Kind:      metav1.GroupVersionKind{Group: "cis.f5.com", Version: "v1", Kind: VirtualServer},
+					Namespace: namespace,
+					Name:      "vs2",
+					Operation: admissionv1.Create,
+					Object:    runtime.RawExtension{Raw: raw},
+				},
+			}
+			body, _ := json.Marshal(review)
+			rec := httptest.NewRecorder()
+			mockCtlr.serveValidatingWebhook(rec, httptest.NewRequest(http.MethodPost, ValidatingWebhookPath, bytes.NewReader(body)))
+			Expect(rec.Code).To(Equal(http.StatusOK))
+
+			resp := admissionv1.AdmissionReview{}
+			Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+			Expect(resp.Kind).To(Equal("AdmissionReview"))
+			Expect(resp.Response.UID).To(BeEquivalentTo("uid-1"))
+			Expect(resp.Response.Allowed).To(BeFalse())
+			Expect(resp.Response.Result.Message).To(ContainSubstring("already used by VirtualServer default/SampleVS"))
+
+			review.Request.Operation = admissionv1.Delete
+			body, _ = json.Marshal(review)
+			rec = httptest.NewRecorder()
+			mockCtlr.serveValidatingWebhook(rec, httptest.NewRequest(http.MethodPost, ValidatingWebhookPath, bytes.NewReader(body)))
+			Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+			Expect(resp.Response.Allowed).To(BeTrue(), "Deletion should be allowed")
+		})
+
+		It("Serves ConversionReviews", func() {
+			vsV1 := &cisapiv1.VirtualServer{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "cis.f5.com/v1", Kind: VirtualServer},
+				ObjectMeta: metav1.ObjectMeta{Name: "vs", Namespace: namespace},
+				Spec: cisapiv1.VirtualServerSpec{
+					Host:               "test.com",
+					PersistenceProfile: "source-address",
+					Pools: []cisapiv1.Pool{{
+						Path:    "/path",
+						Service: "svc1",
+						Monitor: cisapiv1.Monitor{Type: "http", Send: "GET /", Interval: 10, Timeout: 31},
+					}},
+				},
+			}
+			convert := func(obj interface{}, apiVersion string) *apiextensionsv1.ConversionReview {
+				raw, _ := json.Marshal(obj)
+				review := apiextensionsv1.ConversionReview{
+					TypeMeta: metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1", Kind: "ConversionReview"},
+					Request: &apiextensionsv1.ConversionRequest{
+						UID:               "uid-1",
+						DesiredAPIVersion: apiVersion,
+						Objects:           []runtime.RawExtension{{Raw: raw}},
+					},
+				}
+				body, _ := json.Marshal(review)
+				rec := httptest.NewRecorder()
+				serveConversionWebhook(rec, httptest.NewRequest(http.MethodPost, ConversionWebhookPath, bytes.NewReader(body)))
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				resp := &apiextensionsv1.ConversionReview{}
+				Expect(json.Unmarshal(rec.Body.Bytes(), resp)).To(Succeed())
+				Expect(resp.Response.UID).To(BeEquivalentTo("uid-1"))
+				return resp
+			}
+
+			resp := convert(vsV1, "cis.f5.com/v2")
+			Expect(resp.Response.Result.Status).To(Equal(metav1.StatusSuccess))
+			Expect(resp.Response.ConvertedObjects).To(HaveLen(1))
+			vs := &cisapiv2.VirtualServer{}
+			Expect(json.Unmarshal(resp.Response.ConvertedObjects[0].Raw, vs)).To(Succeed())
+			Expect(vs.APIVersion).To(Equal("cis.f5.com/v2"))
+			Expect(vs.Spec.Profiles.PersistenceProfile).To(Equal("source-address"))
+			Expect(vs.Spec.Pools[0].Monitors).To(Equal([]cisapiv2.Monitor{{Type: "http", Send: "GET /", Interval: 10, Timeout: 31}}))
+
+			resp = convert(vs, "cis.f5.com/v1")
+			Expect(resp.Response.Result.Status).To(Equal(metav1.StatusSuccess))
+			converted := &cisapiv1.VirtualServer{}
+			Expect(json.Unmarshal(resp.Response.ConvertedObjects[0].Raw, converted)).To(Succeed())
+			Expect(converted.APIVersion).To(Equal("cis.f5.com/v1"))
+			Expect(converted.Annotations).To(BeEmpty())
+			Expect(converted.Spec).To(Equal(vsV1.Spec), "Conversion should be lossless")
+
+			vsV1.APIVersion = "cis.f5.com/v3"
+			resp = convert(vsV1, "cis.f5.com/v2")
+			Expect(resp.Response.Result.Status).To(Equal(metav1.StatusFailure))
+			Expect(resp.Response.ConvertedObjects).To(BeEmpty())
+		})
+
+		It("Validates the v1 resources in their v2 form", func() {
+			raw, _ := json.Marshal(&cisapiv1.TLSProfile{
+				Spec: cisapiv1.TLSProfileSpec{TLS: cisapiv1.TLS{Termination: "edge", ServerSSL: "/Common/serverssl"}},
+			})
+			resp := mockCtlr.admit(&admissionv1.AdmissionRequest{
+				Kind:      metav1.GroupVersionKind{Group: "cis.f5.com", Version: "v1", Kind: TLSProfile},
+				Operation: admissionv1.Create,
+				Object:    runtime.RawExtension{Raw: raw},
+			})
+			Expect(resp.Allowed).To(BeFalse())
+			Expect(resp.Result.Message).To(ContainSubstring("should contain ClientSSLs"))
+		})
 	})
 })
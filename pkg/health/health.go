@@ -15,17 +15,20 @@ type HealthChecker struct {
 //TODO: add health check if Kubernetes API is still reachable
 func (hc HealthChecker) HealthCheckHandler() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if hc.SubPID != 0 {
-			_, err := os.FindProcess(hc.SubPID)
-			if err == nil {
-				// assume that Python process is still running
-				w.WriteHeader(http.StatusOK)
-				w.Write([]byte("Ok"))
-				return
-			}
-
-			log.Errorf(err.Error())
+		// Without the Python driver there is no sub-process to track
+		if hc.SubPID == 0 {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("Ok"))
+			return
+		}
+		_, err := os.FindProcess(hc.SubPID)
+		if err == nil {
+			// assume that Python process is still running
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("Ok"))
+			return
 		}
+		log.Errorf(err.Error())
 
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte("Python process is dead"))
@@ -0,0 +1,269 @@
+/*-
+ * Copyright (c) 2017-2021 F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pollers
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/F5Networks/k8s-bigip-ctlr/pkg/vlogger"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	bigIPPrometheus "github.com/F5Networks/k8s-bigip-ctlr/pkg/prometheus"
+)
+
+// nodeInformer watches the nodes and notifies the listeners with all the nodes
+// on a change, in place of listing the nodes at every poll interval
+type nodeInformer struct {
+	kubeClient   kubernetes.Interface
+	resyncPeriod time.Duration
+	// Node changes within the debounce period are notified together
+	debounce     time.Duration
+	nodeLabel    string
+	factory      informers.SharedInformerFactory
+	lister       corelisters.NodeLister
+	stopCh       chan struct{}
+	running      bool
+	runningLock  *sync.Mutex
+	regListeners []PollListener
+	// triggers create the informers whose events notify the listeners
+	triggers []func() cache.SharedIndexInformer
+	// synced are the HasSynced of the node and the trigger informers
+	synced []cache.InformerSynced
+	// notifyLock serializes the listener callbacks
+	notifyLock *sync.Mutex
+	pending    bool
+	notified   bool
+}
+
+func NewNodeInformer(
+	kubeClient kubernetes.Interface,
+	resyncPeriod time.Duration,
+	debounce time.Duration,
+	nodeLabel string,
+) Poller {
+	ni := &nodeInformer{
+		kubeClient:   kubeClient,
+		resyncPeriod: resyncPeriod,
+		debounce:     debounce,
+		nodeLabel:    nodeLabel,
+		runningLock:  &sync.Mutex{},
+		notifyLock:   &sync.Mutex{},
+	}
+
+	log.Debugf("[CORE] NodeInformer object created: %p", ni)
+	return ni
+}
+
+func (ni *nodeInformer) Run() error {
+	ni.runningLock.Lock()
+	defer ni.runningLock.Unlock()
+
+	if ni.running {
+		return fmt.Errorf("NodeInformer Run method called while running")
+	}
+	ni.running = true
+	ni.notified = false
+	ni.stopCh = make(chan struct{})
+	ni.factory = informers.NewSharedInformerFactoryWithOptions(
+		ni.kubeClient,
+		ni.resyncPeriod,
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = ni.nodeLabel
+		}),
+	)
+	nodeInformer := ni.factory.Core().V1().Nodes()
+	ni.lister = nodeInformer.Lister()
+	nodeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) { ni.enqueue() },
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldNode, ok1 := oldObj.(*v1.Node)
+			newNode, ok2 := newObj.(*v1.Node)
+			if ok1 && ok2 && !nodeUpdated(oldNode, newNode) {
+				return
+			}
+			ni.enqueue()
+		},
+		DeleteFunc: func(obj interface{}) { ni.enqueue() },
+	})
+	ni.factory.Start(ni.stopCh)
+	ni.synced = []cache.InformerSynced{nodeInformer.Informer().HasSynced}
+	for _, newInformer := range ni.triggers {
+		ni.runTrigger(newInformer)
+	}
+
+	go func(stopCh chan struct{}, synced []cache.InformerSynced) {
+		if !cache.WaitForCacheSync(stopCh, synced...) {
+			return
+		}
+		// The listeners get the nodes once the cache is synced, even without any node
+		ni.runningLock.Lock()
+		notified := ni.notified
+		ni.runningLock.Unlock()
+		if !notified {
+			ni.enqueue()
+		}
+	}(ni.stopCh, ni.synced)
+
+	log.Infof("[CORE] NodeInformer started: (%p)", ni)
+	return nil
+}
+
+func (ni *nodeInformer) Stop() error {
+	ni.runningLock.Lock()
+	defer ni.runningLock.Unlock()
+
+	if !ni.running {
+		return fmt.Errorf("NodeInformer Stop method called while stopped")
+	}
+	ni.running = false
+	close(ni.stopCh)
+
+	log.Infof("[CORE] NodeInformer stopped: %p", ni)
+	return nil
+}
+
+func (ni *nodeInformer) RegisterListener(p PollListener) error {
+	ni.runningLock.Lock()
+	defer ni.runningLock.Unlock()
+
+	log.Infof("[CORE] NodeInformer (%p) registering new listener: %p", ni, p)
+	ni.regListeners = append(ni.regListeners, p)
+	if ni.running && ni.factory.Core().V1().Nodes().Informer().HasSynced() {
+		// Catch up the listener with the current nodes
+		go func() {
+			ni.notifyLock.Lock()
+			defer ni.notifyLock.Unlock()
+			nodes, err := ni.listNodes()
+			p(nodes, err)
+		}()
+	}
+	return nil
+}
+
+func (ni *nodeInformer) RegisterTrigger(newInformer func() cache.SharedIndexInformer) error {
+	ni.runningLock.Lock()
+	defer ni.runningLock.Unlock()
+
+	log.Infof("[CORE] NodeInformer (%p) registering new trigger: %p", ni, newInformer)
+	ni.triggers = append(ni.triggers, newInformer)
+	if ni.running {
+		ni.runTrigger(newInformer)
+	}
+	return nil
+}
+
+// runTrigger runs the informer of the trigger until the node informer is stopped,
+// its changes are notified along with the node changes
+func (ni *nodeInformer) runTrigger(newInformer func() cache.SharedIndexInformer) {
+	informer := newInformer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) { ni.enqueue() },
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldMeta, err1 := meta.Accessor(oldObj)
+			newMeta, err2 := meta.Accessor(newObj)
+			if err1 == nil && err2 == nil && oldMeta.GetResourceVersion() == newMeta.GetResourceVersion() {
+				return
+			}
+			ni.enqueue()
+		},
+		DeleteFunc: func(obj interface{}) { ni.enqueue() },
+	})
+	ni.synced = append(ni.synced, informer.HasSynced)
+	go informer.Run(ni.stopCh)
+}
+
+// enqueue schedules a notification of the listeners after the debounce period,
+// coalescing the node changes in between
+func (ni *nodeInformer) enqueue() {
+	ni.runningLock.Lock()
+	defer ni.runningLock.Unlock()
+
+	if ni.pending {
+		return
+	}
+	ni.pending = true
+	time.AfterFunc(ni.debounce, ni.notify)
+}
+
+func (ni *nodeInformer) notify() {
+	ni.runningLock.Lock()
+	ni.pending = false
+	ni.notified = true
+	running := ni.running
+	listeners := append([]PollListener{}, ni.regListeners...)
+	ni.runningLock.Unlock()
+	if !running {
+		return
+	}
+
+	ni.notifyLock.Lock()
+	defer ni.notifyLock.Unlock()
+	nodes, err := ni.listNodes()
+	for _, listener := range listeners {
+		log.Debugf("[CORE] NodeInformer (%p) notifying listener: %p - num items: %v err: %v",
+			ni, listener, len(nodes), err)
+		listener(nodes, err)
+	}
+}
+
+// listNodes returns the nodes in the informer cache sorted by name
+func (ni *nodeInformer) listNodes() ([]v1.Node, error) {
+	nodeList, err := ni.lister.List(labels.Everything())
+	nodes := make([]v1.Node, 0, len(nodeList))
+	for _, node := range nodeList {
+		nodes = append(nodes, *node)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+	bigIPPrometheus.MonitoredNodes.WithLabelValues(ni.nodeLabel).Set(float64(len(nodes)))
+	return nodes, err
+}
+
+// nodeUpdated checks the changes of a node relevant to the listeners, ignoring the heartbeats.
+// Informer resyncs are notified as well to refresh the listeners periodically
+func nodeUpdated(oldNode, newNode *v1.Node) bool {
+	if oldNode.ResourceVersion == newNode.ResourceVersion {
+		return true
+	}
+	return nodeReady(oldNode) != nodeReady(newNode) ||
+		!reflect.DeepEqual(oldNode.Spec, newNode.Spec) ||
+		!reflect.DeepEqual(oldNode.Labels, newNode.Labels) ||
+		!reflect.DeepEqual(oldNode.Annotations, newNode.Annotations) ||
+		!reflect.DeepEqual(oldNode.Status.Addresses, newNode.Status.Addresses)
+}
+
+func nodeReady(node *v1.Node) v1.ConditionStatus {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady {
+			return condition.Status
+		}
+	}
+	return v1.ConditionUnknown
+}
@@ -0,0 +1,146 @@
+/*-
+ * Copyright (c) 2017-2021 F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pollers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+var _ = Describe("Node Informer Tests", func() {
+	var fakeClient *fake.Clientset
+	var ni Poller
+	var mutex sync.Mutex
+	var updates [][]v1.Node
+
+	listener := func(obj interface{}, err error) {
+		defer GinkgoRecover()
+		Expect(err).To(BeNil())
+		mutex.Lock()
+		defer mutex.Unlock()
+		updates = append(updates, obj.([]v1.Node))
+	}
+	numUpdates := func() int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(updates)
+	}
+	lastUpdate := func() []v1.Node {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return updates[len(updates)-1]
+	}
+
+	BeforeEach(func() {
+		updates = nil
+		fakeClient = fake.NewSimpleClientset(
+			newNode("node1", "1", false, []v1.NodeAddress{{Type: "InternalIP", Address: "127.1.0.1"}},
+				map[string]string{nodeLabel: "true"}),
+			newNode("node0", "0", false, []v1.NodeAddress{{Type: "InternalIP", Address: "127.1.0.0"}},
+				map[string]string{masterLabel: "true"}),
+		)
+		ni = NewNodeInformer(fakeClient, time.Hour, 50*time.Millisecond, "")
+		Expect(ni.RegisterListener(listener)).To(BeNil())
+		Expect(ni.Run()).To(BeNil())
+		Eventually(numUpdates).Should(Equal(1))
+	})
+
+	AfterEach(func() {
+		Expect(ni.Stop()).To(BeNil())
+		Expect(ni.Stop()).NotTo(BeNil())
+	})
+
+	It("notifies the listeners with the sorted nodes", func() {
+		nodes := lastUpdate()
+		Expect(nodes).To(HaveLen(2))
+		Expect(nodes[0].Name).To(Equal("node0"))
+		Expect(nodes[1].Name).To(Equal("node1"))
+		Expect(ni.Run()).NotTo(BeNil())
+
+		// A listener registered later is caught up with the nodes
+		caughtUp := make(chan int, 1)
+		Expect(ni.RegisterListener(func(obj interface{}, err error) {
+			caughtUp <- len(obj.([]v1.Node))
+		})).To(BeNil())
+		Eventually(caughtUp).Should(Receive(Equal(2)))
+	})
+
+	It("coalesces the node changes within the debounce period", func() {
+		node := newNode("node2", "2", false, nil, nil)
+		_, err := fakeClient.CoreV1().Nodes().Create(context.TODO(), node, metav1.CreateOptions{})
+		Expect(err).To(BeNil())
+		node = newNode("node1", "3", true, nil, map[string]string{nodeLabel: "true"})
+		_, err = fakeClient.CoreV1().Nodes().Update(context.TODO(), node, metav1.UpdateOptions{})
+		Expect(err).To(BeNil())
+
+		Eventually(func() bool {
+			nodes := lastUpdate()
+			return len(nodes) == 3 && nodes[1].Spec.Unschedulable
+		}).Should(BeTrue())
+		Consistently(numUpdates, 200*time.Millisecond).Should(BeNumerically("<=", 3))
+	})
+
+	It("ignores the heartbeats of the nodes", func() {
+		node := newNode("node1", "4", false, []v1.NodeAddress{{Type: "InternalIP", Address: "127.1.0.1"}},
+			map[string]string{nodeLabel: "true"})
+		node.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeMemoryPressure, Status: v1.ConditionFalse}}
+		_, err := fakeClient.CoreV1().Nodes().UpdateStatus(context.TODO(), node, metav1.UpdateOptions{})
+		Expect(err).To(BeNil())
+		Consistently(numUpdates, 200*time.Millisecond).Should(Equal(1))
+
+		// Ready and NotReady transitions are notified
+		node.ResourceVersion = "5"
+		node.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionFalse}}
+		_, err = fakeClient.CoreV1().Nodes().UpdateStatus(context.TODO(), node, metav1.UpdateOptions{})
+		Expect(err).To(BeNil())
+		Eventually(numUpdates).Should(Equal(2))
+	})
+
+	It("notifies the listeners on the events of the triggers", func() {
+		triggerClient := fake.NewSimpleClientset()
+		Expect(ni.(TriggerRegistrar).RegisterTrigger(func() cache.SharedIndexInformer {
+			return informers.NewSharedInformerFactory(triggerClient, 0).Core().V1().ConfigMaps().Informer()
+		})).To(BeNil())
+		Consistently(numUpdates, 200*time.Millisecond).Should(Equal(1))
+
+		cm := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"}}
+		_, err := triggerClient.CoreV1().ConfigMaps("default").Create(context.TODO(), cm, metav1.CreateOptions{})
+		Expect(err).To(BeNil())
+		Eventually(numUpdates).Should(Equal(2))
+	})
+
+	It("detects the relevant node changes", func() {
+		oldNode := newNode("node1", "1", false, nil, nil)
+		newNode := oldNode.DeepCopy()
+		newNode.ResourceVersion = "2"
+		Expect(nodeUpdated(oldNode, newNode)).To(BeFalse())
+		newNode.Spec.Taints = []v1.Taint{{Key: "key", Effect: v1.TaintEffectNoExecute}}
+		Expect(nodeUpdated(oldNode, newNode)).To(BeTrue())
+		// Resyncs are notified
+		Expect(nodeUpdated(oldNode, oldNode)).To(BeTrue())
+	})
+})
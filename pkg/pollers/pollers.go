@@ -16,6 +16,8 @@
 
 package pollers
 
+import "k8s.io/client-go/tools/cache"
+
 type PollListener func(interface{}, error)
 
 type Poller interface {
@@ -23,3 +25,11 @@ type Poller interface {
 	Stop() error
 	RegisterListener(p PollListener) error
 }
+
+// TriggerRegistrar is a Poller which also notifies its listeners on the events of
+// the informers of the other objects the listeners read along with the nodes
+type TriggerRegistrar interface {
+	// RegisterTrigger runs an informer created by newInformer while the poller
+	// runs, an informer is created each time the poller is run
+	RegisterTrigger(newInformer func() cache.SharedIndexInformer) error
+}
@@ -31,6 +31,30 @@ var CurrentErrors = prometheus.NewGaugeVec(
 	[]string{},
 )
 
+var CoalescedEvents = prometheus.NewHistogram(
+	prometheus.HistogramOpts{
+		Name:    "bigip_declaration_coalesced_events",
+		Help:    "Count of the resource events coalesced in a declaration posted to BIG-IP",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+	},
+)
+
+var TenantDrift = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "bigip_tenant_drift",
+		Help: "Set to 1 when the tenant on BIG-IP differs from the declaration posted by the BigIP k8s CTLR",
+	},
+	[]string{"tenant"},
+)
+
+var TenantDriftReposts = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "bigip_tenant_drift_reposts_total",
+		Help: "Total count of the reposts of the tenants drifted on BIG-IP",
+	},
+	[]string{"tenant"},
+)
+
 // further metrics? todo think about
 // RegisterMetrics registers all Prometheus metrics defined above
 func RegisterMetrics() {
@@ -38,4 +62,14 @@ func RegisterMetrics() {
 	prometheus.MustRegister(MonitoredNodes)
 	prometheus.MustRegister(MonitoredServices)
 	prometheus.MustRegister(CurrentErrors)
+	prometheus.MustRegister(CoalescedEvents)
+	prometheus.MustRegister(TenantDrift)
+	prometheus.MustRegister(TenantDriftReposts)
+	prometheus.MustRegister(WorkQueueDepth)
+	prometheus.MustRegister(WorkQueueAdds)
+	prometheus.MustRegister(WorkQueueLatency)
+	prometheus.MustRegister(WorkQueueWorkDuration)
+	prometheus.MustRegister(WorkQueueUnfinishedWork)
+	prometheus.MustRegister(WorkQueueLongestRunningProcessor)
+	prometheus.MustRegister(WorkQueueRetries)
 }
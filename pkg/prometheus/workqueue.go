@@ -0,0 +1,102 @@
+package prometheus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// Metrics of the work queues of the controllers, labelled by the queue name
+var WorkQueueDepth = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "bigip_workqueue_depth",
+		Help: "Current depth of the work queue",
+	},
+	[]string{"name"},
+)
+
+var WorkQueueAdds = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "bigip_workqueue_adds_total",
+		Help: "Total number of adds handled by the work queue",
+	},
+	[]string{"name"},
+)
+
+var WorkQueueLatency = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "bigip_workqueue_queue_duration_seconds",
+		Help:    "How long in seconds an item stays in the work queue before being requested",
+		Buckets: prometheus.ExponentialBuckets(10e-9, 10, 10),
+	},
+	[]string{"name"},
+)
+
+var WorkQueueWorkDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "bigip_workqueue_work_duration_seconds",
+		Help:    "How long in seconds processing an item from the work queue takes",
+		Buckets: prometheus.ExponentialBuckets(10e-9, 10, 10),
+	},
+	[]string{"name"},
+)
+
+var WorkQueueUnfinishedWork = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "bigip_workqueue_unfinished_work_seconds",
+		Help: "How many seconds of work has been done that is in progress and hasn't been observed by " +
+			"work_duration. Large values indicate stuck workers",
+	},
+	[]string{"name"},
+)
+
+var WorkQueueLongestRunningProcessor = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "bigip_workqueue_longest_running_processor_seconds",
+		Help: "How many seconds the longest running processor of the work queue has been running",
+	},
+	[]string{"name"},
+)
+
+var WorkQueueRetries = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "bigip_workqueue_retries_total",
+		Help: "Total number of retries handled by the work queue",
+	},
+	[]string{"name"},
+)
+
+// workQueueMetricsProvider provides the Prometheus metrics of the work queues
+type workQueueMetricsProvider struct{}
+
+func init() {
+	// The provider is only used by the queues created after it is set
+	workqueue.SetProvider(workQueueMetricsProvider{})
+}
+
+func (workQueueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return WorkQueueDepth.WithLabelValues(name)
+}
+
+func (workQueueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return WorkQueueAdds.WithLabelValues(name)
+}
+
+func (workQueueMetricsProvider) NewLatencyMetric(name string) workqueue.HistogramMetric {
+	return WorkQueueLatency.WithLabelValues(name)
+}
+
+func (workQueueMetricsProvider) NewWorkDurationMetric(name string) workqueue.HistogramMetric {
+	return WorkQueueWorkDuration.WithLabelValues(name)
+}
+
+func (workQueueMetricsProvider) NewUnfinishedWorkSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return WorkQueueUnfinishedWork.WithLabelValues(name)
+}
+
+func (workQueueMetricsProvider) NewLongestRunningProcessorSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return WorkQueueLongestRunningProcessor.WithLabelValues(name)
+}
+
+func (workQueueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return WorkQueueRetries.WithLabelValues(name)
+}
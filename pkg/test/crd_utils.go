@@ -2,7 +2,7 @@ package test
 
 import (
 	ficV1 "github.com/F5Networks/f5-ipam-controller/pkg/ipamapis/apis/fic/v1"
-	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v1"
+	cisapiv2 "github.com/F5Networks/k8s-bigip-ctlr/config/apis/cis/v2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -19,10 +19,12 @@ const (
 	ExternalDNS = "ExternalDNS"
 	// IPAM is a F5 Customr Resource Kind
 	IPAM = "IPAM"
+	// DeployConfig is a cluster scoped F5 Custom Resource Kind
+	DeployConfig = "DeployConfig"
 )
 
-func NewVirtualServer(name, namespace string, spec cisapiv1.VirtualServerSpec) *cisapiv1.VirtualServer {
-	return &cisapiv1.VirtualServer{
+func NewVirtualServer(name, namespace string, spec cisapiv2.VirtualServerSpec) *cisapiv2.VirtualServer {
+	return &cisapiv2.VirtualServer{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       VirtualServer,
 			APIVersion: "v1",
@@ -35,8 +37,8 @@ func NewVirtualServer(name, namespace string, spec cisapiv1.VirtualServerSpec) *
 	}
 }
 
-func NewTLSProfile(name, namespace string, spec cisapiv1.TLSProfileSpec) *cisapiv1.TLSProfile {
-	return &cisapiv1.TLSProfile{
+func NewTLSProfile(name, namespace string, spec cisapiv2.TLSProfileSpec) *cisapiv2.TLSProfile {
+	return &cisapiv2.TLSProfile{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       TLSProfile,
 			APIVersion: "v1",
@@ -49,8 +51,8 @@ func NewTLSProfile(name, namespace string, spec cisapiv1.TLSProfileSpec) *cisapi
 	}
 }
 
-func NewTransportServer(name, namespace string, spec cisapiv1.TransportServerSpec) *cisapiv1.TransportServer {
-	return &cisapiv1.TransportServer{
+func NewTransportServer(name, namespace string, spec cisapiv2.TransportServerSpec) *cisapiv2.TransportServer {
+	return &cisapiv2.TransportServer{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       TransportServer,
 			APIVersion: "v1",
@@ -63,8 +65,8 @@ func NewTransportServer(name, namespace string, spec cisapiv1.TransportServerSpe
 	}
 }
 
-func NewPolicy(name, namespace string, spec cisapiv1.PolicySpec) *cisapiv1.Policy {
-	return &cisapiv1.Policy{
+func NewPolicy(name, namespace string, spec cisapiv2.PolicySpec) *cisapiv2.Policy {
+	return &cisapiv2.Policy{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       TransportServer,
 			APIVersion: "v1",
@@ -77,8 +79,21 @@ func NewPolicy(name, namespace string, spec cisapiv1.PolicySpec) *cisapiv1.Polic
 	}
 }
 
-func NewIngressLink(name, namespace, rv string, spec cisapiv1.IngressLinkSpec) *cisapiv1.IngressLink {
-	return &cisapiv1.IngressLink{
+func NewDeployConfig(name string, spec cisapiv2.DeployConfigSpec) *cisapiv2.DeployConfig {
+	return &cisapiv2.DeployConfig{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       DeployConfig,
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: spec,
+	}
+}
+
+func NewIngressLink(name, namespace, rv string, spec cisapiv2.IngressLinkSpec) *cisapiv2.IngressLink {
+	return &cisapiv2.IngressLink{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       IngressLink,
 			APIVersion: "v1",
@@ -92,8 +107,8 @@ func NewIngressLink(name, namespace, rv string, spec cisapiv1.IngressLinkSpec) *
 	}
 }
 
-func NewExternalDNS(name, namespace string, spec cisapiv1.ExternalDNSSpec) *cisapiv1.ExternalDNS {
-	return &cisapiv1.ExternalDNS{
+func NewExternalDNS(name, namespace string, spec cisapiv2.ExternalDNSSpec) *cisapiv2.ExternalDNS {
+	return &cisapiv2.ExternalDNS{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       ExternalDNS,
 			APIVersion: "v1",
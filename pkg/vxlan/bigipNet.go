@@ -0,0 +1,390 @@
+/*-
+ * Copyright (c) 2017-2021 F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vxlan
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/F5Networks/k8s-bigip-ctlr/pkg/vlogger"
+	"github.com/F5Networks/k8s-bigip-ctlr/pkg/writer"
+)
+
+const (
+	// Prefix of the ARP entries managed by the controller
+	arpPrefix = "k8s-"
+	// Prefix of the static routes managed by the controller, distinct from the
+	// ARP prefix so that the routes created by the operators are retained
+	routePrefix = "k8s-bigip-ctlr-route-"
+	// Interval of the first retry of a failed section, doubled up to netMaxRetryInterval
+	netRetryInterval    = time.Second
+	netMaxRetryInterval = time.Minute
+	// Interval of the reconciliation of BIG-IP with the last section applied
+	netResyncInterval = 5 * time.Minute
+)
+
+// NetParams holds the iControl REST details of the BIG-IP for the L2/L3 configuration
+type NetParams struct {
+	BIGIPUsername string
+	BIGIPPassword string
+	BIGIPURL      string
+	TrustedCerts  string
+	SSLInsecure   bool
+	// Partition of the VXLAN tunnel, the ARP entries and the static routes
+	Partition  string
+	DisableARP bool
+}
+
+// bigIPNetWriter configures the FDB records and ARP entries of the VxlanMgr and the
+// static routes of the StaticRouteMgr on BIG-IP through iControl REST, in place of the Python driver
+type bigIPNetWriter struct {
+	NetParams
+	httpClient       *http.Client
+	retryInterval    time.Duration
+	maxRetryInterval time.Duration
+	resyncInterval   time.Duration
+	stopCh           chan struct{}
+	// Guards the workers of the sections
+	sync.Mutex
+	workers map[string]*sectionWorker
+}
+
+// sectionWorker applies the sections of a name on BIG-IP one at a time, a section
+// sent while another one is applied replaces the pending one, so that only the
+// latest section is applied
+type sectionWorker struct {
+	sync.Mutex
+	name    string
+	pending func() error
+	notify  chan struct{}
+}
+
+type routeList struct {
+	Items []routeItem `json:"items"`
+}
+
+type routeItem struct {
+	Name      string `json:"name"`
+	Partition string `json:"partition,omitempty"`
+	Network   string `json:"network"`
+	Gateway   string `json:"gw"`
+}
+
+type arpList struct {
+	Items []arpItem `json:"items"`
+}
+
+type arpItem struct {
+	Name       string `json:"name"`
+	Partition  string `json:"partition,omitempty"`
+	IPAddress  string `json:"ipAddress"`
+	MACAddress string `json:"macAddress"`
+}
+
+// NewBigIPNetWriter creates a writer.Writer posting the vxlan-fdb, vxlan-arp and static-routes sections to BIG-IP
+func NewBigIPNetWriter(params NetParams) writer.Writer {
+	if params.Partition == "" {
+		params.Partition = "Common"
+	}
+	rootCAs, _ := x509.SystemCertPool()
+	if rootCAs == nil {
+		rootCAs = x509.NewCertPool()
+	}
+	if ok := rootCAs.AppendCertsFromPEM([]byte(params.TrustedCerts)); !ok {
+		log.Debug("[VxLAN] No certs appended, using only system certs")
+	}
+	return &bigIPNetWriter{
+		NetParams: params,
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: params.SSLInsecure,
+					RootCAs:            rootCAs,
+				},
+			},
+			Timeout: 30 * time.Second,
+		},
+		retryInterval:    netRetryInterval,
+		maxRetryInterval: netMaxRetryInterval,
+		resyncInterval:   netResyncInterval,
+		stopCh:           make(chan struct{}),
+		workers:          make(map[string]*sectionWorker),
+	}
+}
+
+func (nw *bigIPNetWriter) GetOutputFilename() string {
+	return ""
+}
+
+func (nw *bigIPNetWriter) Stop() {
+	nw.Lock()
+	defer nw.Unlock()
+	select {
+	case <-nw.stopCh:
+	default:
+		close(nw.stopCh)
+	}
+}
+
+// SendSection configures the section on BIG-IP, sections other than vxlan-fdb, vxlan-arp and
+// static-routes are ignored. The section is applied by the worker of its name, which retries
+// the failed updates and periodically reconciles BIG-IP with the last section, so the done
+// channel is signaled once the section is queued and the errors are logged by the worker
+func (nw *bigIPNetWriter) SendSection(
+	name string,
+	obj interface{},
+) (<-chan struct{}, <-chan error, error) {
+	var update func() error
+	switch section := obj.(type) {
+	case fdbSection:
+		update = func() error { return nw.updateFDB(section) }
+	case arpSection:
+		if nw.DisableARP {
+			update = func() error { return nil }
+		} else {
+			update = func() error { return nw.updateARPs(section) }
+		}
+	case routeSection:
+		update = func() error { return nw.updateRoutes(section) }
+	default:
+		update = func() error { return nil }
+	}
+	nw.getSectionWorker(name).set(update)
+
+	doneCh := make(chan struct{}, 1)
+	doneCh <- struct{}{}
+	return doneCh, make(chan error, 1), nil
+}
+
+// getSectionWorker returns the worker of the section, starting it if required
+func (nw *bigIPNetWriter) getSectionWorker(name string) *sectionWorker {
+	nw.Lock()
+	defer nw.Unlock()
+	w, ok := nw.workers[name]
+	if !ok {
+		w = &sectionWorker{name: name, notify: make(chan struct{}, 1)}
+		nw.workers[name] = w
+		go nw.runSectionWorker(w)
+	}
+	return w
+}
+
+// set replaces the pending update of the section and notifies the worker
+func (w *sectionWorker) set(update func() error) {
+	w.Lock()
+	w.pending = update
+	w.Unlock()
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+// take returns the pending update of the section, if any
+func (w *sectionWorker) take() func() error {
+	w.Lock()
+	defer w.Unlock()
+	update := w.pending
+	w.pending = nil
+	return update
+}
+
+// runSectionWorker applies the latest section until the writer is stopped. A failed
+// section is retried with backoff unless a newer one is sent, and the last section is
+// applied again every resyncInterval to revert the changes made on BIG-IP
+func (nw *bigIPNetWriter) runSectionWorker(w *sectionWorker) {
+	var update func() error
+	var retry <-chan time.Time
+	var retryInterval time.Duration
+	resync := time.NewTicker(nw.resyncInterval)
+	defer resync.Stop()
+	for {
+		select {
+		case <-nw.stopCh:
+			return
+		case <-w.notify:
+			next := w.take()
+			if next == nil {
+				continue
+			}
+			update = next
+			retryInterval = 0
+		case <-retry:
+		case <-resync.C:
+			// A failed section is already retried
+			if update == nil || retry != nil {
+				continue
+			}
+		}
+		retry = nil
+		if err := update(); err != nil {
+			retryInterval *= 2
+			if retryInterval == 0 {
+				retryInterval = nw.retryInterval
+			} else if retryInterval > nw.maxRetryInterval {
+				retryInterval = nw.maxRetryInterval
+			}
+			log.Warningf("[VxLAN] Failed to configure %s on BIG-IP, retrying in %v: %v", w.name, retryInterval, err)
+			retry = time.After(retryInterval)
+			continue
+		}
+		retryInterval = 0
+		log.Debugf("[VxLAN] Configured %s on BIG-IP", w.name)
+	}
+}
+
+// updateFDB replaces the FDB records of the tunnel
+func (nw *bigIPNetWriter) updateFDB(section fdbSection) error {
+	records := section.Records
+	if records == nil {
+		records = []fdbRecord{}
+	}
+	body := map[string]interface{}{"records": records}
+	url := fmt.Sprintf("%s/mgmt/tm/net/fdb/tunnel/~%s~%s", nw.BIGIPURL, nw.Partition, section.TunnelName)
+	return nw.request(http.MethodPatch, url, body, nil)
+}
+
+// updateARPs creates, updates and deletes the ARP entries managed by the controller
+func (nw *bigIPNetWriter) updateARPs(section arpSection) error {
+	var current arpList
+	url := fmt.Sprintf("%s/mgmt/tm/net/arp?$filter=partition+eq+%s", nw.BIGIPURL, nw.Partition)
+	if err := nw.request(http.MethodGet, url, nil, &current); err != nil {
+		return err
+	}
+	existing := make(map[string]arpItem)
+	for _, item := range current.Items {
+		if strings.HasPrefix(item.Name, arpPrefix) {
+			existing[item.Name] = item
+		}
+	}
+
+	for _, entry := range section.Entries {
+		item, found := existing[entry.Name]
+		delete(existing, entry.Name)
+		if found && item.IPAddress == entry.IPAddr && item.MACAddress == entry.MACAddr {
+			continue
+		}
+		var err error
+		if found {
+			err = nw.request(http.MethodPatch, nw.arpURL(entry.Name),
+				arpItem{Name: entry.Name, IPAddress: entry.IPAddr, MACAddress: entry.MACAddr}, nil)
+		} else {
+			err = nw.request(http.MethodPost, nw.BIGIPURL+"/mgmt/tm/net/arp",
+				arpItem{Name: entry.Name, Partition: nw.Partition, IPAddress: entry.IPAddr, MACAddress: entry.MACAddr}, nil)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	// The remaining entries no longer have pods
+	for name := range existing {
+		if err := nw.request(http.MethodDelete, nw.arpURL(name), nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateRoutes creates, updates and deletes the static routes managed by the controller
+func (nw *bigIPNetWriter) updateRoutes(section routeSection) error {
+	var current routeList
+	url := fmt.Sprintf("%s/mgmt/tm/net/route?$filter=partition+eq+%s", nw.BIGIPURL, nw.Partition)
+	if err := nw.request(http.MethodGet, url, nil, &current); err != nil {
+		return err
+	}
+	existing := make(map[string]routeItem)
+	for _, item := range current.Items {
+		if strings.HasPrefix(item.Name, routePrefix) {
+			existing[item.Name] = item
+		}
+	}
+
+	for _, route := range section.Routes {
+		item, found := existing[route.Name]
+		delete(existing, route.Name)
+		if found && item.Network == route.Network && item.Gateway == route.Gateway {
+			continue
+		}
+		var err error
+		if found {
+			err = nw.request(http.MethodPatch, nw.routeURL(route.Name),
+				routeItem{Name: route.Name, Network: route.Network, Gateway: route.Gateway}, nil)
+		} else {
+			err = nw.request(http.MethodPost, nw.BIGIPURL+"/mgmt/tm/net/route",
+				routeItem{Name: route.Name, Partition: nw.Partition, Network: route.Network, Gateway: route.Gateway}, nil)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	// The remaining routes no longer have nodes
+	for name := range existing {
+		if err := nw.request(http.MethodDelete, nw.routeURL(name), nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (nw *bigIPNetWriter) routeURL(name string) string {
+	return fmt.Sprintf("%s/mgmt/tm/net/route/~%s~%s", nw.BIGIPURL, nw.Partition, name)
+}
+
+func (nw *bigIPNetWriter) arpURL(name string) string {
+	return fmt.Sprintf("%s/mgmt/tm/net/arp/~%s~%s", nw.BIGIPURL, nw.Partition, name)
+}
+
+func (nw *bigIPNetWriter) request(method, url string, body interface{}, resp interface{}) error {
+	var reqBody []byte
+	if body != nil {
+		var err error
+		if reqBody, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(nw.BIGIPUsername, nw.BIGIPPassword)
+	req.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := nw.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+	respBody, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return err
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s responded with status code %d: %s", method, url, httpResp.StatusCode, respBody)
+	}
+	if resp != nil {
+		return json.Unmarshal(respBody, resp)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+/*-
+ * Copyright (c) 2017-2021 F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vxlan
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type netRequest struct {
+	method string
+	path   string
+	body   map[string]interface{}
+}
+
+var _ = Describe("BIG-IP Net Writer Tests", func() {
+	var server *httptest.Server
+	var requests []netRequest
+	var mutex sync.Mutex
+	var arps string
+	var nw *bigIPNetWriter
+	getRequests := func() []netRequest {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]netRequest(nil), requests...)
+	}
+
+	BeforeEach(func() {
+		requests = nil
+		arps = `{"items": []}`
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			data, _ := ioutil.ReadAll(r.Body)
+			req := netRequest{method: r.Method, path: r.URL.Path}
+			_ = json.Unmarshal(data, &req.body)
+			requests = append(requests, req)
+			if r.Method == http.MethodGet {
+				w.Write([]byte(arps))
+				return
+			}
+			w.Write([]byte("{}"))
+		}))
+		nw = NewBigIPNetWriter(NetParams{
+			BIGIPURL:      server.URL,
+			BIGIPUsername: "admin",
+			BIGIPPassword: "admin",
+			Partition:     "test",
+		}).(*bigIPNetWriter)
+	})
+
+	AfterEach(func() {
+		nw.Stop()
+		server.Close()
+	})
+
+	It("replaces the fdb records of the tunnel", func() {
+		doneCh, errCh, err := nw.SendSection("vxlan-fdb", fdbSection{
+			TunnelName: "vxlan-tunnel",
+			Records:    []fdbRecord{{Name: "0a:0a:ac:10:01:01", Endpoint: "172.16.1.1"}},
+		})
+		Expect(err).To(BeNil())
+		Expect(doneCh).To(Receive())
+		Expect(errCh).NotTo(Receive())
+		Eventually(getRequests).Should(HaveLen(1))
+		requests := getRequests()
+		Expect(requests[0].method).To(Equal(http.MethodPatch))
+		Expect(requests[0].path).To(Equal("/mgmt/tm/net/fdb/tunnel/~test~vxlan-tunnel"))
+		Expect(requests[0].body["records"]).To(Equal([]interface{}{
+			map[string]interface{}{"name": "0a:0a:ac:10:01:01", "endpoint": "172.16.1.1"},
+		}))
+	})
+
+	It("syncs the arp entries managed by the controller", func() {
+		arps = `{"items": [
+			{"name": "k8s-10.1.1.1", "ipAddress": "10.1.1.1", "macAddress": "aa:aa:aa:aa:aa:aa"},
+			{"name": "k8s-10.1.1.2", "ipAddress": "10.1.1.2", "macAddress": "bb:bb:bb:bb:bb:bb"},
+			{"name": "k8s-10.1.1.3", "ipAddress": "10.1.1.3", "macAddress": "cc:cc:cc:cc:cc:cc"},
+			{"name": "gateway", "ipAddress": "10.1.1.254", "macAddress": "dd:dd:dd:dd:dd:dd"}
+		]}`
+		doneCh, _, _ := nw.SendSection("vxlan-arp", arpSection{
+			Entries: []arpEntry{
+				{Name: "k8s-10.1.1.1", IPAddr: "10.1.1.1", MACAddr: "aa:aa:aa:aa:aa:aa"},
+				{Name: "k8s-10.1.1.2", IPAddr: "10.1.1.2", MACAddr: "ee:ee:ee:ee:ee:ee"},
+				{Name: "k8s-10.1.1.4", IPAddr: "10.1.1.4", MACAddr: "ff:ff:ff:ff:ff:ff"},
+			},
+		})
+		Expect(doneCh).To(Receive())
+		Eventually(getRequests).Should(HaveLen(4))
+		requests := getRequests()
+		Expect(requests[0].method).To(Equal(http.MethodGet))
+		Expect(requests[1].method).To(Equal(http.MethodPatch))
+		Expect(requests[1].path).To(Equal("/mgmt/tm/net/arp/~test~k8s-10.1.1.2"))
+		Expect(requests[1].body["macAddress"]).To(Equal("ee:ee:ee:ee:ee:ee"))
+		Expect(requests[2].method).To(Equal(http.MethodPost))
+		Expect(requests[2].body["partition"]).To(Equal("test"))
+		Expect(requests[2].body["ipAddress"]).To(Equal("10.1.1.4"))
+		// The entries not created by the controller are retained
+		Expect(requests[3].method).To(Equal(http.MethodDelete))
+		Expect(requests[3].path).To(Equal("/mgmt/tm/net/arp/~test~k8s-10.1.1.3"))
+	})
+
+	It("skips the arp entries when ARP is disabled", func() {
+		nw.DisableARP = true
+		doneCh, _, _ := nw.SendSection("vxlan-arp", arpSection{
+			Entries: []arpEntry{{Name: "k8s-10.1.1.1", IPAddr: "10.1.1.1", MACAddr: "aa:aa:aa:aa:aa:aa"}},
+		})
+		Expect(doneCh).To(Receive())
+		Consistently(getRequests).Should(BeEmpty())
+	})
+
+	It("retries the failed sections with backoff", func() {
+		nw.retryInterval = 10 * time.Millisecond
+		nw.maxRetryInterval = 20 * time.Millisecond
+		failures := 3
+		server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			requests = append(requests, netRequest{method: r.Method, path: r.URL.Path})
+			if failures > 0 {
+				failures--
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"code": 404}`))
+				return
+			}
+			w.Write([]byte("{}"))
+		})
+		_, errCh, _ := nw.SendSection("vxlan-fdb", fdbSection{TunnelName: "vxlan-tunnel"})
+		Eventually(getRequests).Should(HaveLen(4), "Section should be applied after the failures")
+		Consistently(getRequests, 100*time.Millisecond).Should(HaveLen(4), "Applied section should not be retried")
+		Expect(errCh).NotTo(Receive())
+	})
+
+	It("applies only the latest pending section", func() {
+		release := make(chan struct{})
+		server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			data, _ := ioutil.ReadAll(r.Body)
+			req := netRequest{method: r.Method, path: r.URL.Path}
+			_ = json.Unmarshal(data, &req.body)
+			mutex.Lock()
+			requests = append(requests, req)
+			mutex.Unlock()
+			<-release
+			w.Write([]byte("{}"))
+		})
+		for _, tunnel := range []string{"first", "second", "third"} {
+			nw.SendSection("vxlan-fdb", fdbSection{TunnelName: tunnel})
+			Eventually(getRequests).Should(HaveLen(1))
+		}
+		close(release)
+		Eventually(getRequests).Should(HaveLen(2))
+		Consistently(getRequests, 100*time.Millisecond).Should(HaveLen(2))
+		requests := getRequests()
+		Expect(requests[0].path).To(Equal("/mgmt/tm/net/fdb/tunnel/~test~first"))
+		Expect(requests[1].path).To(Equal("/mgmt/tm/net/fdb/tunnel/~test~third"), "Stale section should be skipped")
+	})
+
+	It("reconciles BIG-IP with the last section periodically", func() {
+		nw.resyncInterval = 20 * time.Millisecond
+		nw.SendSection("vxlan-fdb", fdbSection{TunnelName: "vxlan-tunnel"})
+		Eventually(getRequests).Should(HaveLen(3))
+		for _, req := range getRequests() {
+			Expect(req.path).To(Equal("/mgmt/tm/net/fdb/tunnel/~test~vxlan-tunnel"))
+		}
+	})
+})
@@ -0,0 +1,179 @@
+/*-
+ * Copyright (c) 2017-2021 F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vxlan
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/F5Networks/k8s-bigip-ctlr/pkg/vlogger"
+	"github.com/F5Networks/k8s-bigip-ctlr/pkg/writer"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// Node annotations of the pod CIDRs allocated by the Cilium IPAM
+var ciliumPodCIDRAnnotations = []string{
+	"io.cilium.network.ipv4-pod-cidr",
+	"network.cilium.io/ipv4-pod-cidr",
+	"io.cilium.network.ipv6-pod-cidr",
+	"network.cilium.io/ipv6-pod-cidr",
+}
+
+type routeSection struct {
+	Routes []routeEntry `json:"routes"`
+}
+
+type routeEntry struct {
+	Name    string `json:"name"`
+	Network string `json:"network"`
+	Gateway string `json:"gw"`
+}
+
+// StaticRouteMgr maintains the static routes to the pod CIDRs of the nodes on
+// BIG-IP, so that the pods are reachable in cluster mode without VXLAN or BGP
+type StaticRouteMgr struct {
+	useNodeInt bool
+	// Node annotation with the comma separated pod CIDRs of a node,
+	// such as the IPAM blocks of Calico
+	cidrAnnotation string
+	config         writer.Writer
+}
+
+func NewStaticRouteMgr(
+	useNodeInternal bool,
+	cidrAnnotation string,
+	config writer.Writer,
+) (*StaticRouteMgr, error) {
+	if nil == config {
+		return nil, fmt.Errorf("required parameter ConfigWriter not supplied")
+	}
+
+	srMgr := &StaticRouteMgr{
+		useNodeInt:     useNodeInternal,
+		cidrAnnotation: cidrAnnotation,
+		config:         config,
+	}
+
+	return srMgr, nil
+}
+
+func (srm *StaticRouteMgr) ProcessNodeUpdate(obj interface{}, err error) {
+	if nil != err {
+		log.Warningf("[StaticRoute] Static route manager unable to get list of nodes: %v", err)
+		return
+	}
+
+	nodes, ok := obj.([]v1.Node)
+	if false == ok {
+		log.Warningf("[StaticRoute] Static route manager received poll update with unexpected type")
+		return
+	}
+
+	var addrType v1.NodeAddressType
+	if srm.useNodeInt {
+		addrType = v1.NodeInternalIP
+	} else {
+		addrType = v1.NodeExternalIP
+	}
+
+	routes := []routeEntry{}
+	for _, node := range nodes {
+		var gateway string
+		for _, addr := range node.Status.Addresses {
+			if addr.Type == addrType {
+				gateway = addr.Address
+				break
+			}
+		}
+		if gateway == "" {
+			continue
+		}
+		for _, cidr := range srm.getPodCIDRs(node) {
+			ip, network, err := net.ParseCIDR(cidr)
+			if nil != err {
+				log.Errorf("[StaticRoute] Invalid pod CIDR %v of node %v: %v", cidr, node.ObjectMeta.Name, err)
+				continue
+			}
+			// Routes of a family are only valid with a gateway of the same family
+			if (ip.To4() == nil) != (net.ParseIP(gateway).To4() == nil) {
+				continue
+			}
+			routes = append(routes, routeEntry{
+				Name:    routeName(network),
+				Network: network.String(),
+				Gateway: gateway,
+			})
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		return routes[i].Name < routes[j].Name
+	})
+
+	doneCh, errCh, err := srm.config.SendSection("static-routes", routeSection{Routes: routes})
+	if nil != err {
+		log.Warningf("[StaticRoute] Static route manager failed to write route config section: %v", err)
+		return
+	}
+	select {
+	case <-doneCh:
+		log.Debugf("[StaticRoute] Static route manager wrote config section: %v", routes)
+	case e := <-errCh:
+		log.Warningf("[StaticRoute] Static route manager failed to write config section: %v", e)
+	case <-time.After(5 * time.Second):
+		log.Warningf("[StaticRoute] Static route manager did not receive write response in 5s")
+	}
+}
+
+// getPodCIDRs returns the pod CIDRs of the node from the spec, the Cilium
+// annotations and the configured annotation
+func (srm *StaticRouteMgr) getPodCIDRs(node v1.Node) []string {
+	var cidrs []string
+	seen := make(map[string]bool)
+	add := func(cidr string) {
+		cidr = strings.TrimSpace(cidr)
+		if cidr != "" && !seen[cidr] {
+			seen[cidr] = true
+			cidrs = append(cidrs, cidr)
+		}
+	}
+
+	add(node.Spec.PodCIDR)
+	for _, cidr := range node.Spec.PodCIDRs {
+		add(cidr)
+	}
+	for _, annotation := range ciliumPodCIDRAnnotations {
+		add(node.ObjectMeta.Annotations[annotation])
+	}
+	if srm.cidrAnnotation != "" {
+		for _, cidr := range strings.Split(node.ObjectMeta.Annotations[srm.cidrAnnotation], ",") {
+			add(cidr)
+		}
+	}
+	return cidrs
+}
+
+// routeName returns the name of the route to a network, prefixed with the
+// owner prefix of the static routes managed by the controller
+func routeName(network *net.IPNet) string {
+	ones, _ := network.Mask.Size()
+	ip := strings.Replace(network.IP.String(), ":", ".", -1)
+	return fmt.Sprintf("%s%s_%d", routePrefix, ip, ones)
+}
@@ -0,0 +1,152 @@
+/*-
+ * Copyright (c) 2017-2021 F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vxlan
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/F5Networks/k8s-bigip-ctlr/pkg/test"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("StaticRouteMgr Tests", func() {
+	var mock *test.MockWriter
+
+	BeforeEach(func() {
+		mock = &test.MockWriter{
+			FailStyle: test.Success,
+			Sections:  make(map[string]interface{}),
+		}
+	})
+
+	It("is only created using proper arguments", func() {
+		srMgr, err := NewStaticRouteMgr(true, "", nil)
+		Expect(err).To(HaveOccurred())
+		Expect(srMgr).To(BeNil())
+
+		srMgr, err = NewStaticRouteMgr(true, "", mock)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(srMgr).ToNot(BeNil())
+	})
+
+	It("routes the pod CIDRs of the nodes", func() {
+		srMgr, _ := NewStaticRouteMgr(true, "example.com/pod-cidrs", mock)
+		nodes := []v1.Node{
+			*newNode("node1", "1", false, []v1.NodeAddress{
+				{Type: "InternalIP", Address: "10.1.1.1"}}, nil),
+			*newNode("node2", "2", false, []v1.NodeAddress{
+				{Type: "InternalIP", Address: "10.1.1.2"}}, map[string]string{
+				"io.cilium.network.ipv4-pod-cidr": "10.244.2.0/24",
+			}),
+			*newNode("node3", "3", false, []v1.NodeAddress{
+				{Type: "InternalIP", Address: "10.1.1.3"}}, map[string]string{
+				"example.com/pod-cidrs": "10.245.3.0/26, 10.245.3.64/26,invalid",
+			}),
+			// Nodes without the address are not routed
+			*newNode("node4", "4", false, []v1.NodeAddress{
+				{Type: "ExternalIP", Address: "127.0.0.4"}}, nil),
+		}
+		nodes[0].Spec.PodCIDR = "10.244.1.0/24"
+		nodes[0].Spec.PodCIDRs = []string{"10.244.1.0/24", "fd00:10:244:1::/64"}
+		nodes[3].Spec.PodCIDR = "10.244.4.0/24"
+
+		srMgr.ProcessNodeUpdate(nodes, nil)
+		section, ok := mock.Sections["static-routes"].(routeSection)
+		Expect(ok).To(BeTrue())
+		Expect(section.Routes).To(Equal([]routeEntry{
+			{Name: "k8s-bigip-ctlr-route-10.244.1.0_24", Network: "10.244.1.0/24", Gateway: "10.1.1.1"},
+			{Name: "k8s-bigip-ctlr-route-10.244.2.0_24", Network: "10.244.2.0/24", Gateway: "10.1.1.2"},
+			{Name: "k8s-bigip-ctlr-route-10.245.3.0_26", Network: "10.245.3.0/26", Gateway: "10.1.1.3"},
+			{Name: "k8s-bigip-ctlr-route-10.245.3.64_26", Network: "10.245.3.64/26", Gateway: "10.1.1.3"},
+		}))
+
+		// Routes are removed with the nodes
+		srMgr.ProcessNodeUpdate(nodes[1:2], nil)
+		section = mock.Sections["static-routes"].(routeSection)
+		Expect(section.Routes).To(HaveLen(1))
+		srMgr.ProcessNodeUpdate([]v1.Node{}, nil)
+		section = mock.Sections["static-routes"].(routeSection)
+		Expect(section.Routes).To(BeEmpty())
+	})
+
+	It("doesn't write the routes when node update call fails", func() {
+		srMgr, _ := NewStaticRouteMgr(false, "", mock)
+		srMgr.ProcessNodeUpdate(nil, nil)
+		srMgr.ProcessNodeUpdate(getNodeList(), http.ErrServerClosed)
+		Expect(mock.WrittenTimes).To(Equal(0))
+	})
+
+	It("syncs the static routes managed by the controller on BIG-IP", func() {
+		var requests []netRequest
+		var mutex sync.Mutex
+		getRequests := func() []netRequest {
+			mutex.Lock()
+			defer mutex.Unlock()
+			return append([]netRequest(nil), requests...)
+		}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			data, _ := ioutil.ReadAll(r.Body)
+			req := netRequest{method: r.Method, path: r.URL.Path}
+			_ = json.Unmarshal(data, &req.body)
+			mutex.Lock()
+			requests = append(requests, req)
+			mutex.Unlock()
+			if r.Method == http.MethodGet {
+				w.Write([]byte(`{"items": [
+					{"name": "k8s-bigip-ctlr-route-10.244.1.0_24", "network": "10.244.1.0/24", "gw": "10.1.1.1"},
+					{"name": "k8s-bigip-ctlr-route-10.244.2.0_24", "network": "10.244.2.0/24", "gw": "10.1.1.9"},
+					{"name": "k8s-bigip-ctlr-route-10.244.3.0_24", "network": "10.244.3.0/24", "gw": "10.1.1.3"},
+					{"name": "k8s-10.246.1.0_24", "network": "10.246.1.0/24", "gw": "10.1.1.5"},
+					{"name": "default", "network": "default", "gw": "10.1.1.254"}
+				]}`))
+				return
+			}
+			w.Write([]byte("{}"))
+		}))
+		defer server.Close()
+		nw := NewBigIPNetWriter(NetParams{BIGIPURL: server.URL, Partition: "test"})
+		defer nw.Stop()
+
+		doneCh, _, _ := nw.SendSection("static-routes", routeSection{
+			Routes: []routeEntry{
+				{Name: "k8s-bigip-ctlr-route-10.244.1.0_24", Network: "10.244.1.0/24", Gateway: "10.1.1.1"},
+				{Name: "k8s-bigip-ctlr-route-10.244.2.0_24", Network: "10.244.2.0/24", Gateway: "10.1.1.2"},
+				{Name: "k8s-bigip-ctlr-route-10.244.4.0_24", Network: "10.244.4.0/24", Gateway: "10.1.1.4"},
+			},
+		})
+		Expect(doneCh).To(Receive())
+		Eventually(getRequests).Should(HaveLen(4))
+		requests = getRequests()
+		Expect(requests[0].method).To(Equal(http.MethodGet))
+		Expect(requests[1].method).To(Equal(http.MethodPatch))
+		Expect(requests[1].path).To(Equal("/mgmt/tm/net/route/~test~k8s-bigip-ctlr-route-10.244.2.0_24"))
+		Expect(requests[1].body["gw"]).To(Equal("10.1.1.2"))
+		Expect(requests[2].method).To(Equal(http.MethodPost))
+		Expect(requests[2].body["partition"]).To(Equal("test"))
+		Expect(requests[2].body["network"]).To(Equal("10.244.4.0/24"))
+		// The routes not created by the controller are retained
+		Expect(requests[3].method).To(Equal(http.MethodDelete))
+		Expect(requests[3].path).To(Equal("/mgmt/tm/net/route/~test~k8s-bigip-ctlr-route-10.244.3.0_24"))
+	})
+})
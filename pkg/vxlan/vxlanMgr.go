@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/F5Networks/k8s-bigip-ctlr/pkg/resource"
@@ -31,9 +32,32 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
 )
 
+// CNIs of the VXLAN overlay, flannel and OpenShift SDN are used by default
+const (
+	CNICilium = "cilium"
+	CNICalico = "calico"
+)
+
+// Node annotations of the VTEP of Calico VXLAN
+const (
+	calicoIPv4AddressAnnotation = "projectcalico.org/IPv4Address"
+	calicoVtepMACAnnotation     = "projectcalico.org/VXLANTunnelMACAddr"
+)
+
+var ciliumNodeResource = schema.GroupVersionResource{
+	Group:    "cilium.io",
+	Version:  "v2",
+	Resource: "ciliumnodes",
+}
+
 type fdbSection struct {
 	TunnelName string      `json:"name"`
 	Records    []fdbRecord `json:"records"`
@@ -57,9 +81,15 @@ type arpEntry struct {
 type VxlanMgr struct {
 	mode       string
 	vxLAN      string
+	cni        string
 	useNodeInt bool
 	config     writer.Writer
 	podChan    <-chan interface{}
+	// dynamicClient lists the CiliumNodes for Cilium
+	dynamicClient dynamic.Interface
+	// ciliumNodeStore caches the CiliumNodes once their informer is created
+	ciliumNodeStore cache.Store
+	ciliumNodeLock  sync.Mutex
 }
 
 func NewVxlanMgr(
@@ -68,6 +98,19 @@ func NewVxlanMgr(
 	useNodeInternal bool,
 	config writer.Writer,
 	eventChan <-chan interface{},
+) (*VxlanMgr, error) {
+	return NewVxlanMgrForCNI(mode, vxLAN, "", useNodeInternal, config, eventChan, nil)
+}
+
+// NewVxlanMgrForCNI creates a VxlanMgr for the VXLAN overlay of the CNI
+func NewVxlanMgrForCNI(
+	mode string,
+	vxLAN string,
+	cni string,
+	useNodeInternal bool,
+	config writer.Writer,
+	eventChan <-chan interface{},
+	dynamicClient dynamic.Interface,
 ) (*VxlanMgr, error) {
 	if 0 == len(mode) {
 		return nil, fmt.Errorf("required parameter mode not supplied")
@@ -81,12 +124,24 @@ func NewVxlanMgr(
 		return nil, fmt.Errorf("unsupported mode supplied: %s", mode)
 	}
 
+	switch cni {
+	case "", CNICalico:
+	case CNICilium:
+		if nil == dynamicClient {
+			return nil, fmt.Errorf("required parameter dynamic client not supplied for %s", cni)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported CNI supplied: %s", cni)
+	}
+
 	vxMgr := &VxlanMgr{
-		mode:       mode,
-		vxLAN:      vxLAN,
-		useNodeInt: useNodeInternal,
-		config:     config,
-		podChan:    eventChan,
+		mode:          mode,
+		vxLAN:         vxLAN,
+		cni:           cni,
+		useNodeInt:    useNodeInternal,
+		config:        config,
+		podChan:       eventChan,
+		dynamicClient: dynamicClient,
 	}
 
 	return vxMgr, nil
@@ -114,6 +169,16 @@ func (vxm *VxlanMgr) ProcessNodeUpdate(obj interface{}, err error) {
 		addrType = v1.NodeExternalIP
 	}
 
+	var ciliumNodes map[string]string
+	if vxm.cni == CNICilium {
+		ciliumNodes, err = vxm.getCiliumNodeAddrs(addrType)
+		if nil != err {
+			log.Warningf("[VxLAN] Vxlan manager (%s) unable to get list of CiliumNodes: %v",
+				vxm.vxLAN, err)
+			return
+		}
+	}
+
 	for _, node := range nodes {
 		// Ignore the Nodes with status NotReady
 		var notExecutable bool
@@ -125,6 +190,19 @@ func (vxm *VxlanMgr) ProcessNodeUpdate(obj interface{}, err error) {
 		if notExecutable == true {
 			continue
 		}
+		switch vxm.cni {
+		case CNICilium:
+			// The VTEP of Cilium is the address of the CiliumNode
+			if addr, ok := ciliumNodes[node.ObjectMeta.Name]; ok {
+				records = append(records, fdbRecord{Name: ipv4ToMac(addr), Endpoint: addr})
+			}
+			continue
+		case CNICalico:
+			if rec, ok := calicoFDBRecord(node, addrType); ok {
+				records = append(records, rec)
+			}
+			continue
+		}
 		nodeAddrs := node.Status.Addresses
 		rec := fdbRecord{}
 		for _, addr := range nodeAddrs {
@@ -181,6 +259,88 @@ func (vxm *VxlanMgr) ProcessNodeUpdate(obj interface{}, err error) {
 	}
 }
 
+// NewCiliumNodeInformer creates an informer of the CiliumNodes, whose cache is
+// read in place of listing the CiliumNodes. It is registered as a trigger of the
+// node poller, so that the changes of the CiliumNodes update the fdb records
+func (vxm *VxlanMgr) NewCiliumNodeInformer() cache.SharedIndexInformer {
+	informer := dynamicinformer.NewFilteredDynamicInformer(
+		vxm.dynamicClient,
+		ciliumNodeResource,
+		metav1.NamespaceAll,
+		0,
+		cache.Indexers{},
+		nil,
+	).Informer()
+	vxm.ciliumNodeLock.Lock()
+	vxm.ciliumNodeStore = informer.GetStore()
+	vxm.ciliumNodeLock.Unlock()
+	return informer
+}
+
+// getCiliumNodeAddrs returns the addresses of the CiliumNodes by name
+func (vxm *VxlanMgr) getCiliumNodeAddrs(addrType v1.NodeAddressType) (map[string]string, error) {
+	var ciliumNodes []unstructured.Unstructured
+	vxm.ciliumNodeLock.Lock()
+	store := vxm.ciliumNodeStore
+	vxm.ciliumNodeLock.Unlock()
+	if store != nil {
+		for _, obj := range store.List() {
+			if ciliumNode, ok := obj.(*unstructured.Unstructured); ok {
+				ciliumNodes = append(ciliumNodes, *ciliumNode)
+			}
+		}
+	} else {
+		ciliumNodeList, err := vxm.dynamicClient.Resource(ciliumNodeResource).List(context.TODO(), metav1.ListOptions{})
+		if nil != err {
+			return nil, err
+		}
+		ciliumNodes = ciliumNodeList.Items
+	}
+	addrs := make(map[string]string)
+	for _, ciliumNode := range ciliumNodes {
+		if addr := getCiliumNodeAddr(ciliumNode, addrType); addr != "" {
+			addrs[ciliumNode.GetName()] = addr
+		}
+	}
+	return addrs, nil
+}
+
+// getCiliumNodeAddr returns the IPv4 address of the type from spec.addresses of the CiliumNode
+func getCiliumNodeAddr(ciliumNode unstructured.Unstructured, addrType v1.NodeAddressType) string {
+	addresses, _, _ := unstructured.NestedSlice(ciliumNode.Object, "spec", "addresses")
+	for _, address := range addresses {
+		addr, ok := address.(map[string]interface{})
+		if !ok || addr["type"] != string(addrType) {
+			continue
+		}
+		if ip, ok := addr["ip"].(string); ok && strings.Count(ip, ".") == 3 {
+			return ip
+		}
+	}
+	return ""
+}
+
+// calicoFDBRecord returns the fdb record of the VTEP of Calico VXLAN on the node
+func calicoFDBRecord(node v1.Node, addrType v1.NodeAddressType) (fdbRecord, bool) {
+	mac, ok := node.ObjectMeta.Annotations[calicoVtepMACAnnotation]
+	if !ok {
+		// VXLAN is not enabled on the node
+		return fdbRecord{}, false
+	}
+	rec := fdbRecord{Name: mac}
+	if ipv4, ok := node.ObjectMeta.Annotations[calicoIPv4AddressAnnotation]; ok {
+		// The annotation has the prefix length of the address
+		rec.Endpoint = strings.Split(ipv4, "/")[0]
+	} else {
+		for _, addr := range node.Status.Addresses {
+			if addr.Type == addrType {
+				rec.Endpoint = addr.Address
+			}
+		}
+	}
+	return rec, rec.Endpoint != ""
+}
+
 // Convert an IPV4 string to a fake MAC address.
 func ipv4ToMac(addr string) string {
 	ip := strings.Split(addr, ".")
@@ -228,6 +388,12 @@ func (vxm *VxlanMgr) SendEmptyArpBlockForCilium(kubePods *v1.PodList) {
 }
 func (vxm *VxlanMgr) addArpForPods(pods interface{}, kubeClient kubernetes.Interface) {
 	arps := arpSection{}
+	if vxm.cni == CNICilium {
+		// Cilium doesn't require static ARP entries
+		doneCh, errCh, err := vxm.config.SendSection("vxlan-arp", arps)
+		vxm.handleVxLANMgrChannel(doneCh, errCh, err, arps)
+		return
+	}
 	kubePods, err := kubeClient.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
 	if nil != err {
 		log.Errorf("[VxLAN] Vxlan Manager could not list Kubernetes Pods for ARP entries: %v", err)
@@ -241,7 +407,7 @@ func (vxm *VxlanMgr) addArpForPods(pods interface{}, kubeClient kubernetes.Inter
 	}
 	for _, pod := range pods.([]resource.Member) {
 		var mac string
-		mac, err = getVtepMac(pod, kubePods, kubeNodes)
+		mac, err = getVtepMac(pod, kubePods, kubeNodes, vxm.cni)
 		if nil != err {
 			log.Errorf("[VxLAN] %v", err)
 			return
@@ -284,12 +450,20 @@ func getVtepMac(
 	pod resource.Member,
 	kubePods *v1.PodList,
 	kubeNodes *v1.NodeList,
+	cni string,
 ) (string, error) {
 	for _, kPod := range kubePods.Items {
 		// Found the Pod with this address
 		if kPod.Status.PodIP == pod.Address {
 			// Get the Node for this Pod
 			for _, node := range kubeNodes.Items {
+				if cni == CNICalico {
+					if mac, ok := node.ObjectMeta.Annotations[calicoVtepMACAnnotation]; ok &&
+						node.ObjectMeta.Name == kPod.Spec.NodeName {
+						return mac, nil
+					}
+					continue
+				}
 				if _, ok := node.ObjectMeta.Annotations["flannel.alpha.coreos.com/public-ip"]; ok &&
 					node.ObjectMeta.Name == kPod.Spec.NodeName {
 					if mac, ok :=
@@ -28,7 +28,12 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
 )
 
 func newNode(
@@ -374,4 +379,142 @@ var _ = Describe("VxlanMgr Tests", func() {
 		}
 		Expect(section).To(Equal(expected))
 	})
+
+	It("is only created for the supported CNIs", func() {
+		mock := &test.MockWriter{
+			FailStyle: test.Success,
+			Sections:  make(map[string]interface{}),
+		}
+		vxMgr, err := NewVxlanMgrForCNI("maintain", "vxlan500", "weave", true, mock, nil, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(vxMgr).To(BeNil())
+
+		vxMgr, err = NewVxlanMgrForCNI("maintain", "vxlan500", CNICilium, true, mock, nil, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(vxMgr).To(BeNil())
+
+		vxMgr, err = NewVxlanMgrForCNI("maintain", "vxlan500", CNICalico, true, mock, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(vxMgr).ToNot(BeNil())
+	})
+
+	It("writes fdb records and arp entries for Calico", func() {
+		mock := &test.MockWriter{
+			FailStyle: test.Success,
+			Sections:  make(map[string]interface{}),
+		}
+		vxMgr, err := NewVxlanMgrForCNI("maintain", "vxlan500", CNICalico, true, mock, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		nodes := []v1.Node{
+			*newNode("node1", "1", false, []v1.NodeAddress{{Type: "InternalIP", Address: "10.1.1.1"}},
+				map[string]string{
+					"projectcalico.org/IPv4Address":        "10.2.2.1/24",
+					"projectcalico.org/VXLANTunnelMACAddr": "66:cd:f5:4a:3e:01",
+				}),
+			*newNode("node2", "2", false, []v1.NodeAddress{{Type: "InternalIP", Address: "10.1.1.2"}},
+				map[string]string{
+					"projectcalico.org/VXLANTunnelMACAddr": "66:cd:f5:4a:3e:02",
+				}),
+			// Nodes without VXLAN are skipped
+			*newNode("node3", "3", false, []v1.NodeAddress{{Type: "InternalIP", Address: "10.1.1.3"}}, nil),
+		}
+		vxMgr.ProcessNodeUpdate(nodes, nil)
+		section, ok := mock.Sections["vxlan-fdb"].(fdbSection)
+		Expect(ok).To(BeTrue())
+		Expect(section.Records).To(Equal([]fdbRecord{
+			{Name: "66:cd:f5:4a:3e:01", Endpoint: "10.2.2.1"},
+			{Name: "66:cd:f5:4a:3e:02", Endpoint: "10.1.1.2"},
+		}))
+
+		fakeClient := fake.NewSimpleClientset(&nodes[1], &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"},
+			Spec:       v1.PodSpec{NodeName: "node2"},
+			Status:     v1.PodStatus{PodIP: "192.168.2.5"},
+		})
+		vxMgr.addArpForPods([]resource.Member{{Address: "192.168.2.5"}}, fakeClient)
+		arps, ok := mock.Sections["vxlan-arp"].(arpSection)
+		Expect(ok).To(BeTrue())
+		Expect(arps.Entries).To(Equal([]arpEntry{
+			{Name: "k8s-192.168.2.5", IPAddr: "192.168.2.5", MACAddr: "66:cd:f5:4a:3e:02"},
+		}))
+	})
+
+	It("writes fdb records and no arp entries for Cilium", func() {
+		mock := &test.MockWriter{
+			FailStyle: test.Success,
+			Sections:  make(map[string]interface{}),
+		}
+		ciliumNode := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "cilium.io/v2",
+			"kind":       "CiliumNode",
+			"metadata":   map[string]interface{}{"name": "node1"},
+			"spec": map[string]interface{}{
+				"addresses": []interface{}{
+					map[string]interface{}{"type": "CiliumInternalIP", "ip": "10.0.1.55"},
+					map[string]interface{}{"type": "InternalIP", "ip": "10.1.1.1"},
+				},
+			},
+		}}
+		dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{ciliumNodeResource: "CiliumNodeList"}, ciliumNode)
+		vxMgr, err := NewVxlanMgrForCNI("maintain", "vxlan500", CNICilium, true, mock, nil, dynamicClient)
+		Expect(err).ToNot(HaveOccurred())
+
+		nodes := []v1.Node{
+			*newNode("node1", "1", false, []v1.NodeAddress{{Type: "InternalIP", Address: "10.1.1.1"}}, nil),
+			// Nodes without CiliumNode are skipped
+			*newNode("node2", "2", false, []v1.NodeAddress{{Type: "InternalIP", Address: "10.1.1.2"}}, nil),
+		}
+		vxMgr.ProcessNodeUpdate(nodes, nil)
+		section, ok := mock.Sections["vxlan-fdb"].(fdbSection)
+		Expect(ok).To(BeTrue())
+		Expect(section.Records).To(Equal([]fdbRecord{
+			{Name: "0a:0a:0a:01:01:01", Endpoint: "10.1.1.1"},
+		}))
+
+		vxMgr.addArpForPods([]resource.Member{{Address: "10.0.1.10"}}, fake.NewSimpleClientset())
+		arps, ok := mock.Sections["vxlan-arp"].(arpSection)
+		Expect(ok).To(BeTrue())
+		Expect(arps.Entries).To(BeEmpty())
+	})
+
+	It("reads the CiliumNodes from the informer cache", func() {
+		mock := &test.MockWriter{
+			FailStyle: test.Success,
+			Sections:  make(map[string]interface{}),
+		}
+		ciliumNode := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "cilium.io/v2",
+			"kind":       "CiliumNode",
+			"metadata":   map[string]interface{}{"name": "node1"},
+			"spec": map[string]interface{}{
+				"addresses": []interface{}{
+					map[string]interface{}{"type": "InternalIP", "ip": "10.1.1.1"},
+				},
+			},
+		}}
+		dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{ciliumNodeResource: "CiliumNodeList"}, ciliumNode)
+		vxMgr, err := NewVxlanMgrForCNI("maintain", "vxlan500", CNICilium, true, mock, nil, dynamicClient)
+		Expect(err).ToNot(HaveOccurred())
+
+		stopCh := make(chan struct{})
+		defer close(stopCh)
+		informer := vxMgr.NewCiliumNodeInformer()
+		go informer.Run(stopCh)
+		Expect(cache.WaitForCacheSync(stopCh, informer.HasSynced)).To(BeTrue())
+		listCount := len(dynamicClient.Actions())
+
+		nodes := []v1.Node{
+			*newNode("node1", "1", false, []v1.NodeAddress{{Type: "InternalIP", Address: "10.1.1.1"}}, nil),
+		}
+		vxMgr.ProcessNodeUpdate(nodes, nil)
+		section, ok := mock.Sections["vxlan-fdb"].(fdbSection)
+		Expect(ok).To(BeTrue())
+		Expect(section.Records).To(Equal([]fdbRecord{
+			{Name: "0a:0a:0a:01:01:01", Endpoint: "10.1.1.1"},
+		}))
+		Expect(dynamicClient.Actions()).To(HaveLen(listCount), "CiliumNodes should not be listed")
+	})
 })